@@ -8,7 +8,7 @@ import (
 	"github.com/b-thark/cdcx-api/pkg/coindcx"
 )
 
-func main() {
+func runAccount(opts *options, args []string) {
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
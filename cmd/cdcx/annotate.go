@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/inventory"
+	"github.com/b-thark/cdcx-api/internal/notes"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+const annotationsFile = "trade_annotations.json"
+
+func annotateUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cdcx annotate note <buy-order-id> <text...>")
+	fmt.Println("  cdcx annotate manual <buy-order-id> <pnl-inr> [<currency> <quantity>]")
+	fmt.Println("  cdcx annotate show <execution_log.json>")
+	os.Exit(1)
+}
+
+func runAnnotate(opts *options, args []string) {
+	if len(args) < 2 {
+		annotateUsage()
+	}
+
+	store := notes.NewStore(annotationsFile)
+	if err := store.Load(); err != nil {
+		fatal("❌ Error loading annotations", "error", err)
+	}
+
+	switch args[0] {
+	case "note":
+		if len(args) < 3 {
+			annotateUsage()
+		}
+		text := strings.Join(args[2:], " ")
+		if _, err := store.AddNote(args[1], text, operator()); err != nil {
+			fatal("❌ Error saving note", "error", err)
+		}
+		fmt.Printf("📝 Note added to %s\n", args[1])
+
+	case "manual":
+		markManual(store, args[1:])
+
+	case "show":
+		showAnnotations(store, args[1])
+
+	default:
+		annotateUsage()
+	}
+}
+
+// markManual flags a trade as resolved by hand; when the currency and
+// quantity sold are given, the held inventory is reduced to match so the
+// position isn't also counted as unrealized
+func markManual(store *notes.Store, args []string) {
+	if len(args) != 2 && len(args) != 4 {
+		annotateUsage()
+	}
+
+	pnl, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		fatal("❌ Invalid P&L", "pnl", args[1], "error", err)
+	}
+
+	if _, err := store.MarkManual(args[0], pnl); err != nil {
+		fatal("❌ Error saving annotation", "error", err)
+	}
+	fmt.Printf("✋ %s marked as manually resolved (P&L ₹%.2f)\n", args[0], pnl)
+
+	if len(args) == 4 {
+		quantity, err := strconv.ParseFloat(args[3], 64)
+		if err != nil {
+			fatal("❌ Invalid quantity", "quantity", args[3], "error", err)
+		}
+
+		book := inventory.NewBook("inventory.json")
+		if err := book.Load(); err != nil {
+			fatal("❌ Error loading inventory", "error", err)
+		}
+		if err := book.Reduce(args[2], quantity); err != nil {
+			fatal("❌ Error updating inventory", "error", err)
+		}
+		fmt.Printf("📦 Inventory reduced by %.6f %s\n", quantity, args[2])
+	}
+}
+
+func showAnnotations(store *notes.Store, filename string) {
+	var result types.ExecutionResult
+	if err := utils.LoadJSON(filename, &result); err != nil {
+		fatal("❌ Error loading execution log", "file", filename, "error", err)
+	}
+
+	logged := result.TotalProfit
+	store.Apply(&result)
+
+	fmt.Printf("📋 %s\n", filename)
+	for _, order := range result.Orders {
+		status := "✅"
+		if !order.Success {
+			status = "❌"
+		}
+		if order.Annotation != nil && order.Annotation.Manual {
+			status = "✋"
+		}
+
+		fmt.Printf("   %s %s %s: ₹%.2f\n", status, order.BuyOrderID, order.Currency, order.ActualProfit)
+		if order.Annotation != nil {
+			for _, note := range order.Annotation.Notes {
+				fmt.Printf("      📝 %s (%s, %s)\n", note.Text, note.Author, note.CreatedAt.Format("2006-01-02 15:04"))
+			}
+		}
+	}
+
+	fmt.Printf("💵 Logged profit: ₹%.2f\n", logged)
+	fmt.Printf("💵 Adjusted profit: ₹%.2f\n", result.TotalProfit)
+}
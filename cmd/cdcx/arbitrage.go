@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/arbitrage"
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+)
+
+func runArbitrage(opts *options, args []string) {
+	fmt.Println("🚀 CoinDCX Live Arbitrage Engine")
+	fmt.Println("================================")
+	fmt.Println("⚠️  LIVE TRADING MODE - REAL EXECUTION")
+	fmt.Println("🔍 Real-time depth analysis + immediate execution")
+
+	if profile := opts.profile; profile != nil {
+		fmt.Printf("🎛️ Profile: %s - %s\n", profile.Name, profile.Description)
+	}
+	cfg, paper := opts.credentials(), opts.paper()
+	execConfig := opts.execution
+
+	fmt.Printf("🏷️ Build %s\n", version.Current().Label())
+
+	// Create arbitrage engine
+	engine := arbitrage.NewEngine(cfg, execConfig, opts.transport)
+	engine.SetTradingConfig(opts.trading)
+	if paper {
+		fmt.Println("📝 DRY RUN - orders are simulated against live books")
+	}
+
+	// A sub-account keeps its own inventory, ledgers and logs, so its P&L stays separate
+	if stateDir := cfg.StateDir(); stateDir != "" {
+		if err := engine.SetStateDir(stateDir); err != nil {
+			fatal("❌ Error setting state directory", "error", err)
+		}
+		fmt.Printf("👤 Account: %s (state and logs in %s)\n", cfg.Account, stateDir)
+	}
+	defer notifyEvents(engine)()
+	confirmTrades(engine, execConfig.ConfirmTrades)
+
+	// Load opportunities from previous analysis
+	fmt.Println("\n📂 Loading arbitrage opportunities...")
+	opportunities, err := engine.LoadOpportunities(opts.path("arbitrage_opportunities.json"))
+	if err != nil {
+		fatal("❌ Error loading opportunities", "error", err, "hint", "Run opportunity detection first: cdcx detect")
+	}
+
+	// Filter viable opportunities
+	viableCount := 0
+	for _, opp := range opportunities {
+		if opp.Viable {
+			viableCount++
+		}
+	}
+
+	if viableCount == 0 {
+		fmt.Println("❌ No viable opportunities found for execution")
+		shortfall := opportunity.NewShortfall(opts.trading)
+		for _, opp := range opportunities {
+			shortfall.ObserveOpportunity(opp)
+		}
+		for _, suggestion := range shortfall.Suggestions() {
+			fmt.Printf("💡 %s\n", suggestion)
+		}
+		return
+	}
+
+	fmt.Printf("✅ Loaded %d viable opportunities\n", viableCount)
+
+	// Check account readiness
+	fmt.Println("\n🔍 Checking account status...")
+	ready, err := engine.CheckAccountReadiness()
+	if err != nil {
+		fatal("❌ Account check failed", "error", err)
+	}
+
+	if !ready {
+		fmt.Println("❌ Account not ready for execution")
+		return
+	}
+
+	fmt.Println("✅ Account ready for live trading")
+	resumeInterrupted(engine)
+
+	// Display execution plan
+	fmt.Println("\n📋 EXECUTION PLAN:")
+	fmt.Println("==================")
+	engine.DisplayExecutionPlan(opportunities)
+
+	// Execute live arbitrage with real-time depth analysis
+	fmt.Println("\n🚀 Starting live arbitrage execution...")
+	results, err := engine.Execute(opportunities)
+	if err != nil {
+		fatal("❌ Execution failed", "error", err)
+	}
+
+	// Display results
+	fmt.Println("\n📊 EXECUTION RESULTS:")
+	fmt.Println("====================")
+	engine.DisplayResults(results)
+
+	// Save execution log
+	filename := filepath.Join(cfg.StateDir(), fmt.Sprintf("execution_log_%d.json", results.Timestamp.Unix()))
+	err = engine.SaveExecutionLog(results, filename)
+	if err != nil {
+		logger.Warn("⚠️ Error saving execution log", "error", err)
+	} else {
+		fmt.Printf("\n💾 Execution log saved to %s\n", filename)
+	}
+
+	fmt.Println("\n🎯 Live arbitrage execution complete!")
+}
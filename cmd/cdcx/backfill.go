@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/candles"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/pairs"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+const candlesFile = "candles.json"
+
+func runBackfill(opts *options, args []string) {
+	fmt.Println("🕰️ CoinDCX Candle Backfill")
+	fmt.Println("==========================")
+
+	interval := backfillInterval()
+
+	limit := 1000
+	if value := os.Getenv("BACKFILL_LIMIT"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= 1000 {
+			limit = n
+		}
+	}
+
+	fetcher := opts.fetcher()
+	selected, err := selectPairs(fetcher, opts, args)
+	if err != nil {
+		fatal("❌ Error selecting markets", "error", err)
+	}
+	fmt.Printf("📊 Backfilling %d market(s), %d × %s candles each\n", len(selected), limit, interval)
+
+	store := candles.NewStore(candlesFile)
+	if err := store.Load(); err != nil {
+		fatal("❌ Error loading candles", "error", err)
+	}
+
+	failed := 0
+	for _, pair := range selected {
+		bars, err := fetcher.GetCandles(pair.Pair, interval, limit)
+		if err != nil {
+			logger.Warn("⚠️ Candles not fetched", "symbol", pair.Symbol, "error", err)
+			failed++
+			continue
+		}
+
+		added, err := store.Merge(pair.Symbol, interval, bars)
+		if err != nil {
+			fatal("❌ Error saving candles", "symbol", pair.Symbol, "error", err)
+		}
+
+		history := store.Get(pair.Symbol, interval)
+		fmt.Printf("   ✅ %s: +%d new (%d total), volatility %.2f%%/bar, avg range %.2f%%\n",
+			pair.Symbol, added, len(history), candles.Volatility(history), candles.AverageRange(history))
+	}
+
+	fmt.Printf("\n💾 Candle history saved to %s\n", candlesFile)
+	if failed > 0 {
+		fmt.Printf("⚠️ %d market(s) failed\n", failed)
+	}
+}
+
+// backfillInterval is the candle interval backfill fetches and scans seed from
+func backfillInterval() string {
+	if value := os.Getenv("BACKFILL_INTERVAL"); value != "" {
+		return value
+	}
+	return "1h"
+}
+
+// seedFromCandles primes each pair's quote sanity checks with its backfilled
+// history, so the first scan is checked instead of warming up
+func seedFromCandles(arbitragePairs map[string]types.ArbitragePairs, seed func(symbol string, history []types.Candle)) {
+	store := candles.NewStore(candlesFile)
+	if err := store.Load(); err != nil {
+		logger.Warn("⚠️ Candle history not loaded", "error", err)
+		return
+	}
+
+	interval := backfillInterval()
+	seeded := 0
+	for _, group := range arbitragePairs {
+		for _, pair := range group.Pairs {
+			if history := store.Get(pair.Symbol, interval); len(history) > 0 {
+				seed(pair.Symbol, history)
+				seeded++
+			}
+		}
+	}
+	if seeded > 0 {
+		fmt.Printf("🕰️ Price checks seeded from %s candles for %d market(s)\n", interval, seeded)
+	}
+}
+
+// selectPairs resolves the requested symbols (e.g. BTCUSDT), or every pair in
+// arbitrage_pairs.json when none are given
+func selectPairs(fetcher *market.Fetcher, opts *options, symbols []string) ([]types.PairInfo, error) {
+	if len(symbols) == 0 {
+		arbitragePairs, err := pairs.NewAnalyzer(opts.trading).LoadPairs(opts.path("arbitrage_pairs.json"))
+		if err != nil {
+			return nil, fmt.Errorf("error loading pairs: %v\n💡 Pass symbols or run pair detection first: cdcx pairs", err)
+		}
+
+		selected := []types.PairInfo{}
+		for _, group := range arbitragePairs {
+			selected = append(selected, group.Pairs...)
+		}
+		return selected, nil
+	}
+
+	markets, err := fetcher.GetMarketDetails()
+	if err != nil {
+		return nil, fmt.Errorf("error loading markets: %v", err)
+	}
+
+	bySymbol := make(map[string]types.MarketDetail, len(markets))
+	for _, m := range markets {
+		bySymbol[m.Symbol] = m
+	}
+
+	selected := []types.PairInfo{}
+	for _, symbol := range symbols {
+		m, ok := bySymbol[strings.ToUpper(symbol)]
+		if !ok {
+			return nil, fmt.Errorf("unknown market %s", symbol)
+		}
+		selected = append(selected, types.PairInfo{
+			Symbol:         m.Symbol,
+			Pair:           m.Pair,
+			BaseCurrency:   m.BaseCurrencyShortName,
+			TargetCurrency: m.TargetCurrencyShortName,
+		})
+	}
+	return selected, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/backtest"
+	"github.com/b-thark/cdcx-api/pkg/pairs"
+)
+
+const backtestFile = "backtest_results.json"
+
+func backtestUsage() {
+	fmt.Println("Usage: cdcx backtest [--margins=1,2,3] [--stop-loss=1,2,3] [--latency=250ms] [--fill-share=0.5] [--slippage=0.05] [<recording>]")
+	fmt.Println("  Replay a recording (cdcx record) through detection and a simulated execution per")
+	fmt.Println("  min net margin × stop loss combination, and report the P&L of each. The recording")
+	fmt.Println("  defaults to " + recordingFile + "; every trade is saved to " + backtestFile)
+	os.Exit(1)
+}
+
+func runBacktest(opts *options, args []string) {
+	margins := []float64{opts.trading.MinNetMargin}
+	stopLosses := []float64{opts.execution.StopLossPct}
+	model := backtest.DefaultModel()
+	file := opts.path(recordingFile)
+
+	for _, arg := range args {
+		var err error
+		switch {
+		case strings.HasPrefix(arg, "--margins="):
+			margins, err = parseFloats(strings.TrimPrefix(arg, "--margins="))
+		case strings.HasPrefix(arg, "--stop-loss="):
+			stopLosses, err = parseFloats(strings.TrimPrefix(arg, "--stop-loss="))
+		case strings.HasPrefix(arg, "--latency="):
+			model.Latency, err = time.ParseDuration(strings.TrimPrefix(arg, "--latency="))
+		case strings.HasPrefix(arg, "--fill-share="):
+			model.FillShare = parseFloat(strings.TrimPrefix(arg, "--fill-share="))
+		case strings.HasPrefix(arg, "--slippage="):
+			model.SlippagePct = parseFloat(strings.TrimPrefix(arg, "--slippage="))
+		case strings.HasPrefix(arg, "--"):
+			backtestUsage()
+		default:
+			file = arg
+		}
+		if err != nil {
+			fatal("❌ Invalid argument", "arg", arg, "error", err)
+		}
+	}
+
+	recording := loadRecording(file)
+	arbitragePairs, err := pairs.NewAnalyzer(opts.trading).LoadPairs(opts.path("arbitrage_pairs.json"))
+	if err != nil {
+		fatal("❌ Error loading pairs", "error", err, "hint", "Run pair detection first: cdcx pairs")
+	}
+
+	fmt.Printf("🧪 %d combinations, %s latency per leg, %.0f%% of each level, %.2f%% slippage, $%.0f positions\n",
+		len(margins)*len(stopLosses), model.Latency, model.FillShare*100, model.SlippagePct, opts.execution.MaxPositionUSDT)
+
+	// Every frame logs each pair it prices; only the report is worth reading
+	unmute := logging.Mute()
+	results := backtest.NewBacktester(recording, arbitragePairs, model).Sweep(opts.trading, opts.execution, margins, stopLosses)
+	unmute()
+
+	fmt.Println("\n| Min margin % | Stop loss % | Detected | Executed | Partial | Wins | Losses | P&L ₹ | Best ₹ | Worst ₹ | Max drawdown ₹ |")
+	fmt.Println("|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|")
+	for _, r := range results {
+		fmt.Printf("| %.2f | %.2f | %d | %d | %d | %d | %d | %.2f | %.2f | %.2f | %.2f |\n", r.MinNetMargin, r.StopLossPct,
+			r.Detected, r.Executed, r.Partial, r.Wins, r.Losses, r.ProfitINR, r.BestTradeINR, r.WorstTradeINR, r.MaxDrawdownINR)
+	}
+
+	if err := utils.SaveJSON(results, opts.path(backtestFile)); err != nil {
+		fatal("❌ Error saving results", "error", err)
+	}
+	fmt.Printf("\n💾 Trades saved to %s\n", opts.path(backtestFile))
+	fmt.Println("💡 Detected opportunities not executed were refused at the buy or found a trade in flight")
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/bundle"
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/internal/notes"
+	"github.com/b-thark/cdcx-api/internal/report"
+	"github.com/b-thark/cdcx-api/internal/toggles"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Files a run leaves behind, by where they are written
+var (
+	// Pipeline files, in the output directory; the inputs are bundled
+	// however old they are, recordings and logs only from the period
+	pipelineInputs    = []string{"arbitrage_pairs.json", "arbitrage_opportunities.json", "depth_analysis.json"}
+	pipelineArtifacts = []string{"triangular_opportunities.json", snapshotFile, samplesFile, "execution_log_*.json"}
+	// Execution logs, queue and ledgers, in the account's state directory;
+	// a dry run keeps its ledgers in the paper subdirectory
+	stateArtifacts  = []string{"execution_log_*.json", pendingQueueFile, divergenceFile}
+	ledgerArtifacts = []string{"inventory.json", "dust_ledger.json", "audit_trail.jsonl"}
+	// Shared by every account, in the working directory
+	sharedInputs = []string{togglesFile, annotationsFile}
+)
+
+func bundleUsage() {
+	fmt.Println("Usage: cdcx bundle [--since=2h|YYYY-MM-DD] [<archive.tar.gz>]")
+	fmt.Println("  Packs the effective config, pipeline files, order book recordings, execution")
+	fmt.Println("  logs, a strategy report and account state into one archive with a manifest")
+	fmt.Println("  --since limits recordings, logs and state to that period; pairs, opportunities")
+	fmt.Println("  and toggles the run read are always included")
+	fmt.Println("  The archive defaults to " + bundle.Filename(time.Now()) + " in the output directory")
+	os.Exit(1)
+}
+
+func runBundle(opts *options, args []string) {
+	var since time.Time
+	archive := ""
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--since="):
+			value := strings.TrimPrefix(arg, "--since=")
+			if ago, err := time.ParseDuration(value); err == nil {
+				since = time.Now().Add(-ago)
+			} else if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+				since = day
+			} else {
+				fatal("❌ Invalid --since: a duration (2h) or a date (YYYY-MM-DD)", "value", value)
+			}
+		case strings.HasPrefix(arg, "--"):
+			bundleUsage()
+		default:
+			archive = arg
+		}
+	}
+	if archive == "" {
+		archive = opts.path(bundle.Filename(time.Now()))
+	}
+
+	manifest, err := writeBundle(opts, since, archive)
+	if err != nil {
+		fatal("❌ Error writing bundle", "error", err)
+	}
+	for _, entry := range manifest.Files {
+		fmt.Printf("   %-40s %8d bytes\n", entry.Name, entry.Size)
+	}
+	fmt.Printf("📦 Bundled %d files into %s\n", len(manifest.Files), archive)
+}
+
+// bundleRun archives the artifacts of a run that started at started into dir
+func bundleRun(opts *options, started time.Time, dir string) {
+	archive := filepath.Join(dir, bundle.Filename(time.Now()))
+	manifest, err := writeBundle(opts, started, archive)
+	if err != nil {
+		logger.Warn("⚠️ Could not bundle the run", "error", err)
+		return
+	}
+	fmt.Printf("📦 Bundled %d files of this run into %s\n", len(manifest.Files), archive)
+}
+
+// writeBundle archives the artifacts modified since (all when zero): the
+// effective config, the pipeline files in the output directory, the
+// account's state, and a strategy report over the bundled execution logs
+func writeBundle(opts *options, since time.Time, archive string) (*bundle.Manifest, error) {
+	stateDir := ""
+	if opts.api != nil {
+		stateDir = opts.api.StateDir()
+	}
+
+	b := bundle.New(since)
+	if err := b.AddJSON("config.json", config.NewEffective(opts.api, opts.file, opts.profile, opts.trading, opts.execution, toggles.NewStore(togglesFile))); err != nil {
+		return nil, err
+	}
+	if err := b.AddInputs("pipeline", opts.outDir, pipelineInputs...); err != nil {
+		return nil, err
+	}
+	if err := b.AddFiles("pipeline", opts.outDir, pipelineArtifacts...); err != nil {
+		return nil, err
+	}
+	if err := b.AddFiles("state", stateDir, append(stateArtifacts, ledgerArtifacts...)...); err != nil {
+		return nil, err
+	}
+	if err := b.AddFiles("state/paper", filepath.Join(stateDir, "paper"), ledgerArtifacts...); err != nil {
+		return nil, err
+	}
+	if err := b.AddInputs("shared", "", append(sharedInputs, opts.trading.RateCacheFile)...); err != nil {
+		return nil, err
+	}
+
+	if summaries := bundledReport(opts.outDir, stateDir, since); len(summaries) > 0 {
+		var out bytes.Buffer
+		report.WriteMarkdown(&out, summaries)
+		b.AddData("report.md", out.Bytes())
+	}
+
+	return b.Write(archive)
+}
+
+// bundledReport summarizes the execution logs in both directories by
+// strategy, with manual resolutions applied, over the bundled period
+func bundledReport(outDir, stateDir string, since time.Time) []report.Summary {
+	patterns := []string{filepath.Join(outDir, "execution_log_*.json")}
+	if stateDir != outDir {
+		patterns = append(patterns, filepath.Join(stateDir, "execution_log_*.json"))
+	}
+	results, err := report.LoadLogs(patterns...)
+	if err != nil {
+		logger.Warn("⚠️ Bundle report skipped", "error", err)
+		return nil
+	}
+
+	store := notes.NewStore(annotationsFile)
+	if err := store.Load(); err != nil {
+		logger.Warn("⚠️ Bundle report without annotations", "error", err)
+	}
+	groups := make(map[string][]types.ExecutionResult)
+	for i := range results {
+		store.Apply(&results[i])
+		label := report.StrategyLabel(results[i].Config)
+		groups[label] = append(groups[label], results[i])
+	}
+
+	labels := make([]string, 0, len(groups))
+	for label := range groups {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	summaries := []report.Summary{}
+	for _, label := range labels {
+		if summary := report.Summarize(label, groups[label], since, time.Time{}); summary.Trades > 0 {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/internal/toggles"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func configUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cdcx config explain            Document every parameter")
+	fmt.Println("  cdcx config show [--json]      Effective values with where each came from")
+	fmt.Println("                                 (honours --config, --profile, --account, --env-file)")
+	fmt.Println("  cdcx config validate [<file>]  Check a config file's parameters and profiles")
+	fmt.Println("                                 (default: --config, else config.yaml)")
+	os.Exit(1)
+}
+
+func runConfig(opts *options, args []string) {
+	if len(args) < 1 {
+		configUsage()
+	}
+
+	switch args[0] {
+	case "explain":
+		explain()
+	case "show":
+		showConfig(len(args) > 1 && args[1] == "--json")
+	case "validate":
+		validateConfig(args[1:])
+	default:
+		configUsage()
+	}
+}
+
+// showConfig resolves the configuration the way an engine started now would:
+// defaults, then the config file, then the selected profile, then
+// environment overrides
+func showConfig(asJSON bool) {
+	cfg, loadErr := config.Load()
+
+	trading, execution := types.DefaultConfig(), types.DefaultExecutionConfig()
+	file, err := config.ApplySelectedFile(trading, execution)
+	if err != nil {
+		fatal("❌ Error loading config file", "error", err)
+	}
+	profile, err := config.ApplySelectedProfile(file, trading, execution)
+	if err != nil {
+		fatal("❌ Error applying profile", "error", err)
+	}
+	cfg, loadErr = config.UseProfileAccount(cfg, loadErr, profile)
+	envErrs := config.ApplyEnvOverrides(trading, execution)
+
+	effective := config.NewEffective(cfg, file, profile, trading, execution, toggles.NewStore(togglesFile))
+	if asJSON {
+		out, err := json.MarshalIndent(effective, "", "  ")
+		if err != nil {
+			fatal("❌ Error encoding configuration", "error", err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Println("⚙️  Effective Configuration")
+	fmt.Println("==========================")
+	if effective.Profile != "" {
+		fmt.Printf("🎛️ Profile: %s\n", effective.Profile)
+	}
+	if effective.Account != "" {
+		fmt.Printf("👤 Account: %s\n", effective.Account)
+	}
+	if effective.EnvFile != "" {
+		fmt.Printf("📄 Env file: %s\n", effective.EnvFile)
+	}
+	if effective.ConfigFile != "" {
+		fmt.Printf("📄 Config file: %s\n", effective.ConfigFile)
+	}
+	if loadErr != nil {
+		fmt.Printf("⚠️ Credentials: %v\n", loadErr)
+	}
+	for _, err := range envErrs {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+
+	section := ""
+	for _, setting := range effective.Settings {
+		if setting.Section != section {
+			section = setting.Section
+			fmt.Printf("\n[%s]\n", section)
+		}
+		source := setting.Source
+		if setting.Origin != "" {
+			source += " " + setting.Origin
+		}
+		fmt.Printf("  %-26s %-20s ← %s\n", setting.Name, setting.Value, source)
+	}
+
+	fmt.Println("\n[runtime]")
+	if len(effective.Disabled) == 0 {
+		fmt.Println("  all currencies enabled")
+	}
+	for _, toggle := range effective.Disabled {
+		fmt.Printf("  %-26s disabled since %s\n", toggle.Currency, toggle.DisabledAt.Format("2006-01-02 15:04"))
+	}
+}
+
+// validateConfig checks a config file without running anything: unknown
+// parameters, values the engines would refuse and profiles that don't resolve
+func validateConfig(args []string) {
+	var (
+		file *config.File
+		err  error
+	)
+	switch {
+	case len(args) > 1 || (len(args) == 1 && strings.HasPrefix(args[0], "--")):
+		configUsage()
+	case len(args) == 1:
+		file, err = config.LoadFile(args[0])
+	default:
+		file, err = config.SelectedFile()
+		if file == nil && err == nil {
+			fatal("❌ No config file found", "searched", strings.Join(config.ConfigFileCandidates(), ", "))
+		}
+	}
+	if err != nil {
+		fatal("❌ Error loading config file", "error", err)
+	}
+
+	errs := file.Validate()
+	for _, err := range errs {
+		fmt.Printf("❌ %v\n", err)
+	}
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ %s is valid\n", file.Path)
+	if names := file.ProfileNames(); len(names) > 0 {
+		fmt.Printf("🎛️ Profiles: %s\n", strings.Join(names, ", "))
+	}
+	if file.Profile != "" {
+		fmt.Printf("🎛️ Default profile: %s\n", file.Profile)
+	}
+}
+
+// explain prints every parameter documented on the config structs
+func explain() {
+	fmt.Println("⚙️  Configuration Reference")
+	fmt.Println("==========================")
+
+	section := ""
+	for _, doc := range types.ConfigReference() {
+		if doc.Section != section {
+			section = doc.Section
+			fmt.Printf("\n[%s]\n", section)
+		}
+
+		fmt.Printf("  %-26s %-14s default: %s\n", doc.Name, doc.Type, doc.Default)
+		if doc.Env != "" {
+			fmt.Printf("  %-26s env: %s\n", "", doc.Env)
+		}
+		fmt.Printf("  %-26s %s\n", "", doc.Description)
+	}
+}
@@ -2,30 +2,29 @@ package main
 
 import (
 	"fmt"
-	"log"
 
+	"github.com/b-thark/cdcx-api/internal/version"
 	"github.com/b-thark/cdcx-api/pkg/depth"
 	"github.com/b-thark/cdcx-api/pkg/opportunity"
-	"github.com/b-thark/cdcx-api/pkg/types"
 )
 
-func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+func runDepth(opts *options, args []string) {
+	replay := replayFlag(args, "depth")
 
 	fmt.Println("🔬 CoinDCX Order Book Depth Analyzer")
 	fmt.Println("====================================")
 	fmt.Println("⚠️  ANALYSIS MODE - NO EXECUTION")
 	fmt.Println("🔍 Deep diving into profitable arbitrage opportunities...")
 
-	// Load configuration
-	config := types.DefaultConfig()
+	config := opts.trading
+	fmt.Printf("🏷️ Build %s\n", version.Current().Label())
 
 	// Load opportunities from previous analysis
 	fmt.Println("\n📂 Loading arbitrage opportunities...")
 	oppDetector := opportunity.NewDetector(config)
-	opportunities, err := oppDetector.LoadOpportunities("arbitrage_opportunities.json")
+	opportunities, err := oppDetector.LoadOpportunities(opts.path("arbitrage_opportunities.json"))
 	if err != nil {
-		log.Fatalf("❌ Error loading opportunities: %v\n💡 Run opportunity detector first: go run cmd/opportunity-detector/main.go", err)
+		fatal("❌ Error loading opportunities", "error", err, "hint", "Run opportunity detection first: cdcx detect")
 	}
 
 	// Count viable opportunities
@@ -44,24 +43,28 @@ func main() {
 		return
 	}
 
-	// Create depth analyzer
+	// Create depth analyzer, on the recorded books each opportunity was found in when replaying
 	analyzer := depth.NewAnalyzer(config)
+	if replay != "" {
+		analyzer = depth.NewReplayAnalyzer(config, loadRecording(replay))
+	}
+	analyzer.SetTransport(opts.transport)
 
 	// Analyze depth
 	fmt.Println("\n🔍 Analyzing order book depth...")
 	analyses, err := analyzer.AnalyzeDepth(opportunities)
 	if err != nil {
-		log.Fatalf("❌ Error analyzing depth: %v", err)
+		fatal("❌ Error analyzing depth", "error", err)
 	}
 
 	// Display results
 	analyzer.DisplayResults(analyses)
 
 	// Save detailed analysis
-	filename := "depth_analysis.json"
+	filename := opts.path("depth_analysis.json")
 	err = analyzer.SaveAnalyses(analyses, filename)
 	if err != nil {
-		log.Fatalf("❌ Error saving analysis: %v", err)
+		fatal("❌ Error saving analysis", "error", err)
 	}
 
 	fmt.Printf("\n💾 Saved detailed depth analysis to %s\n", filename)
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+	"github.com/b-thark/cdcx-api/pkg/pairs"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// replayFlag returns the recording a pipeline command's --replay=<file>
+// names, empty for the live exchange
+func replayFlag(args []string, command string) string {
+	replay := ""
+	for _, arg := range args {
+		if file, ok := strings.CutPrefix(arg, "--replay="); ok && file != "" {
+			replay = file
+		} else {
+			fmt.Printf("Usage: cdcx %s [--replay=<recording>]\n", command)
+			os.Exit(1)
+		}
+	}
+	return replay
+}
+
+func runDetect(opts *options, args []string) {
+	replay := replayFlag(args, "detect")
+
+	fmt.Println("🚀 CoinDCX Arbitrage Opportunity Detector")
+	fmt.Println("=========================================")
+	fmt.Println("💡 Analyzing real-time prices for arbitrage opportunities")
+
+	config := opts.trading
+	fmt.Printf("🏷️ Build %s\n", version.Current().Label())
+
+	// Load arbitrage pairs
+	fmt.Println("\n📂 Loading arbitrage pairs...")
+	pairAnalyzer := pairs.NewAnalyzer(config)
+	arbitragePairs, err := pairAnalyzer.LoadPairs(opts.path("arbitrage_pairs.json"))
+	if err != nil {
+		fatal("❌ Error loading pairs", "error", err, "hint", "Run pair detection first: cdcx pairs")
+	}
+
+	fmt.Printf("✅ Loaded %d currencies with arbitrage potential\n", len(arbitragePairs))
+
+	// Create opportunity detector
+	detector := opportunity.NewDetector(config)
+	detector.SetTransport(opts.transport)
+	seedFromCandles(arbitragePairs, detector.Seed)
+
+	// Find opportunities
+	fmt.Println("\n🔍 Analyzing arbitrage opportunities...")
+	var opportunities []types.ArbitrageOpportunity
+	if replay != "" {
+		// Every frame in turn, each opportunity stamped with its frame's time
+		for _, frame := range loadRecording(replay).Frames {
+			detector = opportunity.NewSnapshotDetector(config, frame)
+			found, err := detector.FindOpportunities(arbitragePairs)
+			if err != nil {
+				fatal("❌ Error finding opportunities", "error", err)
+			}
+			opportunities = append(opportunities, found...)
+		}
+	} else {
+		opportunities, err = detector.FindOpportunities(arbitragePairs)
+		if err != nil {
+			fatal("❌ Error finding opportunities", "error", err)
+		}
+	}
+
+	// Display results
+	detector.DisplayResults(opportunities)
+
+	// Save opportunities to file
+	filename := opts.path("arbitrage_opportunities.json")
+	err = detector.SaveOpportunities(opportunities, filename)
+	if err != nil {
+		fatal("❌ Error saving opportunities", "error", err)
+	}
+
+	fmt.Printf("\n💾 Saved opportunities to %s\n", filename)
+	fmt.Printf("🔬 Ready for depth analysis! Run: cdcx depth\n")
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// maxClockSkew is the drift beyond which signed requests start getting rejected
+const maxClockSkew = 5 * time.Second
+
+// check is a single preflight result with a fix to show when it fails
+type check struct {
+	name   string
+	ok     bool
+	warn   bool
+	detail string
+	fix    string
+}
+
+func runDoctor(opts *options, args []string) {
+	fmt.Println("🩺 CoinDCX Preflight Check")
+	fmt.Println("=========================")
+
+	checks := []check{}
+
+	cfg, cfgCheck := checkConfig()
+	checks = append(checks, cfgCheck)
+
+	checks = append(checks, checkHost("API host", "https://api.coindcx.com/exchange/ticker"))
+	checks = append(checks, checkHost("Public host", "https://public.coindcx.com/market_data/orderbook?pair=B-BTC_USDT"))
+	checks = append(checks, checkClockSkew())
+	checks = append(checks, checkDataDir())
+
+	if cfg != nil {
+		client := coindcx.NewClient(cfg.APIKey, cfg.APISecret)
+		authCheck := checkAuth(client)
+		checks = append(checks, authCheck)
+
+		if authCheck.ok {
+			checks = append(checks, checkOrderAccess(client))
+			checks = append(checks, checkBalance(client))
+		}
+	}
+
+	failures := 0
+	for _, c := range checks {
+		switch {
+		case c.ok:
+			fmt.Printf("✅ %-16s %s\n", c.name, c.detail)
+		case c.warn:
+			fmt.Printf("⚠️  %-16s %s\n", c.name, c.detail)
+			fmt.Printf("   💡 %s\n", c.fix)
+		default:
+			failures++
+			fmt.Printf("❌ %-16s %s\n", c.name, c.detail)
+			fmt.Printf("   💡 %s\n", c.fix)
+		}
+	}
+
+	fmt.Println()
+	if failures > 0 {
+		fmt.Printf("❌ %d check(s) failed - fix them before a live run\n", failures)
+		os.Exit(1)
+	}
+	fmt.Println("🎯 All checks passed - ready for live trading")
+}
+
+func checkConfig() (*config.Config, check) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, check{
+			name:   "Config",
+			detail: err.Error(),
+			fix:    "Export COINDCX_API_KEY/COINDCX_API_SECRET or create .env (see --env-file, ~/.config/cdcx/.env)",
+		}
+	}
+
+	source := cfg.EnvFile
+	if source == "" {
+		source = "environment"
+	}
+	return cfg, check{name: "Config", ok: true, detail: fmt.Sprintf("credentials loaded from %s", source)}
+}
+
+func checkHost(name, url string) check {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return check{
+			name:   name,
+			detail: err.Error(),
+			fix:    "Check network connectivity, DNS and any proxy/firewall blocking CoinDCX",
+		}
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return check{
+			name:   name,
+			detail: fmt.Sprintf("status %d from %s", resp.StatusCode, url),
+			fix:    "The endpoint may be down or rate limiting this IP - retry in a few minutes",
+		}
+	}
+
+	return check{name: name, ok: true, detail: fmt.Sprintf("reachable (%dms)", time.Since(start).Milliseconds())}
+}
+
+func checkClockSkew() check {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	resp, err := client.Head("https://api.coindcx.com/exchange/ticker")
+	if err != nil {
+		return check{name: "Clock", warn: true, detail: "could not reach server to compare clocks", fix: "Re-run once connectivity is restored"}
+	}
+	resp.Body.Close()
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return check{name: "Clock", warn: true, detail: "server did not return a Date header", fix: "Verify the system clock is NTP-synchronised"}
+	}
+
+	// Date has one-second resolution, so allow for that on top of the limit
+	skew := time.Since(serverTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxClockSkew+time.Second {
+		return check{
+			name:   "Clock",
+			detail: fmt.Sprintf("local clock is %s off server time", skew.Round(time.Second)),
+			fix:    "Enable NTP (e.g. `timedatectl set-ntp true`) - signed requests carry a timestamp",
+		}
+	}
+
+	return check{name: "Clock", ok: true, detail: fmt.Sprintf("within %s of server time", skew.Round(time.Second))}
+}
+
+func checkDataDir() check {
+	dir, err := os.Getwd()
+	if err != nil {
+		return check{name: "Data dir", detail: err.Error(), fix: "Run from a directory you own"}
+	}
+
+	probe := filepath.Join(dir, ".cdcx_doctor_probe")
+	if err := os.WriteFile(probe, []byte("ok"), 0644); err != nil {
+		return check{
+			name:   "Data dir",
+			detail: fmt.Sprintf("%s is not writable: %v", dir, err),
+			fix:    "Execution logs, rate cache and the pending queue are written here - run from a writable directory",
+		}
+	}
+	os.Remove(probe)
+
+	return check{name: "Data dir", ok: true, detail: fmt.Sprintf("%s is writable", dir)}
+}
+
+func checkAuth(client *coindcx.Client) check {
+	userInfo, err := client.GetUserInfo()
+	if err != nil {
+		fix := "Verify the API key/secret pair in CoinDCX → API Dashboard"
+		if strings.Contains(err.Error(), "401") {
+			fix = "Key rejected - regenerate the key and make sure the secret was copied in full"
+		}
+		return check{name: "API auth", detail: err.Error(), fix: fix}
+	}
+
+	return check{name: "API auth", ok: true, detail: fmt.Sprintf("authenticated as %s", userInfo.CoinDCXID)}
+}
+
+// checkOrderAccess confirms the key can read orders; trade permission itself
+// cannot be verified without placing an order
+func checkOrderAccess(client *coindcx.Client) check {
+	if _, err := client.GetActiveOrders("BTCUSDT"); err != nil {
+		return check{
+			name:   "Permissions",
+			detail: fmt.Sprintf("cannot read orders: %v", err),
+			fix:    "Enable trading permissions for this key and whitelist this IP if IP binding is on",
+		}
+	}
+
+	return check{name: "Permissions", ok: true, detail: "order endpoints accessible (trade permission is not verified)"}
+}
+
+func checkBalance(client *coindcx.Client) check {
+	execConfig := types.DefaultExecutionConfig()
+
+	balances, err := client.GetBalances()
+	if err != nil {
+		return check{name: "Balance", detail: err.Error(), fix: "Retry - balance endpoint failed after auth succeeded"}
+	}
+
+	usdt := 0.0
+	for _, balance := range balances {
+		if balance.Currency == "USDT" {
+			usdt = balance.Balance
+		}
+	}
+
+	if usdt < execConfig.MinRequiredUSDT {
+		return check{
+			name:   "Balance",
+			detail: fmt.Sprintf("USDT %.2f below required %.2f", usdt, execConfig.MinRequiredUSDT),
+			fix:    "Deposit USDT or convert INR before running live execution",
+		}
+	}
+
+	return check{name: "Balance", ok: true, detail: fmt.Sprintf("USDT %.2f available", usdt)}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/arbitrage"
+)
+
+func runExecute(opts *options, args []string) {
+	fmt.Println("🚀 CoinDCX Arbitrage Executor")
+	fmt.Println("=============================")
+	fmt.Println("⚠️  LIVE TRADING MODE - REAL EXECUTION")
+	fmt.Println("💰 Executing profitable arbitrage opportunities...")
+
+	cfg, execConfig := opts.credentials(), opts.execution
+	if opts.paper() {
+		fmt.Println("📝 DRY RUN - orders are simulated against live books")
+	}
+	fmt.Printf("🏷️ Build %s\n", version.Current().Label())
+
+	// Analyses execute through the same engine as cdcx arbitrage and cdcx live
+	engine := arbitrage.NewEngine(cfg, execConfig, opts.transport)
+	engine.SetTradingConfig(opts.trading)
+	if stateDir := cfg.StateDir(); stateDir != "" {
+		if err := engine.SetStateDir(stateDir); err != nil {
+			fatal("❌ Error setting state directory", "error", err)
+		}
+		fmt.Printf("👤 Account: %s (state and logs in %s)\n", cfg.Account, stateDir)
+	}
+	defer notifyEvents(engine)()
+	if execConfig.DepthExecution {
+		fmt.Printf("📶 Depth execution: every simulated level, stopping below %.1f%% realized\n", opts.trading.MinNetMargin)
+	}
+
+	// Load depth analysis results
+	fmt.Println("\n📂 Loading depth analysis results...")
+	analyses, err := engine.LoadAnalyses(opts.path("depth_analysis.json"))
+	if err != nil {
+		fatal("❌ Error loading analyses", "error", err, "hint", "Run depth analysis first: cdcx depth")
+	}
+
+	if len(analyses) == 0 {
+		fmt.Println("❌ No profitable opportunities found in analysis")
+		return
+	}
+
+	fmt.Printf("✅ Loaded %d profitable opportunities\n", len(analyses))
+
+	// Books move on; an analysis read long ago would trade levels that are gone
+	fresh := 0
+	for _, analysis := range analyses {
+		if err := engine.CheckFreshness(analysis); err != nil {
+			fmt.Printf("⏰ %v\n", err)
+			continue
+		}
+		fresh++
+	}
+	if fresh == 0 {
+		fatal("❌ No analysis is fresh enough to execute", "max_analysis_age_seconds", execConfig.MaxAnalysisAgeSeconds,
+			"hint", "Re-run depth analysis: cdcx depth (or raise MAX_ANALYSIS_AGE_SECONDS)")
+	}
+
+	// Check account readiness
+	fmt.Println("\n🔍 Checking account status...")
+	ready, err := engine.CheckAccountReadiness()
+	if err != nil {
+		fatal("❌ Account check failed", "error", err)
+	}
+
+	if !ready {
+		fmt.Println("❌ Account not ready for execution")
+		return
+	}
+
+	fmt.Println("✅ Account ready for execution")
+
+	// Display execution plan
+	fmt.Println("\n📋 EXECUTION PLAN:")
+	fmt.Println("==================")
+	engine.DisplayAnalysisPlan(analyses)
+
+	// Execute arbitrage
+	fmt.Println("\n🚀 Starting arbitrage execution...")
+	results, err := engine.ExecuteAnalyses(analyses)
+	if err != nil {
+		fatal("❌ Execution failed", "error", err)
+	}
+
+	// Display results
+	fmt.Println("\n📊 EXECUTION RESULTS:")
+	fmt.Println("====================")
+	engine.DisplayResults(results)
+
+	// Save execution log
+	filename := opts.path(fmt.Sprintf("execution_log_%d.json", results.Timestamp.Unix()))
+	err = engine.SaveExecutionLog(results, filename)
+	if err != nil {
+		logger.Warn("⚠️ Error saving execution log", "error", err)
+	} else {
+		fmt.Printf("\n💾 Execution log saved to %s\n", filename)
+	}
+
+	fmt.Println("\n🎯 Execution complete!")
+}
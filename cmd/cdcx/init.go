@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+// riskProfiles maps the wizard's risk answers to embedded presets
+var riskProfiles = map[string]string{
+	"paper":      "paper",
+	"cautious":   "cautious-live",
+	"aggressive": "aggressive-live",
+}
+
+// dataLayout are the directories a data directory holds beside its files:
+// failure snapshots, the paper engine's state and sub-accounts' state
+var dataLayout = []string{"snapshots", filepath.Join("paper", "snapshots"), "accounts"}
+
+func runInit(_ *options, args []string) {
+	if len(args) > 0 {
+		fmt.Println("Usage: cdcx [--profile name] [--data-dir path] init")
+		os.Exit(1)
+	}
+	fmt.Println("🧙 CoinDCX Setup Wizard")
+	fmt.Println("======================")
+
+	reader := bufio.NewReader(os.Stdin)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fatal("❌ Cannot locate home directory", "error", err)
+	}
+	configDir := filepath.Join(home, ".config", "cdcx")
+	envFile := filepath.Join(configDir, ".env")
+
+	// Step 1: where everything lives; --data-dir answers it
+	dataDir := config.DataDir()
+	if dataDir == "" {
+		cwd, _ := os.Getwd()
+		dataDir = ask(reader, "\n📁 Data directory", cwd)
+	}
+	dataDir, err = filepath.Abs(dataDir)
+	if err != nil {
+		fatal("❌ Invalid data directory", "error", err)
+	}
+	configFile := filepath.Join(dataDir, "config.yaml")
+
+	for _, path := range []string{envFile, configFile} {
+		if _, err := os.Stat(path); err == nil {
+			if ask(reader, fmt.Sprintf("%s exists. Overwrite? (y/N)", path), "n") != "y" {
+				fmt.Println("❌ Setup cancelled")
+				return
+			}
+		}
+	}
+
+	// Step 2: credentials, verified before anything is written
+	fmt.Println("\n🔑 API credentials (CoinDCX → Settings → API Dashboard)")
+	fmt.Println("   Note: input is echoed to the terminal")
+	apiKey := ask(reader, "API key", "")
+	apiSecret := ask(reader, "API secret", "")
+
+	fmt.Println("\n🔍 Verifying credentials...")
+	client := coindcx.NewClient(apiKey, apiSecret)
+	userInfo, err := client.GetUserInfo()
+	if err != nil {
+		fatal("❌ Credentials rejected", "error", err, "hint", "Check the key is active and the secret was copied in full")
+	}
+	fmt.Printf("✅ Authenticated as %s\n", userInfo.CoinDCXID)
+
+	balances, err := client.GetBalances()
+	if err != nil {
+		fatal("❌ Error getting balances", "error", err)
+	}
+
+	// Step 3: trading preferences
+	funding := strings.ToUpper(ask(reader, "\n🏦 Funding currency (USDT/INR)", "USDT"))
+	if funding != "USDT" && funding != "INR" {
+		fatal("❌ Unsupported funding currency", "currency", funding)
+	}
+	spendable := 0.0
+	for _, balance := range balances {
+		if balance.Currency == funding {
+			spendable = balance.Balance
+		}
+	}
+	fmt.Printf("💰 Current %s balance: %.6f\n", funding, spendable)
+	if spendable <= 0 && ask(reader, fmt.Sprintf("⚠️ The account holds no %s to buy with. Continue? (y/N)", funding), "n") != "y" {
+		fmt.Println("❌ Setup cancelled")
+		return
+	}
+
+	// --profile answers the risk question
+	profile := os.Getenv("CDCX_PROFILE")
+	if profile == "" {
+		risk := strings.ToLower(ask(reader, "\n🎚️ Risk appetite (paper/cautious/aggressive)", "paper"))
+		var ok bool
+		if profile, ok = riskProfiles[risk]; !ok {
+			fatal("❌ Unknown risk appetite", "risk", risk)
+		}
+		if funding == "INR" && profile != "paper" {
+			profile = "inr-funded"
+		}
+	}
+	if _, err := config.LoadProfile(profile); err != nil {
+		fatal("❌ Error loading profile", "error", err)
+	}
+
+	// Step 4: where alerts go, one line per backend pkg/notify supports
+	fmt.Println("\n📣 Notification targets (blank to skip each)")
+	notifications := []string{}
+	if webhook := ask(reader, "Webhook URL", ""); webhook != "" {
+		notifications = append(notifications, "NOTIFY_WEBHOOK_URL="+webhook)
+	}
+	if slack := ask(reader, "Slack incoming webhook URL", ""); slack != "" {
+		notifications = append(notifications, "NOTIFY_SLACK_WEBHOOK_URL="+slack)
+	}
+	if token := ask(reader, "Telegram bot token", ""); token != "" {
+		chatID := ask(reader, "Telegram chat ID", "")
+		if chatID == "" {
+			fatal("❌ A Telegram bot token needs a chat ID")
+		}
+		notifications = append(notifications, "NOTIFY_TELEGRAM_TOKEN="+token, "NOTIFY_TELEGRAM_CHAT_ID="+chatID)
+	}
+
+	// Step 5: the data directory layout, the config file and the credentials
+	for _, dir := range dataLayout {
+		dir = filepath.Join(dataDir, dir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fatal("❌ Cannot create data directory", "path", dir, "error", err)
+		}
+	}
+
+	settings := []string{
+		"# Written by the cdcx setup wizard; config.example.yaml lists every parameter",
+		"profile: " + profile,
+		"execution:",
+		"  funding_currency: " + funding,
+	}
+	if err := os.WriteFile(configFile, []byte(strings.Join(settings, "\n")+"\n"), 0644); err != nil {
+		fatal("❌ Cannot write config file", "path", configFile, "error", err)
+	}
+	if file, err := config.LoadFile(configFile); err != nil {
+		fatal("❌ Config file unreadable", "error", err)
+	} else if errs := file.Validate(); len(errs) > 0 {
+		fatal("❌ Config file invalid", "errors", errs)
+	}
+
+	lines := append([]string{
+		"# Written by the cdcx setup wizard",
+		"COINDCX_API_KEY=" + apiKey,
+		"COINDCX_API_SECRET=" + apiSecret,
+		"CDCX_DATA_DIR=" + dataDir,
+	}, notifications...)
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		fatal("❌ Cannot create config directory", "path", configDir, "error", err)
+	}
+	if err := os.WriteFile(envFile, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		fatal("❌ Cannot write env file", "path", envFile, "error", err)
+	}
+
+	fmt.Printf("\n✅ Credentials written to %s\n", envFile)
+	fmt.Printf("⚙️ Configuration written to %s\n", configFile)
+	fmt.Printf("📁 Data directory: %s\n", dataDir)
+	fmt.Printf("🎛️ Profile: %s, funded in %s\n", profile, funding)
+	fmt.Println("💡 Run the doctor next: make doctor")
+}
+
+// ask prompts for a value, returning def when the answer is empty
+func ask(reader *bufio.Reader, prompt, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", prompt, def)
+	} else {
+		fmt.Printf("%s: ", prompt)
+	}
+
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		fatal("❌ Input closed")
+	}
+
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def
+	}
+	return answer
+}
@@ -0,0 +1,765 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/internal/control"
+	"github.com/b-thark/cdcx-api/internal/divergence"
+	"github.com/b-thark/cdcx-api/internal/queue"
+	"github.com/b-thark/cdcx-api/internal/schedule"
+	"github.com/b-thark/cdcx-api/internal/toggles"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/arbitrage"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/exchange"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/metrics"
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+	"github.com/b-thark/cdcx-api/pkg/pairs"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// hostDownAfter failures in a row mark an exchange host down
+const hostDownAfter = 3
+
+const (
+	pendingQueueFile = "pending_opportunities.json"
+	divergenceFile   = "paper_divergence.json"
+)
+
+var (
+	// With PAPER_PARALLEL=true every live execution is mirrored on a paper
+	// engine and outcomes that differ are recorded
+	paperEngine *arbitrage.Engine
+	divergences *divergence.Tracker
+
+	session *arbitrage.Session // Set in session mode; nil for a single pass
+
+	stateDir string // A sub-account's own state and log directory; empty for the main account
+
+	// Done on SIGINT/SIGTERM: scans stop mid-pass and executions still waiting
+	// for the lock stay queued for the next start, while one already trading
+	// finishes
+	shutdown = context.Background()
+)
+
+func runLive(opts *options, args []string) {
+	fmt.Println("🚀 CoinDCX Live Arbitrage Detector")
+	fmt.Println("==================================")
+	fmt.Println("⚠️  LIVE TRADING MODE - REAL EXECUTION")
+	fmt.Println("🔍 Real-time detection → immediate execution")
+
+	tradingConfig, execConfig := opts.trading, opts.execution
+	profile, paper := opts.profile, opts.paper()
+	if opts.file != nil {
+		fmt.Printf("📄 Config file: %s\n", opts.file.Path)
+	}
+	if profile != nil {
+		fmt.Printf("🎛️ Profile: %s - %s\n", profile.Name, profile.Description)
+	}
+	apiConfig := opts.credentials()
+	stateDir = apiConfig.StateDir()
+
+	// BUNDLE_DIR archives what this run wrote, with the inputs it read, when it ends
+	if dir := os.Getenv("BUNDLE_DIR"); dir != "" {
+		defer bundleRun(opts, time.Now(), dir)
+	}
+
+	fmt.Printf("🐢 Exchange call budget: %d tokens/min in bursts of %d, scans at most every %s\n",
+		tradingConfig.APICallBudget(), max(tradingConfig.APICallBurst, 1), tradingConfig.ScanInterval())
+
+	fmt.Printf("🏷️ Build %s\n", version.Current().Label())
+
+	// Load arbitrage pairs
+	fmt.Println("\n📂 Loading arbitrage pairs...")
+	pairAnalyzer := pairs.NewAnalyzer(tradingConfig)
+	arbitragePairs, err := pairAnalyzer.LoadPairs(opts.path("arbitrage_pairs.json"))
+	if err != nil {
+		fatal("❌ Error loading pairs", "error", err, "hint", "Run pair detection first: cdcx pairs")
+	}
+
+	fmt.Printf("✅ Loaded %d currencies with arbitrage potential\n", len(arbitragePairs))
+
+	// Books and orders come from different hosts; when one fails, analysis
+	// carries on with what still answers and execution waits for both. Health
+	// and metrics see each request once, after the budget and its retries.
+	hostHealth := exchange.NewHostHealth(hostDownAfter)
+	hostHealth.LogChanges()
+	opts.transport = hostHealth.Transport(opts.transport)
+	var collector *metrics.Collector
+	if os.Getenv("METRICS_ADDR") != "" {
+		collector = metrics.New()
+		opts.transport = collector.Transport(opts.transport)
+	}
+
+	// Create components
+	fetcher := opts.fetcher()
+	fetcher.SetConcurrency(tradingConfig.BookFetchConcurrency)
+	rateManager := opts.rateManager(tradingConfig)
+	anomalies := market.NewAnomalyFilter(tradingConfig.MaxQuoteDeviationPct)
+	feeds := market.NewPriceFeeds(tradingConfig.PriceEMAPeriod, tradingConfig.PriceAveragePeriod, tradingConfig.MaxEMADeviationPct)
+	seedFromCandles(arbitragePairs, func(symbol string, history []types.Candle) {
+		anomalies.Seed(symbol, history)
+		feeds.Seed(symbol, history)
+	})
+	if tickers, err := fetcher.GetTicker(); err == nil {
+		anomalies.UpdateTicker(tickers)
+	} else {
+		logger.Warn("⚠️ Ticker unavailable for quote sanity checks", "error", err)
+	}
+	engine := arbitrage.NewEngine(apiConfig, execConfig, opts.transport)
+	engine.SetTradingConfig(tradingConfig)
+	if paper {
+		fmt.Println("📝 DRY RUN - orders are simulated against live books")
+	} else if os.Getenv("PAPER_PARALLEL") == "true" {
+		markets, err := fetcher.GetMarketDetails()
+		if err != nil {
+			fatal("❌ Error loading markets for parallel paper trading", "error", err)
+		}
+		venue := executor.NewSimulatedExecutor(fetcher, markets, tradingConfig.FeeRate,
+			map[string]float64{"USDT": 1000, "INR": 100000})
+		paperEngine = arbitrage.NewEngineWithVenue(apiConfig, execConfig, venue)
+		paperEngine.SetTransport(opts.transport)
+		paperEngine.SetTradingConfig(tradingConfig)
+		if err := paperEngine.SetStateDir(filepath.Join(stateDir, "paper")); err != nil {
+			fatal("❌ Error setting paper state directory", "error", err)
+		}
+		divergences = divergence.NewTracker(filepath.Join(stateDir, divergenceFile))
+		if err := divergences.Load(); err != nil {
+			fatal("❌ Error loading divergences", "error", err)
+		}
+		fmt.Printf("📝 Paper engine mirroring live executions, divergences → %s\n", filepath.Join(stateDir, divergenceFile))
+	}
+
+	// A sub-account keeps its own inventory, ledgers, queue and logs, so its P&L stays separate
+	if stateDir != "" {
+		if err := engine.SetStateDir(stateDir); err != nil {
+			fatal("❌ Error setting state directory", "error", err)
+		}
+		fmt.Printf("👤 Account: %s (state and logs in %s)\n", apiConfig.Account, stateDir)
+	}
+
+	// Route execution events to the configured notification backends
+	defer notifyEvents(engine)()
+
+	engine.SetHostHealth(hostHealth)
+
+	// Prometheus scrape endpoint, e.g. curl localhost:9102/metrics
+	if collector != nil {
+		addr := os.Getenv("METRICS_ADDR")
+		engine.Events().Subscribe(collector.Handle)
+		if err := collector.Serve(addr); err != nil {
+			fatal("❌ Metrics endpoint", "error", err)
+		}
+		fmt.Printf("📈 Prometheus metrics on http://%s/metrics\n", addr)
+	}
+
+	// Interactive runs can review each trade before it is placed
+	confirmTrades(engine, execConfig.ConfirmTrades)
+
+	// Read-only view of what this process is running with, e.g. curl localhost:8090/config or /status
+	if addr := os.Getenv("CONTROL_ADDR"); addr != "" {
+		server := control.NewServer(addr)
+		store := toggles.NewStore(togglesFile)
+		server.Handle("/config", func() interface{} {
+			return config.NewEffective(apiConfig, opts.file, profile, tradingConfig, execConfig, store)
+		})
+		started := time.Now()
+		server.Handle("/status", func() interface{} {
+			return struct {
+				Run       *types.RunInfo `json:"run"`
+				StartedAt time.Time      `json:"started_at"`
+				Account   string         `json:"account,omitempty"`
+				Paper     bool           `json:"paper"`
+			}{version.Current(), started, apiConfig.Account, paper}
+		})
+		server.Handle("/health", func() interface{} {
+			paused := ""
+			if err := engine.HostGuard(); err != nil {
+				paused = err.Error()
+			}
+			return struct {
+				Hosts           map[string]exchange.HostStatus `json:"hosts"`
+				ExecutionPaused string                         `json:"execution_paused,omitempty"`
+			}{hostHealth.Statuses(), paused}
+		})
+		// The same availability figures sizing uses: free, locked, reserved and pending fills
+		server.HandleQuery("/balances", func(url.Values) (interface{}, error) {
+			return engine.BalanceView()
+		})
+		// What-if pricing for dashboards: /simulate?market=BTCUSDT&side=sell&quantity=0.01
+		if simulator, err := newSimulator(fetcher, tradingConfig, rateManager); err == nil {
+			server.HandleQuery("/simulate", simulateQuery(simulator))
+		} else {
+			logger.Warn("⚠️ Control API /simulate unavailable", "error", err)
+		}
+		if err := server.Start(); err != nil {
+			fatal("❌ Control API", "error", err)
+		}
+		fmt.Printf("🎛️ Control API on %s\n", addr)
+	}
+
+	// Watch for market suspensions and maintenance while opportunities are executing
+	statusMonitor := exchange.NewStatusMonitor(fetcher, os.Getenv("ANNOUNCEMENTS_URL"))
+	statusMonitor.OnEvent(func(event exchange.StatusEvent) {
+		subject := event.Market
+		if subject == "" {
+			subject = event.Currency
+		}
+		logger.Warn("📢 EXCHANGE STATUS", "kind", event.Kind, "subject", subject, "detail", event.Detail)
+	})
+	if err := statusMonitor.Poll(); err != nil {
+		logger.Warn("⚠️ Status poll failed", "error", err)
+	}
+	stopStatus := make(chan struct{})
+	defer close(stopStatus)
+	go statusMonitor.Start(30*time.Second, stopStatus)
+	engine.SetStatusMonitor(statusMonitor)
+
+	// Check account readiness
+	fmt.Println("\n🔍 Checking account status...")
+	ready, err := engine.CheckAccountReadiness()
+	if err != nil {
+		fatal("❌ Account check failed", "error", err)
+	}
+
+	if !ready {
+		fmt.Println("❌ Account not ready for execution")
+		return
+	}
+
+	fmt.Println("✅ Account ready for live trading")
+	resumeInterrupted(engine)
+
+	// Baseline for spotting balance changes made outside the bot
+	checkBalances(engine)
+
+	// Start live detection and execution
+	fmt.Println("\n🚀 Starting live arbitrage detection...")
+	fmt.Println("🔒 Global execution lock: Only one trade at a time")
+	fmt.Println("🔍 Detection: Parallel across all opportunities")
+
+	session = arbitrage.NewSession(execConfig)
+	if execConfig.RunForever {
+		fmt.Println("♾️ Running until stopped: scanning continues while trades execute")
+	} else if session != nil {
+		fmt.Printf("⏱️ Session mode: %d min, profit target ₹%.2f, loss limit ₹%.2f (0 = none)\n",
+			execConfig.SessionMinutes, execConfig.SessionProfitTargetINR, execConfig.SessionLossLimitINR)
+	}
+
+	var stop context.CancelFunc
+	shutdown, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-shutdown.Done()
+		stop() // A second Ctrl-C kills the process
+		fmt.Println("\n🛑 Shutting down: finishing the execution in progress, queued ones resume on the next start (Ctrl-C again to force)")
+	}()
+
+	// Strategies under evaluation decide alongside this one but never trade
+	if len(tradingConfig.ShadowStrategies) > 0 {
+		defer startShadow(shutdown, tradingConfig, fetcher, opts.transport)()
+	}
+
+	// Resume opportunities queued before the last shutdown; each is re-validated before execution
+	pendingQueue := queue.NewQueue(filepath.Join(stateDir, pendingQueueFile), time.Duration(execConfig.QueueTTLSeconds)*time.Second)
+	pendingQueue.SetCapacity(execConfig.MaxQueuedOpportunities)
+	resumed, err := pendingQueue.Load()
+	if err != nil {
+		logger.Warn("⚠️ Could not restore pending queue", "error", err)
+	}
+
+	totalOpportunities := 0
+	launched := make(map[string]bool)
+	for _, entry := range resumed {
+		totalOpportunities++
+		launched[entry.ID] = true
+
+		logger.Info("♻️ RESUMING", "opportunity_id", entry.ID, "queued_ago", time.Since(entry.EnqueuedAt).Round(time.Second),
+			"expires_in", time.Until(entry.ExpiresAt).Round(time.Second))
+	}
+
+	// A fixed pool executes the queue best margin first, however far
+	// detections run ahead: while one worker trades the others re-validate
+	workers := max(execConfig.ExecutionWorkers, 1)
+	defer startExecutionWorkers(engine, pendingQueue, workers)()
+
+	// Remembers each pair's last scanned prices, so pairs in a quote
+	// partition that isn't due can still be compared with the ones that are
+	scanner := arbitrage.NewScanner(tradingConfig, rateManager, anomalies, feeds)
+	var shortfall *opportunity.Shortfall // Near misses of the latest pass
+
+	// scanPass analyzes the given currencies, or all of them when nil,
+	// refetching the books of pairs quoted in quotes (all when nil)
+	scanPass := func(currencies, quotes map[string]bool) {
+		shortfall = opportunity.NewShortfall(tradingConfig)
+
+		// While a host execution needs is down, opportunities are only reported
+		hostHealth.Probe(shutdown)
+		paused := engine.HostGuard()
+		if paused != nil {
+			logger.Warn("⏸️ Execution paused, analyzing only", "reason", paused)
+		}
+
+		// The pass's books are fetched together, several at a time
+		refetch := []string{}
+		for currency, pairGroup := range arbitragePairs {
+			if len(pairGroup.Pairs) < 2 || (currencies != nil && !currencies[currency]) {
+				continue
+			}
+			for _, pair := range pairGroup.Pairs {
+				if scanner.NeedsRefresh(pair, quotes) {
+					refetch = append(refetch, pair.Pair)
+				}
+			}
+		}
+		books := fetcher.GetOrderBooks(shutdown, refetch)
+
+		for currency, pairGroup := range arbitragePairs {
+			if len(pairGroup.Pairs) < 2 || (currencies != nil && !currencies[currency]) {
+				continue
+			}
+			if stopped, _ := session.Stopped(); stopped || shutdown.Err() != nil {
+				return
+			}
+
+			logger.Info("📊 Analyzing", "currency", currency, "pairs", len(pairGroup.Pairs))
+
+			// Find opportunities for this currency
+			currencyOpps, err := scanner.AnalyzeCurrency(shutdown, currency, pairGroup.Pairs, quotes, books, shortfall)
+			if shutdown.Err() != nil {
+				return
+			}
+			if err != nil {
+				logger.Info("❌ Not analyzed", "currency", currency, "reason", err)
+				continue
+			}
+
+			// Queue each viable opportunity for the execution workers
+			for _, opp := range currencyOpps {
+				if opp.Viable && boughtWith(opp, execConfig.FundingCurrency) {
+					// Already executed this pass; one waiting is refreshed with
+					// this detection and one executing is left alone
+					if launched[queue.OpportunityID(opp)] && !pendingQueue.Has(queue.OpportunityID(opp)) {
+						continue
+					}
+					if paused != nil {
+						logger.Info("⏸️ Not executed: host down", "currency", opp.TargetCurrency, "buy_market", opp.BuyMarket.Symbol,
+							"sell_market", opp.SellMarket.Symbol, "net_margin_pct", opp.NetMarginPct)
+						continue
+					}
+					if enqueueOpportunity(engine, pendingQueue, opp) {
+						launched[queue.OpportunityID(opp)] = true
+						totalOpportunities++
+					}
+				}
+			}
+		}
+
+		for symbol, reason := range anomalies.Quarantined() {
+			logger.Warn("🚧 Quarantined", "market", symbol, "reason", reason)
+		}
+
+		// Save rate cache
+		rateManager.SaveCache()
+	}
+
+	if session == nil {
+		scanPass(nil, nil)
+
+		if totalOpportunities == 0 {
+			fmt.Println("❌ No viable opportunities found")
+			for _, suggestion := range shortfall.Suggestions() {
+				fmt.Printf("💡 %s\n", suggestion)
+			}
+			return
+		}
+
+		fmt.Printf("🚀 Queued %d opportunities for %d execution workers\n", totalOpportunities, workers)
+
+		// Wait for all executions to complete
+		pendingQueue.Wait()
+		checkBalances(engine)
+	} else {
+		// Streamed books let a currency be rescanned the moment they change
+		// instead of waiting for the next full pass
+		var bookUpdates <-chan market.BookUpdate
+		pairCurrency := make(map[string]string)
+		if tradingConfig.StreamOrderBooks {
+			streamed := []string{}
+			for currency, pairGroup := range arbitragePairs {
+				for _, pair := range pairGroup.Pairs {
+					pairCurrency[pair.Pair] = currency
+					streamed = append(streamed, pair.Pair)
+				}
+			}
+			updates, unsubscribe := fetcher.Subscribe(streamed)
+			defer unsubscribe()
+			bookUpdates = updates
+			fmt.Printf("📡 Streaming %d order books\n", len(streamed))
+		}
+
+		sweepEvery := execConfig.RecoverySweepInterval()
+		if sweepEvery > 0 {
+			fmt.Printf("🧹 Recovery sweep every %s for inventory and dust worth ₹%.2f+\n", sweepEvery, execConfig.RecoverySweepMinINR)
+		}
+		var lastSweep time.Time
+
+		partitions := schedule.NewPartitions(quoteCurrencies(arbitragePairs), tradingConfig.QuoteScanInterval)
+		if len(tradingConfig.QuoteScanIntervals) > 0 {
+			for _, quote := range quoteCurrencies(arbitragePairs) {
+				fmt.Printf("🗂️ %s-quoted pairs scanned every %s\n", quote, partitions.Interval(quote))
+			}
+		}
+
+		refreshEvery := time.Duration(max(execConfig.PairsRefreshMinutes, 0)) * time.Minute
+		lastRefresh := time.Now()
+
+		// A bounded session finishes each pass's trades before the next; a
+		// daemon keeps scanning while they execute
+		finishTrades := func() {
+			if !execConfig.RunForever {
+				pendingQueue.Wait()
+			}
+		}
+
+		// Session mode: repeated passes until the session ends, then flatten
+		for pass := 1; ; pass++ {
+			if refreshEvery > 0 && time.Since(lastRefresh) >= refreshEvery && shutdown.Err() == nil {
+				// Listings change; streamed books keep the original pairs and new ones are polled
+				if refreshed, err := refreshPairs(opts, pairAnalyzer); err != nil {
+					logger.Warn("⚠️ Pairs not refreshed", "currencies", len(arbitragePairs), "error", err)
+				} else {
+					logger.Info("🔄 Pairs refreshed", "currencies", len(refreshed), "was", len(arbitragePairs))
+					arbitragePairs = refreshed
+					partitions = schedule.NewPartitions(quoteCurrencies(arbitragePairs), tradingConfig.QuoteScanInterval)
+				}
+				lastRefresh = time.Now()
+			}
+
+			due := partitions.Due(time.Now())
+			logger.Info("⏱️ Session pass", "pass", pass, "quotes", strings.Join(due, "/"), "session", session.Summary())
+			partitions.Ran(due, time.Now())
+			scanPass(nil, setOf(due))
+			finishTrades()
+
+			// Balances and the sweep can't race a trade, so they wait for a moment nothing is executing
+			idle := pendingQueue.Len() == 0
+			if idle {
+				checkBalances(engine)
+			}
+			if idle && sweepEvery > 0 && time.Since(lastSweep) >= sweepEvery && shutdown.Err() == nil {
+				if results := engine.Sweep(execConfig.RecoverySweepMinINR); len(results) > 0 {
+					fmt.Printf("🧹 Swept %d stranded holding(s)\n", len(results))
+				}
+				lastSweep = time.Now()
+			}
+
+			if stopped, reason := session.Stopped(); stopped {
+				fmt.Printf("\n🏁 Session over: %s\n", reason)
+				break
+			}
+			if shutdown.Err() != nil {
+				fmt.Println("\n🏁 Session over: interrupted")
+				break
+			}
+
+			// Slow passes don't add a pause on top; fast ones wait until the
+			// next quote partition is due
+			nextPass := partitions.Next()
+			if bookUpdates != nil {
+				rescanOnUpdates(shutdown, bookUpdates, pairCurrency, nextPass, func(currencies map[string]bool) {
+					scanPass(currencies, nil)
+				})
+				finishTrades()
+			} else {
+				select {
+				case <-time.After(time.Until(nextPass)):
+				case <-shutdown.Done():
+				}
+			}
+			launched = make(map[string]bool) // The same route may be traded again on a later pass
+		}
+
+		pendingQueue.Wait()
+		if execConfig.RunForever {
+			// Stopped for a restart or deploy: held inventory is still tracked at the next start
+			fmt.Println("📦 Inventory kept for the next start")
+		} else if results := engine.Flatten(); len(results) > 0 {
+			fmt.Printf("📉 Flattened %d position(s)\n", len(results))
+		}
+		fmt.Printf("📊 Session: %s\n", session.Summary())
+	}
+
+	if divergences != nil {
+		fmt.Printf("🔀 Paper-vs-live divergences so far: %v\n", divergences.Counts())
+	}
+
+	fmt.Println("\n🎯 All live arbitrage executions complete!")
+}
+
+// enqueueOpportunity queues a detected opportunity for the execution
+// workers, reporting whether it is new. A route already queued is merged
+// with the fresher detection; one the bounded queue can't hold, or pushes
+// out to make room, is dropped with an event.
+func enqueueOpportunity(engine *arbitrage.Engine, pending *queue.Queue, opp types.ArbitrageOpportunity) bool {
+	admission, err := pending.Push(opp)
+	if err != nil {
+		logger.Warn("⚠️ Could not persist queued opportunity", "error", err)
+	}
+	dropOpportunities(engine, admission.Dropped)
+	if admission.Merged || admission.Rejected {
+		if admission.Rejected {
+			dropOpportunities(engine, []queue.Drop{{Entry: admission.Entry, Reason: queue.DropFull}})
+		}
+		return false
+	}
+
+	engine.Events().Publish(events.NewOpportunityDetected(opp.TargetCurrency,
+		opp.BuyMarket.Symbol, opp.SellMarket.Symbol, opp.NetMarginPct))
+	return true
+}
+
+// dropOpportunities reports opportunities the queue let go without executing
+func dropOpportunities(engine *arbitrage.Engine, drops []queue.Drop) {
+	for _, drop := range drops {
+		opp := drop.Entry.Opportunity
+		logger.Warn("🗑️ Dropped: executions are behind detections", "opportunity_id", drop.Entry.ID, "reason", drop.Reason,
+			"net_margin_pct", opp.NetMarginPct)
+		engine.Events().Publish(events.NewOpportunityDropped(opp.TargetCurrency,
+			opp.BuyMarket.Symbol, opp.SellMarket.Symbol, opp.NetMarginPct, drop.Reason))
+	}
+}
+
+// startExecutionWorkers runs count workers executing the pending queue,
+// returning a stop that waits for them once nothing is left to execute
+func startExecutionWorkers(engine *arbitrage.Engine, pending *queue.Queue, count int) func() {
+	done := make(chan struct{})
+	var executions atomic.Int64
+	var workers sync.WaitGroup
+	for range count {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				ready := pending.Ready()
+				entry, stale, ok := pending.Take()
+				dropOpportunities(engine, stale)
+				if ok {
+					executeOpportunity(engine, pending, entry.Opportunity, int(executions.Add(1)))
+					continue
+				}
+				select {
+				case <-ready:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	return func() {
+		close(done)
+		workers.Wait()
+	}
+}
+
+func executeOpportunity(engine *arbitrage.Engine, pending *queue.Queue, opp types.ArbitrageOpportunity, oppNumber int) {
+	opportunityID := queue.OpportunityID(opp)
+	executionID := utils.NewUUID() // On this attempt's lines here and in the engine's logs, events and records
+	log := logger.With("execution", oppNumber, "opportunity_id", opportunityID, "execution_id", executionID)
+	keepQueued := false
+	defer func() {
+		if keepQueued {
+			pending.Keep(opportunityID)
+			return
+		}
+		if err := pending.Remove(opportunityID); err != nil {
+			log.Warn("⚠️ Could not update pending queue", "error", err)
+		}
+	}()
+
+	// Shutting down: stay queued for the next start rather than wait out the trade in progress
+	if shutdown.Err() != nil {
+		keepQueued = true
+		log.Info("🛑 Skipped, shutting down; kept queued for the next start")
+		return
+	}
+
+	log.Info("⏳ Waiting for execution lock...")
+
+	// 🔒 ACQUIRE GLOBAL EXECUTION LOCK, re-validating in the background while we wait
+	lock := engine.ExecutionLock()
+	prevalidated, err := engine.PrevalidateUntilLockedContext(shutdown, lock, []types.ArbitrageOpportunity{opp})
+	if err != nil {
+		keepQueued = true
+		log.Info("🛑 Stopped waiting, shutting down; kept queued for the next start")
+		return
+	}
+	defer lock.Unlock()
+
+	if stopped, reason := session.Stopped(); stopped {
+		log.Info("🏁 Skipped", "reason", reason)
+		return
+	}
+	if shutdown.Err() != nil {
+		keepQueued = true
+		log.Info("🛑 Skipped, shutting down; kept queued for the next start")
+		return
+	}
+
+	log.Info("🚀 Execution lock acquired, starting execution...")
+
+	// Execute with the freshest validation, mirrored on the paper engine when enabled
+	var paperResult *types.ExecutionResult
+	var paperDone sync.WaitGroup
+	if paperEngine != nil {
+		paperDone.Add(1)
+		go func() {
+			defer paperDone.Done()
+			paperResult = paperEngine.ExecutePrevalidated(prevalidated[0])
+		}()
+	}
+
+	liveOpp := prevalidated[0]
+	liveOpp.ExecutionID = executionID
+	result := engine.ExecutePrevalidated(liveOpp)
+	session.Record(result)
+
+	if paperEngine != nil {
+		paperDone.Wait()
+		if record, err := divergences.Compare(opportunityID, paperResult, result); err != nil {
+			log.Warn("⚠️ Could not record divergence", "error", err)
+		} else if record != nil {
+			log.Warn("🔀 Paper and live diverged", "kinds", record.Kinds)
+		}
+	}
+
+	// Log results
+	if result.Successful && len(result.Orders) > 0 {
+		order := result.Orders[0]
+		log.Info("💰 SUCCESS", "profit_inr", order.ActualProfit, "margin_pct", order.ActualMarginPct,
+			"execution_ms", order.ExecutionTimeMs)
+	} else {
+		log.Info("❌ Execution completed but no profit")
+	}
+
+	// Save execution log
+	filename := filepath.Join(stateDir, fmt.Sprintf("execution_log_%s_%d.json", opportunityID, result.Timestamp.Unix()))
+	if err := engine.SaveExecutionLog(result, filename); err != nil {
+		log.Warn("⚠️ Error saving execution log", "error", err)
+	}
+
+	log.Info("✅ Execution complete, lock released")
+}
+
+// checkBalances alerts (through the engine's events) on balance changes the
+// bot's own fills don't explain and on funding running low. Only called
+// while no execution is running.
+func checkBalances(engine *arbitrage.Engine) {
+	if _, err := engine.CheckBalances(); err != nil {
+		logger.Warn("⚠️ Balance check failed", "error", err)
+	}
+	if err := engine.CheckFunding(); err != nil {
+		logger.Warn("⚠️ Funding check failed", "error", err)
+	}
+}
+
+// resumeInterrupted finishes the arbitrages a previous run left between
+// their legs before anything new is traded
+func resumeInterrupted(engine *arbitrage.Engine) {
+	for _, order := range engine.ResumeJournal() {
+		if order.Success {
+			fmt.Printf("🩹 Finished interrupted %s arbitrage: %.6f sold, P&L ₹%.2f\n",
+				order.Currency, order.VolumeExecuted, order.ActualProfit)
+		} else {
+			fmt.Printf("🩹 Interrupted %s arbitrage: %s\n", order.Currency, order.ErrorMessage)
+		}
+	}
+}
+
+// refreshPairs re-extracts the arbitrage pairs from the exchange's current
+// markets and saves them where cdcx pairs would
+func refreshPairs(opts *options, analyzer *pairs.Analyzer) (map[string]types.ArbitragePairs, error) {
+	refreshed, err := analyzer.ExtractArbitragePairs()
+	if err != nil {
+		return nil, err
+	}
+	if len(refreshed) == 0 {
+		return nil, fmt.Errorf("no currency has more than one pair")
+	}
+	if err := analyzer.SavePairs(refreshed, opts.path("arbitrage_pairs.json")); err != nil {
+		logger.Warn("⚠️ Refreshed pairs not saved", "error", err)
+	}
+	return refreshed, nil
+}
+
+// rescanOnUpdates rescans the currencies whose streamed books change until
+// deadline or until ctx is done, batching updates that arrive while a rescan runs
+func rescanOnUpdates(ctx context.Context, updates <-chan market.BookUpdate, pairCurrency map[string]string, deadline time.Time, rescan func(map[string]bool)) {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return
+		case <-ctx.Done():
+			return
+		case update := <-updates:
+			changed := map[string]bool{pairCurrency[update.Pair]: true}
+		drain:
+			for {
+				select {
+				case update := <-updates:
+					changed[pairCurrency[update.Pair]] = true
+				default:
+					break drain
+				}
+			}
+			rescan(changed)
+		}
+	}
+}
+
+// quoteCurrencies lists the quote currencies of every pair, sorted
+func quoteCurrencies(arbitragePairs map[string]types.ArbitragePairs) []string {
+	seen := make(map[string]bool)
+	quotes := []string{}
+	for _, pairGroup := range arbitragePairs {
+		for _, pair := range pairGroup.Pairs {
+			if !seen[pair.BaseCurrency] {
+				seen[pair.BaseCurrency] = true
+				quotes = append(quotes, pair.BaseCurrency)
+			}
+		}
+	}
+	sort.Strings(quotes)
+	return quotes
+}
+
+func setOf(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
+
+// boughtWith reports whether the opportunity's buy leg is paid in funding,
+// as the engine requires
+func boughtWith(opp types.ArbitrageOpportunity, funding string) bool {
+	return opp.BuySymbol().Quote == funding
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/arbitrage"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/exchange"
+	"github.com/b-thark/cdcx-api/pkg/fees"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/notify"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+const togglesFile = "trading_toggles.json"
+
+var logger = logging.For("cdcx")
+
+// fatal logs why the command can't go on and exits. The record is written
+// whatever the level: --quiet must not hide why the process ended.
+func fatal(message string, args ...any) {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // The caller's line rather than this one
+	record := slog.NewRecord(time.Now(), slog.LevelError, message, pcs[0])
+	record.Add(args...)
+	logger.Handler().Handle(context.Background(), record)
+	os.Exit(1)
+}
+
+// command is one cdcx subcommand
+type command struct {
+	name       string
+	summary    string
+	configured bool // Resolves the shared trading and execution configuration first
+	run        func(opts *options, args []string)
+}
+
+var commands = []command{
+	{"pairs", "Find currencies listed in several markets (writes arbitrage_pairs.json)", true, runPairs},
+	{"detect", "Find arbitrage opportunities between those markets (writes arbitrage_opportunities.json)", true, runDetect},
+	{"triangular", "Find three-leg cycles within the exchange (writes triangular_opportunities.json)", true, runTriangular},
+	{"depth", "Analyze order book depth behind the opportunities (writes depth_analysis.json)", true, runDepth},
+	{"execute", "Execute the depth-analyzed opportunities - LIVE", true, runExecute},
+	{"arbitrage", "Re-validate and execute detected opportunities with the engine - LIVE", true, runArbitrage},
+	{"live", "Detect and execute continuously - LIVE", true, runLive},
+	{"watch", "Record spreads, depth and hypothetical P&L without trading", true, runWatch},
+	{"backfill", "Download candle history", true, runBackfill},
+	{"simulate", "Expected fill, fees and taxes for a market order, without placing it", true, runSimulate},
+	{"record", "Record order books of the arbitrage pairs for replay by detect and depth", true, runRecord},
+	{"backtest", "Replay recorded books through detection and simulated execution for a P&L report", true, runBacktest},
+	{"shadow", "Summarize how shadow strategies' logged decisions would have done", true, runShadow},
+	{"thresholds", "Compare detector margin/liquidity settings on one recorded snapshot", true, runThresholds},
+	{"bundle", "Pack a run's config, pipeline files, logs, report and state into one archive", true, runBundle},
+	{"report", "Compare strategies from execution logs", false, runReport},
+	{"timeline", "Replay one execution from the audit trail", false, runTimeline},
+	{"annotate", "Add notes to trades or mark them resolved by hand", false, runAnnotate},
+	{"toggle", "Disable or enable trading per currency", false, runToggle},
+	{"config", "Explain parameters or show the effective configuration", false, runConfig},
+	{"doctor", "Preflight checks before a live run", false, runDoctor},
+	{"init", "Setup wizard for credentials and a risk profile", false, runInit},
+	{"account", "Show account details and balances", false, runAccount},
+	{"recover", "Finish interrupted arbitrages and sell stranded balances back to USDT", true, runRecover},
+	{"order", "Place a stop-limit order by hand", true, runOrder},
+}
+
+// globalFlag is accepted before or after the subcommand and sets the
+// environment variable every command and package already reads, so each
+// setting is parsed and validated in one place
+type globalFlag struct {
+	name    string
+	env     string
+	boolean bool
+	help    string
+}
+
+var globalFlags = []globalFlag{
+	{"config", "CDCX_CONFIG", false, "Load parameters and profiles from this config file"},
+	{"profile", "CDCX_PROFILE", false, "Apply a strategy profile"},
+	{"account", "CDCX_ACCOUNT", false, "Use a named sub-account's credentials and state"},
+	{"env-file", "CDCX_ENV_FILE", false, "Load credentials from this .env file"},
+	{"out", "CDCX_OUT_DIR", false, "Directory pipeline files are read from and written to"},
+	{"data-dir", "CDCX_DATA_DIR", false, "Directory for pairs, logs, caches and state"},
+	{"min-net-margin", "MIN_NET_MARGIN", false, "Minimum net margin percentage"},
+	{"min-liquidity", "MIN_LIQUIDITY", false, "Minimum order book liquidity in INR"},
+	{"all-pairs", "ENABLE_ALL_PAIRS", true, "Include all quote currencies"},
+	{"run-forever", "RUN_FOREVER", true, "Keep live scanning and trading until stopped"},
+	{"interval", "MIN_SCAN_INTERVAL_SECONDS", false, "Seconds between live scan passes"},
+	{"quiet", "LOG_QUIET", true, "Only log warnings and errors"},
+	{"log-level", "LOG_LEVEL", false, "Log level: debug, info, warn or error"},
+	{"log-format", "LOG_FORMAT", false, "Log format: plain, text or json"},
+}
+
+// options are shared by every subcommand and resolved once, here
+type options struct {
+	outDir string
+
+	// Set for configured commands: defaults, then the config file, then the
+	// selected profile, then environment variables and global flags
+	api       *config.Config // Credentials; check apiErr before use
+	apiErr    error
+	file      *config.File // Nil without a config file
+	profile   *config.Profile
+	trading   *types.Config
+	execution *types.ExecutionConfig
+	transport http.RoundTripper // Every exchange client's, so one call budget bounds them all
+}
+
+// path places a pipeline file in the output directory
+func (o *options) path(name string) string {
+	if o.outDir == "" {
+		return name
+	}
+	return filepath.Join(o.outDir, name)
+}
+
+// fetcher creates a market data fetcher on the shared exchange transport
+func (o *options) fetcher() *market.Fetcher {
+	fetcher := market.NewFetcher()
+	fetcher.SetTransport(o.transport)
+	return fetcher
+}
+
+// rateManager creates a rate manager on the shared exchange transport
+func (o *options) rateManager(config *types.Config) *exchange.RateManager {
+	rateManager := exchange.NewRateManager(config)
+	rateManager.SetTransport(o.transport)
+	return rateManager
+}
+
+// client creates a trading client on the shared exchange transport
+func (o *options) client(apiKey, apiSecret string) *coindcx.Client {
+	client := coindcx.NewClient(apiKey, apiSecret)
+	client.SetTransport(o.transport)
+	return client
+}
+
+// paper reports whether trading is simulated (DRY_RUN or a paper profile)
+func (o *options) paper() bool {
+	return o.execution != nil && o.execution.DryRun
+}
+
+// credentials returns the API configuration, exiting when it failed to load
+// unless trading is simulated: paper trading never signs requests
+func (o *options) credentials() *config.Config {
+	if o.apiErr == nil {
+		return o.api
+	}
+	if !o.paper() {
+		fatal("❌ Error loading API config", "error", o.apiErr)
+	}
+	return &config.Config{}
+}
+
+func usage() {
+	fmt.Println("Usage: cdcx [flags] <command> [args]")
+	fmt.Println("\nCommands:")
+	for _, c := range commands {
+		fmt.Printf("  %-11s %s\n", c.name, c.summary)
+	}
+	fmt.Println("\nFlags (each also settable through its environment variable):")
+	for _, f := range globalFlags {
+		arg := " <value>"
+		if f.boolean {
+			arg = ""
+		}
+		fmt.Printf("  %-26s %s (%s)\n", "--"+f.name+arg, f.help, f.env)
+	}
+	os.Exit(1)
+}
+
+func main() {
+	args := parseGlobalFlags(os.Args[1:])
+	if err := logging.SetupFromEnv(); err != nil {
+		fatal("❌ Invalid logging settings", "error", err)
+	}
+	if len(args) == 0 {
+		usage()
+	}
+
+	var selected *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			selected = &commands[i]
+		}
+	}
+	if selected == nil {
+		usage()
+	}
+	// init creates the data directory rather than running in it
+	if selected.name != "init" {
+		enterDataDir()
+	}
+
+	opts := &options{outDir: os.Getenv("CDCX_OUT_DIR")}
+	if selected.configured {
+		resolveConfig(opts)
+	}
+	// A relative directory is inside CDCX_DATA_DIR
+	if opts.outDir != "" {
+		if err := os.MkdirAll(opts.outDir, 0755); err != nil {
+			fatal("❌ Error creating output directory", "error", err)
+		}
+	}
+
+	selected.run(opts, args[1:])
+}
+
+// enterDataDir switches into CDCX_DATA_DIR, once and before any command,
+// so every relative data file (pairs, logs, caches, queues) lives there.
+// The .env it may be set in is loaded first; an explicit --env-file that
+// can't be read is left for the command loading credentials to report.
+func enterDataDir() {
+	config.LoadEnvFile()
+	dataDir := config.DataDir()
+	if dataDir == "" {
+		return
+	}
+	if err := os.Chdir(dataDir); err != nil {
+		fatal("❌ Error entering data directory", "path", dataDir, "error", err)
+	}
+}
+
+// parseGlobalFlags exports the global flags found anywhere in args (as
+// --name value or --name=value) and returns the remaining arguments
+func parseGlobalFlags(args []string) []string {
+	rest := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		matched := false
+		for _, f := range globalFlags {
+			value, hasValue := strings.CutPrefix(arg, "--"+f.name+"=")
+			switch {
+			case hasValue:
+			case arg != "--"+f.name:
+				continue
+			case f.boolean:
+				value = "true"
+			case i+1 < len(args):
+				i++
+				value = args[i]
+			default:
+				fatal("❌ Flag needs a value", "flag", "--"+f.name)
+			}
+			os.Setenv(f.env, value)
+			matched = true
+			break
+		}
+		if !matched {
+			rest = append(rest, arg)
+		}
+	}
+	return rest
+}
+
+// resolveConfig builds the configuration every trading command runs with.
+// Invalid overrides stop the command rather than silently falling back.
+func resolveConfig(opts *options) {
+	// Load API configuration first: its .env may select the profile
+	opts.api, opts.apiErr = config.Load()
+
+	opts.trading, opts.execution = types.DefaultConfig(), types.DefaultExecutionConfig()
+	file, err := config.ApplySelectedFile(opts.trading, opts.execution)
+	if err != nil {
+		fatal("❌ Error loading config file", "error", err, "hint", "Check it with: cdcx config validate")
+	}
+	opts.file = file
+	profile, err := config.ApplySelectedProfile(file, opts.trading, opts.execution)
+	if err != nil {
+		fatal("❌ Error applying profile", "error", err)
+	}
+	opts.profile = profile
+	opts.api, opts.apiErr = config.UseProfileAccount(opts.api, opts.apiErr, profile)
+
+	if errs := config.ApplyEnvOverrides(opts.trading, opts.execution); len(errs) > 0 {
+		for _, err := range errs {
+			logger.Error("❌ Invalid override", "error", err)
+		}
+		os.Exit(1)
+	}
+	for _, setting := range config.Provenance(file, profile, opts.trading, opts.execution) {
+		if setting.Source == config.SourceEnv {
+			fmt.Printf("🔧 %s = %s (%s)\n", setting.Name, setting.Value, setting.Origin)
+		}
+	}
+
+	// Every exchange client the command creates is handed this one transport
+	opts.transport = exchange.NewTransport(nil, opts.trading)
+
+	if opts.trading.DetectFees {
+		detectFees(opts)
+	}
+
+	// Saved artifacts record the build and this hash of the parameters
+	version.Stamp(opts.trading, opts.execution)
+}
+
+// detectFees fills in the account's taker rates from its recent trades;
+// without credentials or history the configured rates stay in effect
+func detectFees(opts *options) {
+	if opts.apiErr != nil {
+		logger.Warn("⚠️ Fee detection skipped", "error", opts.apiErr)
+		return
+	}
+	client := opts.client(opts.api.APIKey, opts.api.APISecret)
+	rates, err := fees.NewService(opts.trading, client).Detect(context.Background())
+	if err != nil {
+		logger.Warn("⚠️ Fee detection failed, keeping configured rates", "error", err)
+		return
+	}
+	if len(rates) == 0 {
+		fmt.Printf("🧾 No recent trades to detect fees from; using %.2f%%\n", opts.trading.FeeRate*100)
+	}
+	for _, rate := range rates {
+		source := fmt.Sprintf("detected from %d trades", rate.Trades)
+		if rate.Configured {
+			source = "FEE_SCHEDULE, kept over detection"
+		}
+		fmt.Printf("🧾 %s taker fee %.3f%% (%s)\n", rate.MarketType, rate.Rate*100, source)
+	}
+}
+
+func parseFloat(s string) float64 {
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0.0
+	}
+	return val
+}
+
+func operator() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// notifyEvents routes the engine's events to the NOTIFY_* backends. The
+// returned func waits for notifications still being delivered.
+func notifyEvents(engine *arbitrage.Engine) func() {
+	notifier, err := notify.FromEnv()
+	if err != nil {
+		fatal("❌ Invalid notification settings", "error", err)
+	}
+	if notifier == nil {
+		return func() {}
+	}
+	engine.Events().Subscribe(notifier.Handle)
+	fmt.Printf("📣 Notifications → %s\n", strings.Join(notifier.Backends(), ", "))
+	return notifier.Flush
+}
+
+// confirmTrades has the engine preview every trade at the terminal and wait
+// for an answer (CONFIRM_TRADES). In session mode the first approval lets
+// the rest of the run trade unattended.
+func confirmTrades(engine *arbitrage.Engine, mode string) {
+	switch mode {
+	case "", "off":
+		return
+	case "trade", "session":
+	default:
+		fatal("❌ Invalid CONFIRM_TRADES: use off, trade or session", "mode", mode)
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		fatal("❌ CONFIRM_TRADES needs an interactive terminal", "mode", mode)
+	}
+
+	prompt := "❓ Place this trade? [y]es, [n]o, [a]ll remaining, [q]uit trading: "
+	if mode == "session" {
+		prompt = "❓ Place this trade and run unattended from here? [y]es, [n]o, [q]uit trading: "
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var mu sync.Mutex // Concurrent executions queue up for the terminal
+	approveAll, declineAll := false, false
+	engine.SetConfirm(func(preview arbitrage.TradePreview) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if approveAll || declineAll {
+			return approveAll
+		}
+
+		fmt.Printf("\n%s\n%s", preview, prompt)
+		answer, _ := reader.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			approveAll = mode == "session"
+			return true
+		case "a", "all":
+			approveAll = true
+			return true
+		case "q", "quit":
+			declineAll = true
+			fmt.Println("🛑 Declining every remaining trade")
+			return false
+		}
+		fmt.Println("⏭️ Skipped")
+		return false
+	})
+	fmt.Printf("🙋 Trades wait for confirmation at the terminal (%s)\n", mode)
+}
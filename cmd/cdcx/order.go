@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/market"
+)
+
+func orderUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cdcx order stop-limit <market> <buy|sell> <quantity> <stop_price> <limit_price> [--yes]")
+	fmt.Println("  Places a stop-limit order that rests until the stop price trades, e.g. to")
+	fmt.Println("  guard stranded inventory by hand. --yes places it without asking; DRY_RUN")
+	fmt.Println("  simulates it.")
+	os.Exit(1)
+}
+
+func runOrder(opts *options, args []string) {
+	confirmed := false
+	positional := []string{}
+	for _, arg := range args {
+		if arg == "--yes" {
+			confirmed = true
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	if len(positional) != 6 || positional[0] != "stop-limit" {
+		orderUsage()
+	}
+
+	symbol, side := strings.ToUpper(positional[1]), strings.ToLower(positional[2])
+	if side != "buy" && side != "sell" {
+		orderUsage()
+	}
+	values := make([]float64, 3)
+	for i, name := range []string{"quantity", "stop price", "limit price"} {
+		value, err := strconv.ParseFloat(positional[3+i], 64)
+		if err != nil || value <= 0 {
+			fatal("❌ Invalid "+name, "value", positional[3+i])
+		}
+		values[i] = value
+	}
+	quantity, stopPrice, limitPrice := values[0], values[1], values[2]
+
+	fetcher := opts.fetcher()
+	var venue executor.Executor
+	if opts.paper() {
+		markets, err := fetcher.GetMarketDetails()
+		if err != nil {
+			fatal("❌ Error loading markets", "error", err)
+		}
+		venue = executor.NewSimulatedExecutor(fetcher, markets, opts.trading.FeeRate, nil)
+		fmt.Println("📝 DRY RUN - the order is simulated")
+	} else {
+		cfg := opts.credentials()
+		venue = executor.NewCapabilityGuard(executor.NewCoinDCXExecutor(opts.client(cfg.APIKey, cfg.APISecret)), fetcher)
+	}
+
+	fmt.Printf("🛡️ Stop-limit %s %s %s: stop %s, limit %s\n", strings.ToUpper(side),
+		market.FormatQuantity(symbol, quantity), symbol,
+		market.FormatPrice(symbol, stopPrice), market.FormatPrice(symbol, limitPrice))
+	if !confirmed {
+		fmt.Print("⚠️ Place this order? (1=YES, 0=NO): ")
+		var choice string
+		fmt.Scanln(&choice)
+
+		if choice != "1" {
+			fmt.Println("❌ Order cancelled")
+			return
+		}
+	}
+
+	order, err := executor.PlaceStopLimit(venue, side, symbol, quantity, stopPrice, limitPrice)
+	if err != nil {
+		fatal("❌ Stop-limit order failed", "market", symbol, "error", err)
+	}
+	fmt.Printf("✅ Order %s %s\n", order.ID, order.Status)
+}
@@ -2,52 +2,44 @@ package main
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/b-thark/cdcx-api/pkg/pairs"
-	"github.com/b-thark/cdcx-api/pkg/types"
 )
 
-func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-
+func runPairs(opts *options, args []string) {
 	fmt.Println("🔍 CoinDCX Arbitrage Pair Detector")
 	fmt.Println("==================================")
 	fmt.Println("🎯 Finding currencies with multiple trading pairs for arbitrage")
 
-	// Load configuration
-	config := types.DefaultConfig()
-
-	// Allow user to enable all pairs via environment variable
-	if os.Getenv("ENABLE_ALL_PAIRS") == "true" {
-		config.EnableAllPairs = true
+	config := opts.trading
+	if config.EnableAllPairs {
 		fmt.Println("🌐 ALL PAIRS MODE: Including all base currencies")
 	} else {
 		fmt.Printf("🔒 FILTERED MODE: Only including %v\n", config.ValidCurrencies)
-		fmt.Println("💡 Set ENABLE_ALL_PAIRS=true to include all currencies")
+		fmt.Println("💡 Pass --all-pairs (or set ENABLE_ALL_PAIRS=true) to include all currencies")
 	}
 
 	// Create analyzer
 	analyzer := pairs.NewAnalyzer(config)
+	analyzer.SetTransport(opts.transport)
 
 	// Extract arbitrage pairs
 	fmt.Println("\n📊 Extracting arbitrage pairs...")
 	arbitragePairs, err := analyzer.ExtractArbitragePairs()
 	if err != nil {
-		log.Fatalf("❌ Error extracting pairs: %v", err)
+		fatal("❌ Error extracting pairs", "error", err)
 	}
 
 	// Display results
 	analyzer.DisplaySummary(arbitragePairs)
 
 	// Save pairs to file
-	filename := "arbitrage_pairs.json"
+	filename := opts.path("arbitrage_pairs.json")
 	err = analyzer.SavePairs(arbitragePairs, filename)
 	if err != nil {
-		log.Fatalf("❌ Error saving pairs: %v", err)
+		fatal("❌ Error saving pairs", "error", err)
 	}
 
 	fmt.Printf("\n💾 Saved arbitrage pairs to %s\n", filename)
-	fmt.Printf("🚀 Ready for opportunity detection! Run: go run cmd/opportunity-detector/main.go\n")
+	fmt.Printf("🚀 Ready for opportunity detection! Run: cdcx detect\n")
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/pairs"
+	"github.com/b-thark/cdcx-api/pkg/recorder"
+)
+
+const recordingFile = "book_recording.jsonl.gz"
+
+func recordUsage() {
+	fmt.Println("Usage: cdcx record [--interval=2s] [--minutes=N] [--currencies=BTC,ETH] [<file>]")
+	fmt.Println("  Capture the order books of the arbitrage pairs (arbitrage_pairs.json) until stopped,")
+	fmt.Println("  compressed, to " + recordingFile + " by default. Replay them with")
+	fmt.Println("  cdcx detect --replay=<file> and cdcx depth --replay=<file>")
+	os.Exit(1)
+}
+
+func runRecord(opts *options, args []string) {
+	interval := 2 * time.Second
+	var duration time.Duration
+	var currencies map[string]bool
+	file := opts.path(recordingFile)
+
+	for _, arg := range args {
+		var err error
+		switch {
+		case strings.HasPrefix(arg, "--interval="):
+			interval, err = time.ParseDuration(strings.TrimPrefix(arg, "--interval="))
+		case strings.HasPrefix(arg, "--minutes="):
+			duration = time.Duration(parseFloat(strings.TrimPrefix(arg, "--minutes="))) * time.Minute
+		case strings.HasPrefix(arg, "--currencies="):
+			currencies = make(map[string]bool)
+			for _, currency := range strings.Split(strings.TrimPrefix(arg, "--currencies="), ",") {
+				currencies[strings.ToUpper(strings.TrimSpace(currency))] = true
+			}
+		case strings.HasPrefix(arg, "--"):
+			recordUsage()
+		default:
+			file = arg
+		}
+		if err != nil || interval <= 0 {
+			fatal("❌ Invalid argument", "arg", arg)
+		}
+	}
+
+	fmt.Println("📼 CoinDCX Order Book Recorder")
+	fmt.Println("==============================")
+	fmt.Println("💡 No orders are placed in this mode")
+	fmt.Printf("🏷️ Build %s\n", version.Current().Label())
+
+	arbitragePairs, err := pairs.NewAnalyzer(opts.trading).LoadPairs(opts.path("arbitrage_pairs.json"))
+	if err != nil {
+		fatal("❌ Error loading pairs", "error", err, "hint", "Run pair detection first: cdcx pairs")
+	}
+
+	pairNames, quotes := []string{}, []string{}
+	seen := make(map[string]bool)
+	for currency, group := range arbitragePairs {
+		if currencies != nil && !currencies[currency] {
+			continue
+		}
+		for _, pair := range group.Pairs {
+			pairNames = append(pairNames, pair.Pair)
+			if !seen[pair.BaseCurrency] {
+				seen[pair.BaseCurrency] = true
+				quotes = append(quotes, pair.BaseCurrency)
+			}
+		}
+	}
+	if len(pairNames) == 0 {
+		fatal("❌ No pairs to record")
+	}
+
+	rateManager := opts.rateManager(opts.trading)
+	rec := recorder.NewRecorder(file, opts.fetcher(), pairNames, func(currency string) (float64, error) {
+		return rateManager.ConvertToINR(1, currency)
+	}, quotes)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	fmt.Printf("⏱️ Recording %d books every %s → %s (Ctrl-C stops)\n", len(pairNames), interval, file)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for frames := 1; ; frames++ {
+		frame, err := rec.Capture()
+		if err != nil {
+			fatal("❌ Error capturing frame", "error", err)
+		}
+		if frames%30 == 0 {
+			logger.Info("📼 Frames recorded", "frames", frames, "books", len(frame.Books))
+		}
+
+		select {
+		case <-ctx.Done():
+			rateManager.SaveCache()
+			fmt.Printf("\n🛑 Stopped after %d frames\n", frames)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadRecording loads a recording for a --replay flag
+func loadRecording(file string) *recorder.Recording {
+	recording, err := recorder.Load(file)
+	if err != nil {
+		fatal("❌ Error loading recording", "error", err, "hint", "Record one first: cdcx record")
+	}
+	first, last := recording.Span()
+	fmt.Printf("📼 Replaying %d frames recorded %s → %s\n", len(recording.Frames),
+		first.Format("2006-01-02 15:04:05"), last.Format("2006-01-02 15:04:05"))
+	return recording
+}
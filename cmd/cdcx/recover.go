@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/pkg/arbitrage"
+)
+
+func recoverUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cdcx recover [--min-inr=<value>] [--yes]")
+	fmt.Println("  Finishes arbitrages a crashed run left between their legs, then sells every")
+	fmt.Println("  balance other than USDT and INR worth at least --min-inr (default")
+	fmt.Println("  RECOVERY_SWEEP_MIN_INR) by its best route: direct to USDT, or via INR or BTC.")
+	fmt.Println("  --yes sells without asking.")
+	os.Exit(1)
+}
+
+func runRecover(opts *options, args []string) {
+	minValueINR := opts.execution.RecoverySweepMinINR
+	confirmed := false
+	for _, arg := range args {
+		switch {
+		case arg == "--yes":
+			confirmed = true
+		case strings.HasPrefix(arg, "--min-inr="):
+			value, err := strconv.ParseFloat(strings.TrimPrefix(arg, "--min-inr="), 64)
+			if err != nil || value < 0 {
+				fatal("❌ Invalid --min-inr", "value", strings.TrimPrefix(arg, "--min-inr="))
+			}
+			minValueINR = value
+		default:
+			recoverUsage()
+		}
+	}
+
+	fmt.Println("🔄 CoinDCX Recovery Tool")
+	fmt.Println("========================")
+
+	cfg := opts.credentials()
+	engine := arbitrage.NewEngine(cfg, opts.execution, opts.transport)
+	engine.SetTradingConfig(opts.trading)
+	if stateDir := cfg.StateDir(); stateDir != "" {
+		if err := engine.SetStateDir(stateDir); err != nil {
+			fatal("❌ Error setting state directory", "error", err)
+		}
+		fmt.Printf("👤 Account: %s (state and logs in %s)\n", cfg.Account, stateDir)
+	}
+
+	// Arbitrages a crashed run left between their legs: sell or unwind what they bought
+	fmt.Println("\n🩹 Checking the execution journal...")
+	resumeInterrupted(engine)
+
+	fmt.Printf("\n🔍 Scanning balances for assets worth ₹%.2f+...\n", minValueINR)
+	assets, err := engine.FindStranded(minValueINR)
+	if err != nil {
+		fatal("❌ Error getting balances", "error", err)
+	}
+
+	sellable := []arbitrage.StrandedAsset{}
+	for _, asset := range assets {
+		if asset.Reason != "" {
+			fmt.Printf("   ⚠️ %s: %.8f (₹%.2f) - %s\n", asset.Currency, asset.Quantity, asset.ValueINR, asset.Reason)
+			continue
+		}
+		if asset.Route == "" {
+			fmt.Printf("   💎 %s: %.8f (₹%.2f) → first listed USDT or INR market\n", asset.Currency, asset.Quantity, asset.ValueINR)
+		} else {
+			fmt.Printf("   💎 %s: %.8f (₹%.2f) → %s, expect %.4f USDT\n",
+				asset.Currency, asset.Quantity, asset.ValueINR, asset.Route, asset.ExpectedUSDT)
+		}
+		sellable = append(sellable, asset)
+	}
+	if len(sellable) == 0 {
+		fmt.Println("✅ Nothing stranded to sell")
+		return
+	}
+
+	if !confirmed {
+		fmt.Printf("\n⚠️ Sell these %d asset(s) for USDT? (1=YES, 0=NO): ", len(sellable))
+		var choice string
+		fmt.Scanln(&choice)
+
+		if choice != "1" {
+			fmt.Println("❌ Recovery cancelled")
+			return
+		}
+	}
+
+	for _, asset := range sellable {
+		result := engine.LiquidateStranded(asset)
+		if result.Success {
+			fmt.Printf("✅ %s sold on %s at %.6f %s (order %s)\n", asset.Currency, result.Market, result.SellPrice, result.Quote, result.OrderID)
+		} else {
+			fmt.Printf("⚠️ %s not sold: %s\n", asset.Currency, result.Reason)
+		}
+	}
+
+	fmt.Println("\n🎯 Recovery complete!")
+}
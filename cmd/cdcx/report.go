@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/notes"
+	"github.com/b-thark/cdcx-api/internal/report"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func reportUsage() {
+	fmt.Println("Usage: cdcx report [--format=markdown|csv] [--since=YYYY-MM-DD] [--until=YYYY-MM-DD] <dir-or-glob>...")
+	fmt.Println("  One source: logs are grouped by execution settings")
+	fmt.Println("  Several sources (e.g. a live and a backtest directory): one row per source")
+	fmt.Println("  Without --since/--until only the period all strategies cover is compared")
+	fmt.Println("  Markdown adds each strategy's daily capital utilization and idle time")
+	os.Exit(1)
+}
+
+func runReport(opts *options, args []string) {
+	format := "markdown"
+	var since, until time.Time
+	sources := []string{}
+
+	for _, arg := range args {
+		var err error
+		switch {
+		case strings.HasPrefix(arg, "--format="):
+			format = strings.TrimPrefix(arg, "--format=")
+		case strings.HasPrefix(arg, "--since="):
+			since, err = time.Parse("2006-01-02", strings.TrimPrefix(arg, "--since="))
+		case strings.HasPrefix(arg, "--until="):
+			until, err = time.Parse("2006-01-02", strings.TrimPrefix(arg, "--until="))
+			until = until.Add(24*time.Hour - time.Nanosecond)
+		case strings.HasPrefix(arg, "--"):
+			reportUsage()
+		default:
+			sources = append(sources, arg)
+		}
+		if err != nil {
+			fatal("❌ Invalid date", "arg", arg, "error", err)
+		}
+	}
+	if len(sources) == 0 || (format != "markdown" && format != "csv") {
+		reportUsage()
+	}
+
+	groups, err := loadGroups(sources)
+	if err != nil {
+		fatal("❌ Error loading execution logs", "error", err)
+	}
+	if len(groups) == 0 {
+		fatal("❌ No execution logs found")
+	}
+
+	if since.IsZero() && until.IsZero() && len(groups) > 1 {
+		since, until = commonPeriod(groups)
+		if !until.After(since) {
+			fatal("❌ Strategies have no period in common; pass --since/--until")
+		}
+	}
+
+	labels := make([]string, 0, len(groups))
+	for label := range groups {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	summaries := make([]report.Summary, 0, len(labels))
+	for _, label := range labels {
+		summaries = append(summaries, report.Summarize(label, groups[label], since, until))
+	}
+
+	if format == "csv" {
+		if err := report.WriteCSV(os.Stdout, summaries); err != nil {
+			fatal("❌ Error writing CSV", "error", err)
+		}
+		return
+	}
+	report.WriteMarkdown(os.Stdout, summaries)
+
+	// Whether MaxPositionUSDT and the thresholds leave capital sitting idle
+	for _, label := range labels {
+		if days := report.DailyUtilization(groups[label], since, until); len(days) > 0 {
+			report.WriteUtilization(os.Stdout, label, days)
+		}
+	}
+}
+
+// loadGroups loads logs per strategy, with manual resolutions applied
+func loadGroups(sources []string) (map[string][]types.ExecutionResult, error) {
+	store := notes.NewStore("trade_annotations.json")
+	if err := store.Load(); err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]types.ExecutionResult)
+	for _, source := range sources {
+		pattern := source
+		if info, err := os.Stat(source); err == nil && info.IsDir() {
+			pattern = filepath.Join(source, "execution_log_*.json")
+		}
+
+		results, err := report.LoadLogs(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range results {
+			store.Apply(&results[i])
+
+			label := filepath.Base(source)
+			if len(sources) == 1 {
+				label = report.StrategyLabel(results[i].Config)
+			}
+			groups[label] = append(groups[label], results[i])
+		}
+	}
+	return groups, nil
+}
+
+// commonPeriod is the overlap of every group's span
+func commonPeriod(groups map[string][]types.ExecutionResult) (time.Time, time.Time) {
+	var since, until time.Time
+	for _, results := range groups {
+		from, to := report.Span(results)
+		if since.IsZero() || from.After(since) {
+			since = from
+		}
+		if until.IsZero() || to.Before(until) {
+			until = to
+		}
+	}
+	return since, until
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/shadow"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+const shadowFile = "shadow_decisions.jsonl"
+
+// startShadow runs the configured shadow strategies beside a live run until
+// ctx is done. The returned function stops them and prints how they did.
+func startShadow(ctx context.Context, config *types.Config, books market.BookFetcher, transport http.RoundTripper) func() {
+	horizon := time.Duration(config.ShadowHorizonSeconds) * time.Second
+	path := filepath.Join(stateDir, shadowFile)
+	runner := shadow.NewRunner(books, horizon, path)
+	for _, name := range config.ShadowStrategies {
+		strategy, err := shadow.New(name, config)
+		if err != nil {
+			fatal("❌ Unknown shadow strategy", "error", err)
+		}
+		// Strategies that fetch for themselves share the run's call budget
+		if fetching, ok := strategy.(interface{ SetTransport(http.RoundTripper) }); ok {
+			fetching.SetTransport(transport)
+		}
+		runner.Register(strategy)
+	}
+	fmt.Printf("👻 Shadow strategies: %s, deciding every %ds and scored %s later → %s (never executed)\n",
+		strings.Join(config.ShadowStrategies, ", "), config.ShadowIntervalSeconds, horizon, path)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runner.Run(ctx, time.Duration(max(config.ShadowIntervalSeconds, 1))*time.Second)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		printShadowSummaries(runner.Summaries())
+	}
+}
+
+func runShadow(opts *options, args []string) {
+	if len(args) > 1 || (len(args) == 1 && strings.HasPrefix(args[0], "--")) {
+		fmt.Println("Usage: cdcx shadow [<log>]")
+		fmt.Println("  Summarize how shadow strategies' decisions (SHADOW_STRATEGIES) turned out; the")
+		fmt.Println("  log defaults to " + shadowFile)
+		os.Exit(1)
+	}
+	path := opts.path(shadowFile)
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	decisions, err := shadow.LoadDecisions(path)
+	if err != nil {
+		fatal("❌ Error loading shadow decisions", "error", err,
+			"hint", "Run cdcx live with SHADOW_STRATEGIES="+strings.Join(shadow.Names(), ",")+" first")
+	}
+	fmt.Printf("👻 %d shadow decisions in %s\n", len(decisions), path)
+	printShadowSummaries(shadow.Summarize(decisions))
+}
+
+func printShadowSummaries(summaries []shadow.Summary) {
+	if len(summaries) == 0 {
+		fmt.Println("👻 No shadow decisions evaluated")
+		return
+	}
+	fmt.Println("\n| Strategy | Decisions | Not re-priced | Wins | Avg expected % | Avg realized % | Expected ₹ | Realized ₹ |")
+	fmt.Println("|---|---:|---:|---:|---:|---:|---:|---:|")
+	for _, s := range summaries {
+		fmt.Printf("| %s | %d | %d | %d | %.3f | %.3f | %.2f | %.2f |\n", s.Strategy, s.Decisions, s.Failed, s.Wins,
+			s.AvgExpectedPct(), s.AvgRealizedPct(), s.ExpectedProfitINR, s.RealizedProfitINR)
+	}
+	fmt.Println("\n💡 Realized is each decision re-priced on the books SHADOW_HORIZON_SECONDS later, walking every level")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/control"
+	"github.com/b-thark/cdcx-api/pkg/exchange"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func simulateUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cdcx simulate <market> <buy|sell> <quantity>   Expected fill, fees, taxes and net for a market order now")
+	fmt.Println("  cdcx simulate --serve=localhost:8091           Answer GET /simulate?market=BTCUSDT&side=sell&quantity=0.01")
+	fmt.Println("No order is placed. The live command's CONTROL_ADDR serves the same /simulate endpoint.")
+	os.Exit(1)
+}
+
+func runSimulate(opts *options, args []string) {
+	if len(args) == 1 && strings.HasPrefix(args[0], "--serve=") {
+		addr := strings.TrimPrefix(args[0], "--serve=")
+		simulator, err := newSimulator(opts.fetcher(), opts.trading, opts.rateManager(opts.trading))
+		if err != nil {
+			fatal("❌ Error creating simulator", "error", err)
+		}
+		server := control.NewServer(addr)
+		server.HandleQuery("/simulate", simulateQuery(simulator))
+		if err := server.Start(); err != nil {
+			fatal("❌ Simulation API", "error", err)
+		}
+		fmt.Printf("🧮 Trade simulations on http://%s/simulate (Ctrl-C stops)\n", addr)
+		select {}
+	}
+	if len(args) != 3 {
+		simulateUsage()
+	}
+
+	quantity, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		fatal("❌ Invalid quantity", "quantity", args[2])
+	}
+	simulator, err := newSimulator(opts.fetcher(), opts.trading, opts.rateManager(opts.trading))
+	if err != nil {
+		fatal("❌ Error creating simulator", "error", err)
+	}
+	sim, err := simulator.Simulate(args[0], strings.ToLower(args[1]), quantity)
+	if err != nil {
+		fatal("❌ Simulation failed", "error", err)
+	}
+
+	fmt.Printf("\n🧮 %s %s %s\n", strings.ToUpper(sim.Side), market.FormatQuantity(sim.Market, sim.Quantity), sim.Market)
+	if sim.Filled < sim.Quantity {
+		fmt.Printf("⚠️ The book only holds %s; the rest would not fill\n", market.FormatQuantity(sim.Market, sim.Filled))
+	}
+	fmt.Printf("   Best price:  %s %s\n", market.FormatPrice(sim.Market, sim.BestPrice), sim.Quote)
+	fmt.Printf("   Fill price:  %s %s (%.3f%% impact)\n", market.FormatPrice(sim.Market, sim.FillPrice), sim.Quote, sim.ImpactPct)
+	fmt.Printf("   Notional:    %.8f %s\n", sim.Cost.Notional, sim.Quote)
+	fmt.Printf("   Fee:         %.8f %s (%.3f%%)\n", sim.Cost.Fee, sim.Quote, sim.FeeRate*100)
+	fmt.Printf("   GST on fee:  %.8f %s\n", sim.Cost.GST, sim.Quote)
+	if sim.Side == "sell" {
+		fmt.Printf("   TDS:         %.8f %s\n", sim.Cost.TDS, sim.Quote)
+		fmt.Printf("💰 Net proceeds: %.8f %s (₹%.2f)\n", sim.Cost.Net, sim.Quote, sim.NetINR)
+	} else {
+		fmt.Printf("💸 Total cost:   %.8f %s (₹%.2f)\n", sim.Cost.Net, sim.Quote, sim.NetINR)
+	}
+}
+
+// newSimulator indexes the exchange's markets for trade simulations
+func newSimulator(fetcher *market.Fetcher, config *types.Config, rates *exchange.RateManager) (*market.Simulator, error) {
+	details, err := fetcher.GetMarketDetails()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch markets: %v", err)
+	}
+	market.RegisterPrecisions(details)
+	return market.NewSimulator(fetcher, market.NewMarkets(details), config, func(currency string) (float64, error) {
+		return rates.ReportINR(1, currency)
+	}), nil
+}
+
+// simulateQuery answers /simulate?market=...&side=buy|sell&quantity=...
+func simulateQuery(simulator *market.Simulator) func(url.Values) (interface{}, error) {
+	return func(query url.Values) (interface{}, error) {
+		quantity, err := strconv.ParseFloat(query.Get("quantity"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("quantity must be a number")
+		}
+		return simulator.Simulate(query.Get("market"), strings.ToLower(query.Get("side")), quantity)
+	}
+}
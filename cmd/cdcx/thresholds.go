@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+	"github.com/b-thark/cdcx-api/pkg/pairs"
+)
+
+const snapshotFile = "detector_snapshot.json"
+
+func thresholdsUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cdcx thresholds capture [<file>]      Record the order books of every arbitrage pair")
+	fmt.Println("  cdcx thresholds [--margins=1,2,3] [--liquidity=50,100,500] [<file>]")
+	fmt.Println("                                        Re-run detection on the recording per combination")
+	fmt.Println("The recording defaults to " + snapshotFile + "; pairs come from arbitrage_pairs.json")
+	os.Exit(1)
+}
+
+func runThresholds(opts *options, args []string) {
+	if len(args) > 0 && args[0] == "capture" {
+		if len(args) > 2 {
+			thresholdsUsage()
+		}
+		file := opts.path(snapshotFile)
+		if len(args) == 2 {
+			file = args[1]
+		}
+		captureSnapshot(opts, file)
+		return
+	}
+
+	margins := []float64{0.5, 1, 1.5, 2, 2.5, 3}
+	liquidities := []float64{50, 100, 250, 500, 1000}
+	file := opts.path(snapshotFile)
+	for _, arg := range args {
+		var err error
+		switch {
+		case strings.HasPrefix(arg, "--margins="):
+			margins, err = parseFloats(strings.TrimPrefix(arg, "--margins="))
+		case strings.HasPrefix(arg, "--liquidity="):
+			liquidities, err = parseFloats(strings.TrimPrefix(arg, "--liquidity="))
+		case strings.HasPrefix(arg, "--"):
+			thresholdsUsage()
+		default:
+			file = arg
+		}
+		if err != nil {
+			fatal("❌ Invalid argument", "arg", arg, "error", err)
+		}
+	}
+
+	snapshot, err := market.LoadSnapshot(file)
+	if err != nil {
+		fatal("❌ Error loading snapshot", "error", err, "hint", "Record one first: cdcx thresholds capture")
+	}
+	arbitragePairs, err := pairs.NewAnalyzer(opts.trading).LoadPairs(opts.path("arbitrage_pairs.json"))
+	if err != nil {
+		fatal("❌ Error loading pairs", "error", err, "hint", "Run pair detection first: cdcx pairs")
+	}
+
+	fmt.Printf("🧪 %d threshold combinations against %d books captured %s\n",
+		len(margins)*len(liquidities), len(snapshot.Books), snapshot.CapturedAt.Format("2006-01-02 15:04:05"))
+
+	// Every run logs each pair it prices; only the table is worth reading
+	unmute := logging.Mute()
+	runs := opportunity.CompareThresholds(opts.trading, snapshot, arbitragePairs, margins, liquidities)
+	unmute()
+
+	fmt.Println("\n| Min margin % | Min liquidity ₹ | Opportunities | Currencies | Best % | Avg % | Est. profit ₹ |")
+	fmt.Println("|---:|---:|---:|---:|---:|---:|---:|")
+	for _, run := range runs {
+		fmt.Printf("| %.2f | %.0f | %d | %d | %.2f | %.2f | %.2f |\n", run.MinNetMargin, run.MinLiquidity,
+			run.Opportunities, run.Currencies, run.BestMarginPct, run.AvgMarginPct, run.EstProfitINR)
+	}
+	fmt.Println("\n💡 Est. profit is each viable route's net margin on what both best levels carry")
+}
+
+// captureSnapshot records the books of every arbitrage pair and the INR rate
+// of each quote currency
+func captureSnapshot(opts *options, file string) {
+	arbitragePairs, err := pairs.NewAnalyzer(opts.trading).LoadPairs(opts.path("arbitrage_pairs.json"))
+	if err != nil {
+		fatal("❌ Error loading pairs", "error", err, "hint", "Run pair detection first: cdcx pairs")
+	}
+
+	pairNames := []string{}
+	quotes := []string{}
+	seen := make(map[string]bool)
+	for _, group := range arbitragePairs {
+		for _, pair := range group.Pairs {
+			pairNames = append(pairNames, pair.Pair)
+			if !seen[pair.BaseCurrency] {
+				seen[pair.BaseCurrency] = true
+				quotes = append(quotes, pair.BaseCurrency)
+			}
+		}
+	}
+
+	fmt.Printf("📸 Capturing %d order books...\n", len(pairNames))
+	rateManager := opts.rateManager(opts.trading)
+	snapshot := market.CaptureSnapshot(opts.fetcher(), pairNames, func(currency string) (float64, error) {
+		return rateManager.ConvertToINR(1, currency)
+	}, quotes)
+	snapshot.Run = version.Current()
+
+	if err := snapshot.Save(file); err != nil {
+		fatal("❌ Error saving snapshot", "error", err)
+	}
+	fmt.Printf("💾 Saved %d books to %s\n", len(snapshot.Books), file)
+}
+
+func parseFloats(list string) ([]float64, error) {
+	values := []float64{}
+	for _, field := range strings.Split(list, ",") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/audit"
+	"github.com/b-thark/cdcx-api/internal/notes"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func timelineUsage() {
+	fmt.Println("Usage: cdcx timeline [--trail=audit_trail.jsonl] <execution_log.json> [<execution-id> | <buy-order-id> | #<n>]")
+	fmt.Println("  Renders one execution step by step from the audit trail, for post-mortems")
+	fmt.Println("  Without an order, a log with one execution shows it; otherwise its executions are listed")
+	os.Exit(1)
+}
+
+var kindIcons = map[string]string{
+	audit.KindDetection:            "🎯",
+	audit.KindValidation:           "🔬",
+	audit.KindAPICall:              "🌐",
+	events.KindOpportunityDetected: "📡",
+	events.KindOpportunityDropped:  "🗑️",
+	events.KindOrderPlaced:         "📤",
+	events.KindOrderFilled:         "✅",
+	events.KindExecutionCompleted:  "🏁",
+	events.KindRecoveryTriggered:   "⚠️",
+	events.KindRiskTripped:         "🛑",
+	events.KindBalanceMismatch:     "🚨",
+}
+
+func runTimeline(opts *options, args []string) {
+	trailPath := "audit_trail.jsonl"
+	positional := []string{}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--trail="):
+			trailPath = strings.TrimPrefix(arg, "--trail=")
+		case strings.HasPrefix(arg, "--"):
+			timelineUsage()
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		timelineUsage()
+	}
+
+	var result types.ExecutionResult
+	if err := utils.LoadJSON(positional[0], &result); err != nil {
+		fatal("❌ Error loading execution log", "file", positional[0], "error", err)
+	}
+	store := notes.NewStore("trade_annotations.json")
+	if err := store.Load(); err != nil {
+		fatal("❌ Error loading annotations", "error", err)
+	}
+	store.Apply(&result)
+
+	if len(positional) == 1 && len(result.Orders) != 1 {
+		listOrders(positional[0], result)
+		return
+	}
+	selector := ""
+	if len(positional) == 2 {
+		selector = positional[1]
+	}
+	order, ok := findOrder(result, selector)
+	if !ok {
+		fatal("❌ No such execution", "execution", selector, "file", positional[0])
+	}
+
+	entries, err := audit.Load(trailPath)
+	if err != nil {
+		fatal("❌ Error loading audit trail", "error", err)
+	}
+
+	render(order, audit.Timeline(entries, order))
+}
+
+// findOrder picks an execution by execution ID, buy order ID or 1-based
+// position (#n).
+// An empty selector picks the only execution.
+func findOrder(result types.ExecutionResult, selector string) (types.ExecutedOrder, bool) {
+	if selector == "" {
+		return result.Orders[0], len(result.Orders) == 1
+	}
+	if strings.HasPrefix(selector, "#") {
+		n, err := strconv.Atoi(strings.TrimPrefix(selector, "#"))
+		if err != nil || n < 1 || n > len(result.Orders) {
+			return types.ExecutedOrder{}, false
+		}
+		return result.Orders[n-1], true
+	}
+	for _, order := range result.Orders {
+		if order.ExecutionID == selector || order.BuyOrderID == selector {
+			return order, true
+		}
+	}
+	return types.ExecutedOrder{}, false
+}
+
+func listOrders(filename string, result types.ExecutionResult) {
+	fmt.Printf("📋 %s has %d executions; pick one by execution ID, buy order ID or #n:\n", filename, len(result.Orders))
+	for i, order := range result.Orders {
+		fmt.Printf("   #%d %s %s %s: ₹%.2f %s\n", i+1, outcomeIcon(order), order.StartTime.Format("15:04:05"),
+			order.Currency, order.ActualProfit, order.BuyOrderID)
+	}
+}
+
+func render(order types.ExecutedOrder, timeline []audit.Entry) {
+	fmt.Printf("🧾 EXECUTION TIMELINE: %s (%s → %s)\n", order.Currency, order.BuyMarket, order.SellMarket)
+	fmt.Println("==========================================")
+	fmt.Printf("Started:  %s (%dms)\n", order.StartTime.Format("2006-01-02 15:04:05.000"), order.ExecutionTimeMs)
+	if order.ExecutionID != "" {
+		fmt.Printf("ID:       %s\n", order.ExecutionID)
+	}
+	fmt.Printf("Orders:   buy %s, sell %s", orDash(order.BuyOrderID), orDash(order.SellOrderID))
+	if order.StopOrderID != "" {
+		fmt.Printf(", stop %s", order.StopOrderID)
+	}
+	fmt.Println()
+	fmt.Printf("Volume:   %.6f of %.6f planned at %.8f → %.8f\n", order.VolumeExecuted, order.PlannedVolume, order.BuyPrice, order.SellPrice)
+	fmt.Printf("Outcome:  %s ₹%.2f (expected ₹%.2f, %.2f%%)\n", outcomeIcon(order), order.ActualProfit, order.ExpectedProfit, order.ActualMarginPct)
+	if order.ErrorMessage != "" {
+		fmt.Printf("Error:    %s\n", order.ErrorMessage)
+	}
+
+	fmt.Println("\n📜 Timeline (relative to execution start):")
+	if len(timeline) == 0 {
+		fmt.Println("   No audit entries cover this execution (was the trail enabled, or moved with --trail?)")
+	}
+	for _, entry := range timeline {
+		icon := kindIcons[entry.Kind]
+		if icon == "" {
+			icon = "•"
+		}
+		subject := entry.Market
+		if subject == "" {
+			subject = entry.Currency
+		}
+		if entry.OrderID != "" {
+			subject += " " + entry.OrderID
+		}
+
+		fmt.Printf("   %9s %s %s %-20s %-22s %s\n", offset(entry.Time.Sub(order.StartTime)),
+			entry.Time.Format("15:04:05.000"), icon, entry.Kind, strings.TrimSpace(subject), entry.Summary)
+		if entry.Error != "" {
+			fmt.Printf("   %9s ❗ %s\n", "", entry.Error)
+		}
+	}
+
+	if books := order.DecisionBooks; books != nil {
+		fmt.Printf("\n📚 Books at decision (%s):\n", books.CapturedAt.Format("15:04:05.000"))
+		fmt.Printf("   %-28s %s\n", books.BuyMarket+" asks", books.SellMarket+" bids")
+		for i := 0; i < len(books.BuyAsks) || i < len(books.SellBids); i++ {
+			fmt.Printf("   %-28s %s\n", level(books.BuyAsks, i), level(books.SellBids, i))
+		}
+	}
+
+	l := order.Latency
+	fmt.Println("\n⏱️ Phases:")
+	fmt.Printf("   validation %dms, buy place %dms, buy fill %dms, sell place %dms, sell fill %dms\n",
+		l.ValidationMs, l.BuyPlaceMs, l.BuyFillMs, l.SellPlaceMs, l.SellFillMs)
+
+	if len(order.Slices) > 0 {
+		fmt.Println("\n🔪 Slices:")
+		for _, slice := range order.Slices {
+			status := "✅"
+			if !slice.Success {
+				status = "❌"
+			}
+			fmt.Printf("   %s #%d %s: %.6f of %.6f at %.8f → %.8f, %.2f%%, ₹%.2f %s\n", status, slice.Slice,
+				slice.Timestamp.Format("15:04:05.000"), slice.VolumeExecuted, slice.PlannedVolume,
+				slice.BuyPrice, slice.SellPrice, slice.MarginPct, slice.ActualProfit, slice.ErrorMessage)
+		}
+	}
+
+	if a := order.Attribution; a != nil {
+		fmt.Println("\n💹 Attribution:")
+		fmt.Printf("   spread ₹%.2f, buy drift ₹%.2f, sell drift ₹%.2f, fees ₹%.2f → realized ₹%.2f\n",
+			a.SpreadCaptured, a.BuyDrift, a.SellDrift, a.Fees, a.Realized)
+	}
+
+	if order.Annotation != nil {
+		fmt.Println("\n📝 Notes:")
+		if order.Annotation.Manual {
+			fmt.Printf("   ✋ Resolved by hand (P&L ₹%.2f)\n", order.Annotation.ManualPnLINR)
+		}
+		for _, note := range order.Annotation.Notes {
+			fmt.Printf("   %s (%s, %s)\n", note.Text, note.Author, note.CreatedAt.Format("2006-01-02 15:04"))
+		}
+	}
+}
+
+func outcomeIcon(order types.ExecutedOrder) string {
+	switch {
+	case order.Annotation != nil && order.Annotation.Manual:
+		return "✋"
+	case order.Success:
+		return "✅"
+	default:
+		return "❌"
+	}
+}
+
+func level(levels []types.OrderLevel, i int) string {
+	if i >= len(levels) {
+		return ""
+	}
+	return fmt.Sprintf("%.8f × %.4f", levels[i].Price, levels[i].Volume)
+}
+
+func offset(d time.Duration) string {
+	return fmt.Sprintf("%+dms", d.Milliseconds())
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/toggles"
+)
+
+func toggleUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cdcx toggle list")
+	fmt.Println("  cdcx toggle disable <currency> [<reason...>]")
+	fmt.Println("  cdcx toggle enable <currency>")
+	fmt.Println("Running engines pick up changes on their next validation; no restart needed.")
+	os.Exit(1)
+}
+
+func runToggle(opts *options, args []string) {
+	if len(args) < 1 {
+		toggleUsage()
+	}
+
+	store := toggles.NewStore(togglesFile)
+	if err := store.Load(); err != nil {
+		fatal("❌ Error loading toggles", "error", err)
+	}
+
+	switch args[0] {
+	case "list":
+		list(store)
+
+	case "disable":
+		if len(args) < 2 {
+			toggleUsage()
+		}
+		reason := strings.Join(args[2:], " ")
+		toggle, err := store.Disable(args[1], reason, operator())
+		if err != nil {
+			fatal("❌ Error saving toggle", "error", err)
+		}
+		fmt.Printf("⛔ Trading disabled for %s\n", toggle.Currency)
+
+	case "enable":
+		if len(args) != 2 {
+			toggleUsage()
+		}
+		enabled, err := store.Enable(args[1])
+		if err != nil {
+			fatal("❌ Error saving toggle", "error", err)
+		}
+		if !enabled {
+			fmt.Printf("ℹ️ %s was not disabled\n", strings.ToUpper(args[1]))
+			return
+		}
+		fmt.Printf("✅ Trading enabled for %s\n", strings.ToUpper(args[1]))
+
+	default:
+		toggleUsage()
+	}
+}
+
+func list(store *toggles.Store) {
+	disabled := store.List()
+	if len(disabled) == 0 {
+		fmt.Println("✅ All currencies enabled")
+		return
+	}
+
+	fmt.Printf("⛔ %d currencies disabled:\n", len(disabled))
+	for _, toggle := range disabled {
+		fmt.Printf("   %-8s since %s", toggle.Currency, toggle.DisabledAt.Format("2006-01-02 15:04"))
+		if toggle.By != "" {
+			fmt.Printf(" by %s", toggle.By)
+		}
+		if toggle.Reason != "" {
+			fmt.Printf(": %s", toggle.Reason)
+		}
+		fmt.Println()
+	}
+}
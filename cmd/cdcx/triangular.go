@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+)
+
+func runTriangular(opts *options, args []string) {
+	fmt.Println("🔺 CoinDCX Triangular Arbitrage Detector")
+	fmt.Println("========================================")
+	fmt.Println("💡 Pricing three-leg cycles within the exchange")
+
+	config := opts.trading
+	fmt.Printf("🏷️ Build %s\n", version.Current().Label())
+
+	// Cycles start and end in these currencies: USDT and INR unless given
+	starts := []string{"USDT", "INR"}
+	if len(args) > 0 {
+		starts = nil
+		for _, arg := range args {
+			starts = append(starts, strings.ToUpper(arg))
+		}
+	}
+
+	detector := opportunity.NewTriangularDetector(config)
+	detector.SetTransport(opts.transport)
+	opportunities, err := detector.FindOpportunities(starts)
+	if err != nil {
+		fatal("❌ Error finding triangular opportunities", "error", err)
+	}
+
+	detector.DisplayResults(opportunities)
+
+	filename := opts.path("triangular_opportunities.json")
+	if err := detector.SaveOpportunities(opportunities, filename); err != nil {
+		fatal("❌ Error saving opportunities", "error", err)
+	}
+	fmt.Printf("\n💾 Saved triangular opportunities to %s\n", filename)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/watch"
+	"github.com/b-thark/cdcx-api/pkg/pairs"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+const samplesFile = "watch_samples.jsonl"
+
+func watchUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cdcx watch <currency>...   Record spreads, depth and hypothetical P&L without trading (Ctrl-C stops)")
+	fmt.Println("  cdcx watch report [<file>] Summarize a recorded dataset per route")
+	fmt.Println("Environment: WATCH_INTERVAL_MS (default 2000), WATCH_NOTIONAL_INR (default 5000),")
+	fmt.Println("             WATCH_MINUTES (default: until stopped), WATCH_FILE (default " + samplesFile + ")")
+	os.Exit(1)
+}
+
+func runWatch(opts *options, args []string) {
+	if len(args) < 1 {
+		watchUsage()
+	}
+
+	tradingConfig := opts.trading
+
+	file := samplesFile
+	if value := os.Getenv("WATCH_FILE"); value != "" {
+		file = value
+	}
+
+	if args[0] == "report" {
+		if len(args) > 1 {
+			file = args[1]
+		}
+		samples, err := watch.Load(file)
+		if err != nil {
+			fatal("❌ Error loading samples", "error", err)
+		}
+		watchReport(watch.Summarize(samples, tradingConfig.MinNetMargin), tradingConfig.MinNetMargin)
+		return
+	}
+
+	fmt.Println("👀 CoinDCX Watch-Only Telemetry")
+	fmt.Println("===============================")
+	fmt.Println("💡 No orders are placed in this mode")
+
+	interval := 2 * time.Second
+	if value := os.Getenv("WATCH_INTERVAL_MS"); value != "" {
+		if val := parseFloat(value); val > 0 {
+			interval = time.Duration(val) * time.Millisecond
+		}
+	}
+
+	notional := 5000.0
+	if value := os.Getenv("WATCH_NOTIONAL_INR"); value != "" {
+		if val := parseFloat(value); val > 0 {
+			notional = val
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if value := os.Getenv("WATCH_MINUTES"); value != "" {
+		if val := parseFloat(value); val > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(val*float64(time.Minute)))
+			defer cancel()
+		}
+	}
+
+	// Watched currencies need not be enabled for trading, so look at every market
+	allPairs := *tradingConfig
+	allPairs.EnableAllPairs = true
+	analyzer := pairs.NewAnalyzer(&allPairs)
+	analyzer.SetTransport(opts.transport)
+	available, err := analyzer.ExtractArbitragePairs()
+	if err != nil {
+		fatal("❌ Error extracting pairs", "error", err)
+	}
+
+	watchlist := make(map[string][]types.PairInfo)
+	for _, currency := range args {
+		currency = strings.ToUpper(currency)
+		group, ok := available[currency]
+		if !ok {
+			fatal("❌ Fewer than two active markets; nothing to compare", "currency", currency)
+		}
+		watchlist[currency] = group.Pairs
+		symbols := []string{}
+		for _, pair := range group.Pairs {
+			symbols = append(symbols, pair.Symbol)
+		}
+		fmt.Printf("📊 %s: %s\n", currency, strings.Join(symbols, ", "))
+	}
+
+	watcher := watch.NewWatcher(opts.fetcher(), opts.rateManager(tradingConfig), tradingConfig, notional)
+	recorder := watch.NewRecorder(file)
+	fmt.Printf("⏱️ Sampling every %s, ₹%.0f hypothetical trades → %s\n", interval, notional, file)
+
+	started, recorded := time.Now(), 0
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for pass := 1; ; pass++ {
+		for currency, group := range watchlist {
+			samples := watcher.Sample(currency, group)
+			if err := recorder.Append(samples); err != nil {
+				fatal("❌ Error saving samples", "error", err)
+			}
+			recorded += len(samples)
+		}
+		if pass%30 == 0 {
+			logger.Info("📈 Samples recorded", "samples", recorded)
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Printf("\n🛑 Stopped after %d passes\n", pass)
+			summarizeSince(file, started, tradingConfig.MinNetMargin)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// summarizeSince reports on this run's samples only; the file may hold earlier runs
+func summarizeSince(file string, since time.Time, minNetMargin float64) {
+	samples, err := watch.Load(file)
+	if err != nil {
+		fatal("❌ Error loading samples", "error", err)
+	}
+	run := []watch.Sample{}
+	for _, sample := range samples {
+		if !sample.Time.Before(since) {
+			run = append(run, sample)
+		}
+	}
+	watchReport(watch.Summarize(run, minNetMargin), minNetMargin)
+}
+
+func watchReport(summaries []watch.RouteSummary, minNetMargin float64) {
+	if len(summaries) == 0 {
+		fmt.Println("📭 No samples")
+		return
+	}
+
+	fmt.Printf("\n📋 Routes (viable = net margin ≥ %.1f%%)\n", minNetMargin)
+	for _, s := range summaries {
+		fmt.Printf("   %-6s %s → %s\n", s.Currency, s.BuyMarket, s.SellMarket)
+		fmt.Printf("      %d samples over %s, viable %.1f%%, fillable %.1f%%\n", s.Samples,
+			s.Last.Sub(s.First).Round(time.Second), pct(s.Viable, s.Samples), pct(s.Fillable, s.Samples))
+		fmt.Printf("      net margin avg %.2f%% max %.2f%%, depth ±%.0f%% avg ₹%.0f\n",
+			s.AvgNetMarginPct, s.MaxNetMarginPct, watch.DepthBandPct, s.AvgDepthINR)
+		fmt.Printf("      hypothetical P&L avg ₹%.2f/sample, ₹%.2f over viable samples\n", s.AvgPL, s.ViablePL)
+	}
+}
+
+func pct(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
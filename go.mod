@@ -3,3 +3,7 @@ module github.com/b-thark/cdcx-api
 go 1.24.4
 
 require github.com/joho/godotenv v1.5.1
+
+require github.com/gorilla/websocket v1.5.3
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+const (
+	detectionLookback = time.Minute     // Window start when no detection snapshot was recorded
+	completionGrace   = 2 * time.Second // Late calls, e.g. a stop released after the result
+)
+
+// Timeline selects the entries belonging to one executed order, in time
+// order: from the last detection of its currency before it started until
+// just after it ended, every entry on its currency, its orders or a market
+// trading its currency (which catches recovery on other markets), plus
+// balance checks made while it ran. Entries stamped with an execution ID are
+// matched on it instead, wherever they fall, when the order has one.
+func Timeline(entries []Entry, order types.ExecutedOrder) []Entry {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	from := order.StartTime.Add(-detectionLookback)
+	var snapshot, announced time.Time
+	for _, entry := range sorted {
+		if entry.Time.After(order.StartTime) {
+			break
+		}
+		if entry.Currency != order.Currency {
+			continue
+		}
+		switch entry.Kind {
+		case KindDetection:
+			snapshot = entry.Time
+		case events.KindOpportunityDetected:
+			announced = entry.Time
+		}
+	}
+	if !snapshot.IsZero() {
+		from = snapshot
+		// A live scanner announces the opportunity just before handing it over
+		if !announced.IsZero() && announced.Before(from) && from.Sub(announced) <= detectionLookback {
+			from = announced
+		}
+	}
+	until := order.EndTime.Add(completionGrace)
+
+	orderIDs := map[string]bool{}
+	for _, id := range []string{order.BuyOrderID, order.SellOrderID, order.StopOrderID} {
+		if id != "" {
+			orderIDs[id] = true
+		}
+	}
+
+	trades := func(s string) bool {
+		return order.Currency != "" && strings.HasPrefix(s, order.Currency)
+	}
+
+	timeline := []Entry{}
+	for _, entry := range sorted {
+		if order.ExecutionID != "" && entry.ExecutionID != "" {
+			if entry.ExecutionID == order.ExecutionID {
+				if entry.OrderID != "" {
+					orderIDs[entry.OrderID] = true
+				}
+				timeline = append(timeline, entry)
+			}
+			continue
+		}
+		if entry.Time.Before(from) || entry.Time.After(until) {
+			continue
+		}
+
+		include := orderIDs[entry.OrderID] || trades(entry.Currency) || trades(entry.Market)
+		if !include && entry.Kind == KindAPICall && entry.Market == "" && entry.OrderID == "" {
+			include = !entry.Time.Before(order.StartTime) && !entry.Time.After(order.EndTime)
+		}
+		if !include {
+			continue
+		}
+
+		// Later status checks and cancels carry only the order ID
+		if entry.OrderID != "" {
+			orderIDs[entry.OrderID] = true
+		}
+		timeline = append(timeline, entry)
+	}
+	return timeline
+}
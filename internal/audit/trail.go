@@ -0,0 +1,192 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+)
+
+var logger = logging.For("audit")
+
+// Entry kinds recorded by the engine itself; bus events keep their event kind
+const (
+	KindDetection  = "detection"  // Opportunity as it reached the engine
+	KindValidation = "validation" // Outcome of re-checking it against live books
+	KindAPICall    = "api_call"   // One request to the venue
+	KindSnapshot   = "snapshot"   // State captured after a failed or deviating execution
+)
+
+// Entry is one step of an execution in the audit trail
+type Entry struct {
+	Time        time.Time       `json:"time"`
+	Kind        string          `json:"kind"`
+	Currency    string          `json:"currency,omitempty"`
+	Market      string          `json:"market,omitempty"`
+	OrderID     string          `json:"order_id,omitempty"`
+	ExecutionID string          `json:"execution_id,omitempty"` // Execution attempt the step belongs to
+	Summary     string          `json:"summary"`
+	Error       string          `json:"error,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// Trail appends execution steps to a JSON-lines file. Unlike the other state
+// files it is never rewritten, so it survives crashes mid-execution and stays
+// cheap to write on every API call.
+type Trail struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewTrail creates a trail appending to path
+func NewTrail(path string) *Trail {
+	return &Trail{path: path}
+}
+
+// SetPath moves future entries to another file
+func (t *Trail) SetPath(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.path = path
+}
+
+// Record appends an entry, with data (if any) attached as JSON. Failures are
+// logged, never returned: auditing must not stop a trade. A nil trail drops
+// entries.
+func (t *Trail) Record(entry Entry, data interface{}) {
+	if t == nil {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if data != nil {
+		sanitized, _ := utils.SanitizeFloats(data)
+		raw, err := json.Marshal(sanitized)
+		if err != nil {
+			logger.Warn("⚠️ Audit trail: error encoding data", "kind", entry.Kind, "error", err)
+		} else {
+			entry.Data = raw
+		}
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logger.Warn("⚠️ Audit trail: error encoding entry", "kind", entry.Kind, "error", err)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	file, err := os.OpenFile(t.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.Warn("⚠️ Audit trail: error opening", "path", t.path, "error", err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		logger.Warn("⚠️ Audit trail: error writing", "path", t.path, "error", err)
+	}
+}
+
+// RecordCall is the venue recorder callback: executor.NewRecorder(venue, trail.RecordCall)
+func (t *Trail) RecordCall(call executor.APICall) {
+	entry := Entry{
+		Time:        call.Time,
+		Kind:        KindAPICall,
+		Market:      call.Market,
+		OrderID:     call.OrderID,
+		ExecutionID: call.ExecutionID,
+		Summary:     fmt.Sprintf("%s (%dms)", call.Method, call.Duration.Milliseconds()),
+	}
+	if call.Side != "" {
+		entry.Summary = fmt.Sprintf("%s %s (%dms)", call.Method, call.Side, call.Duration.Milliseconds())
+	}
+	if call.Status != "" {
+		entry.Summary += " → " + call.Status
+	}
+	if call.Err != nil {
+		entry.Error = call.Err.Error()
+	}
+	t.Record(entry, nil)
+}
+
+// Handle is the bus subscriber: bus.Subscribe(trail.Handle)
+func (t *Trail) Handle(event events.Event) {
+	entry := Entry{Time: event.At(), Kind: event.Kind(), ExecutionID: event.Execution()}
+
+	switch e := event.(type) {
+	case events.OpportunityDetected:
+		entry.Currency = e.Currency
+		entry.Summary = fmt.Sprintf("detected %s → %s at %.2f%%", e.BuyMarket, e.SellMarket, e.MarginPct)
+	case events.OpportunityDropped:
+		entry.Currency = e.Currency
+		entry.Summary = fmt.Sprintf("dropped %s → %s at %.2f%%: %s", e.BuyMarket, e.SellMarket, e.MarginPct, e.Reason)
+	case events.OrderPlaced:
+		entry.Market, entry.OrderID = e.Market, e.OrderID
+		entry.Summary = fmt.Sprintf("%s %.6f placed", e.Side, e.Quantity)
+	case events.OrderFilled:
+		entry.Market, entry.OrderID = e.Market, e.OrderID
+		entry.Summary = fmt.Sprintf("%s %.6f filled at %.8f (fee %.8f)", e.Side, e.Quantity, e.AvgPrice, e.Fee)
+	case events.ExecutionCompleted:
+		entry.Currency = e.Currency
+		entry.Error = e.Error
+		if e.Success {
+			entry.Summary = fmt.Sprintf("completed: %.6f for ₹%.2f (%.2f%%)", e.Volume, e.Profit, e.MarginPct)
+		} else {
+			entry.Summary = "failed"
+		}
+	case events.RecoveryTriggered:
+		entry.Currency = e.Currency
+		entry.Summary = fmt.Sprintf("recovering %.6f: %s", e.Volume, e.Reason)
+	case events.RiskTripped:
+		entry.Currency = e.Subject
+		entry.Summary = fmt.Sprintf("%s blocked %s: %s", e.Rule, e.Subject, e.Detail)
+	case events.BalanceMismatch:
+		entry.Currency = e.Currency
+		entry.Summary = fmt.Sprintf("balance moved outside the bot: expected %.8f, found %.8f", e.Expected, e.Actual)
+	default:
+		return
+	}
+
+	t.Record(entry, nil)
+}
+
+// Load reads every entry in a trail file. A missing file is an empty trail;
+// a truncated last line (a crash mid-write) is skipped.
+func Load(path string) ([]Entry, error) {
+	entries := []Entry{}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("error opening audit trail %s: %v", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logger.Warn("⚠️ Line skipped", "path", path, "line", line, "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit trail %s: %v", path, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,204 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// ManifestFile is the manifest's name at the root of every archive
+const ManifestFile = "manifest.json"
+
+// Entry is one file in a bundle
+type Entry struct {
+	Name     string    `json:"name"`             // Path inside the archive
+	Source   string    `json:"source,omitempty"` // File it was copied from; empty when generated
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// Manifest describes a bundle so it can be read without unpacking it all
+type Manifest struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Since     time.Time      `json:"since,omitempty"` // Outputs older than this were left out
+	Run       *types.RunInfo `json:"run"`
+	Files     []Entry        `json:"files"`
+}
+
+type item struct {
+	entry Entry
+	data  []byte // Generated content; nil for a copied file
+}
+
+// Bundle gathers the artifacts of a run for one archive
+type Bundle struct {
+	since time.Time
+	items map[string]item // By archive name
+	seen  map[string]bool // Absolute source paths already added
+}
+
+// New starts a bundle of files modified at or after since; a zero since
+// takes every file
+func New(since time.Time) *Bundle {
+	return &Bundle{since: since, items: make(map[string]item), seen: make(map[string]bool)}
+}
+
+// AddFiles adds the files in dir matching each pattern under prefix/ in the
+// archive, skipping ones older than the bundle's since and ones already
+// added from another directory
+func (b *Bundle) AddFiles(prefix, dir string, patterns ...string) error {
+	return b.add(prefix, dir, b.since, patterns)
+}
+
+// AddInputs is AddFiles for files a run reads rather than writes (its pairs,
+// toggles): they are added however old they are
+func (b *Bundle) AddInputs(prefix, dir string, patterns ...string) error {
+	return b.add(prefix, dir, time.Time{}, patterns)
+}
+
+func (b *Bundle) add(prefix, dir string, since time.Time, patterns []string) error {
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return fmt.Errorf("bad pattern %s: %v", pattern, err)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || !info.Mode().IsRegular() || info.ModTime().Before(since) {
+				continue
+			}
+			abs, err := filepath.Abs(match)
+			if err != nil || b.seen[abs] {
+				continue
+			}
+			b.seen[abs] = true
+
+			name := path.Join(prefix, filepath.Base(match))
+			b.items[name] = item{entry: Entry{Name: name, Source: match, Size: info.Size(), Modified: info.ModTime()}}
+		}
+	}
+	return nil
+}
+
+// AddData adds generated content, such as a rendered report, as name
+func (b *Bundle) AddData(name string, data []byte) {
+	b.items[name] = item{entry: Entry{Name: name, Size: int64(len(data)), Modified: time.Now()}, data: data}
+}
+
+// AddJSON adds v as indented JSON
+func (b *Bundle) AddJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling %s: %v", name, err)
+	}
+	b.AddData(name, data)
+	return nil
+}
+
+// Write saves the bundle as a gzipped tar at file, manifest first, and
+// returns the manifest. A partial archive is removed on error.
+func (b *Bundle) Write(file string) (*Manifest, error) {
+	names := make([]string, 0, len(b.items))
+	for name := range b.items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	manifest := &Manifest{CreatedAt: time.Now(), Since: b.since, Run: version.Current(), Files: []Entry{}}
+	for _, name := range names {
+		manifest.Files = append(manifest.Files, b.items[name].entry)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling manifest: %v", err)
+	}
+
+	if dir := filepath.Dir(file); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating %s: %v", dir, err)
+		}
+	}
+	out, err := os.Create(file)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s: %v", file, err)
+	}
+
+	err = b.write(out, manifestData, names)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (b *Bundle) write(out io.Writer, manifestData []byte, names []string) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	if err := writeData(tw, ManifestFile, manifestData, time.Now()); err != nil {
+		return err
+	}
+	for _, name := range names {
+		item := b.items[name]
+		var err error
+		if item.data != nil {
+			err = writeData(tw, name, item.data, item.entry.Modified)
+		} else {
+			err = writeFile(tw, name, item.entry.Source)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error finishing archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error finishing archive: %v", err)
+	}
+	return nil
+}
+
+func writeData(tw *tar.Writer, name string, data []byte, modified time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modified}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("error adding %s: %v", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("error adding %s: %v", name, err)
+	}
+	return nil
+}
+
+// writeFile copies source into the archive; it is read whole so a file
+// still being appended to (an audit trail) can't overrun its header size
+func writeFile(tw *tar.Writer, name, source string) error {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", source, err)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", source, err)
+	}
+	return writeData(tw, name, data, info.ModTime())
+}
+
+// Filename is the default archive name for a bundle created at now
+func Filename(now time.Time) string {
+	return fmt.Sprintf("cdcx_bundle_%s.tar.gz", now.Format("20060102_150405"))
+}
@@ -0,0 +1,139 @@
+package candles
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Store keeps candle history per market and interval, persisted to disk on
+// every merge, so statistics have data before enough has been recorded live
+type Store struct {
+	path   string
+	series map[string][]types.Candle // "SYMBOL/interval" → candles, oldest first
+	mu     sync.Mutex
+}
+
+// NewStore creates a store persisted at path
+func NewStore(path string) *Store {
+	return &Store{
+		path:   path,
+		series: make(map[string][]types.Candle),
+	}
+}
+
+// Load restores persisted history. A missing file is an empty store.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series := make(map[string][]types.Candle)
+	if err := utils.LoadJSON(s.path, &series); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error loading candle store %s: %v", s.path, err)
+	}
+
+	s.series = series
+	return nil
+}
+
+// Merge adds candles for a market, replacing any bar with the same open time,
+// and returns how many bars were new
+func (s *Store) Merge(symbol, interval string, candles []types.Candle) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := seriesKey(symbol, interval)
+	byTime := make(map[int64]types.Candle, len(s.series[key])+len(candles))
+	for _, candle := range s.series[key] {
+		byTime[candle.Time] = candle
+	}
+
+	added := 0
+	for _, candle := range candles {
+		if _, exists := byTime[candle.Time]; !exists {
+			added++
+		}
+		byTime[candle.Time] = candle
+	}
+
+	merged := make([]types.Candle, 0, len(byTime))
+	for _, candle := range byTime {
+		merged = append(merged, candle)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Time < merged[j].Time })
+
+	s.series[key] = merged
+	return added, s.save()
+}
+
+// Get returns a market's candles at the interval, oldest first
+func (s *Store) Get(symbol, interval string) []types.Candle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]types.Candle(nil), s.series[seriesKey(symbol, interval)]...)
+}
+
+// save writes history to disk. Callers hold mu.
+func (s *Store) save() error {
+	if err := utils.SaveJSON(s.series, s.path); err != nil {
+		return fmt.Errorf("error saving candle store %s: %v", s.path, err)
+	}
+	return nil
+}
+
+func seriesKey(symbol, interval string) string {
+	return symbol + "/" + interval
+}
+
+// Volatility is the standard deviation of close-to-close log returns, in
+// percent per bar; 0 with fewer than three candles
+func Volatility(candles []types.Candle) float64 {
+	returns := []float64{}
+	for i := 1; i < len(candles); i++ {
+		if candles[i-1].Close > 0 && candles[i].Close > 0 {
+			returns = append(returns, math.Log(candles[i].Close/candles[i-1].Close))
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	return math.Sqrt(variance) * 100
+}
+
+// AverageRange is the mean high-low range as a percentage of the close, a
+// proxy for intrabar spread before live spread samples exist
+func AverageRange(candles []types.Candle) float64 {
+	total, count := 0.0, 0
+	for _, candle := range candles {
+		if candle.Close > 0 && candle.High >= candle.Low {
+			total += (candle.High - candle.Low) / candle.Close * 100
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
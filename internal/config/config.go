@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/joho/godotenv"
 )
@@ -10,22 +13,164 @@ import (
 type Config struct {
 	APIKey    string
 	APISecret string
+	EnvFile   string // .env file the credentials were loaded from, empty when taken from the environment
+	Account   string // Named (sub-)account the credentials belong to, empty for the main account
 }
 
+// Load reads credentials from the first .env file found (see EnvFileCandidates).
+// The file is optional when COINDCX_API_KEY and COINDCX_API_SECRET are already
+// exported; variables set in the environment always win over file values.
+// An account selected in CDCX_ACCOUNT (cdcx --account) uses its own
+// credentials, see ForAccount. Load leaves the working directory alone; see
+// DataDir.
 func Load() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
-		return nil, fmt.Errorf("error loading .env file: %v", err)
+	envFile, err := LoadEnvFile()
+	if err != nil {
+		return nil, err
 	}
 
-	apiKey := os.Getenv("COINDCX_API_KEY")
-	apiSecret := os.Getenv("COINDCX_API_SECRET")
+	cfg, err := ForAccount(os.Getenv("CDCX_ACCOUNT"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.EnvFile = envFile
+	return cfg, nil
+}
+
+// ForAccount reads the credentials of a named account from the environment:
+// COINDCX_<NAME>_API_KEY and COINDCX_<NAME>_API_SECRET, e.g. the API key of a
+// CoinDCX sub-account dedicated to one strategy. The empty name is the main
+// account (COINDCX_API_KEY, COINDCX_API_SECRET).
+func ForAccount(name string) (*Config, error) {
+	prefix := "COINDCX_"
+	if name != "" {
+		prefix += envName(name) + "_"
+	}
+	keyVar, secretVar := prefix+"API_KEY", prefix+"API_SECRET"
+
+	apiKey := os.Getenv(keyVar)
+	apiSecret := os.Getenv(secretVar)
 
 	if apiKey == "" || apiSecret == "" {
-		return nil, fmt.Errorf("COINDCX_API_KEY and COINDCX_API_SECRET must be set in .env file")
+		searched := strings.Join(EnvFileCandidates(), ", ")
+		return nil, fmt.Errorf("%s and %s must be exported or set in a .env file (searched: %s)", keyVar, secretVar, searched)
 	}
 
 	return &Config{
 		APIKey:    apiKey,
 		APISecret: apiSecret,
+		Account:   name,
 	}, nil
 }
+
+// StateDir is where the account keeps its own inventory, ledgers, queue and
+// execution logs so its limits and P&L stay separate: accounts/<name>, or
+// empty (the working directory) for the main account
+func (c *Config) StateDir() string {
+	if c.Account == "" {
+		return ""
+	}
+	return filepath.Join("accounts", c.Account)
+}
+
+// UseProfileAccount switches to the account a profile's strategy is routed
+// to, unless one was chosen explicitly in CDCX_ACCOUNT (cdcx --account). The
+// route comes from CDCX_PROFILE_ACCOUNTS ("aggressive-live=scalper,...")
+// or else the profile's own account field. cfg and loadErr are Load's
+// results, returned unchanged when nothing switches.
+func UseProfileAccount(cfg *Config, loadErr error, profile *Profile) (*Config, error) {
+	if profile == nil || os.Getenv("CDCX_ACCOUNT") != "" {
+		return cfg, loadErr
+	}
+
+	name := profile.Account
+	for _, route := range strings.Split(os.Getenv("CDCX_PROFILE_ACCOUNTS"), ",") {
+		if profileName, account, ok := strings.Cut(strings.TrimSpace(route), "="); ok && profileName == profile.Name {
+			name = account
+		}
+	}
+	if name == "" {
+		return cfg, loadErr
+	}
+
+	account, err := ForAccount(name)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %v", profile.Name, err)
+	}
+	if cfg != nil {
+		account.EnvFile = cfg.EnvFile
+	}
+	return account, nil
+}
+
+func envName(account string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, account)
+}
+
+// EnvFileCandidates lists the .env locations Load tries, in order: the current
+// directory, the binary's directory and $HOME/.config/cdcx/.
+func EnvFileCandidates() []string {
+	candidates := []string{".env"}
+
+	if exe, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), ".env"))
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".config", "cdcx", ".env"))
+	}
+
+	return candidates
+}
+
+// DataDir is the directory relative data files (pairs, logs, caches,
+// queues) are kept in, from CDCX_DATA_DIR; empty for the working directory.
+// Call LoadEnvFile first, a .env may set it.
+func DataDir() string {
+	return os.Getenv("CDCX_DATA_DIR")
+}
+
+var envFile struct {
+	once sync.Once
+	path string
+	err  error
+}
+
+// LoadEnvFile loads the first .env file found into the environment, once per
+// process, and returns its path; empty when there is none
+func LoadEnvFile() (string, error) {
+	envFile.once.Do(func() {
+		envFile.path, envFile.err = loadEnvFile()
+	})
+	return envFile.path, envFile.err
+}
+
+func loadEnvFile() (string, error) {
+	// An explicitly requested file must exist
+	if explicit := os.Getenv("CDCX_ENV_FILE"); explicit != "" {
+		if err := godotenv.Load(explicit); err != nil {
+			return "", fmt.Errorf("error loading env file %s: %v", explicit, err)
+		}
+		return explicit, nil
+	}
+
+	for _, path := range EnvFileCandidates() {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := godotenv.Load(path); err != nil {
+			return "", fmt.Errorf("error loading env file %s: %v", path, err)
+		}
+		return path, nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/toggles"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+var logger = logging.For("config")
+
+// Sources a setting's value can come from, lowest precedence first
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceProfile = "profile"
+	SourceEnv     = "env"
+	SourceRuntime = "runtime" // Changed by the process itself after startup configuration
+)
+
+// Setting is one effective parameter and where its value came from
+type Setting struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Source  string `json:"source"`
+	Origin  string `json:"origin,omitempty"` // Config file, profile name or environment variable
+}
+
+// Effective is everything an engine runs with: the merged parameters and
+// the runtime state layered on top of them
+type Effective struct {
+	Profile    string           `json:"profile,omitempty"`
+	Account    string           `json:"account,omitempty"`
+	EnvFile    string           `json:"env_file,omitempty"`
+	ConfigFile string           `json:"config_file,omitempty"`
+	Settings   []Setting        `json:"settings"`
+	Disabled   []toggles.Toggle `json:"disabled_currencies"` // Runtime toggles (cmd/toggle)
+}
+
+// NewEffective gathers the effective configuration; file (the config file
+// applied, if any) and store (the runtime currency toggles) may be nil
+func NewEffective(cfg *Config, file *File, profile *Profile, trading *types.Config, execution *types.ExecutionConfig, store *toggles.Store) Effective {
+	effective := Effective{Settings: Provenance(file, profile, trading, execution), Disabled: []toggles.Toggle{}}
+	if cfg != nil {
+		effective.Account, effective.EnvFile = cfg.Account, cfg.EnvFile
+	}
+	if file != nil {
+		effective.ConfigFile = file.Path
+	}
+	if profile != nil {
+		effective.Profile = profile.Name
+	}
+	if store != nil {
+		if err := store.Load(); err != nil {
+			logger.Warn("⚠️ Trading toggles not loaded", "error", err)
+		}
+		effective.Disabled = store.List()
+	}
+	return effective
+}
+
+// envChoices restricts string overrides, and the keys of map overrides, the
+// engines only accept from a fixed set
+var envChoices = map[string][]string{
+	"EXECUTION_POLICY":    {"sequential", "atomic"},
+	"FUNDING_CURRENCY":    {"USDT", "INR"},
+	"CONFIRM_TRADES":      {"off", "trade", "session"},
+	"LIMIT_TIME_IN_FORCE": {"immediate_or_cancel", "good_till_cancel"},
+	"FEE_SCHEDULE":        {"INR_TAKER", "INR_MAKER", "C2C_TAKER", "C2C_MAKER"},
+	"SHADOW_STRATEGIES":   {"triangular"},
+}
+
+// ApplyEnvOverrides sets every parameter with an env tag whose variable is
+// set, following the rules the commands apply: numbers must be positive
+// (zero is accepted where the parameter documents "0 disables"), booleans
+// are "true" or "false". Invalid values are skipped and reported.
+func ApplyEnvOverrides(trading *types.Config, execution *types.ExecutionConfig) []error {
+	errs := applyEnv(reflect.ValueOf(trading).Elem())
+	return append(errs, applyEnv(reflect.ValueOf(execution).Elem())...)
+}
+
+func applyEnv(value reflect.Value) []error {
+	errs := []error{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := field.Tag.Get("env")
+		raw := os.Getenv(name)
+		if name == "" || raw == "" {
+			continue
+		}
+		if err := setFromEnv(value.Field(i), field, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s=%q ignored: %v", name, raw, err))
+		}
+	}
+	return errs
+}
+
+func setFromEnv(target reflect.Value, field reflect.StructField, raw string) error {
+	parsed := reflect.New(target.Type()).Elem()
+	switch target.Kind() {
+	case reflect.Float64:
+		val, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		parsed.SetFloat(val)
+	case reflect.Int:
+		val, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		parsed.SetInt(int64(val))
+	case reflect.Bool:
+		val, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		parsed.SetBool(val)
+	case reflect.String:
+		parsed.SetString(raw)
+	case reflect.Slice:
+		if target.Type().Elem().Kind() == reflect.String {
+			values := []string{}
+			for _, value := range strings.Split(raw, ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+			parsed.Set(reflect.ValueOf(values))
+			break
+		}
+		if target.Type().Elem().Kind() != reflect.Int {
+			return fmt.Errorf("unsupported type %s", target.Type())
+		}
+		codes, err := types.ParseStatusCodes(raw)
+		if err != nil {
+			return err
+		}
+		parsed.Set(reflect.ValueOf(codes))
+	case reflect.Map:
+		if target.Type().Elem().Kind() == reflect.Int {
+			intervals, err := types.ParseQuoteScanIntervals(raw)
+			if err != nil {
+				return err
+			}
+			parsed.Set(reflect.ValueOf(intervals))
+			break
+		}
+		overrides, err := types.ParseFeeOverrides(raw)
+		if err != nil {
+			return err
+		}
+		parsed.Set(reflect.ValueOf(overrides))
+	default:
+		return fmt.Errorf("unsupported type %s", target.Type())
+	}
+
+	if err := checkSetting(parsed, field); err != nil {
+		return err
+	}
+	target.Set(parsed)
+	return nil
+}
+
+// checkSetting applies the rules every way of setting a parameter follows:
+// numbers must be positive (zero is accepted where the parameter documents
+// "0 disables"), and values restricted by envChoices must be one of them
+func checkSetting(value reflect.Value, field reflect.StructField) error {
+	allowZero := strings.Contains(field.Tag.Get("desc"), "0 disables")
+	checkNumber := func(val float64) error {
+		if val < 0 || (val == 0 && !allowZero) {
+			return fmt.Errorf("must be positive")
+		}
+		return nil
+	}
+	choices, restricted := envChoices[field.Tag.Get("env")]
+
+	switch value.Kind() {
+	case reflect.Float64:
+		return checkNumber(value.Float())
+	case reflect.Int:
+		return checkNumber(float64(value.Int()))
+	case reflect.String:
+		if restricted && !utils.Contains(choices, value.String()) {
+			return fmt.Errorf("must be one of %s", strings.Join(choices, ", "))
+		}
+	case reflect.Slice:
+		if !restricted || value.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			if item := value.Index(i).String(); !utils.Contains(choices, item) {
+				return fmt.Errorf("%s must be one of %s", item, strings.Join(choices, ", "))
+			}
+		}
+	case reflect.Map:
+		if !restricted {
+			return nil
+		}
+		for _, key := range value.MapKeys() {
+			if !utils.Contains(choices, key.String()) {
+				return fmt.Errorf("%s must be one of %s", key.String(), strings.Join(choices, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Provenance lists the effective trading and execution parameters with the
+// layer each value came from: the built-in default, the config file, the
+// profile, an environment variable, or the process itself when none of
+// those explain it
+func Provenance(file *File, profile *Profile, trading *types.Config, execution *types.ExecutionConfig) []Setting {
+	// What the defaults plus the file and profile alone would give
+	layeredTrading, layeredExec := types.DefaultConfig(), types.DefaultExecutionConfig()
+	fileKeys, profileKeys := map[string]map[string]bool{}, map[string]map[string]bool{}
+	if file != nil && file.Apply(layeredTrading, layeredExec) == nil {
+		fileKeys["trading"] = rawKeys(file.Trading)
+		fileKeys["execution"] = rawKeys(file.Execution)
+	}
+	if profile != nil && profile.Apply(layeredTrading, layeredExec) == nil {
+		profileKeys["trading"] = rawKeys(profile.Trading)
+		profileKeys["execution"] = rawKeys(profile.Execution)
+	}
+	layered := append(types.Explain("trading", layeredTrading), types.Explain("execution", layeredExec)...)
+
+	actual := append(types.Explain("trading", trading), types.Explain("execution", execution)...)
+	settings := make([]Setting, 0, len(actual))
+	for i, doc := range actual {
+		setting := Setting{Section: doc.Section, Name: doc.Name, Value: doc.Default, Source: SourceDefault}
+		switch {
+		case doc.Default != layered[i].Default:
+			if doc.Env != "" && os.Getenv(doc.Env) != "" {
+				setting.Source, setting.Origin = SourceEnv, doc.Env
+			} else {
+				setting.Source = SourceRuntime
+			}
+		case profileKeys[doc.Section][doc.Name]:
+			setting.Source, setting.Origin = SourceProfile, profile.Name
+		case fileKeys[doc.Section][doc.Name]:
+			setting.Source, setting.Origin = SourceFile, file.Path
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
+
+// rawKeys returns the top-level keys of a JSON object
+func rawKeys(raw json.RawMessage) map[string]bool {
+	keys := map[string]bool{}
+	fields := map[string]json.RawMessage{}
+	if len(raw) > 0 && json.Unmarshal(raw, &fields) == nil {
+		for key := range fields {
+			keys[key] = true
+		}
+	}
+	return keys
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// File is a config file (config.yaml): trading and execution parameters
+// applied over the built-in defaults, and profiles of its own beside the
+// shipped presets. Keys are the parameters' json names (cdcx config explain).
+type File struct {
+	Path      string              `json:"-"`
+	Profile   string              `json:"profile,omitempty"` // Applied when none is selected on the command line
+	Trading   json.RawMessage     `json:"trading,omitempty"`
+	Execution json.RawMessage     `json:"execution,omitempty"`
+	Profiles  map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// ConfigFileCandidates lists the config file locations tried, in order: the
+// current (data) directory, then $HOME/.config/cdcx/
+func ConfigFileCandidates() []string {
+	candidates := []string{"config.yaml", "config.yml", "config.json"}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".config", "cdcx", "config.yaml"))
+	}
+	return candidates
+}
+
+// SelectedFile loads the config file named in CDCX_CONFIG (cdcx --config), which must
+// exist, else the first candidate found. It returns nil when there is none.
+func SelectedFile() (*File, error) {
+	if explicit := os.Getenv("CDCX_CONFIG"); explicit != "" {
+		return LoadFile(explicit)
+	}
+	for _, path := range ConfigFileCandidates() {
+		if _, err := os.Stat(path); err == nil {
+			return LoadFile(path)
+		}
+	}
+	return nil, nil
+}
+
+// LoadFile reads a YAML or, by its .json extension, JSON config file
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config file %s: %v", path, err)
+	}
+
+	// YAML is read through JSON so the json tags stay the only parameter names
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		var tree interface{}
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("error parsing config file %s: %v", path, err)
+		}
+		if tree == nil {
+			tree = map[string]interface{}{}
+		}
+		if data, err = json.Marshal(tree); err != nil {
+			return nil, fmt.Errorf("error parsing config file %s: %v", path, err)
+		}
+	}
+
+	file := &File{Path: path}
+	if err := decodeStrict(data, file); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+	for name, profile := range file.Profiles {
+		if profile == nil {
+			return nil, fmt.Errorf("config file %s: profile %s is empty", path, name)
+		}
+		profile.Name = name
+	}
+	return file, nil
+}
+
+// Apply overlays the file's parameters onto the configs
+func (f *File) Apply(trading *types.Config, execution *types.ExecutionConfig) error {
+	if len(f.Trading) > 0 {
+		if err := decodeStrict(f.Trading, trading); err != nil {
+			return fmt.Errorf("config file %s trading settings: %v", f.Path, err)
+		}
+	}
+	if len(f.Execution) > 0 {
+		if err := decodeStrict(f.Execution, execution); err != nil {
+			return fmt.Errorf("config file %s execution settings: %v", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// LoadProfile finds a profile in the file, else among the shipped presets
+func (f *File) LoadProfile(name string) (*Profile, error) {
+	if f != nil {
+		if profile, ok := f.Profiles[name]; ok {
+			return profile, nil
+		}
+	}
+	profile, err := LoadProfile(name)
+	if err != nil && f != nil && len(f.Profiles) > 0 {
+		return nil, fmt.Errorf("%v; %s defines %s", err, f.Path, strings.Join(f.ProfileNames(), ", "))
+	}
+	return profile, err
+}
+
+// ProfileNames lists the profiles the file defines
+func (f *File) ProfileNames() []string {
+	names := []string{}
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks every parameter the file and its profiles set: names must
+// exist and values follow the rules environment overrides do. The default
+// profile must resolve.
+func (f *File) Validate() []error {
+	errs := []error{}
+	check := func(what string, tradingRaw, executionRaw json.RawMessage) {
+		for _, section := range []struct {
+			name   string
+			raw    json.RawMessage
+			target interface{}
+		}{
+			{"trading", tradingRaw, types.DefaultConfig()},
+			{"execution", executionRaw, types.DefaultExecutionConfig()},
+		} {
+			if len(section.raw) == 0 {
+				continue
+			}
+			if err := decodeStrict(section.raw, section.target); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %v", what, section.name, err))
+				continue
+			}
+			for _, err := range checkSettings(reflect.ValueOf(section.target).Elem(), rawKeys(section.raw)) {
+				errs = append(errs, fmt.Errorf("%s %s: %v", what, section.name, err))
+			}
+		}
+	}
+
+	check(f.Path, f.Trading, f.Execution)
+	for _, name := range f.ProfileNames() {
+		profile := f.Profiles[name]
+		check("profile "+name, profile.Trading, profile.Execution)
+	}
+	if f.Profile != "" {
+		if _, err := f.LoadProfile(f.Profile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// checkSettings applies the environment override rules to the fields of a
+// config struct whose json names are in keys
+func checkSettings(value reflect.Value, keys map[string]bool) []error {
+	errs := []error{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if !keys[name] {
+			continue
+		}
+		if err := checkSetting(value.Field(i), field); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", name, err))
+		}
+	}
+	return errs
+}
+
+// decodeStrict unmarshals JSON, rejecting keys v has no field for
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestExampleConfigFile(t *testing.T) {
+	file, err := LoadFile("../../config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := file.Validate(); len(errs) > 0 {
+		t.Fatalf("example is invalid: %v", errs)
+	}
+
+	trading, execution := types.DefaultConfig(), types.DefaultExecutionConfig()
+	if err := file.Apply(trading, execution); err != nil {
+		t.Fatal(err)
+	}
+	profile, err := file.LoadProfile(file.Profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := profile.Apply(trading, execution); err != nil {
+		t.Fatal(err)
+	}
+	// The profile wins over the file, which wins over the defaults
+	if trading.MinNetMargin != 2.5 || execution.MaxPositionUSDT != 25 || execution.ExecutionPolicy != "sequential" {
+		t.Errorf("min_net_margin %v, max_position_usdt %v, execution_policy %s", trading.MinNetMargin,
+			execution.MaxPositionUSDT, execution.ExecutionPolicy)
+	}
+
+	settings := Provenance(file, profile, trading, execution)
+	sources := map[string]string{}
+	for _, setting := range settings {
+		sources[setting.Name] = setting.Source
+	}
+	if sources["min_net_margin"] != SourceProfile || sources["execution_policy"] != SourceFile || sources["fee_rate"] != SourceDefault {
+		t.Errorf("sources = %v", sources)
+	}
+
+	if _, err := file.LoadProfile("paper"); err != nil {
+		t.Errorf("shipped preset not found beside the file's: %v", err)
+	}
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte(`
+profile: missing
+trading:
+  min_net_margn: 1
+execution:
+  execution_policy: yolo
+  max_position_usdt: -5
+  protective_stop_pct: 0
+profiles:
+  broken:
+    execution:
+      stop_loss_pct: 0
+`), 0644)
+
+	file, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := file.Validate()
+	joined := []string{}
+	for _, err := range errs {
+		joined = append(joined, err.Error())
+	}
+	report := strings.Join(joined, "\n")
+	for _, want := range []string{"min_net_margn", "execution_policy: must be one of", "max_position_usdt: must be positive",
+		"profile broken execution: stop_loss_pct", `unknown profile "missing"`} {
+		if !strings.Contains(report, want) {
+			t.Errorf("missing %q in:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "protective_stop_pct") {
+		t.Errorf("0 accepted where it disables, got:\n%s", report)
+	}
+}
+
+func TestINRFundedProfile(t *testing.T) {
+	t.Setenv("CDCX_PROFILE", "inr-funded")
+	t.Setenv("MAX_POSITION_USDT", "80")
+
+	trading, execution := types.DefaultConfig(), types.DefaultExecutionConfig()
+	profile, err := ApplySelectedProfile(nil, trading, execution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := ApplyEnvOverrides(trading, execution); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	// The preset funds buys from INR; the user's override layers over it
+	if profile.Name != "inr-funded" || execution.FundingCurrency != "INR" || execution.MaxPositionUSDT != 80 {
+		t.Errorf("profile %s, funding_currency %s, max_position_usdt %v", profile.Name, execution.FundingCurrency,
+			execution.MaxPositionUSDT)
+	}
+}
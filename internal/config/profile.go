@@ -0,0 +1,106 @@
+package config
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+//go:embed profiles/*.json
+var profileFS embed.FS
+
+// Profile is a preset, shipped or defined in the config file, applied on
+// top of the built-in defaults and the config file and under environment
+// overrides
+type Profile struct {
+	Name        string          `json:"-"`
+	Description string          `json:"description"`
+	Paper       bool            `json:"paper"`             // Same as execution dry_run: route orders to the simulated venue
+	Account     string          `json:"account,omitempty"` // Sub-account the strategy trades on (see ForAccount)
+	Trading     json.RawMessage `json:"trading"`
+	Execution   json.RawMessage `json:"execution"`
+}
+
+// Profiles lists the embedded preset names
+func Profiles() []string {
+	entries, err := profileFS.ReadDir("profiles")
+	if err != nil {
+		return nil
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadProfile reads an embedded preset by name
+func LoadProfile(name string) (*Profile, error) {
+	data, err := profileFS.ReadFile(path.Join("profiles", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(Profiles(), ", "))
+	}
+
+	profile := &Profile{Name: name}
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, fmt.Errorf("error parsing profile %s: %v", name, err)
+	}
+	return profile, nil
+}
+
+// Apply overlays the profile's values onto the configs; fields the profile
+// doesn't mention keep their current values
+func (p *Profile) Apply(trading *types.Config, execution *types.ExecutionConfig) error {
+	if len(p.Trading) > 0 {
+		if err := decodeStrict(p.Trading, trading); err != nil {
+			return fmt.Errorf("profile %s trading settings: %v", p.Name, err)
+		}
+	}
+	if len(p.Execution) > 0 {
+		if err := decodeStrict(p.Execution, execution); err != nil {
+			return fmt.Errorf("profile %s execution settings: %v", p.Name, err)
+		}
+	}
+	if p.Paper {
+		execution.DryRun = true
+	}
+	return nil
+}
+
+// ApplySelectedProfile applies the profile named in CDCX_PROFILE (cdcx --profile), else
+// the config file's default one, to the configs. The file (nil without one)
+// is searched for the profile before the shipped presets. It returns nil
+// when no profile was selected.
+func ApplySelectedProfile(file *File, trading *types.Config, execution *types.ExecutionConfig) (*Profile, error) {
+	name := os.Getenv("CDCX_PROFILE")
+	if name == "" && file != nil {
+		name = file.Profile
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, err := file.LoadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	return profile, profile.Apply(trading, execution)
+}
+
+// ApplySelectedFile applies the config file (see SelectedFile) to the
+// configs. It returns nil when there is none.
+func ApplySelectedFile(trading *types.Config, execution *types.ExecutionConfig) (*File, error) {
+	file, err := SelectedFile()
+	if err != nil || file == nil {
+		return nil, err
+	}
+	return file, file.Apply(trading, execution)
+}
@@ -0,0 +1,78 @@
+package control
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+)
+
+var logger = logging.For("control")
+
+// Server is a read-only HTTP endpoint for inspecting a running engine. Each
+// path serves the JSON of whatever its function returns at request time.
+type Server struct {
+	addr string
+	mux  *http.ServeMux
+}
+
+// NewServer creates a server that will listen on addr (host:port)
+func NewServer(addr string) *Server {
+	return &Server{addr: addr, mux: http.NewServeMux()}
+}
+
+// Handle serves the JSON encoding of view() on GET path
+func (s *Server) Handle(path string, view func() interface{}) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(view()); err != nil {
+			logger.Warn("⚠️ Control API response failed", "path", path, "error", err)
+		}
+	})
+}
+
+// HandleQuery serves the JSON encoding of view(query) on GET path, where
+// query is the request's URL parameters. An error answers 400 with
+// {"error": ...}.
+func (s *Server) HandleQuery(path string, view func(query url.Values) (interface{}, error)) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		result, err := view(r.URL.Query())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			result = map[string]string{"error": err.Error()}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			logger.Warn("⚠️ Control API response failed", "path", path, "error", err)
+		}
+	})
+}
+
+// Start listens and serves in the background. Bind to localhost unless the
+// port is otherwise protected: the endpoints are unauthenticated.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := http.Serve(listener, s.mux); err != nil {
+			logger.Warn("⚠️ Control API stopped", "error", err)
+		}
+	}()
+	return nil
+}
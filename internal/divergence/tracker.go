@@ -0,0 +1,172 @@
+package divergence
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Divergence kinds, from the simulator's point of view
+const (
+	KindPaperOnlyFill  = "paper_only_fill" // Simulator filled, live missed: the fill model is optimistic
+	KindLiveOnlyFill   = "live_only_fill"  // Live filled, simulator missed: the fill model is pessimistic
+	KindVolumeMismatch = "volume_mismatch" // Both filled, different volumes
+	KindPriceMismatch  = "price_mismatch"  // Both filled, different average prices
+)
+
+// Tolerances below which paper and live outcomes count as agreeing
+const (
+	volumeTolerancePct = 1.0
+	priceTolerancePct  = 0.5
+)
+
+// Outcome is one engine's result for an opportunity
+type Outcome struct {
+	Success        bool    `json:"success"`
+	VolumeExecuted float64 `json:"volume_executed"`
+	BuyPrice       float64 `json:"buy_price"`
+	SellPrice      float64 `json:"sell_price"`
+	ProfitINR      float64 `json:"profit_inr"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// Record is an opportunity where paper and live outcomes diverged
+type Record struct {
+	OpportunityID string    `json:"opportunity_id"`
+	Currency      string    `json:"currency"`
+	BuyMarket     string    `json:"buy_market"`
+	SellMarket    string    `json:"sell_market"`
+	Kinds         []string  `json:"kinds"`
+	Paper         Outcome   `json:"paper"`
+	Live          Outcome   `json:"live"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// Tracker records paper-vs-live divergences, persisted to disk on every
+// change, to calibrate the simulator's fill model
+type Tracker struct {
+	path    string
+	records []Record
+	mu      sync.Mutex
+}
+
+// NewTracker creates a tracker persisted at path
+func NewTracker(path string) *Tracker {
+	return &Tracker{path: path}
+}
+
+// Load restores persisted records. A missing file is an empty tracker.
+func (t *Tracker) Load() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	records := []Record{}
+	if err := utils.LoadJSON(t.path, &records); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error loading divergences %s: %v", t.path, err)
+	}
+
+	t.records = records
+	return nil
+}
+
+// Compare checks a paper and a live result for the same opportunity and
+// records them when they diverge; nil means they agreed
+func (t *Tracker) Compare(opportunityID string, paper, live *types.ExecutionResult) (*Record, error) {
+	paperOrder, liveOrder := firstOrder(paper), firstOrder(live)
+	record := Record{
+		OpportunityID: opportunityID,
+		Currency:      liveOrder.Currency,
+		BuyMarket:     liveOrder.BuyMarket,
+		SellMarket:    liveOrder.SellMarket,
+		Paper:         outcome(paperOrder),
+		Live:          outcome(liveOrder),
+		Timestamp:     time.Now(),
+	}
+	if record.Currency == "" {
+		record.Currency, record.BuyMarket, record.SellMarket = paperOrder.Currency, paperOrder.BuyMarket, paperOrder.SellMarket
+	}
+
+	switch {
+	case record.Paper.Success && !record.Live.Success:
+		record.Kinds = append(record.Kinds, KindPaperOnlyFill)
+	case !record.Paper.Success && record.Live.Success:
+		record.Kinds = append(record.Kinds, KindLiveOnlyFill)
+	case record.Paper.Success && record.Live.Success:
+		if differsPct(record.Paper.VolumeExecuted, record.Live.VolumeExecuted) > volumeTolerancePct {
+			record.Kinds = append(record.Kinds, KindVolumeMismatch)
+		}
+		if differsPct(record.Paper.BuyPrice, record.Live.BuyPrice) > priceTolerancePct ||
+			differsPct(record.Paper.SellPrice, record.Live.SellPrice) > priceTolerancePct {
+			record.Kinds = append(record.Kinds, KindPriceMismatch)
+		}
+	}
+
+	if len(record.Kinds) == 0 {
+		return nil, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.records = append(t.records, record)
+	return &record, t.save()
+}
+
+// Counts returns how often each kind of divergence was recorded
+func (t *Tracker) Counts() map[string]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, record := range t.records {
+		for _, kind := range record.Kinds {
+			counts[kind]++
+		}
+	}
+	return counts
+}
+
+// save writes records to disk. Callers hold mu.
+func (t *Tracker) save() error {
+	if err := utils.SaveJSON(t.records, t.path); err != nil {
+		return fmt.Errorf("error saving divergences %s: %v", t.path, err)
+	}
+	return nil
+}
+
+func firstOrder(result *types.ExecutionResult) types.ExecutedOrder {
+	if result == nil || len(result.Orders) == 0 {
+		return types.ExecutedOrder{}
+	}
+	return result.Orders[0]
+}
+
+func outcome(order types.ExecutedOrder) Outcome {
+	return Outcome{
+		Success:        order.Success,
+		VolumeExecuted: order.VolumeExecuted,
+		BuyPrice:       order.BuyPrice,
+		SellPrice:      order.SellPrice,
+		ProfitINR:      order.ActualProfit,
+		Error:          order.ErrorMessage,
+	}
+}
+
+// differsPct is the relative difference of b from a in percent
+func differsPct(a, b float64) float64 {
+	if a == 0 {
+		if b == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return math.Abs(b-a) / math.Abs(a) * 100
+}
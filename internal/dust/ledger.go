@@ -0,0 +1,106 @@
+package dust
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+)
+
+// Entry is leftover inventory too small to sell on its own
+type Entry struct {
+	Currency    string    `json:"currency"`
+	Quantity    float64   `json:"quantity"`
+	Leftovers   int       `json:"leftovers"` // Number of recoveries that contributed
+	FirstSeen   time.Time `json:"first_seen"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// Ledger tracks dust per currency, persisted to disk on every change, so
+// leftovers accumulate across runs until they clear the minimum order size
+type Ledger struct {
+	path    string
+	entries map[string]Entry
+	mu      sync.Mutex
+}
+
+// NewLedger creates a ledger persisted at path
+func NewLedger(path string) *Ledger {
+	return &Ledger{
+		path:    path,
+		entries: make(map[string]Entry),
+	}
+}
+
+// Load restores persisted dust. A missing file is an empty ledger.
+func (l *Ledger) Load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make(map[string]Entry)
+	if err := utils.LoadJSON(l.path, &entries); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error loading dust ledger %s: %v", l.path, err)
+	}
+
+	l.entries = entries
+	return nil
+}
+
+// Add records a leftover and returns the accumulated dust for the currency
+func (l *Ledger) Add(currency string, quantity float64) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	entry, exists := l.entries[currency]
+	if !exists {
+		entry = Entry{Currency: currency, FirstSeen: now}
+	}
+	entry.Quantity += quantity
+	entry.Leftovers++
+	entry.LastUpdated = now
+
+	l.entries[currency] = entry
+	return entry, l.save()
+}
+
+// Quantity returns the accumulated dust for a currency
+func (l *Ledger) Quantity(currency string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.entries[currency].Quantity
+}
+
+// Clear drops a currency's dust once it has been sold with a larger order
+func (l *Ledger) Clear(currency string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.entries[currency]; !exists {
+		return nil
+	}
+	delete(l.entries, currency)
+	return l.save()
+}
+
+// Entries returns a snapshot of all tracked dust
+func (l *Ledger) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]Entry, 0, len(l.entries))
+	for _, entry := range l.entries {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// save writes the ledger to disk. Callers hold l.mu.
+func (l *Ledger) save() error {
+	return utils.SaveJSON(l.entries, l.path)
+}
@@ -0,0 +1,146 @@
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Book tracks held inventory and its acquisition cost, persisted to disk on
+// every change so unrealized P&L survives restarts
+type Book struct {
+	path      string
+	positions map[string]types.Position
+	mu        sync.Mutex
+}
+
+// NewBook creates an inventory book persisted at path
+func NewBook(path string) *Book {
+	return &Book{
+		path:      path,
+		positions: make(map[string]types.Position),
+	}
+}
+
+// Load restores persisted positions. A missing file is an empty book.
+func (b *Book) Load() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	positions := make(map[string]types.Position)
+	if err := utils.LoadJSON(b.path, &positions); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error loading inventory %s: %v", b.path, err)
+	}
+
+	b.positions = positions
+	return nil
+}
+
+// Add records acquired inventory, averaging cost into any existing position
+func (b *Book) Add(currency string, quantity, costINR float64, source string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	position, exists := b.positions[currency]
+	if !exists {
+		position = types.Position{Currency: currency, AcquiredAt: time.Now()}
+	}
+	position.Quantity += quantity
+	position.CostINR += costINR
+	position.Source = source
+
+	b.positions[currency] = position
+	return b.save()
+}
+
+// Reduce removes quantity at average cost, dropping the position when emptied
+func (b *Book) Reduce(currency string, quantity float64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	position, exists := b.positions[currency]
+	if !exists {
+		return nil
+	}
+
+	if quantity >= position.Quantity {
+		delete(b.positions, currency)
+	} else {
+		position.CostINR *= (position.Quantity - quantity) / position.Quantity
+		position.Quantity -= quantity
+		b.positions[currency] = position
+	}
+	return b.save()
+}
+
+// Reconcile shrinks positions to what the venue still holds, so inventory
+// sold elsewhere (e.g. by hand on the website) stops counting
+func (b *Book) Reconcile(balances []coindcx.Balance) error {
+	held := make(map[string]float64)
+	for _, balance := range balances {
+		held[balance.Currency] = balance.Balance + balance.Locked
+	}
+
+	for _, position := range b.Positions() {
+		if excess := position.Quantity - held[position.Currency]; excess > 0 {
+			if err := b.Reduce(position.Currency, excess); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Positions returns a snapshot of held inventory sorted by currency
+func (b *Book) Positions() []types.Position {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	positions := make([]types.Position, 0, len(b.positions))
+	for _, position := range b.positions {
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Currency < positions[j].Currency
+	})
+	return positions
+}
+
+// Value marks every position with markINR, the live INR price per unit.
+// Positions without a price are returned at cost with zero unrealized P&L.
+func (b *Book) Value(markINR func(currency string) (float64, error)) ([]types.PositionValuation, float64) {
+	valuations := []types.PositionValuation{}
+	total := 0.0
+
+	for _, position := range b.Positions() {
+		valuation := types.PositionValuation{Position: position, ValueINR: position.CostINR}
+
+		if price, err := markINR(position.Currency); err == nil && price > 0 {
+			valuation.MarkPriceINR = price
+			valuation.ValueINR = position.Quantity * price
+			valuation.UnrealizedINR = valuation.ValueINR - position.CostINR
+			if position.CostINR > 0 {
+				valuation.UnrealizedPct = valuation.UnrealizedINR / position.CostINR * 100
+			}
+		}
+
+		total += valuation.UnrealizedINR
+		valuations = append(valuations, valuation)
+	}
+
+	return valuations, total
+}
+
+// save writes the book to disk. Callers hold b.mu.
+func (b *Book) save() error {
+	return utils.SaveJSON(b.positions, b.path)
+}
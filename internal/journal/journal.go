@@ -0,0 +1,187 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State is how far an arbitrage's legs got
+type State string
+
+const (
+	Buying  State = "buying"  // Buy leg being placed; anything may be held
+	Bought  State = "bought"  // Buy filled; the coins wait for the sell leg
+	Selling State = "selling" // Sell leg placed for what was bought
+)
+
+// transitions lists the states each state may move to. An entry stays in
+// a state while more of its orders are recorded; Finish ends it from any.
+var transitions = map[State][]State{
+	Buying:  {Buying, Bought},
+	Bought:  {Selling},
+	Selling: {Selling},
+}
+
+// Entry is one arbitrage in flight: its markets and every order its legs
+// placed, enough for a later process to find out what is still held
+type Entry struct {
+	ID           string   `json:"id"`
+	ExecutionID  string   `json:"execution_id,omitempty"` // Execution attempt, for its logs and audit trail
+	Currency     string   `json:"currency"`
+	BuyMarket    string   `json:"buy_market"`
+	SellMarket   string   `json:"sell_market"`
+	SellPair     string   `json:"sell_pair"`  // For the sell market's order book
+	BuyQuote     string   `json:"buy_quote"`  // Currency the buy was paid in
+	SellQuote    string   `json:"sell_quote"` // Currency the sell is paid in
+	State        State    `json:"state"`
+	BuyOrderIDs  []string `json:"buy_order_ids,omitempty"`
+	SellOrderIDs []string `json:"sell_order_ids,omitempty"`
+	// Client order IDs are journaled before each create is sent, so an
+	// order whose create never returned can still be looked up
+	BuyClientOrderIDs  []string  `json:"buy_client_order_ids,omitempty"`
+	SellClientOrderIDs []string  `json:"sell_client_order_ids,omitempty"`
+	Bought             float64   `json:"bought,omitempty"` // Filled buy quantity, once Bought
+	BuyPrice           float64   `json:"buy_price,omitempty"`
+	BuyFee             float64   `json:"buy_fee,omitempty"`
+	StartedAt          time.Time `json:"started_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// Journal records each arbitrage's legs as they progress, persisted on
+// every change, so a process that dies between the buy and the sell leaves
+// behind what the next one needs to finish or unwind the trade
+type Journal struct {
+	path    string
+	entries map[string]Entry
+	mu      sync.Mutex
+}
+
+// NewJournal creates a journal persisted at path
+func NewJournal(path string) *Journal {
+	return &Journal{path: path, entries: make(map[string]Entry)}
+}
+
+// Load restores the entries a previous process left unfinished. A missing
+// file is an empty journal.
+func (j *Journal) Load() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error loading execution journal %s: %v", j.path, err)
+	}
+	entries := make(map[string]Entry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error loading execution journal %s: %v", j.path, err)
+	}
+
+	j.entries = entries
+	return j.sorted(), nil
+}
+
+// Begin opens an entry in the Buying state and returns its ID
+func (j *Journal) Begin(entry Entry) (string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	entry.ID = fmt.Sprintf("%s-%d", entry.Currency, now.UnixNano())
+	entry.State = Buying
+	entry.StartedAt, entry.UpdatedAt = now, now
+	j.entries[entry.ID] = entry
+	return entry.ID, j.save()
+}
+
+// Advance moves an entry to state, applying change to it first, and fails
+// on a transition the state machine doesn't allow
+func (j *Journal) Advance(id string, state State, change func(*Entry)) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[id]
+	if !ok {
+		return fmt.Errorf("no journal entry %s", id)
+	}
+	allowed := false
+	for _, next := range transitions[entry.State] {
+		allowed = allowed || next == state
+	}
+	if !allowed {
+		return fmt.Errorf("journal entry %s: %s can't move to %s", id, entry.State, state)
+	}
+
+	if change != nil {
+		change(&entry)
+	}
+	entry.State = state
+	entry.UpdatedAt = time.Now()
+	j.entries[id] = entry
+	return j.save()
+}
+
+// Finish removes a settled entry
+func (j *Journal) Finish(id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.entries[id]; !ok {
+		return nil
+	}
+	delete(j.entries, id)
+	return j.save()
+}
+
+// Entries returns the open entries, oldest first
+func (j *Journal) Entries() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sorted()
+}
+
+func (j *Journal) sorted() []Entry {
+	entries := make([]Entry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].StartedAt.Before(entries[b].StartedAt) })
+	return entries
+}
+
+// save writes the journal through a temporary file, so a crash mid-write
+// leaves the previous version rather than a truncated one. Callers hold j.mu.
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*")
+	if err != nil {
+		return fmt.Errorf("error saving execution journal: %v", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("error saving execution journal: %v", err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("error saving execution journal: %v", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("error saving execution journal: %v", err)
+	}
+	if err := os.Rename(temp.Name(), j.path); err != nil {
+		return fmt.Errorf("error saving execution journal: %v", err)
+	}
+	return nil
+}
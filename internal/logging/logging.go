@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// level is shared by every logger, so Mute and --quiet reach them all
+var level = new(slog.LevelVar)
+
+// Levels and formats Setup accepts
+var (
+	Levels  = []string{"debug", "info", "warn", "error"}
+	Formats = []string{"plain", "text", "json"}
+)
+
+// Setup routes all logging through slog at level ("debug", "info", "warn"
+// or "error") in format: "plain" keeps the terminal look (time, message,
+// then any attributes), "text" is slog's key=value and "json" one object
+// per line for log shippers. Modules log through For; anything still
+// written with the standard log package becomes an info record.
+func Setup(levelName, format string, w io.Writer) error {
+	switch strings.ToLower(levelName) {
+	case "debug":
+		level.Set(slog.LevelDebug)
+	case "", "info":
+		level.Set(slog.LevelInfo)
+	case "warn", "warning":
+		level.Set(slog.LevelWarn)
+	case "error":
+		level.Set(slog.LevelError)
+	default:
+		return fmt.Errorf("log level %q: use %s", levelName, strings.Join(Levels, ", "))
+	}
+
+	options := &slog.HandlerOptions{Level: level, AddSource: true}
+	var handler slog.Handler
+	switch format {
+	case "", "plain":
+		handler = &plainHandler{w: w, mu: &sync.Mutex{}}
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("log format %q: use %s", format, strings.Join(Formats, ", "))
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetupFromEnv applies LOG_LEVEL and LOG_FORMAT; LOG_QUIET=true (--quiet)
+// raises the level to warn
+func SetupFromEnv() error {
+	levelName := os.Getenv("LOG_LEVEL")
+	if os.Getenv("LOG_QUIET") == "true" {
+		levelName = "warn"
+	}
+	return Setup(levelName, os.Getenv("LOG_FORMAT"), os.Stderr)
+}
+
+// For returns a component's logger; its records carry the component name.
+// It writes through whatever Setup installed last, so packages can create
+// theirs before the command configures logging.
+func For(component string) *slog.Logger {
+	return slog.New(&deferred{wrap: func(h slog.Handler) slog.Handler {
+		return h.WithAttrs([]slog.Attr{slog.String("component", component)})
+	}})
+}
+
+// deferred resolves the default handler on every record
+type deferred struct {
+	wrap func(slog.Handler) slog.Handler
+}
+
+func (d *deferred) Enabled(ctx context.Context, l slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, l)
+}
+
+func (d *deferred) Handle(ctx context.Context, record slog.Record) error {
+	return d.wrap(slog.Default().Handler()).Handle(ctx, record)
+}
+
+func (d *deferred) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &deferred{wrap: func(h slog.Handler) slog.Handler { return d.wrap(h).WithAttrs(attrs) }}
+}
+
+func (d *deferred) WithGroup(name string) slog.Handler {
+	return &deferred{wrap: func(h slog.Handler) slog.Handler { return d.wrap(h).WithGroup(name) }}
+}
+
+// Mute silences all logging until the returned func restores the level,
+// e.g. around a batch whose per-item logging drowns its result
+func Mute() func() {
+	previous := level.Level()
+	level.Set(slog.LevelError + 4)
+	return func() { level.Set(previous) }
+}
+
+// plainHandler writes "2006/01/02 15:04:05 file.go:12: message key=value"
+// as the log package always has; the component is left off the terminal
+type plainHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	attrs []slog.Attr // Keys already qualified by their group
+	group string      // Prefix of later keys, "" or ending in "."
+}
+
+func (h *plainHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h *plainHandler) Handle(_ context.Context, record slog.Record) error {
+	var line strings.Builder
+	line.WriteString(record.Time.Format("2006/01/02 15:04:05 "))
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		fmt.Fprintf(&line, "%s:%d: ", filepath.Base(frame.File), frame.Line)
+	}
+	line.WriteString(record.Message)
+	for _, attr := range h.attrs {
+		fmt.Fprintf(&line, " %s=%v", attr.Key, attr.Value)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key != "component" {
+			fmt.Fprintf(&line, " %s=%v", h.group+attr.Key, attr.Value)
+		}
+		return true
+	})
+	line.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line.String())
+	return err
+}
+
+func (h *plainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, attr := range attrs {
+		if attr.Key != "component" {
+			clone.attrs = append(clone.attrs, slog.Attr{Key: h.group + attr.Key, Value: attr.Value})
+		}
+	}
+	return &clone
+}
+
+func (h *plainHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.group = h.group + name + "."
+	return &clone
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := Setup("info", "json", &out); err != nil {
+		t.Fatal(err)
+	}
+	defer Setup("info", "plain", &bytes.Buffer{})
+
+	For("market").Warn("⚠️ Book stale", "symbol", "BTCINR")
+	For("arbitrage").Info("💰 SUCCESS", "currency", "BTC", "profit_inr", 12.5)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2:\n%s", len(lines), out.String())
+	}
+
+	var warned, structured map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &warned); err != nil {
+		t.Fatal(err)
+	}
+	if warned["level"] != "WARN" || warned["msg"] != "⚠️ Book stale" || warned["component"] != "market" {
+		t.Errorf("warn record = %v", warned)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &structured); err != nil {
+		t.Fatal(err)
+	}
+	if structured["component"] != "arbitrage" || structured["currency"] != "BTC" || structured["profit_inr"] != 12.5 {
+		t.Errorf("structured record = %v", structured)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	var out bytes.Buffer
+	if err := Setup("warn", "plain", &out); err != nil {
+		t.Fatal(err)
+	}
+	defer Setup("info", "plain", &bytes.Buffer{})
+
+	logger := For("detector")
+	logger.Info("📊 progress")
+	logger.Info("🎯 VIABLE", "market", "BTCINR")
+	logger.Error("❌ failed")
+	if got := out.String(); strings.Contains(got, "progress") || strings.Contains(got, "VIABLE") || !strings.Contains(got, "❌ failed") {
+		t.Errorf("warn level logged:\n%s", got)
+	}
+
+	out.Reset()
+	unmute := Mute()
+	logger.Error("❌ hidden")
+	unmute()
+	logger.Error("🚨 shown")
+	if got := out.String(); strings.Contains(got, "hidden") || !strings.Contains(got, "🚨 shown") {
+		t.Errorf("muted logging:\n%s", got)
+	}
+
+	if err := Setup("verbose", "plain", &out); err == nil {
+		t.Error("unknown level accepted")
+	}
+}
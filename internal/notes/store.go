@@ -0,0 +1,126 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Store keeps trade annotations apart from execution logs so logs stay an
+// untouched record of what the system did, persisted to disk on every change
+type Store struct {
+	path        string
+	annotations map[string]types.TradeAnnotation
+	mu          sync.Mutex
+}
+
+// NewStore creates an annotation store persisted at path
+func NewStore(path string) *Store {
+	return &Store{
+		path:        path,
+		annotations: make(map[string]types.TradeAnnotation),
+	}
+}
+
+// Load restores persisted annotations. A missing file is an empty store.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	annotations := make(map[string]types.TradeAnnotation)
+	if err := utils.LoadJSON(s.path, &annotations); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error loading annotations %s: %v", s.path, err)
+	}
+
+	s.annotations = annotations
+	return nil
+}
+
+// AddNote attaches a note to the execution with the given buy order ID
+func (s *Store) AddNote(orderID, text, author string) (types.TradeAnnotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	annotation := s.get(orderID)
+	annotation.Notes = append(annotation.Notes, types.TradeNote{
+		Text:      text,
+		Author:    author,
+		CreatedAt: time.Now(),
+	})
+
+	s.annotations[orderID] = annotation
+	return annotation, s.save()
+}
+
+// MarkManual flags an execution as resolved by hand with the operator's P&L
+func (s *Store) MarkManual(orderID string, pnlINR float64) (types.TradeAnnotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	annotation := s.get(orderID)
+	annotation.Manual = true
+	annotation.ManualPnLINR = pnlINR
+	annotation.ManualAt = time.Now()
+
+	s.annotations[orderID] = annotation
+	return annotation, s.save()
+}
+
+// Get returns the annotation for an order, if any
+func (s *Store) Get(orderID string) (types.TradeAnnotation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	annotation, exists := s.annotations[orderID]
+	return annotation, exists
+}
+
+// Apply attaches annotations to a result's orders and recomputes its total
+// profit. Manually resolved orders count the operator's P&L instead of the
+// automated figure and lose their attribution, which no longer describes them.
+func (s *Store) Apply(result *types.ExecutionResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totalProfit := 0.0
+	for i := range result.Orders {
+		order := &result.Orders[i]
+
+		if annotation, exists := s.annotations[order.BuyOrderID]; exists && order.BuyOrderID != "" {
+			annotation := annotation
+			order.Annotation = &annotation
+			if annotation.Manual {
+				order.ActualProfit = annotation.ManualPnLINR
+				order.Attribution = nil
+				order.Success = true
+			}
+		}
+
+		if order.Success {
+			totalProfit += order.ActualProfit
+		}
+	}
+
+	result.TotalProfit = totalProfit
+	result.Successful = totalProfit > 0
+}
+
+func (s *Store) get(orderID string) types.TradeAnnotation {
+	annotation, exists := s.annotations[orderID]
+	if !exists {
+		annotation = types.TradeAnnotation{OrderID: orderID}
+	}
+	return annotation
+}
+
+// save writes the store to disk. Callers hold s.mu.
+func (s *Store) save() error {
+	return utils.SaveJSON(s.annotations, s.path)
+}
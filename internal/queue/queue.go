@@ -0,0 +1,317 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Entry is a detected opportunity waiting for execution
+type Entry struct {
+	ID          string                     `json:"id"`
+	Opportunity types.ArbitrageOpportunity `json:"opportunity"`
+	EnqueuedAt  time.Time                  `json:"enqueued_at"`
+	ExpiresAt   time.Time                  `json:"expires_at"`
+}
+
+// Expired reports whether the entry outlived its TTL
+func (e Entry) Expired(now time.Time) bool {
+	return now.After(e.ExpiresAt)
+}
+
+// Why the queue let an opportunity go without executing it
+const (
+	DropStale   = "stale"   // Not detected again within the TTL
+	DropEvicted = "evicted" // Made room for a higher-margin opportunity
+	DropFull    = "full"    // Arrived at a full queue without beating anything in it
+)
+
+// Drop is an entry the queue let go without executing it
+type Drop struct {
+	Entry  Entry
+	Reason string
+}
+
+// Admission is what Push did with an opportunity
+type Admission struct {
+	Entry    Entry
+	Merged   bool   // Refreshed the same route already queued or executing
+	Rejected bool   // Dropped itself (DropFull): nothing queued was worth less
+	Dropped  []Drop // Waiting entries let go to make room
+}
+
+// Queue is the pending opportunity queue, persisted to disk on every change
+// so a restart resumes opportunities detected moments before shutdown.
+// Executors Take the best waiting entry and Remove it once done, or Keep it
+// for the next start.
+type Queue struct {
+	path        string
+	ttl         time.Duration
+	capacity    int // Most entries waiting, not counting executing ones; 0 is unbounded
+	entries     []Entry
+	executing   map[string]bool
+	outstanding int           // Entries this process has yet to execute, drop or keep
+	settled     *sync.Cond    // Signalled when outstanding reaches zero
+	ready       chan struct{} // Closed, and replaced, when an entry is queued
+	mu          sync.Mutex
+}
+
+// NewQueue creates a queue persisted at path whose entries live for ttl
+func NewQueue(path string, ttl time.Duration) *Queue {
+	q := &Queue{
+		path:      path,
+		ttl:       ttl,
+		executing: make(map[string]bool),
+		ready:     make(chan struct{}),
+	}
+	q.settled = sync.NewCond(&q.mu)
+	return q
+}
+
+// SetCapacity bounds the entries waiting for execution (0 is unbounded)
+func (q *Queue) SetCapacity(capacity int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.capacity = max(capacity, 0)
+}
+
+// OpportunityID identifies an opportunity by currency and route
+func OpportunityID(opp types.ArbitrageOpportunity) string {
+	return fmt.Sprintf("%s_%s_%s", opp.TargetCurrency, opp.BuyMarket.Symbol, opp.SellMarket.Symbol)
+}
+
+// Load restores persisted entries, dropping any whose TTL has passed.
+// A missing file is an empty queue.
+func (q *Queue) Load() ([]Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var entries []Entry
+	if err := utils.LoadJSON(q.path, &entries); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error loading queue %s: %v", q.path, err)
+	}
+
+	now := time.Now()
+	q.entries = q.entries[:0]
+	for _, entry := range entries {
+		if !entry.Expired(now) {
+			q.entries = append(q.entries, entry)
+		}
+	}
+	q.finished(-len(q.entries))
+	q.wake()
+
+	return append([]Entry(nil), q.entries...), q.save()
+}
+
+// Push enqueues an opportunity. A route already waiting is merged: the
+// fresher detection replaces it and its TTL is refreshed; one executing is
+// left alone. At capacity, stale entries are dropped first, then the
+// lowest-margin waiting one if opp beats it; otherwise opp is rejected.
+func (q *Queue) Push(opp types.ArbitrageOpportunity) (Admission, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	entry := Entry{
+		ID:          OpportunityID(opp),
+		Opportunity: opp,
+		EnqueuedAt:  now,
+		ExpiresAt:   now.Add(q.ttl),
+	}
+	admission := Admission{Entry: entry}
+
+	if q.executing[entry.ID] {
+		admission.Merged = true
+		return admission, nil
+	}
+	for i := range q.entries {
+		if q.entries[i].ID == entry.ID {
+			entry.EnqueuedAt = q.entries[i].EnqueuedAt
+			q.entries[i] = entry
+			admission.Entry, admission.Merged = entry, true
+			return admission, q.save()
+		}
+	}
+
+	defer func() { q.finished(len(admission.Dropped)) }()
+	if q.capacity > 0 && q.waiting() >= q.capacity {
+		admission.Dropped = q.dropStale(now)
+	}
+	if q.capacity > 0 && q.waiting() >= q.capacity {
+		worst := q.worstWaiting()
+		if q.entries[worst].Opportunity.NetMarginPct >= opp.NetMarginPct {
+			admission.Rejected = true
+			return admission, q.save()
+		}
+		admission.Dropped = append(admission.Dropped, Drop{Entry: q.entries[worst], Reason: DropEvicted})
+		q.entries = append(q.entries[:worst], q.entries[worst+1:]...)
+	}
+
+	q.entries = append(q.entries, entry)
+	q.finished(-1)
+	q.wake()
+	return admission, q.save()
+}
+
+// Take hands the highest-margin waiting entry to an executor, which Removes
+// it when done. Entries not detected again within the TTL are dropped as
+// stale on the way; ok is false when nothing is left waiting.
+func (q *Queue) Take() (entry Entry, stale []Drop, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if stale = q.dropStale(time.Now()); len(stale) > 0 {
+		q.finished(len(stale))
+		q.save() // Best effort: a failed write is retried by the next change
+	}
+
+	best := -1
+	for i, candidate := range q.entries {
+		if q.executing[candidate.ID] {
+			continue
+		}
+		if best < 0 || candidate.Opportunity.NetMarginPct > q.entries[best].Opportunity.NetMarginPct {
+			best = i
+		}
+	}
+	if best < 0 {
+		return Entry{}, stale, false
+	}
+	q.executing[q.entries[best].ID] = true
+	return q.entries[best], stale, true
+}
+
+// Remove drops an entry once it has been executed or discarded
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.executing, id)
+	for i := range q.entries {
+		if q.entries[i].ID == id {
+			q.entries = append(q.entries[:i], q.entries[i+1:]...)
+			q.finished(1)
+			return q.save()
+		}
+	}
+	return nil
+}
+
+// Keep leaves a taken entry persisted for the next start without executing
+// it, as when shutting down. It is not taken again by this process.
+func (q *Queue) Keep(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, entry := range q.entries {
+		if entry.ID == id && q.executing[id] {
+			q.finished(1)
+			return
+		}
+	}
+}
+
+// Ready returns a channel closed once an entry is next queued. Fetch it
+// before a Take that finds nothing, so a push in between isn't missed.
+func (q *Queue) Ready() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.ready
+}
+
+// Wait blocks until every entry queued or loaded has been removed, dropped
+// or kept
+func (q *Queue) Wait() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.outstanding > 0 {
+		q.settled.Wait()
+	}
+}
+
+// Has reports whether an opportunity with id is queued or executing
+func (q *Queue) Has(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, entry := range q.entries {
+		if entry.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of pending entries
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// finished counts n entries as settled; a negative n adds outstanding ones.
+// Callers hold q.mu.
+func (q *Queue) finished(n int) {
+	q.outstanding -= n
+	if q.outstanding <= 0 {
+		q.outstanding = 0
+		q.settled.Broadcast()
+	}
+}
+
+// wake releases everything waiting on Ready. Callers hold q.mu.
+func (q *Queue) wake() {
+	close(q.ready)
+	q.ready = make(chan struct{})
+}
+
+// waiting counts the entries not yet taken. Callers hold q.mu.
+func (q *Queue) waiting() int {
+	return len(q.entries) - len(q.executing)
+}
+
+// worstWaiting indexes the lowest-margin entry not yet taken. Callers hold
+// q.mu and know one is waiting.
+func (q *Queue) worstWaiting() int {
+	worst := -1
+	for i, entry := range q.entries {
+		if q.executing[entry.ID] {
+			continue
+		}
+		if worst < 0 || entry.Opportunity.NetMarginPct < q.entries[worst].Opportunity.NetMarginPct {
+			worst = i
+		}
+	}
+	return worst
+}
+
+// dropStale removes the waiting entries whose TTL has passed. Callers hold
+// q.mu and save.
+func (q *Queue) dropStale(now time.Time) []Drop {
+	var dropped []Drop
+	kept := q.entries[:0]
+	for _, entry := range q.entries {
+		if !q.executing[entry.ID] && entry.Expired(now) {
+			dropped = append(dropped, Drop{Entry: entry, Reason: DropStale})
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	q.entries = kept
+	return dropped
+}
+
+// save writes the queue to disk. Callers hold q.mu.
+func (q *Queue) save() error {
+	if q.entries == nil {
+		q.entries = []Entry{}
+	}
+	return utils.SaveJSON(q.entries, q.path)
+}
@@ -0,0 +1,403 @@
+package queue
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func opportunity(currency string, marginPct float64) types.ArbitrageOpportunity {
+	opp := types.ArbitrageOpportunity{TargetCurrency: currency, NetMarginPct: marginPct, Viable: true}
+	opp.BuyMarket.Symbol, opp.SellMarket.Symbol = currency+"USDT", currency+"INR"
+	return opp
+}
+
+func id(currency string) string {
+	return OpportunityID(opportunity(currency, 0))
+}
+
+// queued is a waiting entry set up by a test: a currency, its margin, and
+// whether its TTL has already passed
+type queued struct {
+	currency  string
+	marginPct float64
+	stale     bool
+}
+
+func newQueue(t *testing.T, capacity int, entries ...queued) *Queue {
+	t.Helper()
+	q := NewQueue(filepath.Join(t.TempDir(), "queue.json"), time.Minute)
+	for _, entry := range entries {
+		if _, err := q.Push(opportunity(entry.currency, entry.marginPct)); err != nil {
+			t.Fatal(err)
+		}
+		if entry.stale {
+			q.entries[len(q.entries)-1].ExpiresAt = time.Now().Add(-time.Second)
+		}
+	}
+	q.SetCapacity(capacity)
+	return q
+}
+
+func ids(q *Queue) []string {
+	var ids []string
+	for _, entry := range q.entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func drops(dropped []Drop) []string {
+	var out []string
+	for _, drop := range dropped {
+		out = append(out, drop.Entry.ID+" "+drop.Reason)
+	}
+	return out
+}
+
+func TestPushAtCapacity(t *testing.T) {
+	cases := []struct {
+		name     string
+		capacity int
+		queued   []queued
+		taken    string // Taken before the push, so executing
+		push     queued
+		rejected bool
+		dropped  []string
+		want     []string
+	}{
+		{
+			name:   "unbounded keeps everything",
+			queued: []queued{{"AAA", 1, false}, {"BBB", 2, false}},
+			push:   queued{"CCC", 0.5, false},
+			want:   []string{id("AAA"), id("BBB"), id("CCC")},
+		},
+		{
+			name:     "room left",
+			capacity: 3,
+			queued:   []queued{{"AAA", 1, false}},
+			push:     queued{"BBB", 0.5, false},
+			want:     []string{id("AAA"), id("BBB")},
+		},
+		{
+			name:     "evicts the lowest margin",
+			capacity: 2,
+			queued:   []queued{{"AAA", 2, false}, {"BBB", 1, false}},
+			push:     queued{"CCC", 3, false},
+			dropped:  []string{id("BBB") + " " + DropEvicted},
+			want:     []string{id("AAA"), id("CCC")},
+		},
+		{
+			name:     "rejects what beats nothing",
+			capacity: 2,
+			queued:   []queued{{"AAA", 2, false}, {"BBB", 1, false}},
+			push:     queued{"CCC", 1, false},
+			rejected: true,
+			want:     []string{id("AAA"), id("BBB")},
+		},
+		{
+			name:     "stale dropped before evicting",
+			capacity: 2,
+			queued:   []queued{{"AAA", 5, true}, {"BBB", 1, false}},
+			push:     queued{"CCC", 0.5, false},
+			dropped:  []string{id("AAA") + " " + DropStale},
+			want:     []string{id("BBB"), id("CCC")},
+		},
+		{
+			name:     "all stale dropped, then the worst evicted",
+			capacity: 1,
+			queued:   []queued{{"AAA", 5, true}, {"BBB", 1, true}, {"CCC", 2, false}},
+			push:     queued{"DDD", 3, false},
+			dropped:  []string{id("AAA") + " " + DropStale, id("BBB") + " " + DropStale, id("CCC") + " " + DropEvicted},
+			want:     []string{id("DDD")},
+		},
+		{
+			name:     "executing entries neither count nor get evicted",
+			capacity: 1,
+			queued:   []queued{{"AAA", 0.1, false}, {"BBB", 1, false}},
+			taken:    "BBB",
+			push:     queued{"CCC", 2, false},
+			dropped:  []string{id("AAA") + " " + DropEvicted},
+			want:     []string{id("BBB"), id("CCC")},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newQueue(t, tc.capacity, tc.queued...)
+			if tc.taken != "" {
+				q.executing[id(tc.taken)] = true
+			}
+
+			admission, err := q.Push(opportunity(tc.push.currency, tc.push.marginPct))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if admission.Rejected != tc.rejected || admission.Merged {
+				t.Errorf("rejected %v, merged %v; want rejected %v", admission.Rejected, admission.Merged, tc.rejected)
+			}
+			if got := drops(admission.Dropped); !slices.Equal(got, tc.dropped) {
+				t.Errorf("dropped %v, want %v", got, tc.dropped)
+			}
+			if got := ids(q); !slices.Equal(got, tc.want) {
+				t.Errorf("queue holds %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPushMergesDuplicate(t *testing.T) {
+	cases := []struct {
+		name       string
+		executing  bool
+		wantMargin float64
+	}{
+		{"waiting entry refreshed", false, 3},
+		{"executing entry left alone", true, 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newQueue(t, 1, queued{"AAA", 1, false})
+			first := q.entries[0]
+			q.entries[0].ExpiresAt = time.Now().Add(time.Second)
+			if tc.executing {
+				q.executing[first.ID] = true
+			}
+
+			admission, err := q.Push(opportunity("AAA", 3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !admission.Merged || admission.Rejected || len(admission.Dropped) != 0 {
+				t.Errorf("admission = %+v, want a plain merge", admission)
+			}
+			if q.Len() != 1 {
+				t.Fatalf("queue holds %v, want the one route", ids(q))
+			}
+
+			entry := q.entries[0]
+			if entry.Opportunity.NetMarginPct != tc.wantMargin {
+				t.Errorf("margin %g, want %g", entry.Opportunity.NetMarginPct, tc.wantMargin)
+			}
+			if !entry.EnqueuedAt.Equal(first.EnqueuedAt) {
+				t.Errorf("enqueued at %v, want the first detection's %v", entry.EnqueuedAt, first.EnqueuedAt)
+			}
+			if refreshed := entry.ExpiresAt.After(time.Now().Add(time.Second)); refreshed == tc.executing {
+				t.Errorf("TTL refreshed %v, want %v", refreshed, !tc.executing)
+			}
+		})
+	}
+}
+
+func TestTakeAndRemove(t *testing.T) {
+	cases := []struct {
+		name   string
+		queued []queued
+		takes  []string // IDs expected from successive Takes, until one reports nothing
+		stale  []string
+	}{
+		{
+			name:   "empty",
+			queued: nil,
+		},
+		{
+			name:   "highest margin first",
+			queued: []queued{{"AAA", 1, false}, {"BBB", 3, false}, {"CCC", 2, false}},
+			takes:  []string{id("BBB"), id("CCC"), id("AAA")},
+		},
+		{
+			name:   "stale dropped, not taken",
+			queued: []queued{{"AAA", 1, false}, {"BBB", 3, true}},
+			takes:  []string{id("AAA")},
+			stale:  []string{id("BBB") + " " + DropStale},
+		},
+		{
+			name:   "only stale",
+			queued: []queued{{"AAA", 1, true}},
+			stale:  []string{id("AAA") + " " + DropStale},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newQueue(t, 0, tc.queued...)
+
+			var taken, stale []string
+			for {
+				entry, dropped, ok := q.Take()
+				stale = append(stale, drops(dropped)...)
+				if !ok {
+					break
+				}
+				taken = append(taken, entry.ID)
+				if !q.Has(entry.ID) {
+					t.Errorf("%s not held while executing", entry.ID)
+				}
+			}
+			if !slices.Equal(taken, tc.takes) {
+				t.Errorf("took %v, want %v", taken, tc.takes)
+			}
+			if !slices.Equal(stale, tc.stale) {
+				t.Errorf("dropped %v, want %v", stale, tc.stale)
+			}
+
+			// Taken entries stay until removed
+			if q.Len() != len(tc.takes) {
+				t.Errorf("%d entries before removal, want %d", q.Len(), len(tc.takes))
+			}
+			for _, taken := range taken {
+				if err := q.Remove(taken); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if q.Len() != 0 || len(q.executing) != 0 {
+				t.Errorf("left %v, executing %v", ids(q), q.executing)
+			}
+		})
+	}
+}
+
+func TestRemovedRouteCanQueueAgain(t *testing.T) {
+	q := newQueue(t, 0, queued{"AAA", 1, false})
+	entry, _, ok := q.Take()
+	if !ok {
+		t.Fatal("nothing taken")
+	}
+	if admission, _ := q.Push(opportunity("AAA", 2)); !admission.Merged {
+		t.Error("a detection of the executing route was queued twice")
+	}
+	if err := q.Remove(entry.ID); err != nil {
+		t.Fatal(err)
+	}
+	if admission, _ := q.Push(opportunity("AAA", 2)); admission.Merged || !q.Has(entry.ID) {
+		t.Errorf("admission = %+v after removal, want the route queued again", admission)
+	}
+}
+
+func TestLoadDropsExpired(t *testing.T) {
+	q := newQueue(t, 0, queued{"AAA", 1, false}, queued{"BBB", 2, true})
+	if err := q.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewQueue(q.path, time.Minute)
+	entries, err := restarted.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ID != id("AAA") {
+		t.Errorf("loaded %+v, want only the live entry", entries)
+	}
+}
+
+func TestConcurrentPushTakeRemove(t *testing.T) {
+	q := newQueue(t, 4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				q.Push(opportunity(fmt.Sprintf("C%d", (i+j)%6), float64(j)))
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if entry, _, ok := q.Take(); ok {
+					q.Remove(entry.ID)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if q.waiting() > 4 {
+		t.Errorf("%d entries waiting past a capacity of 4", q.waiting())
+	}
+	if len(q.executing) != 0 {
+		t.Errorf("still executing %v after every take was removed", q.executing)
+	}
+}
+
+func TestWaitSettles(t *testing.T) {
+	cases := []struct {
+		name   string
+		settle func(q *Queue, taken Entry)
+	}{
+		{"removed", func(q *Queue, taken Entry) { q.Remove(taken.ID) }},
+		{"kept", func(q *Queue, taken Entry) { q.Keep(taken.ID) }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newQueue(t, 0, queued{"AAA", 1, false}, queued{"BBB", 2, true})
+
+			settled := make(chan struct{})
+			go func() {
+				q.Wait()
+				close(settled)
+			}()
+
+			// The stale entry settles as it is dropped; the live one once taken and settled
+			taken, _, ok := q.Take()
+			if !ok {
+				t.Fatal("nothing taken")
+			}
+			select {
+			case <-settled:
+				t.Fatal("settled while an entry was executing")
+			case <-time.After(20 * time.Millisecond):
+			}
+			tc.settle(q, taken)
+			select {
+			case <-settled:
+			case <-time.After(time.Second):
+				t.Fatal("Wait still blocked")
+			}
+		})
+	}
+
+	t.Run("kept entries stay queued but are not taken again", func(t *testing.T) {
+		q := newQueue(t, 0, queued{"AAA", 1, false})
+		taken, _, _ := q.Take()
+		q.Keep(taken.ID)
+		if _, _, ok := q.Take(); ok {
+			t.Error("a kept entry was taken again")
+		}
+		if !q.Has(taken.ID) {
+			t.Error("kept entry not persisted")
+		}
+	})
+}
+
+func TestReadyWakesOnQueue(t *testing.T) {
+	q := newQueue(t, 0)
+	ready := q.Ready()
+	if _, _, ok := q.Take(); ok {
+		t.Fatal("took from an empty queue")
+	}
+
+	q.Push(opportunity("AAA", 1))
+	select {
+	case <-ready:
+	default:
+		t.Fatal("a push after Ready didn't wake the waiter")
+	}
+
+	// A merge isn't new work
+	ready = q.Ready()
+	q.Push(opportunity("AAA", 2))
+	select {
+	case <-ready:
+		t.Error("a merged push woke the waiter")
+	default:
+	}
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Utilization is how much of the available capital one day's trades put to
+// work, measured between the day's first and last execution
+type Utilization struct {
+	Day             time.Time     `json:"day"`
+	Trades          int           `json:"trades"`
+	AvailableUSDT   float64       `json:"available_usdt"`    // Mean spendable funding across executions
+	AvgDeployedUSDT float64       `json:"avg_deployed_usdt"` // Per trade that tied up capital
+	AvgDeployedPct  float64       `json:"avg_deployed_pct"`  // Of the funding available when it ran
+	MaxPositionPct  float64       `json:"max_position_pct"`  // Average trade against MaxPositionUSDT
+	TimeDeployedPct float64       `json:"time_deployed_pct"` // Capital-time in trades over available capital-time
+	IdlePct         float64       `json:"idle_pct"`          // Share of the period with no trade open
+	LongestIdle     time.Duration `json:"longest_idle"`
+}
+
+// DailyUtilization measures capital utilization per day over executions
+// started within [from, to] (zero bounds are open). Logs written before
+// orders recorded their capital count as trades that deployed nothing.
+func DailyUtilization(results []types.ExecutionResult, from, to time.Time) []Utilization {
+	byDay := make(map[time.Time][]types.ExecutionResult)
+	for _, result := range results {
+		if (!from.IsZero() && result.StartTime.Before(from)) || (!to.IsZero() && result.StartTime.After(to)) {
+			continue
+		}
+		day := time.Date(result.StartTime.Year(), result.StartTime.Month(), result.StartTime.Day(), 0, 0, 0, 0, result.StartTime.Location())
+		byDay[day] = append(byDay[day], result)
+	}
+
+	days := make([]Utilization, 0, len(byDay))
+	for day, results := range byDay {
+		days = append(days, utilization(day, results))
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Day.Before(days[j].Day) })
+	return days
+}
+
+// utilization measures one day's executions
+func utilization(day time.Time, results []types.ExecutionResult) Utilization {
+	sort.Slice(results, func(i, j int) bool { return results[i].StartTime.Before(results[j].StartTime) })
+	u := Utilization{Day: day}
+
+	var start, end, busyUntil time.Time
+	availableSum, deployedSum, deployedPctSum, maxPositionPctSum := 0.0, 0.0, 0.0, 0.0
+	capitalTime := 0.0
+	deployed, priced := 0, 0
+	var idle time.Duration
+
+	for _, result := range results {
+		if start.IsZero() {
+			start, busyUntil = result.StartTime, result.StartTime
+		}
+		end = maxTime(end, result.EndTime)
+		if result.AvailableCapitalUSDT > 0 {
+			availableSum += result.AvailableCapitalUSDT
+			priced++
+		}
+
+		for _, order := range result.Orders {
+			u.Trades++
+			// Gaps between open orders are capital waiting for an opportunity
+			if gap := order.StartTime.Sub(busyUntil); gap > 0 {
+				idle += gap
+				u.LongestIdle = max(u.LongestIdle, gap)
+			}
+			busyUntil = maxTime(busyUntil, order.EndTime)
+
+			if order.CapitalUSDT <= 0 {
+				continue
+			}
+			deployed++
+			deployedSum += order.CapitalUSDT
+			held := order.EndTime.Sub(order.StartTime).Seconds()
+			capitalTime += order.CapitalUSDT * held
+			if result.AvailableCapitalUSDT > 0 {
+				deployedPctSum += order.CapitalUSDT / result.AvailableCapitalUSDT * 100
+			}
+			if result.Config.MaxPositionUSDT > 0 {
+				maxPositionPctSum += order.CapitalUSDT / result.Config.MaxPositionUSDT * 100
+			}
+		}
+	}
+	if gap := end.Sub(busyUntil); gap > 0 {
+		idle += gap
+		u.LongestIdle = max(u.LongestIdle, gap)
+	}
+
+	if priced > 0 {
+		u.AvailableUSDT = availableSum / float64(priced)
+	}
+	if deployed > 0 {
+		u.AvgDeployedUSDT = deployedSum / float64(deployed)
+		u.AvgDeployedPct = deployedPctSum / float64(deployed)
+		u.MaxPositionPct = maxPositionPctSum / float64(deployed)
+	}
+	if period := end.Sub(start); period > 0 {
+		u.IdlePct = idle.Seconds() / period.Seconds() * 100
+		// Capital at the mean available level over the whole period, not just while trading
+		if u.AvailableUSDT > 0 {
+			u.TimeDeployedPct = capitalTime / (u.AvailableUSDT * period.Seconds()) * 100
+		}
+	}
+	return u
+}
+
+// WriteUtilization renders a markdown table of daily capital utilization
+func WriteUtilization(w io.Writer, strategy string, days []Utilization) {
+	fmt.Fprintf(w, "\n## Capital utilization (%s)\n\n", strategy)
+	fmt.Fprintln(w, "| Day | Trades | Available | Avg deployed | Of available | Of max position | Time deployed | Idle | Longest idle |")
+	fmt.Fprintln(w, "|---|---:|---:|---:|---:|---:|---:|---:|---:|")
+	for _, u := range days {
+		fmt.Fprintf(w, "| %s | %d | $%.2f | $%.2f | %.1f%% | %.1f%% | %.3f%% | %.1f%% | %s |\n",
+			u.Day.Format("2006-01-02"), u.Trades, u.AvailableUSDT, u.AvgDeployedUSDT, u.AvgDeployedPct,
+			u.MaxPositionPct, u.TimeDeployedPct, u.IdlePct, u.LongestIdle.Round(time.Second))
+	}
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Summary is one strategy's performance over a period
+type Summary struct {
+	Strategy       string    `json:"strategy"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	Trades         int       `json:"trades"`
+	Winners        int       `json:"winners"`
+	HitRatePct     float64   `json:"hit_rate_pct"`
+	AvgMarginPct   float64   `json:"avg_margin_pct"`   // Over filled trades
+	AvgSlippageINR float64   `json:"avg_slippage_inr"` // Buy plus sell drift per attributed trade; negative costs money
+	PnLINR         float64   `json:"pnl_inr"`
+	MaxDrawdownINR float64   `json:"max_drawdown_inr"` // Largest peak-to-trough fall of cumulative P&L
+	Runs           []string  `json:"runs"`             // Build and config labels of the logs included
+}
+
+// LoadLogs reads every execution log matching the glob patterns
+func LoadLogs(patterns ...string) ([]types.ExecutionResult, error) {
+	results := []types.ExecutionResult{}
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern %s: %v", pattern, err)
+		}
+		for _, file := range files {
+			var result types.ExecutionResult
+			if err := utils.LoadJSON(file, &result); err != nil {
+				return nil, fmt.Errorf("error loading %s: %v", file, err)
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// StrategyLabel names the execution settings a log was produced with, so
+// logs from differently configured runs are compared side by side
+func StrategyLabel(cfg types.ExecutionConfig) string {
+	label := fmt.Sprintf("%s/%s", orDefault(cfg.ExecutionPolicy, "sequential"), orDefault(cfg.FundingCurrency, "USDT"))
+	if cfg.MaxSlices > 1 {
+		label += fmt.Sprintf("/slices=%d", cfg.MaxSlices)
+	}
+	if cfg.ProtectiveStopPct > 0 {
+		label += fmt.Sprintf("/stop=%g%%", cfg.ProtectiveStopPct)
+	}
+	return label
+}
+
+// Span returns the earliest start and latest end of the orders in results
+func Span(results []types.ExecutionResult) (time.Time, time.Time) {
+	var from, to time.Time
+	for _, result := range results {
+		for _, order := range result.Orders {
+			if from.IsZero() || order.StartTime.Before(from) {
+				from = order.StartTime
+			}
+			if order.EndTime.After(to) {
+				to = order.EndTime
+			}
+		}
+	}
+	return from, to
+}
+
+// Summarize computes a strategy's metrics over orders started within
+// [from, to]; a zero bound is open and reported as the orders' own span
+func Summarize(strategy string, results []types.ExecutionResult, from, to time.Time) Summary {
+	orders := []types.ExecutedOrder{}
+	runs := make(map[string]bool)
+	for _, result := range results {
+		for _, order := range result.Orders {
+			if (!from.IsZero() && order.StartTime.Before(from)) || (!to.IsZero() && order.StartTime.After(to)) {
+				continue
+			}
+			orders = append(orders, order)
+			runs[result.Run.Label()] = true
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].EndTime.Before(orders[j].EndTime) })
+
+	summary := Summary{Strategy: strategy, From: from, To: to, Trades: len(orders), Runs: []string{}}
+	for run := range runs {
+		summary.Runs = append(summary.Runs, run)
+	}
+	sort.Strings(summary.Runs)
+	first, last := Span([]types.ExecutionResult{{Orders: orders}})
+	if from.IsZero() {
+		summary.From = first
+	}
+	if to.IsZero() {
+		summary.To = last
+	}
+
+	filled, attributed := 0, 0
+	marginSum, slippageSum := 0.0, 0.0
+	cumulative, peak := 0.0, 0.0
+
+	for _, order := range orders {
+		if order.Success {
+			filled++
+			marginSum += order.ActualMarginPct
+			cumulative += order.ActualProfit
+			if order.ActualProfit > 0 {
+				summary.Winners++
+			}
+		}
+		if a := order.Attribution; a != nil {
+			attributed++
+			slippageSum += a.BuyDrift + a.SellDrift
+		}
+
+		peak = max(peak, cumulative)
+		summary.MaxDrawdownINR = max(summary.MaxDrawdownINR, peak-cumulative)
+	}
+
+	summary.PnLINR = cumulative
+	if summary.Trades > 0 {
+		summary.HitRatePct = float64(summary.Winners) / float64(summary.Trades) * 100
+	}
+	if filled > 0 {
+		summary.AvgMarginPct = marginSum / float64(filled)
+	}
+	if attributed > 0 {
+		summary.AvgSlippageINR = slippageSum / float64(attributed)
+	}
+	return summary
+}
+
+// WriteMarkdown renders summaries as a markdown table, followed by the
+// builds and configs each strategy's logs came from
+func WriteMarkdown(w io.Writer, summaries []Summary) {
+	if len(summaries) > 0 {
+		fmt.Fprintf(w, "## Strategy comparison (%s → %s)\n\n", formatBound(summaries[0].From), formatBound(summaries[0].To))
+	}
+	fmt.Fprintln(w, "| Strategy | Trades | Hit rate | Avg margin | Avg slippage | P&L | Max drawdown |")
+	fmt.Fprintln(w, "|---|---:|---:|---:|---:|---:|---:|")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "| %s | %d | %.1f%% | %.2f%% | ₹%.2f | ₹%.2f | ₹%.2f |\n",
+			s.Strategy, s.Trades, s.HitRatePct, s.AvgMarginPct, s.AvgSlippageINR, s.PnLINR, s.MaxDrawdownINR)
+	}
+
+	fmt.Fprintln(w)
+	for _, s := range summaries {
+		fmt.Fprintf(w, "- %s runs: %s\n", s.Strategy, strings.Join(s.Runs, ", "))
+	}
+	fmt.Fprintf(w, "\n_Generated by %s_\n", version.Current().Label())
+}
+
+// WriteCSV renders summaries as CSV with a header row
+func WriteCSV(w io.Writer, summaries []Summary) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"strategy", "from", "to", "trades", "winners", "hit_rate_pct",
+		"avg_margin_pct", "avg_slippage_inr", "pnl_inr", "max_drawdown_inr", "runs"})
+	for _, s := range summaries {
+		out.Write([]string{s.Strategy, formatBound(s.From), formatBound(s.To),
+			strconv.Itoa(s.Trades), strconv.Itoa(s.Winners), formatFloat(s.HitRatePct),
+			formatFloat(s.AvgMarginPct), formatFloat(s.AvgSlippageINR), formatFloat(s.PnLINR), formatFloat(s.MaxDrawdownINR),
+			strings.Join(s.Runs, ";")})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func formatBound(t time.Time) string {
+	if t.IsZero() {
+		return "open"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
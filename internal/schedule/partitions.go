@@ -0,0 +1,61 @@
+package schedule
+
+import (
+	"sort"
+	"time"
+)
+
+// Partitions schedules scan jobs keyed by partition, such as the quote
+// currency of the pairs scanned, each on its own interval
+type Partitions struct {
+	intervals map[string]time.Duration
+	next      map[string]time.Time
+}
+
+// NewPartitions schedules every key every interval(key); all are due at once
+func NewPartitions(keys []string, interval func(string) time.Duration) *Partitions {
+	p := &Partitions{intervals: make(map[string]time.Duration), next: make(map[string]time.Time)}
+	for _, key := range keys {
+		p.intervals[key] = interval(key)
+		p.next[key] = time.Time{}
+	}
+	return p
+}
+
+// Interval is how often key runs
+func (p *Partitions) Interval(key string) time.Duration {
+	return p.intervals[key]
+}
+
+// Due lists the partitions whose next run is at or before now, sorted
+func (p *Partitions) Due(now time.Time) []string {
+	due := []string{}
+	for key, next := range p.next {
+		if !next.After(now) {
+			due = append(due, key)
+		}
+	}
+	sort.Strings(due)
+	return due
+}
+
+// Ran records that keys started a run at started; each is next due one of
+// its intervals later, so a slow run doesn't add a pause on top
+func (p *Partitions) Ran(keys []string, started time.Time) {
+	for _, key := range keys {
+		if _, ok := p.next[key]; ok {
+			p.next[key] = started.Add(p.intervals[key])
+		}
+	}
+}
+
+// Next is when the earliest partition is due; zero when there are none
+func (p *Partitions) Next() time.Time {
+	var earliest time.Time
+	for _, next := range p.next {
+		if earliest.IsZero() || next.Before(earliest) {
+			earliest = next
+		}
+	}
+	return earliest
+}
@@ -0,0 +1,155 @@
+package toggles
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+)
+
+var logger = logging.For("toggles")
+
+// Toggle is a currency an operator has switched off
+type Toggle struct {
+	Currency   string    `json:"currency"`
+	Reason     string    `json:"reason,omitempty"`
+	By         string    `json:"by,omitempty"`
+	DisabledAt time.Time `json:"disabled_at"`
+}
+
+// Store holds currencies disabled for trading at runtime, persisted to disk
+// on every change. A running engine re-reads the file when it changes, so
+// `toggle disable XYZ` from another shell takes effect on the next validation
+// without a restart (e.g. when a coin's network is halted).
+type Store struct {
+	path     string
+	disabled map[string]Toggle
+	modTime  time.Time
+	mu       sync.Mutex
+}
+
+// NewStore creates a toggle store persisted at path
+func NewStore(path string) *Store {
+	return &Store{
+		path:     path,
+		disabled: make(map[string]Toggle),
+	}
+}
+
+// Load restores persisted toggles. A missing file means everything is enabled.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Disable switches trading of a currency off
+func (s *Store) Disable(currency, reason, by string) (Toggle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return Toggle{}, err
+	}
+
+	toggle := Toggle{
+		Currency:   normalize(currency),
+		Reason:     reason,
+		By:         by,
+		DisabledAt: time.Now(),
+	}
+	s.disabled[toggle.Currency] = toggle
+	return toggle, s.save()
+}
+
+// Enable switches trading of a currency back on; ok is false if it wasn't disabled
+func (s *Store) Enable(currency string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return false, err
+	}
+
+	currency = normalize(currency)
+	if _, exists := s.disabled[currency]; !exists {
+		return false, nil
+	}
+	delete(s.disabled, currency)
+	return true, s.save()
+}
+
+// Disabled reports whether a currency is switched off, picking up changes
+// other processes made to the file. If the file can't be read the last
+// known toggles stay in force.
+func (s *Store) Disabled(currency string) (Toggle, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var modTime time.Time
+	if info, err := os.Stat(s.path); err == nil {
+		modTime = info.ModTime()
+	}
+	if !modTime.Equal(s.modTime) {
+		if err := s.load(); err != nil {
+			logger.Warn("⚠️ Toggles not reloaded", "error", err)
+		}
+	}
+
+	toggle, exists := s.disabled[normalize(currency)]
+	return toggle, exists
+}
+
+// List returns the disabled currencies sorted by name
+func (s *Store) List() []Toggle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Toggle, 0, len(s.disabled))
+	for _, toggle := range s.disabled {
+		list = append(list, toggle)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Currency < list[j].Currency })
+	return list
+}
+
+// load reads the file. A file that fails to parse isn't retried until it
+// changes again. Callers hold s.mu.
+func (s *Store) load() error {
+	disabled := make(map[string]Toggle)
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.disabled, s.modTime = disabled, time.Time{}
+		return nil
+	}
+	if err == nil {
+		s.modTime = info.ModTime()
+	}
+
+	if err := utils.LoadJSON(s.path, &disabled); err != nil {
+		return fmt.Errorf("error loading trading toggles %s: %v", s.path, err)
+	}
+
+	s.disabled = disabled
+	return nil
+}
+
+// save writes the store to disk. Callers hold s.mu.
+func (s *Store) save() error {
+	if err := utils.SaveJSON(s.disabled, s.path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+func normalize(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
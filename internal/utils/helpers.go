@@ -3,8 +3,13 @@ package utils
 import (
 	"encoding/json"
 	"os"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
 )
 
+var logger = logging.For("utils")
+
 // Contains checks if a slice contains a specific string
 func Contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -15,8 +20,15 @@ func Contains(slice []string, item string) bool {
 	return false
 }
 
-// SaveJSON saves any data structure to a JSON file
+// SaveJSON saves any data structure to a JSON file. NaN and infinite floats
+// can't be represented in JSON; they are saved as 0 with a warning.
 func SaveJSON(data interface{}, filename string) error {
+	data, fixed := SanitizeFloats(data)
+	if len(fixed) > 0 {
+		logger.Warn("⚠️ Replaced non-finite values with 0", "file", filename, "count", len(fixed),
+			"fields", strings.Join(fixed, ", "))
+	}
+
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return err
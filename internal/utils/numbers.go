@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDecimal parses a number as the exchange sends it: a decimal string
+// ("0.00012", "1e-5") or a JSON number. Empty strings, malformed values,
+// NaN and infinities are errors, never a silent 0.
+func ParseDecimal(value interface{}) (float64, error) {
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("malformed number %q", v.String())
+		}
+		f = parsed
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, fmt.Errorf("empty number")
+		}
+		parsed, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("malformed number %q", v)
+		}
+		f = parsed
+	case nil:
+		return 0, fmt.Errorf("missing number")
+	default:
+		return 0, fmt.Errorf("unexpected %T where a number was expected", value)
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("non-finite number %v", value)
+	}
+	return f, nil
+}
+
+// ParsePrice parses a price, which must be positive
+func ParsePrice(value interface{}) (float64, error) {
+	price, err := ParseDecimal(value)
+	if err != nil {
+		return 0, fmt.Errorf("bad price: %v", err)
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("bad price: %v is not positive", value)
+	}
+	return price, nil
+}
+
+// ParseQuantity parses a volume or quantity, which may be zero but not negative
+func ParseQuantity(value interface{}) (float64, error) {
+	quantity, err := ParseDecimal(value)
+	if err != nil {
+		return 0, fmt.Errorf("bad quantity: %v", err)
+	}
+	if quantity < 0 {
+		return 0, fmt.Errorf("bad quantity: %v is negative", value)
+	}
+	return quantity, nil
+}
+
+// ParseTimestamp parses an exchange timestamp: Unix seconds or milliseconds
+// (as a number or numeric string) or an RFC 3339 string
+func ParseTimestamp(value interface{}) (time.Time, error) {
+	if s, ok := value.(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(s)); err == nil {
+			return t, nil
+		}
+	}
+
+	n, err := ParseDecimal(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad timestamp: %v", err)
+	}
+	if n <= 0 {
+		return time.Time{}, fmt.Errorf("bad timestamp: %v is not positive", value)
+	}
+
+	// Millisecond timestamps passed 1e12 in 2001; second timestamps won't for millennia
+	if n >= 1e12 {
+		return time.UnixMilli(int64(n)), nil
+	}
+	return time.Unix(int64(n), 0), nil
+}
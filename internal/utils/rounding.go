@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode selects the direction values are rounded in
+type RoundingMode int
+
+const (
+	RoundDown    RoundingMode = iota // Toward negative infinity; use for buy and sell quantities
+	RoundNearest                     // Half away from zero
+	RoundUp                          // Toward positive infinity
+)
+
+// stepTolerance is how close (in steps) a value must be to a multiple of the
+// step to count as exactly on it, absorbing float error such as 0.3/0.1 =
+// 2.9999999999999996
+const stepTolerance = 1e-9
+
+// RoundToStep rounds value to a multiple of step. The result carries no more
+// decimals than step itself, so 0.1+0.2 floored to 0.1 is exactly 0.3. A
+// non-positive step, NaN or infinity returns value unchanged.
+func RoundToStep(value, step float64, mode RoundingMode) float64 {
+	if step <= 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+
+	steps := value / step
+	if nearest := math.Round(steps); math.Abs(steps-nearest) < stepTolerance {
+		steps = nearest
+	} else {
+		switch mode {
+		case RoundDown:
+			steps = math.Floor(steps)
+		case RoundUp:
+			steps = math.Ceil(steps)
+		default:
+			steps = math.Round(steps)
+		}
+	}
+
+	return trimDecimals(steps*step, decimalsOf(step))
+}
+
+// RoundToPrecision rounds value to the given number of decimal places, as
+// used by the exchange's base and target currency precisions
+func RoundToPrecision(value float64, precision int, mode RoundingMode) float64 {
+	if precision < 0 {
+		precision = 0
+	}
+	return RoundToStep(value, math.Pow10(-precision), mode)
+}
+
+// decimalsOf counts the decimal places in the shortest representation of step
+func decimalsOf(step float64) int {
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// trimDecimals removes float noise beyond the given decimal places
+func trimDecimals(value float64, decimals int) float64 {
+	trimmed, err := strconv.ParseFloat(strconv.FormatFloat(value, 'f', decimals, 64), 64)
+	if err != nil {
+		return value
+	}
+	return trimmed
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestRoundToStep(t *testing.T) {
+	cases := []struct {
+		name  string
+		value float64
+		step  float64
+		mode  RoundingMode
+		want  float64
+	}{
+		{"down exact multiple", 1.5, 0.5, RoundDown, 1.5},
+		{"down between steps", 1.74, 0.5, RoundDown, 1.5},
+		{"down float error below multiple", 0.3, 0.1, RoundDown, 0.3},
+		{"down sum with float error", 0.1 + 0.2, 0.1, RoundDown, 0.3},
+		{"down just under a multiple", 0.2999, 0.1, RoundDown, 0.2},
+		{"down integer step", 17.9, 1, RoundDown, 17},
+		{"down coarse step", 1234, 100, RoundDown, 1200},
+		{"down tiny step", 0.123456789, 0.00001, RoundDown, 0.12345},
+		{"down negative", -1.25, 0.5, RoundDown, -1.5},
+		{"down zero", 0, 0.01, RoundDown, 0},
+		{"down below one step", 0.004, 0.01, RoundDown, 0},
+		{"nearest below half", 1.24, 0.1, RoundNearest, 1.2},
+		{"nearest half away from zero", 1.25, 0.5, RoundNearest, 1.5},
+		{"nearest above half", 1.26, 0.1, RoundNearest, 1.3},
+		{"up between steps", 1.21, 0.1, RoundUp, 1.3},
+		{"up float error above multiple", 0.1 * 3, 0.1, RoundUp, 0.3},
+		{"up exact multiple", 2, 0.25, RoundUp, 2},
+		{"up negative", -1.26, 0.5, RoundUp, -1},
+		{"zero step unchanged", 1.23456, 0, RoundDown, 1.23456},
+		{"negative step unchanged", 1.23456, -0.1, RoundDown, 1.23456},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := RoundToStep(tc.value, tc.step, tc.mode); got != tc.want {
+				t.Errorf("RoundToStep(%v, %v) = %v, want %v", tc.value, tc.step, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRoundToStepLeavesNonFiniteValues(t *testing.T) {
+	if got := RoundToStep(math.NaN(), 0.1, RoundDown); !math.IsNaN(got) {
+		t.Errorf("NaN rounded to %v", got)
+	}
+	if got := RoundToStep(math.Inf(1), 0.1, RoundDown); !math.IsInf(got, 1) {
+		t.Errorf("+Inf rounded to %v", got)
+	}
+}
+
+func TestRoundToPrecision(t *testing.T) {
+	cases := []struct {
+		value     float64
+		precision int
+		mode      RoundingMode
+		want      float64
+	}{
+		{1.23456789, 0, RoundDown, 1},
+		{1.23456789, 2, RoundDown, 1.23},
+		{1.23456789, 4, RoundNearest, 1.2346},
+		{1.23456789, 4, RoundUp, 1.2346},
+		{1.005, 2, RoundDown, 1.0},
+		{1.015, 2, RoundUp, 1.02},
+		{0.00012345, 8, RoundDown, 0.00012345},
+		{0.000123456, 8, RoundDown, 0.00012345},
+		{99999.999999, 2, RoundDown, 99999.99},
+		{4.35, 2, RoundDown, 4.35}, // 4.35*100 = 434.99999999999994
+		{1.1, 1, RoundDown, 1.1},   // 1.1*10 = 11.000000000000002
+		{2.675, 3, RoundDown, 2.675},
+		{5.5, -1, RoundDown, 5}, // negative precision treated as 0
+	}
+
+	for _, tc := range cases {
+		if got := RoundToPrecision(tc.value, tc.precision, tc.mode); got != tc.want {
+			t.Errorf("RoundToPrecision(%v, %d, %d) = %v, want %v", tc.value, tc.precision, tc.mode, got, tc.want)
+		}
+	}
+}
+
+// Every representable quantity on a grid must survive a floor unchanged, even
+// when it was built up with float arithmetic
+func TestRoundDownKeepsGridValues(t *testing.T) {
+	for precision := 0; precision <= 8; precision++ {
+		step := math.Pow10(-precision)
+		accumulated := 0.0
+		for i := 0; i <= 2000; i++ {
+			want, _ := strconv.ParseFloat(strconv.FormatFloat(float64(i)*step, 'f', precision, 64), 64)
+			for _, value := range []float64{float64(i) * step, accumulated} {
+				if got := RoundToPrecision(value, precision, RoundDown); got != want {
+					t.Fatalf("precision %d: RoundToPrecision(%v) = %v, want %v", precision, value, got, want)
+				}
+			}
+			accumulated += step
+		}
+	}
+}
+
+// Floored quantities never exceed the input, so buys never overspend
+func TestRoundDownNeverExceedsValue(t *testing.T) {
+	for i := 1; i <= 5000; i++ {
+		value := float64(i) * 0.0013337
+		for precision := 0; precision <= 6; precision++ {
+			got := RoundToPrecision(value, precision, RoundDown)
+			if got > value+math.Pow10(-precision)*stepTolerance {
+				t.Fatalf("RoundToPrecision(%v, %d) = %v exceeds input", value, precision, got)
+			}
+			if value-got >= math.Pow10(-precision) {
+				t.Fatalf("RoundToPrecision(%v, %d) = %v drops a whole step", value, precision, got)
+			}
+			if clean, _ := strconv.ParseFloat(fmt.Sprintf("%.*f", precision, got), 64); clean != got {
+				t.Fatalf("RoundToPrecision(%v, %d) = %v has float noise", value, precision, got)
+			}
+		}
+	}
+}
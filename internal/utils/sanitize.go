@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// SanitizeFloats replaces NaN and infinite floats reachable from v with 0 so
+// the data can be marshalled and later sorted or summed. Pointed-to, sliced
+// and mapped data is fixed in place; a struct passed by value is copied.
+// Returns the (possibly copied) value and the paths of the fields replaced.
+func SanitizeFloats(v interface{}) (interface{}, []string) {
+	if v == nil {
+		return nil, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		copied := reflect.New(rv.Type()).Elem()
+		copied.Set(rv)
+		rv = copied
+	}
+
+	fixed := []string{}
+	sanitizeValue(rv, "$", &fixed)
+	return rv.Interface(), fixed
+}
+
+func sanitizeValue(v reflect.Value, path string, fixed *[]string) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); (math.IsNaN(f) || math.IsInf(f, 0)) && v.CanSet() {
+			v.SetFloat(0)
+			*fixed = append(*fixed, fmt.Sprintf("%s=%v", path, f))
+		}
+
+	case reflect.Ptr:
+		if !v.IsNil() {
+			sanitizeValue(v.Elem(), path, fixed)
+		}
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		// Interface contents aren't addressable; fix a copy and store it back
+		elem := v.Elem()
+		copied := reflect.New(elem.Type()).Elem()
+		copied.Set(elem)
+		before := len(*fixed)
+		sanitizeValue(copied, path, fixed)
+		if len(*fixed) > before && v.CanSet() {
+			v.Set(copied)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() {
+				sanitizeValue(v.Field(i), path+"."+field.Name, fixed)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			sanitizeValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fixed)
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			copied := reflect.New(iter.Value().Type()).Elem()
+			copied.Set(iter.Value())
+			before := len(*fixed)
+			sanitizeValue(copied, fmt.Sprintf("%s[%v]", path, iter.Key()), fixed)
+			if len(*fixed) > before {
+				v.SetMapIndex(iter.Key(), copied)
+			}
+		}
+	}
+}
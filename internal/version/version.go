@@ -0,0 +1,72 @@
+package version
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"runtime/debug"
+	"sync"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Set at build time, e.g.
+// go build -ldflags "-X github.com/b-thark/cdcx-api/internal/version.Version=v1.2.0 -X github.com/b-thark/cdcx-api/internal/version.Commit=$(git rev-parse --short=12 HEAD)"
+var (
+	Version = "dev"
+	Commit  = "" // Falls back to the VCS revision Go embeds when building a package
+)
+
+var (
+	mu         sync.Mutex
+	configHash string
+)
+
+// Stamp records a hash of the configs the process runs with; every
+// artifact saved afterwards carries it. Returns the hash.
+func Stamp(configs ...interface{}) string {
+	sum := sha256.New()
+	for _, config := range configs {
+		data, _ := json.Marshal(config)
+		sum.Write(data)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	configHash = hex.EncodeToString(sum.Sum(nil))[:12]
+	return configHash
+}
+
+// Current describes this build and the stamped config
+func Current() *types.RunInfo {
+	mu.Lock()
+	defer mu.Unlock()
+	return &types.RunInfo{Version: Version, Commit: commit(), ConfigHash: configHash}
+}
+
+func commit() string {
+	if Commit != "" {
+		return Commit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
@@ -0,0 +1,298 @@
+package watch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+var logger = logging.For("watch")
+
+// Sample is one observation of a route (buy on one market, sell on another)
+// of a watched currency. Prices and amounts are in INR.
+type Sample struct {
+	Time       time.Time `json:"time"`
+	Currency   string    `json:"currency"`
+	BuyMarket  string    `json:"buy_market"`
+	SellMarket string    `json:"sell_market"`
+
+	BestAskINR   float64 `json:"best_ask_inr"` // Buy market
+	BestBidINR   float64 `json:"best_bid_inr"` // Sell market
+	SpreadPct    float64 `json:"spread_pct"`   // Sell bid over buy ask, before fees
+	NetMarginPct float64 `json:"net_margin_pct"`
+
+	AskDepthINR float64 `json:"ask_depth_inr"` // Value of the buy market's ask side within DepthBandPct of the best price
+	BidDepthINR float64 `json:"bid_depth_inr"` // Same for the sell market's bids
+
+	// Hypothetical P&L of buying Notional INR of the currency and selling it
+	// straight away, walking both books and paying fees on both legs
+	Notional       float64 `json:"notional_inr"`
+	Quantity       float64 `json:"quantity"`
+	Fillable       bool    `json:"fillable"` // Both books could absorb Quantity
+	HypotheticalPL float64 `json:"hypothetical_pl_inr"`
+	BuyImpactPct   float64 `json:"buy_impact_pct"`
+	SellImpactPct  float64 `json:"sell_impact_pct"`
+}
+
+// DepthBandPct is how far from the best price depth is counted
+const DepthBandPct = 1.0
+
+// BookSource fetches order books
+type BookSource interface {
+	GetOrderBook(pair string) (*market.OrderBook, error)
+}
+
+// RateSource converts quote-currency prices to INR for reporting, where a
+// rate the exchange briefly can't refresh is still good enough
+type RateSource interface {
+	ReportINR(price float64, fromCurrency string) (float64, error)
+}
+
+// Watcher samples every route of a set of currencies without trading
+type Watcher struct {
+	books    BookSource
+	rates    RateSource
+	feeRate  float64
+	notional float64
+}
+
+// NewWatcher samples with the configured fee rate, pricing hypothetical
+// trades of notional INR
+func NewWatcher(books BookSource, rates RateSource, config *types.Config, notional float64) *Watcher {
+	return &Watcher{books: books, rates: rates, feeRate: config.FeeRate, notional: notional}
+}
+
+// side is one market's book converted to INR
+type side struct {
+	pair types.PairInfo
+	asks []types.OrderLevel
+	bids []types.OrderLevel
+}
+
+// Sample fetches each market of the currency once and returns a sample for
+// every buy/sell combination. Markets that fail to load are logged and left
+// out.
+func (w *Watcher) Sample(currency string, pairs []types.PairInfo) []Sample {
+	now := time.Now()
+
+	books := []side{}
+	for _, pair := range pairs {
+		book, err := w.load(pair)
+		if err != nil {
+			logger.Warn("⚠️ Book not sampled", "symbol", pair.Symbol, "error", err)
+			continue
+		}
+		books = append(books, book)
+	}
+
+	samples := []Sample{}
+	for _, buy := range books {
+		for _, sell := range books {
+			if buy.pair.Symbol == sell.pair.Symbol || len(buy.asks) == 0 || len(sell.bids) == 0 {
+				continue
+			}
+			samples = append(samples, w.route(now, currency, buy, sell))
+		}
+	}
+	return samples
+}
+
+func (w *Watcher) load(pair types.PairInfo) (side, error) {
+	orderBook, err := w.books.GetOrderBook(pair.Pair)
+	if err != nil {
+		return side{}, err
+	}
+	// Copies: the prices are rescaled to INR below
+	asks := append([]types.OrderLevel(nil), orderBook.Asks...)
+	bids := append([]types.OrderLevel(nil), orderBook.Bids...)
+
+	rate, err := w.rates.ReportINR(1, pair.BaseCurrency)
+	if err != nil {
+		return side{}, fmt.Errorf("no INR rate for %s: %v", pair.BaseCurrency, err)
+	}
+	for i := range asks {
+		asks[i].Price *= rate
+	}
+	for i := range bids {
+		bids[i].Price *= rate
+	}
+	return side{pair: pair, asks: asks, bids: bids}, nil
+}
+
+func (w *Watcher) route(now time.Time, currency string, buy, sell side) Sample {
+	ask, bid := buy.asks[0].Price, sell.bids[0].Price
+	sample := Sample{
+		Time:        now,
+		Currency:    currency,
+		BuyMarket:   buy.pair.Symbol,
+		SellMarket:  sell.pair.Symbol,
+		BestAskINR:  ask,
+		BestBidINR:  bid,
+		AskDepthINR: depthWithin(buy.asks, ask*(1+DepthBandPct/100), true),
+		BidDepthINR: depthWithin(sell.bids, bid*(1-DepthBandPct/100), false),
+		Notional:    w.notional,
+	}
+	if ask <= 0 {
+		return sample
+	}
+	sample.SpreadPct = (bid - ask) / ask * 100
+	sample.NetMarginPct = (bid*(1-w.feeRate) - ask*(1+w.feeRate)) / ask * 100
+
+	sample.Quantity = w.notional / ask
+	buyImpact := market.EstimateImpact(buy.asks, sample.Quantity)
+	sellImpact := market.EstimateImpact(sell.bids, sample.Quantity)
+	sample.BuyImpactPct, sample.SellImpactPct = buyImpact.ImpactPct, sellImpact.ImpactPct
+	sample.Fillable = buyImpact.Filled >= sample.Quantity && sellImpact.Filled >= sample.Quantity
+
+	// A thin book trades only what both sides can take
+	traded := min(buyImpact.Filled, sellImpact.Filled)
+	cost := traded * buyImpact.EffectivePrice
+	proceeds := traded * sellImpact.EffectivePrice
+	sample.HypotheticalPL = proceeds - cost - (cost+proceeds)*w.feeRate
+	return sample
+}
+
+// depthWithin sums the INR value of levels priced no worse than limit
+func depthWithin(levels []types.OrderLevel, limit float64, asks bool) float64 {
+	total := 0.0
+	for _, level := range levels {
+		if (asks && level.Price > limit) || (!asks && level.Price < limit) {
+			break
+		}
+		total += level.Price * level.Volume
+	}
+	return total
+}
+
+// Recorder appends samples to a JSON-lines dataset
+type Recorder struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewRecorder creates a recorder appending to path
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// Append writes samples, one per line
+func (r *Recorder) Append(samples []Sample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %v", r.path, err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, sample := range samples {
+		sanitized, _ := utils.SanitizeFloats(sample)
+		line, err := json.Marshal(sanitized)
+		if err != nil {
+			return fmt.Errorf("error encoding sample: %v", err)
+		}
+		writer.Write(append(line, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error writing %s: %v", r.path, err)
+	}
+	return nil
+}
+
+// Load reads a dataset. Malformed lines (a crash mid-write) are skipped.
+func Load(path string) ([]Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", path, err)
+	}
+	defer file.Close()
+
+	samples := []Sample{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var sample Sample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			logger.Warn("⚠️ Line skipped", "path", path, "line", line, "error", err)
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return samples, nil
+}
+
+// RouteSummary aggregates the samples of one route
+type RouteSummary struct {
+	Currency        string
+	BuyMarket       string
+	SellMarket      string
+	Samples         int
+	Viable          int // Net margin at or above the threshold it was summarized with
+	Fillable        int
+	AvgNetMarginPct float64
+	MaxNetMarginPct float64
+	AvgDepthINR     float64 // Thinner side of the two books
+	AvgPL           float64
+	ViablePL        float64 // Sum of hypothetical P&L over viable, fillable samples
+	First, Last     time.Time
+}
+
+// Summarize aggregates samples per route, counting those at or above
+// minNetMargin as viable, sorted by ViablePL, best first
+func Summarize(samples []Sample, minNetMargin float64) []RouteSummary {
+	routes := make(map[string]*RouteSummary)
+	for _, sample := range samples {
+		key := sample.Currency + "|" + sample.BuyMarket + "|" + sample.SellMarket
+		summary, ok := routes[key]
+		if !ok {
+			summary = &RouteSummary{Currency: sample.Currency, BuyMarket: sample.BuyMarket, SellMarket: sample.SellMarket,
+				MaxNetMarginPct: sample.NetMarginPct, First: sample.Time}
+			routes[key] = summary
+		}
+
+		summary.Samples++
+		summary.AvgNetMarginPct += sample.NetMarginPct
+		summary.AvgDepthINR += min(sample.AskDepthINR, sample.BidDepthINR)
+		summary.AvgPL += sample.HypotheticalPL
+		summary.MaxNetMarginPct = max(summary.MaxNetMarginPct, sample.NetMarginPct)
+		if sample.Fillable {
+			summary.Fillable++
+		}
+		if sample.NetMarginPct >= minNetMargin {
+			summary.Viable++
+			if sample.Fillable {
+				summary.ViablePL += sample.HypotheticalPL
+			}
+		}
+		if sample.Time.Before(summary.First) {
+			summary.First = sample.Time
+		}
+		if sample.Time.After(summary.Last) {
+			summary.Last = sample.Time
+		}
+	}
+
+	summaries := []RouteSummary{}
+	for _, summary := range routes {
+		n := float64(summary.Samples)
+		summary.AvgNetMarginPct /= n
+		summary.AvgDepthINR /= n
+		summary.AvgPL /= n
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ViablePL > summaries[j].ViablePL })
+	return summaries
+}
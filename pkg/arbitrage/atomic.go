@@ -0,0 +1,133 @@
+package arbitrage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// executeAtomicOrder submits both legs together as IOC/FOK limit orders at
+// the validated prices. If either leg fails the other is cancelled, so the
+// trade is skipped rather than left half-done; whatever a leg filled before
+// that is settled by settleAborted.
+func (e *Engine) executeAtomicOrder(opportunity RealTimeOpportunity, executedOrder types.ExecutedOrder) types.ExecutedOrder {
+	opportunity.log().Info("⚛️ ATOMIC", "currency", opportunity.Currency, "volume", opportunity.Volume,
+		"buy_market", opportunity.BuyMarket, "sell_market", opportunity.SellMarket)
+
+	// Both legs are placed and awaited together, so each leg's timings cover the whole submission
+	submitStart := time.Now()
+	result := executor.SubmitAtomic(e.venueFor(opportunity),
+		coindcx.OrderRequest{
+			Side:          "buy",
+			OrderType:     "limit_order",
+			Market:        opportunity.BuyMarket,
+			TotalQuantity: opportunity.Volume,
+			PricePerUnit:  opportunity.BuyPrice,
+			TimeInForce:   e.config.AtomicTimeInForce,
+		},
+		coindcx.OrderRequest{
+			Side:          "sell",
+			OrderType:     "limit_order",
+			Market:        opportunity.SellMarket,
+			TotalQuantity: opportunity.Volume,
+			PricePerUnit:  opportunity.SellPrice,
+			TimeInForce:   e.config.AtomicTimeInForce,
+		},
+		e.orderTimeout(),
+	)
+	submitMs := time.Since(submitStart).Milliseconds()
+	executedOrder.Latency.BuyFillMs = submitMs
+	executedOrder.Latency.SellFillMs = submitMs
+
+	if result.Buy != nil {
+		executedOrder.BuyOrderID = result.Buy.ID
+		executedOrder.BuyPrice = result.Buy.AvgPrice
+	}
+	if result.Sell != nil {
+		executedOrder.SellOrderID = result.Sell.ID
+		executedOrder.SellPrice = result.Sell.AvgPrice
+	}
+
+	if !result.Filled() {
+		if len(result.Cancelled) > 0 {
+			opportunity.log().Warn("🚫 Cancelled surviving legs", "currency", opportunity.Currency, "legs", len(result.Cancelled))
+		}
+		e.settleAborted(opportunity, &executedOrder, result)
+		executedOrder.EndTime = time.Now()
+		executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+		return executedOrder
+	}
+
+	volume := min(result.Buy.TotalQuantity-result.Buy.RemainingQuantity,
+		result.Sell.TotalQuantity-result.Sell.RemainingQuantity)
+	e.publish(opportunity, events.NewOrderFilled(result.Buy.ID, opportunity.BuyMarket, "buy",
+		volume, result.Buy.AvgPrice, result.Buy.FeeAmount))
+	e.publish(opportunity, events.NewOrderFilled(result.Sell.ID, opportunity.SellMarket, "sell",
+		volume, result.Sell.AvgPrice, result.Sell.FeeAmount))
+
+	executedOrder.VolumeExecuted = volume
+	e.settleProfit(opportunity, &executedOrder, volume, result.Buy.AvgPrice, result.Buy.FeeAmount,
+		soldOn(opportunity, volume, volume*result.Sell.AvgPrice, result.Sell.FeeAmount))
+
+	executedOrder.EndTime = time.Now()
+	executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+	return executedOrder
+}
+
+// settleAborted settles an atomic submission that didn't fill both legs.
+// Coins the buy leg took beyond what the sell leg sold, IOC partials
+// included, go through recovery like any unsold buy. Volume both legs
+// filled is settled as a smaller round trip; a sell that outran its buy
+// spent inventory already held, which is reported rather than undone.
+func (e *Engine) settleAborted(opportunity RealTimeOpportunity, executedOrder *types.ExecutedOrder, result executor.AtomicResult) {
+	reason := fmt.Sprintf("atomic submission aborted: %v", result.Err)
+	bought, sold := filledQuantity(result.Buy), filledQuantity(result.Sell)
+	if bought > 0 {
+		e.publish(opportunity, events.NewOrderFilled(result.Buy.ID, opportunity.BuyMarket, "buy",
+			bought, result.Buy.AvgPrice, result.Buy.FeeAmount))
+	}
+	if sold > 0 {
+		e.publish(opportunity, events.NewOrderFilled(result.Sell.ID, opportunity.SellMarket, "sell",
+			sold, result.Sell.AvgPrice, result.Sell.FeeAmount))
+	}
+
+	if bought > sold {
+		executedOrder.VolumeExecuted = bought
+		e.journalBought(opportunity.journalID, bought, result.Buy.AvgPrice, result.Buy.FeeAmount)
+		soldValue, soldFee := 0.0, 0.0
+		if sold > 0 {
+			soldValue, soldFee = sold*result.Sell.AvgPrice, result.Sell.FeeAmount
+		}
+		e.recoverUnsold(opportunity, executedOrder, bought, result.Buy.AvgPrice, result.Buy.FeeAmount,
+			soldOn(opportunity, sold, soldValue, soldFee), reason)
+		return
+	}
+
+	if bought > 0 {
+		executedOrder.VolumeExecuted = bought
+		e.settleProfit(opportunity, executedOrder, bought, result.Buy.AvgPrice, result.Buy.FeeAmount,
+			soldOn(opportunity, bought, bought*result.Sell.AvgPrice, result.Sell.FeeAmount*bought/sold))
+	} else {
+		executedOrder.ErrorMessage = reason
+	}
+	if sold > bought {
+		opportunity.log().Warn("⚠️ Sold held inventory without its buy", "currency", opportunity.Currency,
+			"sold", sold, "bought", bought)
+		executedOrder.ErrorMessage = fmt.Sprintf("%s; sold %s %s of held inventory, %s bought back",
+			reason, market.FormatQuantity(opportunity.SellMarket, sold), opportunity.Currency,
+			market.FormatQuantity(opportunity.BuyMarket, bought))
+	}
+}
+
+// filledQuantity is how much of order filled; zero for an order never placed
+func filledQuantity(order *coindcx.Order) float64 {
+	if order == nil {
+		return 0
+	}
+	return order.TotalQuantity - order.RemainingQuantity
+}
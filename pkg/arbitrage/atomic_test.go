@@ -0,0 +1,45 @@
+package arbitrage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAtomicPartialBuyIsRecovered(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+	engine.config.ExecutionPolicy = "atomic"
+	engine.config.AtomicTimeInForce = "immediate_or_cancel"
+
+	// Nothing bids 95, so the sell leg sells nothing; the IOC buy takes the
+	// 20000 at 1.00 and cancels the rest
+	order := engine.executeRealTimeOrder(limitOpportunity(25000, 1.00, 95.0))
+	if order.VolumeExecuted != 20000 {
+		t.Fatalf("executed %.2f, want the 20000 the buy filled: %s", order.VolumeExecuted, order.ErrorMessage)
+	}
+	if !order.Success || order.ActualProfit >= 0 {
+		t.Errorf("want the bought coins recovered at a loss, got success=%v profit ₹%.2f %q",
+			order.Success, order.ActualProfit, order.ErrorMessage)
+	}
+
+	balances, err := venue.GetBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, balance := range balances {
+		if balance.Currency == "XYZ" && balance.Balance > 1e-9 {
+			t.Errorf("%g XYZ left after the aborted submission", balance.Balance)
+		}
+	}
+}
+
+func TestAtomicFillOrKillLeavesNothing(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.ExecutionPolicy = "atomic"
+	engine.config.AtomicTimeInForce = "fill_or_kill"
+
+	order := engine.executeRealTimeOrder(limitOpportunity(25000, 1.00, 90.0))
+	if order.Success || order.VolumeExecuted != 0 || !strings.Contains(order.ErrorMessage, "aborted") {
+		t.Errorf("want an aborted submission with nothing traded, got success=%v volume %.2f %q",
+			order.Success, order.VolumeExecuted, order.ErrorMessage)
+	}
+}
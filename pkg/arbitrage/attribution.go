@@ -0,0 +1,84 @@
+package arbitrage
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// sale is what one sell of a round trip took, in its quote currency
+type sale struct {
+	volume, value, fee float64
+	quote              string
+}
+
+// soldOn is the sale of a sell leg's fill on the opportunity's sell market
+func soldOn(opportunity RealTimeOpportunity, volume, value, fee float64) sale {
+	return sale{volume: volume, value: value, fee: fee, quote: opportunity.Opportunity.SellSymbol().Quote}
+}
+
+// settleProfit records executedOrder's realized profit and margin in INR for
+// bought coins sold through sales. Each leg is converted from its own quote
+// currency first, so a USDT buy is never subtracted from an INR sell. A
+// single sale of everything bought is attributed too. The round trip
+// succeeded either way; an unavailable rate only leaves the profit unknown.
+func (e *Engine) settleProfit(opportunity RealTimeOpportunity, executedOrder *types.ExecutedOrder, bought, buyPrice, buyFee float64, sales ...sale) {
+	executedOrder.Success = true
+
+	buyRate, err := e.rateManager.ConvertToINR(1, opportunity.Opportunity.BuySymbol().Quote)
+	if err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("margin unavailable: %v", err)
+		return
+	}
+	buyINR := bought * buyPrice * buyRate
+	profit := -buyINR - buyFee*buyRate
+
+	rates := make([]float64, len(sales))
+	for i, s := range sales {
+		if rates[i], err = e.rateManager.ConvertToINR(1, s.quote); err != nil {
+			executedOrder.ErrorMessage = fmt.Sprintf("margin unavailable: %v", err)
+			return
+		}
+		profit += (s.value - s.fee) * rates[i]
+	}
+
+	executedOrder.ActualProfit = profit
+	if pct, err := types.PercentOf(profit, buyINR, "buy value"); err == nil {
+		executedOrder.ActualMarginPct = pct
+	} else {
+		executedOrder.ErrorMessage = fmt.Sprintf("margin unavailable: %v", err)
+	}
+	if len(sales) == 1 && sales[0].volume == bought {
+		executedOrder.Attribution = attributeProfit(opportunity, bought,
+			buyPrice, buyFee, buyRate, sales[0].value/bought, sales[0].fee, rates[0])
+	}
+}
+
+// attributeProfit decomposes a completed round trip into spread captured at
+// decision time and drift during execution, in INR. buyRate and sellRate
+// convert the legs' quote currencies to INR; the sell leg's differs from the
+// opportunity's sell market when the position was recovered through another
+// route.
+func attributeProfit(opportunity RealTimeOpportunity, volume, buyFill, buyFee, buyRate, sellFill, sellFee, sellRate float64) *types.ProfitAttribution {
+	if opportunity.BuyPrice <= 0 || opportunity.SellPrice <= 0 || volume <= 0 {
+		return nil
+	}
+
+	attribution := &types.ProfitAttribution{
+		SpreadCaptured: (opportunity.SellPriceINR - opportunity.BuyPriceINR) * volume,
+		BuyDrift:       (opportunity.BuyPriceINR - buyFill*buyRate) * volume,
+		SellDrift:      (sellFill*sellRate - opportunity.SellPriceINR) * volume,
+		Fees:           buyFee*buyRate + sellFee*sellRate,
+	}
+	attribution.Realized = attribution.SpreadCaptured + attribution.BuyDrift + attribution.SellDrift - attribution.Fees
+
+	return attribution
+}
+
+// decisionSellRate is the INR rate of the opportunity's sell market quote
+func decisionSellRate(opportunity RealTimeOpportunity) float64 {
+	if opportunity.SellPrice <= 0 {
+		return 0
+	}
+	return opportunity.SellPriceINR / opportunity.SellPrice
+}
@@ -0,0 +1,87 @@
+package arbitrage
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// CheckBalances compares the account's balances with the engine's own fills
+// since the previous check and publishes a BalanceMismatch for every
+// currency that moved otherwise. The first call records the baseline. Call
+// it between executions, when no order is in flight.
+func (e *Engine) CheckBalances() ([]executor.BalanceDrift, error) {
+	drifts, err := e.balances.Check()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, drift := range drifts {
+		e.events.Publish(events.NewBalanceMismatch(drift.Currency, drift.Previous, drift.Expected, drift.Actual))
+	}
+	return drifts, nil
+}
+
+// BalanceView is the account's balances net of the engine's reservations,
+// with its fills since the last balance check, as every availability check
+// sees them
+func (e *Engine) BalanceView() (*executor.BalanceView, error) {
+	balances, err := e.venue.GetBalances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %v", err)
+	}
+	return e.balanceView(balances), nil
+}
+
+func (e *Engine) balanceView(balances []coindcx.Balance) *executor.BalanceView {
+	return executor.NewBalanceView(balances, e.reserved, e.balances.Pending())
+}
+
+// CheckFunding publishes a LowBalance when the funding currency's spendable
+// balance first drops below MinRequiredUSDT, and again only after it has
+// recovered in between. Call it between executions, like CheckBalances.
+func (e *Engine) CheckFunding() error {
+	view, err := e.BalanceView()
+	if err != nil {
+		return err
+	}
+	_, _, err = e.fundingLevel(view)
+	return err
+}
+
+// recordCapital values what an order's buy leg tied up in USDT, for
+// capital utilization reporting
+func (e *Engine) recordCapital(order *types.ExecutedOrder, opportunity RealTimeOpportunity) {
+	if order.VolumeExecuted <= 0 || order.BuyPrice <= 0 {
+		return
+	}
+	quote := opportunity.Opportunity.BuySymbol().Quote
+	capital, err := e.toUSDT(order.VolumeExecuted*order.BuyPrice, quote)
+	if err != nil {
+		logger.Warn("⚠️ Could not value capital deployed", "currency", order.Currency, "error", err)
+		return
+	}
+	order.CapitalUSDT = capital
+}
+
+// fundingLevel values the spendable funding balance in USDT and tracks
+// whether it is below the minimum
+func (e *Engine) fundingLevel(view *executor.BalanceView) (executor.Funds, float64, error) {
+	funding := e.fundingCurrency()
+	funds := view.Get(funding)
+	usdtBalance, err := e.toUSDT(funds.Available, funding)
+	if err != nil {
+		return funds, 0, fmt.Errorf("failed to value %s balance: %v", funding, err)
+	}
+
+	e.fundingUSDT = usdtBalance
+	low := usdtBalance < e.config.MinRequiredUSDT
+	if low && !e.lowFunding {
+		e.events.Publish(events.NewLowBalance(funding, funds.Available, usdtBalance, e.config.MinRequiredUSDT))
+	}
+	e.lowFunding = low
+	return funds, usdtBalance, nil
+}
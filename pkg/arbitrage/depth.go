@@ -0,0 +1,320 @@
+package arbitrage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// depthLegTimeout bounds how long one level's order may take to settle
+const depthLegTimeout = 10 * time.Second
+
+func (e *Engine) LoadAnalyses(filename string) ([]types.ArbitrageDepthAnalysis, error) {
+	var analyses []types.ArbitrageDepthAnalysis
+	err := utils.LoadJSON(filename, &analyses)
+	return analyses, err
+}
+
+// CheckFreshness fails for an analysis whose order books were read longer
+// ago than MaxAnalysisAgeSeconds: its simulated levels no longer describe
+// the books it would trade against
+func (e *Engine) CheckFreshness(analysis types.ArbitrageDepthAnalysis) error {
+	maxAge := time.Duration(e.config.MaxAnalysisAgeSeconds) * time.Second
+	if maxAge <= 0 {
+		return nil
+	}
+	booksAt := analysis.BooksTime()
+	if booksAt.IsZero() {
+		return fmt.Errorf("%s analysis has no order book timestamp", analysis.Currency)
+	}
+	if age := time.Since(booksAt); age > maxAge {
+		return fmt.Errorf("%s analysis is %s old, past the %s limit", analysis.Currency,
+			age.Round(time.Second), maxAge)
+	}
+	return nil
+}
+
+// ExecuteAnalyses executes fresh depth analyses funded in the funding
+// currency in order, until the position limit is reached. With
+// DepthExecution each is traded level by level; otherwise its market pair
+// is validated and executed like any other opportunity.
+func (e *Engine) ExecuteAnalyses(analyses []types.ArbitrageDepthAnalysis) (*types.ExecutionResult, error) {
+	result := e.newResult()
+	for _, analysis := range analyses {
+		if analysis.BuyMarket.BaseCurrency != e.fundingCurrency() {
+			continue
+		}
+		if err := e.CheckFreshness(analysis); err != nil {
+			logger.Info("⏰ Skipping stale analysis", "currency", analysis.Currency, "error", err)
+			continue
+		}
+
+		var room bool
+		if e.config.DepthExecution && len(analysis.OrderSimulations) > 0 {
+			room = e.executeDepthLevels(result, analysis)
+		} else {
+			room = e.executeInto(result, RealTimeOpportunity{Opportunity: analysis.Opportunity()})
+		}
+		if !room {
+			break
+		}
+
+		// Small delay between executions
+		time.Sleep(time.Duration(e.config.DelayBetweenOrders) * time.Millisecond)
+	}
+	e.finishResult(result)
+	return result, nil
+}
+
+// executeDepthLevels trades analysis level by level: each simulated order
+// becomes an immediate-or-cancel buy at its ask level's price and a sell of
+// what filled at its bid level's price, so no slice fills past the level it
+// was sized for. Each level is validated against live books and executed
+// like any other opportunity, funds reserved, confirmed and journaled. It
+// stops when a level no longer validates, after the first slice whose
+// realized net margin falls below MinNetMargin, when a level no longer fills,
+// or when the position limit is reached, which it reports like addOrder.
+// Each slice is one executed order under the analysis's execution ID.
+func (e *Engine) executeDepthLevels(result *types.ExecutionResult, analysis types.ArbitrageDepthAnalysis) bool {
+	executionID := utils.NewUUID()
+	log := logger.With("execution_id", executionID)
+	log.Info("📶 Executing by level", "currency", analysis.Currency, "buy_market", analysis.BuyMarket.Symbol,
+		"sell_market", analysis.SellMarket.Symbol, "levels", len(analysis.OrderSimulations),
+		"estimated_profit_inr", analysis.TotalEstimatedProfit)
+
+	minNetMargin := types.DefaultConfig().MinNetMargin
+	if e.trading != nil {
+		minNetMargin = e.trading.MinNetMargin
+	}
+
+	for _, sim := range analysis.OrderSimulations {
+		if sim.BuyLevelPrice <= 0 || sim.SellLevelPrice <= 0 {
+			log.Warn("⚠️ No level prices; re-run depth analysis to execute by level", "level", sim.OrderNumber)
+			return true
+		}
+
+		budget, err := e.levelBudget(result, analysis.BuyMarket.BaseCurrency)
+		if err != nil {
+			log.Warn("⚠️ Position budget unavailable", "currency", analysis.Currency, "error", err)
+			return true
+		}
+		volume := e.roundQuantity(analysis.BuyMarket.Symbol, min(sim.Volume, budget/sim.BuyLevelPrice))
+		if volume <= 0 {
+			log.Info("💰 Position budget spent", "currency", analysis.Currency, "levels", sim.OrderNumber-1)
+			return false
+		}
+
+		opportunity := e.analyzeAndValidateRealTime(analysis.Opportunity())
+		if !opportunity.Viable {
+			log.Info("❌ Level not viable", "level", sim.OrderNumber, "currency", analysis.Currency, "reason", opportunity.Reason)
+			return true
+		}
+		opportunity.ExecutionID = executionID
+		opportunity.Volume = min(opportunity.Volume, volume)
+		opportunity.level = &sim
+
+		log.Info("📶 LEVEL", "level", sim.OrderNumber, "currency", analysis.Currency, "volume", opportunity.Volume,
+			"buy_price", sim.BuyLevelPrice, "sell_price", sim.SellLevelPrice, "simulated_margin_pct", sim.NetMarginPct)
+
+		order := e.executeOpportunity(opportunity)
+		e.recordCapital(&order, opportunity)
+		if !e.addOrder(result, order) {
+			return false
+		}
+
+		if !order.Success {
+			log.Warn("❌ Level failed", "level", sim.OrderNumber, "reason", order.ErrorMessage)
+			return true
+		}
+		if order.ActualMarginPct < minNetMargin {
+			log.Info("🛑 Realized margin below minimum, stopping", "level", sim.OrderNumber,
+				"margin_pct", order.ActualMarginPct, "min_net_margin", minNetMargin)
+			return true
+		}
+		if order.VolumeExecuted < order.PlannedVolume {
+			log.Info("✂️ Level filled in part; deeper levels have moved", "level", sim.OrderNumber,
+				"filled", order.VolumeExecuted, "volume", order.PlannedVolume)
+			return true
+		}
+	}
+	return true
+}
+
+// levelBudget is what MaxPositionUSDT leaves after result's investment, in quote
+func (e *Engine) levelBudget(result *types.ExecutionResult, quote string) (float64, error) {
+	perUnit, err := e.toUSDT(1, quote)
+	if err != nil {
+		return 0, err
+	}
+	return (e.config.MaxPositionUSDT - result.TotalInvestment) / perUnit, nil
+}
+
+// executeDepthLevel trades the opportunity's depth level and times it
+func (e *Engine) executeDepthLevel(opportunity RealTimeOpportunity, executedOrder types.ExecutedOrder) types.ExecutedOrder {
+	executedOrder = e.tradeLevel(opportunity, executedOrder)
+	executedOrder.EndTime = time.Now()
+	executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+	return executedOrder
+}
+
+// tradeLevel buys the opportunity's volume at its level's ask price and
+// sells what filled at the level's bid price; what the sell leaves goes
+// through recovery
+func (e *Engine) tradeLevel(opportunity RealTimeOpportunity, executedOrder types.ExecutedOrder) types.ExecutedOrder {
+	sim, volume := *opportunity.level, opportunity.Volume
+	executedOrder.OrderNumber = sim.OrderNumber
+	executedOrder.ExpectedProfit = sim.NetMargin * volume / sim.Volume
+
+	buy, err := e.fillLevel(opportunity, "buy", opportunity.BuyMarket, volume, sim.BuyLevelPrice)
+	executedOrder.LimitOrderIDs = append(executedOrder.LimitOrderIDs, buy.orderIDs...)
+	if len(buy.orderIDs) > 0 {
+		executedOrder.BuyOrderID = buy.orderIDs[0]
+	}
+	if buy.volume <= 0 {
+		executedOrder.ErrorMessage = "buy level not filled"
+		if err != nil {
+			executedOrder.ErrorMessage = fmt.Sprintf("buy level not filled: %v", err)
+		}
+		return executedOrder
+	}
+	bought, buyPrice := buy.volume, buy.avgPrice()
+	stopID := e.placeProtectiveStop(opportunity, bought, buyPrice)
+	executedOrder.VolumeExecuted = bought
+	executedOrder.BuyPrice = buyPrice
+	e.journalBought(opportunity.journalID, bought, buyPrice, buy.fee)
+
+	if !e.releaseStop(opportunity, &executedOrder, stopID, bought, buyPrice, buy.fee) {
+		return executedOrder
+	}
+
+	sell, err := e.fillLevel(opportunity, "sell", opportunity.SellMarket, bought, sim.SellLevelPrice)
+	executedOrder.LimitOrderIDs = append(executedOrder.LimitOrderIDs, sell.orderIDs...)
+	if len(sell.orderIDs) > 0 {
+		executedOrder.SellOrderID = sell.orderIDs[0]
+	}
+	if sell.volume > 0 {
+		executedOrder.SellPrice = sell.avgPrice()
+	}
+
+	// Whatever the bid level didn't take is sold like failed-arbitrage inventory
+	recoveredINR, recoveredFeeINR := 0.0, 0.0
+	if unsold := bought - sell.volume; unsold > bought*1e-9 {
+		reason := "sell level not filled"
+		if err != nil {
+			reason = fmt.Sprintf("sell level not filled: %v", err)
+		}
+		opportunity.log().Warn("⚠️ Recovering unsold level", "currency", opportunity.Currency, "volume", unsold, "reason", reason)
+		e.publish(opportunity, events.NewRecoveryTriggered(opportunity.Currency, unsold, reason))
+
+		recovered := e.recoverInventory(e.venueFor(opportunity), opportunity.Currency, unsold)
+		if recovered.Dust {
+			executedOrder.ErrorMessage = recovered.Reason
+			return executedOrder
+		}
+		if !recovered.Success {
+			executedOrder.ErrorMessage = "recovery failed"
+			if recovered.ManualRequired {
+				executedOrder.ErrorMessage = "recovery failed, manual action required: " + recovered.Reason
+			}
+			held := unsold - recovered.Sold
+			e.publish(opportunity, events.NewRecoveryFailed(opportunity.Currency, held, recovered.Reason, recovered.ManualRequired))
+			if held > 0 {
+				e.trackStranded(opportunity, held, buyPrice, buy.fee*held/bought)
+			}
+			return executedOrder
+		}
+		executedOrder.SellOrderID = recovered.OrderID
+		if recoveredINR, recoveredFeeINR, err = e.levelINR(unsold*recovered.SellPrice, recovered.FeeAmount, recovered.Quote); err != nil {
+			executedOrder.ErrorMessage = fmt.Sprintf("recovered, value unavailable: %v", err)
+			return executedOrder
+		}
+	}
+
+	sellINR, sellFeeINR, err := e.levelINR(sell.value, sell.fee, opportunity.Opportunity.SellMarket.BaseCurrency)
+	if err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("margin unavailable: %v", err)
+		return executedOrder
+	}
+	sellINR, sellFeeINR = sellINR+recoveredINR, sellFeeINR+recoveredFeeINR
+
+	return e.settleLevel(executedOrder, opportunity, bought*buyPrice, buy.fee, sellINR, sellFeeINR)
+}
+
+// settleLevel records a slice's realized profit in INR from its buy in the
+// buy market's quote and its proceeds already in INR
+func (e *Engine) settleLevel(executedOrder types.ExecutedOrder, opportunity RealTimeOpportunity, buyValue, buyFee, sellINR, sellFeeINR float64) types.ExecutedOrder {
+	buyINR, buyFeeINR, err := e.levelINR(buyValue, buyFee, opportunity.Opportunity.BuyMarket.BaseCurrency)
+	if err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("margin unavailable: %v", err)
+		return executedOrder
+	}
+
+	executedOrder.ActualProfit = sellINR - buyINR - buyFeeINR - sellFeeINR
+	pct, err := types.PercentOf(executedOrder.ActualProfit, buyINR, "buy value")
+	if err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("margin unavailable: %v", err)
+		return executedOrder
+	}
+	executedOrder.ActualMarginPct = pct
+	executedOrder.Success = true
+	return executedOrder
+}
+
+// fillLevel places an immediate-or-cancel limit order at price and returns
+// what it filled once it reached a final state
+func (e *Engine) fillLevel(opportunity RealTimeOpportunity, side, symbol string, quantity, price float64) (limitFill, error) {
+	venue := e.venueFor(opportunity)
+	fill := limitFill{}
+	order, err := e.placeOrder(opportunity, coindcx.OrderRequest{
+		Side:          side,
+		OrderType:     "limit_order",
+		Market:        symbol,
+		TotalQuantity: quantity,
+		PricePerUnit:  price,
+		TimeInForce:   "immediate_or_cancel",
+	})
+	if err != nil {
+		return fill, fmt.Errorf("%s order failed: %v", side, err)
+	}
+	fill.orderIDs = append(fill.orderIDs, order.ID)
+	e.publish(opportunity, events.NewOrderPlaced(order.ID, symbol, side, quantity))
+
+	final, err := executor.WaitOrCancel(venue, order, depthLegTimeout)
+	if final != nil {
+		fill.add(final)
+		if filled := final.TotalQuantity - final.RemainingQuantity; filled > 0 {
+			e.publish(opportunity, events.NewOrderFilled(order.ID, symbol, side, filled, final.AvgPrice, final.FeeAmount))
+		}
+	}
+	return fill, err
+}
+
+// levelINR converts a value and its fee from quote to INR
+func (e *Engine) levelINR(value, fee float64, quote string) (float64, float64, error) {
+	valueINR, err := e.rateManager.ConvertToINR(value, quote)
+	if err != nil {
+		return 0, 0, err
+	}
+	feeINR, err := e.rateManager.ConvertToINR(fee, quote)
+	if err != nil {
+		return 0, 0, err
+	}
+	return valueINR, feeINR, nil
+}
+
+// DisplayAnalysisPlan prints what ExecuteAnalyses is about to execute
+func (e *Engine) DisplayAnalysisPlan(analyses []types.ArbitrageDepthAnalysis) {
+	fmt.Printf("🎯 Found %d opportunities to validate in real-time\n", len(analyses))
+	fmt.Printf("   💰 Max Position: $%.2f USDT\n", e.config.MaxPositionUSDT)
+	fmt.Printf("   🛑 Stop Loss: %.1f%%\n", e.config.StopLossPct)
+	if e.config.DepthExecution {
+		fmt.Printf("   📶 Mode: Level-by-level depth execution\n")
+	} else {
+		fmt.Printf("   🔍 Mode: Real-time validation + immediate execution\n")
+	}
+}
@@ -0,0 +1,118 @@
+package arbitrage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func depthAnalysis(booksAt time.Time, levels ...types.OrderSimulation) types.ArbitrageDepthAnalysis {
+	opp := fakeOpportunity()
+	analysis := types.ArbitrageDepthAnalysis{Currency: "XYZ", OrderSimulations: levels, Timestamp: booksAt, BooksAt: booksAt}
+	analysis.BuyMarket.Symbol, analysis.BuyMarket.Pair, analysis.BuyMarket.BaseCurrency = opp.BuyMarket.Symbol, opp.BuyMarket.Pair, opp.BuyMarket.BaseCurrency
+	analysis.SellMarket.Symbol, analysis.SellMarket.Pair, analysis.SellMarket.BaseCurrency = opp.SellMarket.Symbol, opp.SellMarket.Pair, opp.SellMarket.BaseCurrency
+	return analysis
+}
+
+func TestExecuteAnalysesByLevelWithinPositionLimit(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.DepthExecution = true
+	engine.config.MaxAnalysisAgeSeconds = 60
+	engine.config.MaxPositionUSDT = 150
+
+	level := func(n int) types.OrderSimulation {
+		return types.OrderSimulation{OrderNumber: n, Volume: 100, BuyLevelPrice: 1.00, SellLevelPrice: 90.0, NetMargin: 1}
+	}
+	stale := depthAnalysis(time.Now().Add(-time.Hour), level(1))
+	fresh := depthAnalysis(time.Now(), level(1), level(2), level(3))
+
+	result, err := engine.ExecuteAnalyses([]types.ArbitrageDepthAnalysis{stale, fresh})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The stale analysis is skipped; the fresh one's second level is cut to what the limit leaves
+	if len(result.Orders) != 2 {
+		t.Fatalf("executed %d levels, want 2: %+v", len(result.Orders), result.Orders)
+	}
+	for i, want := range []float64{100, 50} {
+		order := result.Orders[i]
+		if !order.Success || order.VolumeExecuted != want {
+			t.Errorf("level %d: success %v, volume %g (%s); want %g", i+1, order.Success, order.VolumeExecuted, order.ErrorMessage, want)
+		}
+		if order.ExecutionID == "" || order.ExecutionID != result.Orders[0].ExecutionID {
+			t.Errorf("level %d execution ID %q, want the analysis's", i+1, order.ExecutionID)
+		}
+	}
+	if result.TotalInvestment < 150 {
+		t.Errorf("invested $%.2f, want the $150 limit", result.TotalInvestment)
+	}
+}
+
+func TestDepthLevelsGoThroughConfirmAndJournal(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.DepthExecution = true
+	engine.config.MaxAnalysisAgeSeconds = 60
+
+	previews := 0
+	engine.SetConfirm(func(preview TradePreview) bool {
+		previews++
+		// Each level is journaled only once confirmed
+		if entries := engine.journal.Entries(); len(entries) != 0 {
+			t.Errorf("journal holds %+v before confirmation", entries)
+		}
+		return previews == 1
+	})
+
+	level := func(n int) types.OrderSimulation {
+		return types.OrderSimulation{OrderNumber: n, Volume: 100, BuyLevelPrice: 1.00, SellLevelPrice: 90.0, NetMargin: 1}
+	}
+	result, err := engine.ExecuteAnalyses([]types.ArbitrageDepthAnalysis{depthAnalysis(time.Now(), level(1), level(2))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if previews != 2 || len(result.Orders) != 2 {
+		t.Fatalf("%d previews, %d orders; want both levels previewed", previews, len(result.Orders))
+	}
+	if first := result.Orders[0]; !first.Success || first.VolumeExecuted <= 0 || first.VolumeExecuted != first.PlannedVolume {
+		t.Errorf("confirmed level: %+v", result.Orders[0])
+	}
+	if result.Orders[1].Success || result.Orders[1].ErrorMessage != "declined at preview" {
+		t.Errorf("declined level: %+v", result.Orders[1])
+	}
+	if result.Orders[1].BuyOrderID != "" {
+		t.Errorf("declined level placed buy %s", result.Orders[1].BuyOrderID)
+	}
+	if left := engine.journal.Entries(); len(left) != 0 {
+		t.Errorf("journal still holds %+v", left)
+	}
+}
+
+func TestDepthLevelSkippedForDisabledCurrency(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+	engine.config.DepthExecution = true
+	engine.config.MaxAnalysisAgeSeconds = 60
+	if _, err := engine.toggles.Disable("XYZ", "delisting", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	level := types.OrderSimulation{OrderNumber: 1, Volume: 100, BuyLevelPrice: 1.00, SellLevelPrice: 90.0, NetMargin: 1}
+	result, err := engine.ExecuteAnalyses([]types.ArbitrageDepthAnalysis{depthAnalysis(time.Now(), level)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Orders) != 0 {
+		t.Errorf("traded a disabled currency: %+v", result.Orders)
+	}
+	balances, err := venue.GetBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, balance := range balances {
+		if balance.Currency == "XYZ" && balance.Balance > 0 {
+			t.Errorf("bought %g XYZ while it was disabled", balance.Balance)
+		}
+	}
+}
@@ -2,41 +2,259 @@ package arbitrage
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
 	"sort"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 
+	"github.com/b-thark/cdcx-api/internal/audit"
 	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/internal/dust"
+	"github.com/b-thark/cdcx-api/internal/inventory"
+	"github.com/b-thark/cdcx-api/internal/journal"
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/toggles"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/internal/version"
 	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/events"
 	"github.com/b-thark/cdcx-api/pkg/exchange"
+	"github.com/b-thark/cdcx-api/pkg/executor"
 	"github.com/b-thark/cdcx-api/pkg/market"
 	"github.com/b-thark/cdcx-api/pkg/types"
-	"github.com/b-thark/cdcx-api/pkg/utils"
 )
 
+// logger carries the engine's execution records: every value is an
+// attribute, so LOG_FORMAT=json output can be parsed as-is
+var logger = logging.For("arbitrage")
+
 type Engine struct {
-	client      *coindcx.Client
+	venue       executor.Executor
+	scopedVenue func(executionID string) executor.Executor // The venue auditing its calls under an execution
 	config      *types.ExecutionConfig
 	apiConfig   *config.Config
 	fetcher     *market.Fetcher
 	rateManager *exchange.RateManager
+	status      *exchange.StatusMonitor // Optional; blocks execution on suspended markets
+	health      *exchange.HostHealth    // Optional; pauses execution while a host it needs is down
+	planner     *executor.RoutePlanner  // Built on first use from market details
+	dust        *dust.Ledger
+	reserved    *executor.Reservations
+	inventory   *inventory.Book
+	journal     *journal.Journal // Legs of the arbitrages in flight, for resuming after a crash
+	toggles     *toggles.Store   // Currencies switched off at runtime
+	balances    *executor.BalanceWatcher
+	events      *events.Bus
+	audit       *audit.Trail
+	snapshotDir string           // Where failure snapshots are saved
+	confirm     Confirm          // Optional; asked before placing each trade
+	fillModel   market.FillModel // Estimates whether sell legs fill in time
+	trading     *types.Config    // Optional; the account's fee rates for margin math
+	lowFunding  bool             // Funding was below MinRequiredUSDT at the last check
+	fundingUSDT float64          // Spendable funding in USDT at the last check
+	plannerMu   sync.Mutex
+	fundsMu     sync.Mutex // Serializes sizing against the balance and reservations
+	executing   sync.Mutex // Held by the one queued execution trading at a time
 	startTime   time.Time
 }
 
-func NewEngine(apiConfig *config.Config, execConfig *types.ExecutionConfig) *Engine {
+// dryRunStateDir keeps a dry run's inventory, ledgers and audit trail apart
+// from the account's real ones
+const dryRunStateDir = "paper"
+
+// NewEngine creates an engine trading on CoinDCX, or simulating every order
+// against live books when execConfig.DryRun is set. Every request goes
+// through transport (see exchange.NewTransport); nil sends them directly.
+func NewEngine(apiConfig *config.Config, execConfig *types.ExecutionConfig, transport http.RoundTripper) *Engine {
+	if execConfig.DryRun {
+		engine := NewEngineWithVenue(apiConfig, execConfig, executor.NewDryRunVenue(apiConfig, transport))
+		engine.SetTransport(transport)
+		if err := engine.SetStateDir(apiConfig.StateDir()); err != nil {
+			logger.Warn("⚠️ State directory not set", "error", err)
+		}
+		return engine
+	}
+	client := coindcx.NewClient(apiConfig.APIKey, apiConfig.APISecret)
+	client.SetTransport(transport)
+	engine := NewEngineWithVenue(apiConfig, execConfig, executor.NewCoinDCXExecutor(client))
+	engine.SetTransport(transport)
+	return engine
+}
+
+// NewEngineWithVenue creates an engine that routes orders to the given venue
+func NewEngineWithVenue(apiConfig *config.Config, execConfig *types.ExecutionConfig, venue executor.Executor) *Engine {
 	tradingConfig := types.DefaultConfig()
+
+	dustLedger := dust.NewLedger("dust_ledger.json")
+	if err := dustLedger.Load(); err != nil {
+		logger.Warn("⚠️ Dust ledger not loaded", "error", err)
+	}
+
+	inventoryBook := inventory.NewBook("inventory.json")
+	if err := inventoryBook.Load(); err != nil {
+		logger.Warn("⚠️ Inventory not loaded", "error", err)
+	}
+
+	executionJournal := journal.NewJournal("execution_journal.json")
+	if _, err := executionJournal.Load(); err != nil {
+		logger.Warn("⚠️ Execution journal not loaded", "error", err)
+	}
+
+	// Shared by every engine in the working directory: a halted coin is halted for paper too
+	toggleStore := toggles.NewStore("trading_toggles.json")
+	if err := toggleStore.Load(); err != nil {
+		logger.Warn("⚠️ Trading toggles not loaded", "error", err)
+	}
+
+	trail := audit.NewTrail("audit_trail.jsonl")
+
+	bus := events.NewBus()
+	bus.Subscribe(events.LogSink)
+	bus.Subscribe(trail.Handle)
+
+	// Unsupported order types fail fast with a clear error instead of an exchange rejection;
+	// fill polling for concurrent legs shares cached, batched status requests. Every
+	// call that gets past the guard goes into the audit trail, and every fill seen is
+	// tallied against the account's balances.
+	fetcher := market.NewFetcher()
+	balanceWatcher := executor.NewBalanceWatcher(executor.NewStatusCache(venue), fetcher)
+	recorder := executor.NewRecorder(balanceWatcher, trail.RecordCall)
+	guard := executor.NewCapabilityGuard(recorder, fetcher)
+	scopedVenue := func(executionID string) executor.Executor {
+		return guard.Over(recorder.For(executionID))
+	}
+
 	return &Engine{
-		client:      coindcx.NewClient(apiConfig.APIKey, apiConfig.APISecret),
+		venue:       guard,
+		scopedVenue: scopedVenue,
 		config:      execConfig,
 		apiConfig:   apiConfig,
-		fetcher:     market.NewFetcher(),
+		fetcher:     fetcher,
 		rateManager: exchange.NewRateManager(tradingConfig),
+		dust:        dustLedger,
+		reserved:    executor.NewReservations(),
+		inventory:   inventoryBook,
+		journal:     executionJournal,
+		toggles:     toggleStore,
+		balances:    balanceWatcher,
+		events:      bus,
+		audit:       trail,
+		snapshotDir: "snapshots",
+		fillModel:   loadFillModel(execConfig),
 		startTime:   time.Now(),
 	}
 }
 
+// SetTransport sends the engine's market data and rate requests through
+// transport; orders go through the venue, which has its own
+func (e *Engine) SetTransport(transport http.RoundTripper) {
+	e.fetcher.SetTransport(transport)
+	e.rateManager.SetTransport(transport)
+}
+
+// SetTradingConfig has validation, sizing and settlement charge config's fee
+// rates (FEE_OVERRIDES, FEE_SCHEDULE, detected fees, FEE_RATE) instead of
+// the default fee buffer
+func (e *Engine) SetTradingConfig(config *types.Config) {
+	e.trading = config
+}
+
+// feeRate is the rate charged on symbol: the trading config's (see
+// Config.FeeRateFor), or the default fee buffer without one
+func (e *Engine) feeRate(symbol string) float64 {
+	if e.trading == nil {
+		return types.DefaultConfig().FeeRate
+	}
+	return e.trading.FeeRateFor(symbol)
+}
+
+// fundingCurrency is the currency buys are paid from, USDT unless configured
+func (e *Engine) fundingCurrency() string {
+	if e.config.FundingCurrency == "" {
+		return "USDT"
+	}
+	return e.config.FundingCurrency
+}
+
+// toUSDT values an amount of currency in USDT via INR rates
+func (e *Engine) toUSDT(amount float64, currency string) (float64, error) {
+	if currency == "USDT" {
+		return amount, nil
+	}
+	inr, err := e.rateManager.ConvertToINR(amount, currency)
+	if err != nil {
+		return 0, err
+	}
+	usdtRate, err := e.rateManager.ConvertToINR(1, "USDT")
+	if err != nil {
+		return 0, err
+	}
+	return inr / usdtRate, nil
+}
+
+// SetStateDir keeps the engine's dust ledger, inventory, execution journal, audit trail and
+// failure snapshots in dir instead of the working directory, so a second
+// engine (paper alongside live) doesn't share them. A dry run uses dir/paper.
+func (e *Engine) SetStateDir(dir string) error {
+	if e.config.DryRun {
+		dir = filepath.Join(dir, dryRunStateDir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating state directory %s: %v", dir, err)
+	}
+
+	dustLedger := dust.NewLedger(filepath.Join(dir, "dust_ledger.json"))
+	if err := dustLedger.Load(); err != nil {
+		return err
+	}
+	inventoryBook := inventory.NewBook(filepath.Join(dir, "inventory.json"))
+	if err := inventoryBook.Load(); err != nil {
+		return err
+	}
+	executionJournal := journal.NewJournal(filepath.Join(dir, "execution_journal.json"))
+	if _, err := executionJournal.Load(); err != nil {
+		return err
+	}
+
+	e.dust = dustLedger
+	e.inventory = inventoryBook
+	e.journal = executionJournal
+	e.audit.SetPath(filepath.Join(dir, "audit_trail.jsonl"))
+	e.snapshotDir = filepath.Join(dir, "snapshots")
+	return nil
+}
+
+// Events returns the bus execution events are published on
+func (e *Engine) Events() *events.Bus {
+	return e.events
+}
+
+// SetStatusMonitor enables execution guards for suspended or maintenance markets
+func (e *Engine) SetStatusMonitor(monitor *exchange.StatusMonitor) {
+	e.status = monitor
+}
+
+// SetHostHealth pauses execution while an exchange host it depends on is down
+func (e *Engine) SetHostHealth(health *exchange.HostHealth) {
+	e.health = health
+}
+
+// HostGuard errors while execution is paused for a down host: the public
+// host serves the books trades are validated against and, unless orders
+// are simulated, the API host takes them
+func (e *Engine) HostGuard() error {
+	if e.health == nil {
+		return nil
+	}
+	if e.config.DryRun {
+		return e.health.Guard(exchange.PublicHost)
+	}
+	return e.health.Guard(exchange.APIHost, exchange.PublicHost)
+}
+
 func (e *Engine) LoadOpportunities(filename string) ([]types.ArbitrageOpportunity, error) {
 	var opportunities []types.ArbitrageOpportunity
 	err := utils.LoadJSON(filename, &opportunities)
@@ -44,28 +262,39 @@ func (e *Engine) LoadOpportunities(filename string) ([]types.ArbitrageOpportunit
 }
 
 func (e *Engine) CheckAccountReadiness() (bool, error) {
-	log.Println("🔍 Checking account balances...")
+	logger.Info("🔍 Checking account balances...")
 
-	balances, err := e.client.GetBalances()
+	balances, err := e.venue.GetBalances()
 	if err != nil {
 		return false, fmt.Errorf("failed to get balances: %v", err)
 	}
 
-	usdtBalance := 0.0
-	for _, balance := range balances {
-		if balance.Currency == "USDT" {
-			usdtBalance = balance.Balance
-			break
-		}
+	// Funds locked in open orders or reserved for in-flight ones can't back a new trade
+	funding := e.fundingCurrency()
+	funds, usdtBalance, err := e.fundingLevel(e.balanceView(balances))
+	if err != nil {
+		return false, err
 	}
 
-	fmt.Printf("💰 Available USDT: %.6f\n", usdtBalance)
+	fmt.Printf("💰 Available %s: %.6f\n", funding, funds.Available)
+	if funding != "USDT" {
+		fmt.Printf("💱 Equivalent USDT: %.6f\n", usdtBalance)
+	}
+	if funds.Locked > 0 || funds.Reserved > 0 {
+		fmt.Printf("🔒 Locked in orders: %.6f, reserved: %.6f\n", funds.Locked, funds.Reserved)
+	}
 
 	if usdtBalance < e.config.MinRequiredUSDT {
-		return false, fmt.Errorf("insufficient USDT balance: %.6f < %.6f required",
-			usdtBalance, e.config.MinRequiredUSDT)
+		return false, fmt.Errorf("insufficient %s balance: %.6f USDT equivalent < %.6f required",
+			funding, usdtBalance, e.config.MinRequiredUSDT)
 	}
 
+	// Drop inventory that was sold elsewhere, then show what is still held
+	if err := e.inventory.Reconcile(balances); err != nil {
+		logger.Warn("⚠️ Could not reconcile inventory", "error", err)
+	}
+	displayInventory(e.ValueInventory())
+
 	// Check if max position is within available balance
 	if e.config.MaxPositionUSDT > usdtBalance*0.9 { // 90% of balance max
 		e.config.MaxPositionUSDT = usdtBalance * 0.8 // Use 80% of balance
@@ -95,6 +324,8 @@ type RealTimeOpportunity struct {
 	SellMarket           string
 	BuyPrice             float64
 	SellPrice            float64
+	BuyPriceINR          float64
+	SellPriceINR         float64
 	Volume               float64
 	ExpectedMargin       float64
 	MarginPct            float64
@@ -103,25 +334,36 @@ type RealTimeOpportunity struct {
 	DepthAnalysis        types.QuickDepthResult
 	MaxProfitableOrders  int
 	TotalEstimatedProfit float64
+	TopOfBookVolume      float64
+	Opportunity          types.ArbitrageOpportunity
+	ValidationMs         int64
+	ValidatedAt          time.Time
+	Books                *types.BookSnapshot // Top of both books at validation
+	BuyImpact            market.Impact       // Walking the books with Volume
+	SellImpact           market.Impact
+	Imbalance            market.Imbalance       // Sell market bids against buy market asks near the top
+	WorstCaseLossINR     float64                // Buy slipped, sell failed, recovered at the buy market's bids
+	FillProbability      float64                // Of the sell leg filling Volume within its timeout
+	ExecutionID          string                 // Tags the attempt's log lines, events, API calls and records; set at execution unless chosen by the caller
+	journalID            string                 // Execution journal entry while executing
+	level                *types.OrderSimulation // Depth level to trade at its own prices instead of the live ones
 }
 
-func (e *Engine) Execute(opportunities []types.ArbitrageOpportunity) (*types.ExecutionResult, error) {
-	result := &types.ExecutionResult{
-		StartTime:  time.Now(),
-		Timestamp:  time.Now(),
-		Successful: false,
-		Orders:     []types.ExecutedOrder{},
-		Config:     *e.config,
+// log is the engine logger with the execution attempt attached
+func (o RealTimeOpportunity) log() *slog.Logger {
+	if o.ExecutionID == "" {
+		return logger
 	}
+	return logger.With("execution_id", o.ExecutionID)
+}
 
-	totalProfit := 0.0
-	totalInvestment := 0.0
-	processedCount := 0
-
+// Execute validates each viable opportunity funded in the funding currency
+// just before executing it, best expected margin first
+func (e *Engine) Execute(opportunities []types.ArbitrageOpportunity) (*types.ExecutionResult, error) {
 	// Filter and sort viable opportunities
 	viableOpps := []types.ArbitrageOpportunity{}
 	for _, opp := range opportunities {
-		if opp.Viable && strings.Contains(opp.BuyMarket.Symbol, "USDT") {
+		if opp.Viable && opp.BuySymbol().Quote == e.fundingCurrency() {
 			viableOpps = append(viableOpps, opp)
 		}
 	}
@@ -131,60 +373,120 @@ func (e *Engine) Execute(opportunities []types.ArbitrageOpportunity) (*types.Exe
 		return viableOpps[i].NetMarginPct > viableOpps[j].NetMarginPct
 	})
 
-	// fmt.Println("\n🔄 LIVE ARBITRAGE EXECUTION:")
-	// fmt.Println("============================")
-
-	for _, opp := range viableOpps {
-		processedCount++
-		// log.Printf("\n📊 [%d/%d] Processing %s (%s → %s)",
-		// 	processedCount, len(viableOpps), opp.TargetCurrency,
-		// 	opp.BuyMarket.Symbol, opp.SellMarket.Symbol)
-
-		// Real-time depth analysis + validation
-		liveOpp := e.analyzeAndValidateRealTime(opp)
+	// Never validated, so each is validated when its turn comes
+	pending := make([]RealTimeOpportunity, len(viableOpps))
+	for i, opp := range viableOpps {
+		pending[i] = RealTimeOpportunity{Opportunity: opp}
+	}
+	return e.ExecutePrevalidated(pending...), nil
+}
 
-		if !liveOpp.Viable {
-			log.Printf("❌ %s: %s", opp.TargetCurrency, liveOpp.Reason)
-			continue
+// ExecutePrevalidated executes opportunities validated ahead of time by
+// PrevalidateUntilLocked in order, re-checking each first if its validation
+// went stale, until the position limit is reached
+func (e *Engine) ExecutePrevalidated(liveOpps ...RealTimeOpportunity) *types.ExecutionResult {
+	result := e.newResult()
+	for i, liveOpp := range liveOpps {
+		if i > 0 {
+			// Small delay between executions
+			time.Sleep(time.Duration(e.config.DelayBetweenOrders) * time.Millisecond)
 		}
+		if !e.executeInto(result, liveOpp) {
+			break
+		}
+	}
+	e.finishResult(result)
+	return result
+}
 
-		// log.Printf("✅ %s: %.2f%% margin, %d profitable orders - EXECUTING",
-		// 	opp.TargetCurrency, liveOpp.MarginPct, liveOpp.MaxProfitableOrders)
+// newResult starts the result every execution entry point fills in
+func (e *Engine) newResult() *types.ExecutionResult {
+	return &types.ExecutionResult{
+		StartTime:            time.Now(),
+		Timestamp:            time.Now(),
+		Successful:           false,
+		Orders:               []types.ExecutedOrder{},
+		Config:               *e.config,
+		AvailableCapitalUSDT: e.fundingUSDT,
+	}
+}
 
-		// Execute immediately while conditions are good
-		executedOrder := e.executeRealTimeOrder(liveOpp)
-		result.Orders = append(result.Orders, executedOrder)
+// executeInto executes one opportunity, refreshed first if its validation
+// went stale, and adds its order to result. It reports whether the position
+// limit leaves room for another.
+func (e *Engine) executeInto(result *types.ExecutionResult, liveOpp RealTimeOpportunity) bool {
+	liveOpp = e.RefreshIfStale(liveOpp)
+	if !liveOpp.Viable {
+		logger.Info("❌ Not viable", "currency", liveOpp.Currency, "reason", liveOpp.Reason)
+		return true
+	}
 
-		if executedOrder.Success {
-			totalProfit += executedOrder.ActualProfit
-			totalInvestment += (executedOrder.VolumeExecuted * executedOrder.BuyPrice) / 83.0
-			log.Printf("💰 %s SUCCESS: ₹%.2f profit", opp.TargetCurrency, executedOrder.ActualProfit)
-		}
+	executedOrder := e.executeOpportunity(liveOpp)
+	e.recordCapital(&executedOrder, liveOpp)
+	return e.addOrder(result, executedOrder)
+}
 
-		// Check limits
-		if totalInvestment >= e.config.MaxPositionUSDT {
-			log.Printf("💰 Position limit reached: $%.2f", e.config.MaxPositionUSDT)
-			break
-		}
+// addOrder adds an executed order to result, counting the capital its buy
+// tied up against MaxPositionUSDT whether or not it succeeded. It reports
+// whether the position limit leaves room for another.
+func (e *Engine) addOrder(result *types.ExecutionResult, executedOrder types.ExecutedOrder) bool {
+	result.Orders = append(result.Orders, executedOrder)
+	result.TotalInvestment += executedOrder.CapitalUSDT
+	if executedOrder.Success {
+		result.TotalProfit += executedOrder.ActualProfit
+		result.TotalVolume += executedOrder.VolumeExecuted
+		logger.Info("💰 SUCCESS", "execution_id", executedOrder.ExecutionID, "currency", executedOrder.Currency,
+			"profit_inr", executedOrder.ActualProfit)
+	}
 
-		// Small delay between executions
-		time.Sleep(time.Duration(e.config.DelayBetweenOrders) * time.Millisecond)
+	if result.TotalInvestment >= e.config.MaxPositionUSDT {
+		logger.Info("💰 Position limit reached", "max_position_usdt", e.config.MaxPositionUSDT)
+		return false
 	}
+	return true
+}
 
+// finishResult totals result once its entry point stops executing
+func (e *Engine) finishResult(result *types.ExecutionResult) {
 	result.EndTime = time.Now()
-	result.TotalProfit = totalProfit
-	result.TotalInvestment = totalInvestment
-	result.Successful = totalProfit > 0
-
-	return result, nil
+	result.Successful = result.TotalProfit > 0
+	result.LatencySummary = SummarizeLatency(result.Orders)
+	e.attachInventory(result)
 }
 
 func (e *Engine) analyzeAndValidateRealTime(opp types.ArbitrageOpportunity) RealTimeOpportunity {
 	liveOpp := RealTimeOpportunity{
-		Currency:   opp.TargetCurrency,
-		BuyMarket:  opp.BuyMarket.Symbol,
-		SellMarket: opp.SellMarket.Symbol,
-		Viable:     false,
+		Currency:    opp.TargetCurrency,
+		BuyMarket:   opp.BuyMarket.Symbol,
+		SellMarket:  opp.SellMarket.Symbol,
+		Viable:      false,
+		Opportunity: opp,
+	}
+
+	// Skip currencies an operator switched off (cmd/toggle) until they are re-enabled
+	if toggle, disabled := e.toggles.Disabled(opp.TargetCurrency); disabled {
+		liveOpp.Reason = fmt.Sprintf("trading disabled for %s", toggle.Currency)
+		if toggle.Reason != "" {
+			liveOpp.Reason += ": " + toggle.Reason
+		}
+		return liveOpp
+	}
+
+	// Skip markets the exchange has suspended or put under maintenance
+	if e.status != nil {
+		for _, symbol := range []string{opp.BuyMarket.Symbol, opp.SellMarket.Symbol} {
+			if err := e.status.Guard(symbol); err != nil {
+				liveOpp.Reason = fmt.Sprintf("execution guard: %v", err)
+				e.events.Publish(events.NewRiskTripped("market_status", symbol, err.Error()))
+				return liveOpp
+			}
+		}
+	}
+
+	if err := e.HostGuard(); err != nil {
+		liveOpp.Reason = fmt.Sprintf("execution paused: %v", err)
+		e.events.Publish(events.NewRiskTripped("host_health", opp.TargetCurrency, err.Error()))
+		return liveOpp
 	}
 
 	// Step 1: Get fresh order book data
@@ -200,8 +502,19 @@ func (e *Engine) analyzeAndValidateRealTime(opp types.ArbitrageOpportunity) Real
 		return liveOpp
 	}
 
+	buyLevels, sellLevels := buyOrderBook.Asks, sellOrderBook.Bids
+	liveOpp.Books = types.NewBookSnapshot(opp.BuyMarket.Symbol, opp.SellMarket.Symbol, buyLevels, sellLevels, types.BookSnapshotDepth)
+
+	// Both legs may be quoted in different currencies; everything below is compared in INR
+	buyRate, sellRate, err := e.rateManager.NormalizePrices(1, opp.BuyMarket.BaseCurrency, 1, opp.SellMarket.BaseCurrency)
+	if err != nil {
+		liveOpp.Reason = fmt.Sprintf("cross-base pricing unavailable: %v", err)
+		return liveOpp
+	}
+
 	// Step 2: Perform real-time depth analysis
-	depthResult := e.performQuickDepthAnalysis(opp.TargetCurrency, buyOrderBook, sellOrderBook)
+	buyFeeRate, sellFeeRate := e.feeRate(opp.BuyMarket.Symbol), e.feeRate(opp.SellMarket.Symbol)
+	depthResult := e.performQuickDepthAnalysis(opp.TargetCurrency, buyLevels, sellLevels, buyRate, sellRate, buyFeeRate+sellFeeRate)
 	liveOpp.DepthAnalysis = depthResult
 
 	if depthResult.MaxProfitableOrders == 0 {
@@ -210,58 +523,87 @@ func (e *Engine) analyzeAndValidateRealTime(opp types.ArbitrageOpportunity) Real
 	}
 
 	// Step 3: Validate current best prices
-	buyPrice, buyVolume := e.getBestAsk(buyOrderBook)
-	sellPrice, sellVolume := e.getBestBid(sellOrderBook)
-
-	if buyPrice == 0 || sellPrice == 0 {
+	if len(buyLevels) == 0 || len(sellLevels) == 0 {
 		liveOpp.Reason = "no valid prices available"
 		return liveOpp
 	}
+	buyPrice, buyVolume := buyLevels[0].Price, buyLevels[0].Volume
+	sellPrice, sellVolume := sellLevels[0].Price, sellLevels[0].Volume
+
+	buyPriceINR := buyPrice * buyRate
+	sellPriceINR := sellPrice * sellRate
+	if err := types.RequirePositive("buy price INR", buyPriceINR); err != nil {
+		liveOpp.Reason = err.Error()
+		return liveOpp
+	}
 
-	if sellPrice <= buyPrice {
-		liveOpp.Reason = fmt.Sprintf("no arbitrage: sell ₹%.6f <= buy ₹%.6f", sellPrice, buyPrice)
+	if sellPriceINR <= buyPriceINR {
+		liveOpp.Reason = fmt.Sprintf("no arbitrage: sell ₹%.6f <= buy ₹%.6f", sellPriceINR, buyPriceINR)
 		return liveOpp
 	}
 
 	// Step 4: Calculate current margins
-	grossMargin := sellPrice - buyPrice
-	estimatedFees := (buyPrice + sellPrice) * 0.01 // 1% each side
+	grossMargin := sellPriceINR - buyPriceINR
+	estimatedFees := buyPriceINR*buyFeeRate + sellPriceINR*sellFeeRate
 	netMargin := grossMargin - estimatedFees
-	netMarginPct := (netMargin / buyPrice) * 100
+	netMarginPct := (netMargin / buyPriceINR) * 100
 
 	liveOpp.BuyPrice = buyPrice
 	liveOpp.SellPrice = sellPrice
+	liveOpp.BuyPriceINR = buyPriceINR
+	liveOpp.SellPriceINR = sellPriceINR
 	liveOpp.ExpectedMargin = netMargin
 	liveOpp.MarginPct = netMarginPct
 	liveOpp.MaxProfitableOrders = depthResult.MaxProfitableOrders
 	liveOpp.TotalEstimatedProfit = depthResult.TotalEstimatedProfit
 
-	// Step 5: Check volume and margin thresholds
-	minVolume := 1000.0
-	maxVolume := min(buyVolume, sellVolume)
-
-	if maxVolume < minVolume {
-		liveOpp.Reason = fmt.Sprintf("insufficient volume: %.0f < %.0f", maxVolume, minVolume)
-		return liveOpp
-	}
+	// Step 5: Check margin and size the position
+	liveOpp.TopOfBookVolume = min(buyVolume, sellVolume)
 
 	if netMarginPct < e.config.StopLossPct {
 		liveOpp.Reason = fmt.Sprintf("margin too low: %.2f%% < %.1f%%", netMarginPct, e.config.StopLossPct)
 		return liveOpp
 	}
 
-	// Opportunity is viable
-	liveOpp.Volume = min(maxVolume, 5000.0) // Cap at reasonable volume
+	if err := e.sizePosition(&liveOpp, buyLevels, sellLevels, buyRate, sellRate); err != nil {
+		liveOpp.Reason = fmt.Sprintf("insufficient volume: %v", err)
+		return liveOpp
+	}
+	// Sellers competing for the bids lower the odds
+	if err := e.sizeForFill(&liveOpp, sellLevels, sellOrderBook.Asks); err != nil {
+		liveOpp.Reason = err.Error()
+		e.events.Publish(events.NewRiskTripped("fill_probability", liveOpp.Currency, err.Error()))
+		return liveOpp
+	}
+	if err := e.checkImpact(&liveOpp, buyLevels, sellLevels, buyRate, sellRate); err != nil {
+		liveOpp.Reason = err.Error()
+		e.events.Publish(events.NewRiskTripped("market_impact", liveOpp.Currency, err.Error()))
+		return liveOpp
+	}
+	if err := e.checkSellDepth(&liveOpp, buyLevels, sellLevels); err != nil {
+		liveOpp.Reason = err.Error()
+		e.events.Publish(events.NewRiskTripped("sell_depth", liveOpp.Currency, err.Error()))
+		return liveOpp
+	}
+	// An empty bid side offers no recovery: the whole buy counts as lost
+	if err := e.checkWorstCase(&liveOpp, buyLevels, buyOrderBook.Bids, buyRate); err != nil {
+		liveOpp.Reason = err.Error()
+		e.events.Publish(events.NewRiskTripped("max_trade_loss", liveOpp.Currency, err.Error()))
+		return liveOpp
+	}
 	liveOpp.Viable = true
 	liveOpp.Reason = "profitable arbitrage with sufficient depth"
 
-	log.Printf("   💡 Live prices: Buy ₹%.6f, Sell ₹%.6f", buyPrice, sellPrice)
-	log.Printf("   📊 Net margin: ₹%.6f (%.2f%%), Depth: %d orders", netMargin, netMarginPct, depthResult.MaxProfitableOrders)
+	logger.Info("📊 Validated", "currency", liveOpp.Currency, "buy_inr", buyPriceINR, "sell_inr", sellPriceINR,
+		"net_margin_inr", netMargin, "net_margin_pct", netMarginPct, "depth_orders", depthResult.MaxProfitableOrders,
+		"imbalance", liveOpp.Imbalance.Signal(), "fill_probability", liveOpp.FillProbability, "volume", liveOpp.Volume)
 
 	return liveOpp
 }
 
-func (e *Engine) performQuickDepthAnalysis(currency string, buyOrderBook, sellOrderBook map[string]interface{}) types.QuickDepthResult {
+// performQuickDepthAnalysis walks the top levels of both books, charging
+// feeRate (both legs together) on each level's buy value
+func (e *Engine) performQuickDepthAnalysis(currency string, buyLevels, sellLevels []types.OrderLevel, buyRate, sellRate, feeRate float64) types.QuickDepthResult {
 	result := types.QuickDepthResult{
 		Currency:             currency,
 		MaxProfitableOrders:  0,
@@ -269,9 +611,9 @@ func (e *Engine) performQuickDepthAnalysis(currency string, buyOrderBook, sellOr
 		BottleneckSide:       "none",
 	}
 
-	// Parse order book levels (top 5 levels for speed)
-	buyLevels := e.parseOrderBookLevels(buyOrderBook, "asks", 5)
-	sellLevels := e.parseOrderBookLevels(sellOrderBook, "bids", 5)
+	// Top 5 levels for speed
+	buyLevels = buyLevels[:minInt(len(buyLevels), 5)]
+	sellLevels = sellLevels[:minInt(len(sellLevels), 5)]
 
 	if len(buyLevels) == 0 || len(sellLevels) == 0 {
 		return result
@@ -292,14 +634,15 @@ func (e *Engine) performQuickDepthAnalysis(currency string, buyOrderBook, sellOr
 			break
 		}
 
-		// Calculate margins
-		grossMargin := sellLevel.Price - buyLevel.Price
+		// Calculate margins in INR
+		buyPriceINR := buyLevel.Price * buyRate
+		grossMargin := sellLevel.Price*sellRate - buyPriceINR
 		if grossMargin <= 0 {
 			break
 		}
 
-		tradeValue := volume * buyLevel.Price
-		fees := tradeValue * 0.02 // 2% total fees
+		tradeValue := volume * buyPriceINR
+		fees := tradeValue * feeRate
 		netProfit := (grossMargin * volume) - fees
 		netMarginPct := (netProfit / tradeValue) * 100
 
@@ -331,133 +674,14 @@ func (e *Engine) performQuickDepthAnalysis(currency string, buyOrderBook, sellOr
 	return result
 }
 
-func (e *Engine) parseOrderBookLevels(orderBook map[string]interface{}, side string, maxLevels int) []types.OrderLevel {
-	levels := []types.OrderLevel{}
-
-	orders, ok := orderBook[side].(map[string]interface{})
-	if !ok {
-		return levels
-	}
-
-	type priceLevel struct {
-		price  float64
-		volume float64
-	}
-
-	priceLevels := []priceLevel{}
-
-	for priceStr, volumeInterface := range orders {
-		price, err := strconv.ParseFloat(priceStr, 64)
-		if err != nil {
-			continue
-		}
-
-		var volume float64
-		switch v := volumeInterface.(type) {
-		case string:
-			volume, _ = strconv.ParseFloat(v, 64)
-		case float64:
-			volume = v
-		}
-
-		if volume > 0 {
-			priceLevels = append(priceLevels, priceLevel{price: price, volume: volume})
-		}
-	}
-
-	// Sort levels
-	if side == "bids" {
-		sort.Slice(priceLevels, func(i, j int) bool {
-			return priceLevels[i].price > priceLevels[j].price
-		})
-	} else {
-		sort.Slice(priceLevels, func(i, j int) bool {
-			return priceLevels[i].price < priceLevels[j].price
-		})
-	}
-
-	// Convert to OrderLevel and limit count
-	maxCount := minInt(len(priceLevels), maxLevels)
-	for i := 0; i < maxCount; i++ {
-		level := priceLevels[i]
-		levels = append(levels, types.OrderLevel{
-			Price:  level.price,
-			Volume: level.volume,
-		})
-	}
-
-	return levels
-}
-
-func (e *Engine) getBestAsk(orderBook map[string]interface{}) (float64, float64) {
-	asks, ok := orderBook["asks"].(map[string]interface{})
-	if !ok {
-		return 0, 0
-	}
-
-	bestPrice := 999999999.0
-	bestVolume := 0.0
-
-	for priceStr, volumeInterface := range asks {
-		price, err := strconv.ParseFloat(priceStr, 64)
-		if err != nil {
-			continue
-		}
-
-		var volume float64
-		switch v := volumeInterface.(type) {
-		case string:
-			volume, _ = strconv.ParseFloat(v, 64)
-		case float64:
-			volume = v
-		}
-
-		if price < bestPrice && volume > 0 {
-			bestPrice = price
-			bestVolume = volume
-		}
-	}
-
-	if bestPrice == 999999999.0 {
-		return 0, 0
-	}
-	return bestPrice, bestVolume
-}
-
-func (e *Engine) getBestBid(orderBook map[string]interface{}) (float64, float64) {
-	bids, ok := orderBook["bids"].(map[string]interface{})
-	if !ok {
-		return 0, 0
-	}
-
-	bestPrice := 0.0
-	bestVolume := 0.0
-
-	for priceStr, volumeInterface := range bids {
-		price, err := strconv.ParseFloat(priceStr, 64)
-		if err != nil {
-			continue
-		}
-
-		var volume float64
-		switch v := volumeInterface.(type) {
-		case string:
-			volume, _ = strconv.ParseFloat(v, 64)
-		case float64:
-			volume = v
-		}
-
-		if price > bestPrice && volume > 0 {
-			bestPrice = price
-			bestVolume = volume
-		}
+func (e *Engine) executeRealTimeOrder(opportunity RealTimeOpportunity) types.ExecutedOrder {
+	if opportunity.ExecutionID == "" {
+		opportunity.ExecutionID = utils.NewUUID()
 	}
+	venue := e.venueFor(opportunity)
 
-	return bestPrice, bestVolume
-}
-
-func (e *Engine) executeRealTimeOrder(opportunity RealTimeOpportunity) types.ExecutedOrder {
 	executedOrder := types.ExecutedOrder{
+		ExecutionID:    opportunity.ExecutionID,
 		OrderNumber:    1,
 		Currency:       opportunity.Currency,
 		BuyMarket:      opportunity.BuyMarket,
@@ -465,19 +689,33 @@ func (e *Engine) executeRealTimeOrder(opportunity RealTimeOpportunity) types.Exe
 		PlannedVolume:  opportunity.Volume,
 		ExpectedProfit: opportunity.ExpectedMargin * opportunity.Volume,
 		StartTime:      time.Now(),
+		Latency:        types.PhaseLatency{ValidationMs: opportunity.ValidationMs},
+		DecisionBooks:  opportunity.Books,
 	}
 
-	// log.Printf("   🚀 EXECUTING: %.0f %s", opportunity.Volume, opportunity.Currency)
+	// Until it settles, a crash leaves the journal entry for the next start to finish
+	opportunity.journalID = e.journalBegin(opportunity)
+	defer e.journalFinish(opportunity.journalID)
 
-	// Step 1: BUY immediately
-	// log.Printf("   🟢 BUY: %.0f %s on %s", opportunity.Volume, opportunity.Currency, opportunity.BuyMarket)
+	if opportunity.level != nil {
+		return e.executeDepthLevel(opportunity, executedOrder)
+	}
+	if e.config.ExecutionPolicy == "atomic" {
+		return e.executeAtomicOrder(opportunity, executedOrder)
+	}
+	if !e.config.UseMarketOrders {
+		return e.executeLimitOrder(opportunity, executedOrder)
+	}
 
-	buyOrder, err := e.client.CreateOrder(coindcx.OrderRequest{
+	// Step 1: BUY immediately
+	phaseStart := time.Now()
+	buyOrder, err := e.placeOrder(opportunity, coindcx.OrderRequest{
 		Side:          "buy",
 		OrderType:     "market_order",
 		Market:        opportunity.BuyMarket,
 		TotalQuantity: opportunity.Volume,
 	})
+	executedOrder.Latency.BuyPlaceMs = time.Since(phaseStart).Milliseconds()
 
 	if err != nil {
 		executedOrder.ErrorMessage = fmt.Sprintf("buy failed: %v", err)
@@ -485,94 +723,95 @@ func (e *Engine) executeRealTimeOrder(opportunity RealTimeOpportunity) types.Exe
 		return executedOrder
 	}
 
-	if len(buyOrder.Orders) == 0 {
-		executedOrder.ErrorMessage = "no buy order returned"
-		executedOrder.EndTime = time.Now()
-		return executedOrder
-	}
-
-	buyOrderID := buyOrder.Orders[0].ID
+	buyOrderID := buyOrder.ID
 	executedOrder.BuyOrderID = buyOrderID
+	e.publish(opportunity, events.NewOrderPlaced(buyOrderID, opportunity.BuyMarket, "buy", opportunity.Volume))
 
 	// Wait for buy fill
-	buyFilled, err := e.waitForOrderFill(buyOrderID, e.config.OrderTimeoutSeconds)
-	if err != nil || !buyFilled {
+	phaseStart = time.Now()
+	filledBuy, err := executor.WaitForFill(venue, buyOrderID, e.orderTimeout())
+	executedOrder.Latency.BuyFillMs = time.Since(phaseStart).Milliseconds()
+	if err != nil {
 		executedOrder.ErrorMessage = "buy timeout"
 		executedOrder.EndTime = time.Now()
 		return executedOrder
 	}
 
-	// Get buy details
-	filledBuy, err := e.client.GetOrderStatus(buyOrderID)
-	if err != nil {
-		executedOrder.ErrorMessage = "buy status error"
+	actualVolume := filledBuy.TotalQuantity - filledBuy.RemainingQuantity
+	if err := types.RequirePositive("buy fill volume", actualVolume); err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("buy failed: %v", err)
 		executedOrder.EndTime = time.Now()
 		return executedOrder
 	}
-
-	actualVolume := filledBuy.TotalQuantity - filledBuy.RemainingQuantity
+	stopID := e.placeProtectiveStop(opportunity, actualVolume, filledBuy.AvgPrice)
 	executedOrder.VolumeExecuted = actualVolume
 	executedOrder.BuyPrice = filledBuy.AvgPrice
+	e.publish(opportunity, events.NewOrderFilled(buyOrderID, opportunity.BuyMarket, "buy",
+		actualVolume, filledBuy.AvgPrice, filledBuy.FeeAmount))
+	e.journalBought(opportunity.journalID, actualVolume, filledBuy.AvgPrice, filledBuy.FeeAmount)
 
-	// log.Printf("   ✅ Bought: %.0f at ₹%.6f", actualVolume, filledBuy.AvgPrice)
+	if !e.releaseStop(opportunity, &executedOrder, stopID, actualVolume, filledBuy.AvgPrice, filledBuy.FeeAmount) {
+		executedOrder.EndTime = time.Now()
+		executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+		return executedOrder
+	}
 
-	// Step 2: SELL immediately for arbitrage
-	// log.Printf("   🔴 SELL: %.0f %s on %s", actualVolume, opportunity.Currency, opportunity.SellMarket)
+	if e.config.LockInSeconds > 0 {
+		e.sellLockedIn(opportunity, &executedOrder, actualVolume, filledBuy.AvgPrice, filledBuy.FeeAmount)
+		executedOrder.EndTime = time.Now()
+		executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+		return executedOrder
+	}
 
-	sellOrder, err := e.client.CreateOrder(coindcx.OrderRequest{
+	// Step 2: SELL immediately for arbitrage
+	phaseStart = time.Now()
+	sellOrder, err := e.placeOrder(opportunity, coindcx.OrderRequest{
 		Side:          "sell",
 		OrderType:     "market_order",
 		Market:        opportunity.SellMarket,
 		TotalQuantity: actualVolume,
 	})
+	executedOrder.Latency.SellPlaceMs = time.Since(phaseStart).Milliseconds()
 
-	if err == nil && len(sellOrder.Orders) > 0 {
-		sellOrderID := sellOrder.Orders[0].ID
+	if err == nil {
+		sellOrderID := sellOrder.ID
 		executedOrder.SellOrderID = sellOrderID
+		e.publish(opportunity, events.NewOrderPlaced(sellOrderID, opportunity.SellMarket, "sell", actualVolume))
 
-		sellFilled, err := e.waitForOrderFill(sellOrderID, e.config.OrderTimeoutSeconds)
-		if err == nil && sellFilled {
-			filledSell, err := e.client.GetOrderStatus(sellOrderID)
-			if err == nil {
-				executedOrder.SellPrice = filledSell.AvgPrice
-
-				// Calculate actual profit
-				buyValue := actualVolume * filledBuy.AvgPrice
-				sellValue := actualVolume * filledSell.AvgPrice
-				fees := filledBuy.FeeAmount + filledSell.FeeAmount
-
-				executedOrder.ActualProfit = sellValue - buyValue - fees
-				executedOrder.ActualMarginPct = (executedOrder.ActualProfit / buyValue) * 100
-				executedOrder.Success = true
-
-				log.Printf("   💰 ARBITRAGE: sold at ₹%.6f, profit ₹%.2f (%.2f%%)",
-					filledSell.AvgPrice, executedOrder.ActualProfit, executedOrder.ActualMarginPct)
-
-				executedOrder.EndTime = time.Now()
-				executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
-				return executedOrder
-			}
+		// A sell still working at the timeout is cancelled first, so recovery
+		// only plans for the coins it didn't take
+		phaseStart = time.Now()
+		filledSell, err := executor.WaitOrCancel(venue, sellOrder, e.orderTimeout())
+		executedOrder.Latency.SellFillMs = time.Since(phaseStart).Milliseconds()
+		if err != nil {
+			executedOrder.ErrorMessage = fmt.Sprintf("sell leg state unknown, manual action required: %v", err)
+			e.publish(opportunity, events.NewRecoveryFailed(opportunity.Currency, actualVolume, err.Error(), true))
+			executedOrder.EndTime = time.Now()
+			executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+			return executedOrder
 		}
-	}
-
-	// Step 3: Recovery to USDT if arbitrage failed
-	log.Printf("   ⚠️ Arbitrage failed, recovering...")
-	recovered := e.recoverToUSDT(opportunity.Currency, actualVolume)
 
-	if recovered.Success {
-		buyValue := actualVolume * filledBuy.AvgPrice
-		sellValue := actualVolume * recovered.SellPrice
-		fees := filledBuy.FeeAmount + recovered.FeeAmount
-
-		executedOrder.ActualProfit = sellValue - buyValue - fees
-		executedOrder.ActualMarginPct = (executedOrder.ActualProfit / buyValue) * 100
-		executedOrder.SellPrice = recovered.SellPrice
-		executedOrder.SellOrderID = recovered.OrderID
-		executedOrder.Success = true
+		sold := filledQuantity(filledSell)
+		if sold > 0 {
+			e.publish(opportunity, events.NewOrderFilled(sellOrderID, opportunity.SellMarket, "sell",
+				sold, filledSell.AvgPrice, filledSell.FeeAmount))
+		}
+		if filledSell.Status == "filled" {
+			executedOrder.SellPrice = filledSell.AvgPrice
+			e.settleProfit(opportunity, &executedOrder, actualVolume, filledBuy.AvgPrice, filledBuy.FeeAmount,
+				soldOn(opportunity, actualVolume, actualVolume*filledSell.AvgPrice, filledSell.FeeAmount))
+
+			executedOrder.EndTime = time.Now()
+			executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+			return executedOrder
+		}
 
-		log.Printf("   🔄 Recovered: ₹%.2f (%.2f%%)", executedOrder.ActualProfit, executedOrder.ActualMarginPct)
+		// Step 3: Recover what the sell left via the best route back to USDT
+		e.recoverUnsold(opportunity, &executedOrder, actualVolume, filledBuy.AvgPrice, filledBuy.FeeAmount,
+			soldOn(opportunity, sold, sold*filledSell.AvgPrice, filledSell.FeeAmount), "sell leg "+filledSell.Status)
 	} else {
-		executedOrder.ErrorMessage = "recovery failed"
+		e.recoverUnsold(opportunity, &executedOrder, actualVolume, filledBuy.AvgPrice, filledBuy.FeeAmount,
+			sale{}, fmt.Sprintf("sell leg failed: %v", err))
 	}
 
 	executedOrder.EndTime = time.Now()
@@ -580,71 +819,124 @@ func (e *Engine) executeRealTimeOrder(opportunity RealTimeOpportunity) types.Exe
 	return executedOrder
 }
 
-type RecoveryResult struct {
-	Success   bool
-	SellPrice float64
-	FeeAmount float64
-	OrderID   string
-}
+// recoverUnsold sends what the sell leg left of the bought volume through
+// recovery and settles executedOrder. sold is what the sell leg (or a
+// triggered protective stop) did take; empty when nothing was.
+func (e *Engine) recoverUnsold(opportunity RealTimeOpportunity, executedOrder *types.ExecutedOrder, bought, buyPrice, buyFee float64, sold sale, reason string) {
+	unsold := bought - sold.volume
+	e.publish(opportunity, events.NewRecoveryTriggered(opportunity.Currency, unsold, reason))
+	recovered := e.recoverInventory(e.venueFor(opportunity), opportunity.Currency, unsold)
 
-func (e *Engine) recoverToUSDT(currency string, volume float64) RecoveryResult {
-	market := fmt.Sprintf("%sUSDT", currency)
+	if recovered.Success {
+		recovery := sale{volume: unsold, value: unsold * recovered.SellPrice, fee: recovered.FeeAmount, quote: recovered.Quote}
+		if sold.volume > 0 {
+			// A blended sell has no single fill to attribute the profit to
+			e.settleProfit(opportunity, executedOrder, bought, buyPrice, buyFee, sold, recovery)
+			executedOrder.SellPrice = (sold.value + recovery.value) / bought
+		} else {
+			e.settleProfit(opportunity, executedOrder, bought, buyPrice, buyFee, recovery)
+			executedOrder.SellPrice = recovered.SellPrice
+			executedOrder.SellOrderID = recovered.OrderID
+		}
 
-	sellOrder, err := e.client.CreateOrder(coindcx.OrderRequest{
-		Side:          "sell",
-		OrderType:     "market_order",
-		Market:        market,
-		TotalQuantity: volume,
-	})
+		opportunity.log().Info("🔄 Recovered", "currency", opportunity.Currency, "profit_inr", executedOrder.ActualProfit, "margin_pct", executedOrder.ActualMarginPct)
+	} else if recovered.Dust {
+		executedOrder.ErrorMessage = recovered.Reason
+	} else {
+		executedOrder.ErrorMessage = "recovery failed"
+		if recovered.ManualRequired {
+			executedOrder.ErrorMessage = "recovery failed, manual action required: " + recovered.Reason
+		}
+		// Coins a failed route sold before stopping aren't held any more
+		held := unsold - recovered.Sold
+		e.publish(opportunity, events.NewRecoveryFailed(opportunity.Currency, held, recovered.Reason, recovered.ManualRequired))
+		if held > 0 {
+			e.trackStranded(opportunity, held, buyPrice, buyFee*held/bought)
+		}
+	}
+}
 
-	if err != nil || len(sellOrder.Orders) == 0 {
-		return RecoveryResult{Success: false}
+// routePlanner returns the recovery planner, building it from market details
+// on first use; nil when market details are unavailable
+func (e *Engine) routePlanner() *executor.RoutePlanner {
+	e.plannerMu.Lock()
+	defer e.plannerMu.Unlock()
+
+	if e.planner == nil {
+		markets, err := e.fetcher.GetMarketDetails()
+		if err != nil {
+			logger.Warn("⚠️ Route planning unavailable", "error", err)
+			return nil
+		}
+		e.planner = executor.NewRoutePlanner(e.fetcher, markets, e.feeRate)
 	}
+	return e.planner
+}
 
-	orderID := sellOrder.Orders[0].ID
-	filled, err := e.waitForOrderFill(orderID, 15)
-	if err != nil || !filled {
-		return RecoveryResult{Success: false}
+// recoverInventory liquidates stranded inventory through venue via the best
+// recovery route, falling back to direct USDT/INR sells when market details
+// are unavailable
+func (e *Engine) recoverInventory(venue executor.Executor, currency string, volume float64) executor.RecoveryResult {
+	planner := e.routePlanner()
+
+	// Fold in earlier leftovers; below the minimum order size everything stays as dust
+	dustQty := e.dust.Quantity(currency)
+	total := volume + dustQty
+	if planner != nil {
+		if minQty, ok := planner.MinQuantity(currency); ok && total < minQty {
+			entry, err := e.dust.Add(currency, volume)
+			if err != nil {
+				logger.Warn("⚠️ Could not persist dust", "currency", currency, "error", err)
+			}
+			logger.Info("🧹 Below minimum, tracked as dust", "currency", currency, "volume", volume,
+				"min_quantity", minQty, "accumulated", entry.Quantity)
+			return executor.RecoveryResult{
+				Dust:   true,
+				Reason: fmt.Sprintf("%.8f %s below minimum order size %.8f, tracked as dust", volume, currency, minQty),
+			}
+		}
 	}
 
-	finalOrder, err := e.client.GetOrderStatus(orderID)
-	if err != nil {
-		return RecoveryResult{Success: false}
+	var recovered executor.RecoveryResult
+	if planner == nil {
+		recovered = executor.RecoverInventory(venue, e.marketGuard(), currency, total, 15*time.Second)
+	} else {
+		recovered = planner.Recover(venue, e.marketGuard(), currency, total, 15*time.Second)
 	}
 
-	return RecoveryResult{
-		Success:   true,
-		SellPrice: finalOrder.AvgPrice,
-		FeeAmount: finalOrder.FeeAmount,
-		OrderID:   orderID,
+	if recovered.Success && dustQty > 0 {
+		logger.Info("🧹 Sold accumulated dust with this recovery", "currency", currency, "volume", dustQty)
+		if err := e.dust.Clear(currency); err != nil {
+			logger.Warn("⚠️ Could not update dust ledger", "currency", currency, "error", err)
+		}
 	}
+	return recovered
 }
 
-func (e *Engine) waitForOrderFill(orderID string, timeoutSeconds int) (bool, error) {
-	timeout := time.After(time.Duration(timeoutSeconds) * time.Second)
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+// venueFor returns the venue an execution places its orders through, which
+// audits each call under the execution's ID
+func (e *Engine) venueFor(opportunity RealTimeOpportunity) executor.Executor {
+	if opportunity.ExecutionID == "" {
+		return e.venue
+	}
+	return e.scopedVenue(opportunity.ExecutionID)
+}
 
-	for {
-		select {
-		case <-timeout:
-			return false, fmt.Errorf("timeout")
-		case <-ticker.C:
-			order, err := e.client.GetOrderStatus(orderID)
-			if err != nil {
-				continue
-			}
+// publish puts an execution's event on the bus, tagged with its ID
+func (e *Engine) publish(opportunity RealTimeOpportunity, event events.Event) {
+	e.events.Publish(events.Tag(event, opportunity.ExecutionID))
+}
 
-			switch order.Status {
-			case "filled":
-				return true, nil
-			case "cancelled", "rejected":
-				return false, fmt.Errorf("order %s", order.Status)
-			default:
-				continue
-			}
-		}
+// marketGuard returns the status monitor as a guard, or nil when none is set
+func (e *Engine) marketGuard() executor.MarketGuard {
+	if e.status == nil {
+		return nil
 	}
+	return e.status
+}
+
+func (e *Engine) orderTimeout() time.Duration {
+	return time.Duration(e.config.OrderTimeoutSeconds) * time.Second
 }
 
 func min(a, b float64) float64 {
@@ -669,6 +961,8 @@ func (e *Engine) DisplayResults(result *types.ExecutionResult) {
 	fmt.Printf("💵 Total Profit: ₹%.2f\n", result.TotalProfit)
 	fmt.Printf("📈 Success Rate: %.1f%%\n", e.calculateSuccessRate(result))
 	fmt.Printf("⏱️ Total Time: %v\n", result.EndTime.Sub(result.StartTime))
+	displayLatencySummary(result.LatencySummary)
+	displayInventory(result.Inventory, result.UnrealizedPnL)
 
 	if len(result.Orders) > 0 {
 		fmt.Printf("\n📋 Order Details:\n")
@@ -677,9 +971,18 @@ func (e *Engine) DisplayResults(result *types.ExecutionResult) {
 			if !order.Success {
 				status = "❌"
 			}
-			fmt.Printf("   %s %s: %.0f tokens, ₹%.2f profit (%.2f%%) in %dms\n",
-				status, order.Currency, order.VolumeExecuted,
+			fmt.Printf("   %s %s: %s tokens, ₹%.2f profit (%.2f%%) in %dms\n",
+				status, order.Currency, market.FormatQuantity(order.BuyMarket, order.VolumeExecuted),
 				order.ActualProfit, order.ActualMarginPct, order.ExecutionTimeMs)
+			if a := order.Attribution; a != nil {
+				fmt.Printf("      🧾 spread ₹%.2f, buy drift ₹%.2f, sell drift ₹%.2f, fees ₹%.2f\n",
+					a.SpreadCaptured, a.BuyDrift, a.SellDrift, a.Fees)
+			}
+			for _, slice := range order.Slices {
+				fmt.Printf("      🔹 slice %d: %s/%s filled, ₹%.2f profit (%.2f%%)\n", slice.Slice,
+					market.FormatQuantity(order.BuyMarket, slice.VolumeExecuted),
+					market.FormatQuantity(order.BuyMarket, slice.PlannedVolume), slice.ActualProfit, slice.MarginPct)
+			}
 		}
 	}
 }
@@ -700,6 +1003,9 @@ func (e *Engine) calculateSuccessRate(result *types.ExecutionResult) float64 {
 }
 
 func (e *Engine) SaveExecutionLog(result *types.ExecutionResult, filename string) error {
+	if result.Run == nil {
+		result.Run = version.Current()
+	}
 	return utils.SaveJSON(result, filename)
 }
 
@@ -707,7 +1013,8 @@ func (e *Engine) AnalyzeAndValidateRealTime(opp types.ArbitrageOpportunity) Real
 	return e.analyzeAndValidateRealTime(opp)
 }
 
-// ExecuteRealTimeOrder - made public for use by live detector
+// ExecuteRealTimeOrder executes one validated opportunity outside of any
+// entry point's position limit
 func (e *Engine) ExecuteRealTimeOrder(opportunity RealTimeOpportunity) types.ExecutedOrder {
-	return e.executeRealTimeOrder(opportunity)
+	return e.executeOpportunity(opportunity)
 }
@@ -0,0 +1,48 @@
+package arbitrage
+
+import (
+	"testing"
+
+	"github.com/b-thark/cdcx-api/internal/audit"
+	"github.com/b-thark/cdcx-api/pkg/events"
+)
+
+func TestExecutionIDTagsTheAttempt(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	var tagged []events.Event
+	engine.Events().Subscribe(func(event events.Event) { tagged = append(tagged, event) })
+
+	order := engine.ExecuteRealTimeOrder(limitOpportunity(1000, 1.00, 90.0))
+	if !order.Success {
+		t.Fatalf("execution failed: %s", order.ErrorMessage)
+	}
+	if order.ExecutionID == "" {
+		t.Fatal("executed order has no execution ID")
+	}
+
+	for _, event := range tagged {
+		if event.Execution() != order.ExecutionID {
+			t.Errorf("%s event tagged %q, want %q", event.Kind(), event.Execution(), order.ExecutionID)
+		}
+	}
+
+	entries, err := audit.Load("audit_trail.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	for _, entry := range entries {
+		if entry.Kind == audit.KindAPICall {
+			calls++
+			if entry.ExecutionID != order.ExecutionID {
+				t.Errorf("API call %q tagged %q, want %q", entry.Summary, entry.ExecutionID, order.ExecutionID)
+			}
+		}
+	}
+	if calls == 0 {
+		t.Error("no API calls in the audit trail")
+	}
+	if timeline := audit.Timeline(entries, order); len(timeline) != len(entries) {
+		t.Errorf("timeline has %d of the %d entries", len(timeline), len(entries))
+	}
+}
@@ -0,0 +1,67 @@
+package arbitrage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// fillSizingSteps is how many times sizing halves the volume looking for one
+// whose sell leg is likely enough to fill
+const fillSizingSteps = 4
+
+// SetFillModel replaces the model estimating whether sell legs fill, e.g.
+// with one trained on past executions
+func (e *Engine) SetFillModel(model market.FillModel) {
+	e.fillModel = model
+}
+
+// loadFillModel is the configured trained model, else the depth heuristic
+func loadFillModel(config *types.ExecutionConfig) market.FillModel {
+	if config.FillModelFile == "" {
+		return market.DepthFillModel{}
+	}
+	model, err := market.LoadLogisticFillModel(config.FillModelFile)
+	if err != nil {
+		logger.Warn("⚠️ Fill model unavailable, using the depth heuristic", "error", err)
+		return market.DepthFillModel{}
+	}
+	return model
+}
+
+// sellTimeout is how long the sell leg works before it is given up
+func (e *Engine) sellTimeout() time.Duration {
+	if e.config.UseMarketOrders {
+		return e.orderTimeout()
+	}
+	return e.limitOrderTimeout()
+}
+
+// sizeForFill estimates the probability the sell leg of the opportunity's
+// volume fills within its timeout and, below MinFillProbability, halves the
+// volume until it is likely enough to. It fails when even the smallest size
+// tried isn't; sizes are only ever reduced.
+func (e *Engine) sizeForFill(liveOpp *RealTimeOpportunity, sellBids, sellAsks []types.OrderLevel) error {
+	state := market.FillState{Market: liveOpp.SellMarket, Quantity: liveOpp.Volume, Bids: sellBids, Asks: sellAsks, Timeout: e.sellTimeout()}
+	liveOpp.FillProbability = e.fillModel.FillProbability(state)
+
+	required := e.config.MinFillProbability
+	if required <= 0 || liveOpp.FillProbability >= required {
+		return nil
+	}
+
+	planned, probability := liveOpp.Volume, liveOpp.FillProbability
+	for step := 0; step < fillSizingSteps; step++ {
+		state.Quantity /= 2
+		if p := e.fillModel.FillProbability(state); p >= required {
+			liveOpp.Volume, liveOpp.FillProbability = state.Quantity, p
+			logger.Info("🎯 Sized for the sell to fill", "currency", liveOpp.Currency, "planned", planned,
+				"volume", liveOpp.Volume, "planned_probability", probability, "fill_probability", p)
+			return nil
+		}
+	}
+	return fmt.Errorf("sell fill probability %.0f%% below %.0f%% even at %.4f of %.4f",
+		probability*100, required*100, state.Quantity, planned)
+}
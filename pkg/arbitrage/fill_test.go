@@ -0,0 +1,49 @@
+package arbitrage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestSizeForFillHalvesUntilLikely(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.MinFillProbability = 0.8
+	// Likely only at 1000 or less
+	engine.SetFillModel(market.FillModelFunc(func(state market.FillState) float64 {
+		if state.Quantity <= 1000 {
+			return 0.9
+		}
+		return 0.3
+	}))
+	bids := []types.OrderLevel{{Price: 90, Volume: 5000}}
+
+	opp := limitOpportunity(4000, 1, 90)
+	if err := engine.sizeForFill(&opp, bids, nil); err != nil {
+		t.Fatal(err)
+	}
+	if opp.Volume != 1000 || opp.FillProbability != 0.9 {
+		t.Errorf("sized to %.0f at %.0f%%, want 1000 at 90%%", opp.Volume, opp.FillProbability*100)
+	}
+
+	// Four halvings of 32000 stop at 2000, still unlikely
+	opp = limitOpportunity(32000, 1, 90)
+	if err := engine.sizeForFill(&opp, bids, nil); err == nil {
+		t.Errorf("sized to %.0f, want a refusal", opp.Volume)
+	}
+	if opp.Volume != 32000 {
+		t.Errorf("refused trade resized to %.0f", opp.Volume)
+	}
+}
+
+func TestDepthFillModelGrowsWithCoverage(t *testing.T) {
+	bids := []types.OrderLevel{{Price: 90, Volume: 100}, {Price: 89, Volume: 100}}
+	model := market.DepthFillModel{}
+	thin := model.FillProbability(market.FillState{Quantity: 200, Bids: bids, Timeout: 30 * time.Second})
+	deep := model.FillProbability(market.FillState{Quantity: 50, Bids: bids, Timeout: 30 * time.Second})
+	if thin <= 0 || deep >= 1 || thin >= deep {
+		t.Errorf("probabilities %.3f (covered once) and %.3f (four times), want 0 < thin < deep < 1", thin, deep)
+	}
+}
@@ -0,0 +1,85 @@
+package arbitrage
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// checkImpact estimates what buying and selling the opportunity's volume
+// would cost beyond the best prices the margin was computed from, and fails
+// when either book can't absorb the size or the impact alone would eat more
+// than MaxImpactMarginShare of the expected margin. The rates convert each
+// leg's quote currency to INR, as for the margin.
+func (e *Engine) checkImpact(liveOpp *RealTimeOpportunity, buyLevels, sellLevels []types.OrderLevel, buyRate, sellRate float64) error {
+	liveOpp.BuyImpact = market.EstimateImpact(buyLevels, liveOpp.Volume)
+	liveOpp.SellImpact = market.EstimateImpact(sellLevels, liveOpp.Volume)
+
+	share := e.config.MaxImpactMarginShare
+	if share <= 0 {
+		return nil
+	}
+
+	if liveOpp.BuyImpact.Filled < liveOpp.Volume {
+		return fmt.Errorf("buy book too thin: %.4f of %.4f fillable", liveOpp.BuyImpact.Filled, liveOpp.Volume)
+	}
+	if liveOpp.SellImpact.Filled < liveOpp.Volume {
+		return fmt.Errorf("sell book too thin: %.4f of %.4f fillable", liveOpp.SellImpact.Filled, liveOpp.Volume)
+	}
+
+	// Per unit, in INR, like ExpectedMargin
+	impact := liveOpp.BuyImpact.PerUnit()*buyRate + liveOpp.SellImpact.PerUnit()*sellRate
+	if impact > share*liveOpp.ExpectedMargin {
+		return fmt.Errorf("market impact ₹%.6f/unit (buy %.2f%%, sell %.2f%%) exceeds %.0f%% of ₹%.6f margin",
+			impact, liveOpp.BuyImpact.ImpactPct, liveOpp.SellImpact.ImpactPct, share*100, liveOpp.ExpectedMargin)
+	}
+	return nil
+}
+
+// checkWorstCase prices the opportunity's worst outcome in INR: the buy
+// slips to the last of WorstCaseLevels ask levels, the sell leg fails, and
+// recovery sells everything back into the buy market's current bids (what
+// they can't take counts as lost), fees charged on both. It fails when that
+// loss exceeds MaxTradeLossINR, whatever the stop-loss percentages allow.
+func (e *Engine) checkWorstCase(liveOpp *RealTimeOpportunity, buyAsks, buyBids []types.OrderLevel, buyRate float64) error {
+	if e.config.MaxTradeLossINR <= 0 || len(buyAsks) == 0 {
+		return nil
+	}
+
+	levels := max(e.config.WorstCaseLevels, 1)
+	if levels > len(buyAsks) {
+		levels = len(buyAsks)
+	}
+	worstAsk := buyAsks[levels-1].Price
+	feeRate := e.feeRate(liveOpp.BuyMarket)
+
+	buyCost := liveOpp.Volume * worstAsk * (1 + feeRate)
+	recovery := market.EstimateImpact(buyBids, liveOpp.Volume)
+	recovered := recovery.Filled * recovery.EffectivePrice * (1 - feeRate)
+
+	liveOpp.WorstCaseLossINR = (buyCost - recovered) * buyRate
+	if liveOpp.WorstCaseLossINR > e.config.MaxTradeLossINR {
+		return fmt.Errorf("worst-case loss ₹%.2f exceeds the ₹%.2f cap (buy slipping %d levels to %g, recovery into bids at %g for %.4f of %.4f)",
+			liveOpp.WorstCaseLossINR, e.config.MaxTradeLossINR, levels, worstAsk, recovery.EffectivePrice, recovery.Filled, liveOpp.Volume)
+	}
+	return nil
+}
+
+// checkSellDepth measures the imbalance between the bids the opportunity
+// sells into and the asks it buys from, and fails when the sell market's top
+// bids hold less than MinSellDepthRatio times the volume bought. A sell into
+// a thin bid stack is what most often leaves inventory for recovery.
+func (e *Engine) checkSellDepth(liveOpp *RealTimeOpportunity, buyLevels, sellLevels []types.OrderLevel) error {
+	liveOpp.Imbalance = market.MeasureImbalance(sellLevels, buyLevels)
+
+	ratio := e.config.MinSellDepthRatio
+	if ratio <= 0 {
+		return nil
+	}
+	if required := ratio * liveOpp.Volume; liveOpp.Imbalance.BidVolume < required {
+		return fmt.Errorf("sell bids too thin: %.4f in the top %d levels, need %.1fx the %.4f bought (imbalance %+.2f)",
+			liveOpp.Imbalance.BidVolume, market.ImbalanceLevels, ratio, liveOpp.Volume, liveOpp.Imbalance.Signal())
+	}
+	return nil
+}
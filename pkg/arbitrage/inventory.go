@@ -0,0 +1,162 @@
+package arbitrage
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// trackStranded records inventory left behind by a failed sell leg at its
+// INR acquisition cost so unrealized P&L can be followed until it is sold
+func (e *Engine) trackStranded(opportunity RealTimeOpportunity, volume, buyPrice, buyFee float64) {
+	quote := opportunity.Opportunity.BuyMarket.BaseCurrency
+	if quote == "" {
+		quote = "USDT"
+	}
+
+	costINR, err := e.rateManager.ReportINR(volume*buyPrice+buyFee, quote)
+	if err != nil {
+		opportunity.log().Warn("⚠️ Could not price stranded inventory", "currency", opportunity.Currency, "error", err)
+		return
+	}
+
+	if err := e.inventory.Add(opportunity.Currency, volume, costINR, opportunity.BuyMarket); err != nil {
+		opportunity.log().Warn("⚠️ Could not persist inventory", "error", err)
+	}
+}
+
+// ValueInventory marks held inventory to live prices, returning each
+// position and the total unrealized P&L in INR
+func (e *Engine) ValueInventory() ([]types.PositionValuation, float64) {
+	if len(e.inventory.Positions()) == 0 {
+		return nil, 0
+	}
+	return e.inventory.Value(e.markINR())
+}
+
+// markINR returns a live INR price per unit for any currency, marked at the
+// best bid: what it would fetch if sold now. Markets without a valid bid
+// can't mark anything.
+func (e *Engine) markINR() func(currency string) (float64, error) {
+	tickers, err := e.fetcher.GetTicker()
+	if err != nil {
+		logger.Warn("⚠️ Inventory valuation unavailable", "error", err)
+		return func(string) (float64, error) { return 0, err }
+	}
+
+	return func(currency string) (float64, error) {
+		for _, quote := range []string{"INR", "USDT"} {
+			if bid, ok := tickers.Bid(types.NewSymbol(currency, quote).Code()); ok {
+				return e.rateManager.ReportINR(bid, quote)
+			}
+		}
+		return 0, fmt.Errorf("no INR or USDT market for %s", currency)
+	}
+}
+
+// attachInventory adds marked inventory to an execution result
+func (e *Engine) attachInventory(result *types.ExecutionResult) {
+	result.Inventory, result.UnrealizedPnL = e.ValueInventory()
+}
+
+func displayInventory(valuations []types.PositionValuation, unrealized float64) {
+	if len(valuations) == 0 {
+		return
+	}
+
+	fmt.Printf("\n📦 Held Inventory (unrealized ₹%.2f):\n", unrealized)
+	for _, v := range valuations {
+		fmt.Printf("   %s: %.6f @ cost ₹%.2f → ₹%.2f (%+.2f, %+.2f%%)\n",
+			v.Currency, v.Quantity, v.CostINR, v.ValueINR, v.UnrealizedINR, v.UnrealizedPct)
+	}
+}
+
+// Flatten sells all held inventory via the recovery routes, removing what was
+// sold from the book. Positions that can't be sold stay tracked.
+func (e *Engine) Flatten() []executor.RecoveryResult {
+	results := []executor.RecoveryResult{}
+	for _, position := range e.inventory.Positions() {
+		logger.Info("📉 Flattening", "currency", position.Currency, "quantity", position.Quantity)
+		results = append(results, e.sellPosition(position))
+	}
+	return results
+}
+
+// Sweep runs held inventory and accumulated dust worth at least minValueINR
+// back through the recovery planner, so stranded assets don't sit unmanaged
+// between manual interventions. Call it while no execution is in flight.
+// What can't be sold, or is still below the minimum order size, stays tracked.
+func (e *Engine) Sweep(minValueINR float64) []executor.RecoveryResult {
+	positions, entries := e.inventory.Positions(), e.dust.Entries()
+	if len(positions) == 0 && len(entries) == 0 {
+		return nil
+	}
+
+	mark := e.markINR()
+	worthSweeping := func(currency string, quantity float64) bool {
+		price, err := mark(currency)
+		if err != nil {
+			return minValueINR <= 0 // Unpriced: only an unconditional sweep tries it
+		}
+		return quantity*price >= minValueINR
+	}
+
+	results := []executor.RecoveryResult{}
+	held := make(map[string]bool)
+	for _, position := range positions {
+		held[position.Currency] = true
+		if !worthSweeping(position.Currency, position.Quantity+e.dust.Quantity(position.Currency)) {
+			continue
+		}
+
+		logger.Info("🧹 Sweeping", "currency", position.Currency, "quantity", position.Quantity)
+		e.events.Publish(events.NewRecoveryTriggered(position.Currency, position.Quantity, "scheduled sweep"))
+		results = append(results, e.sellPosition(position))
+	}
+
+	planner := e.routePlanner()
+	for _, entry := range entries {
+		// Dust of a held currency was folded into its position's recovery
+		if held[entry.Currency] || !worthSweeping(entry.Currency, entry.Quantity) {
+			continue
+		}
+		if planner != nil {
+			if minQty, ok := planner.MinQuantity(entry.Currency); ok && entry.Quantity < minQty {
+				continue
+			}
+		}
+
+		logger.Info("🧹 Sweeping dust", "currency", entry.Currency, "quantity", entry.Quantity)
+		e.events.Publish(events.NewRecoveryTriggered(entry.Currency, entry.Quantity, "scheduled dust sweep"))
+		recovered := e.recoverInventory(e.venue, entry.Currency, 0)
+		if !recovered.Success {
+			logger.Warn("⚠️ Dust not swept", "currency", entry.Currency, "reason", recovered.Reason)
+			if !recovered.Dust {
+				e.events.Publish(events.NewRecoveryFailed(entry.Currency, entry.Quantity, recovered.Reason, recovered.ManualRequired))
+			}
+		}
+		results = append(results, recovered)
+	}
+	return results
+}
+
+// sellPosition recovers a held position, removing it from the book once sold
+func (e *Engine) sellPosition(position types.Position) executor.RecoveryResult {
+	recovered := e.recoverInventory(e.venue, position.Currency, position.Quantity)
+	if recovered.Success {
+		if err := e.inventory.Reduce(position.Currency, position.Quantity); err != nil {
+			logger.Warn("⚠️ Could not update inventory", "error", err)
+		}
+	} else {
+		if recovered.Sold > 0 {
+			if err := e.inventory.Reduce(position.Currency, min(recovered.Sold, position.Quantity)); err != nil {
+				logger.Warn("⚠️ Could not update inventory", "error", err)
+			}
+		}
+		logger.Warn("⚠️ Not sold", "currency", position.Currency, "reason", recovered.Reason)
+		e.events.Publish(events.NewRecoveryFailed(position.Currency, position.Quantity, recovered.Reason, recovered.ManualRequired))
+	}
+	return recovered
+}
@@ -0,0 +1,266 @@
+package arbitrage
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/journal"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// journalBegin opens the execution journal entry of an arbitrage about to
+// place its buy leg. A journal that can't be written doesn't stop the trade.
+func (e *Engine) journalBegin(opportunity RealTimeOpportunity) string {
+	id, err := e.journal.Begin(journal.Entry{
+		ExecutionID: opportunity.ExecutionID,
+		Currency:    opportunity.Currency,
+		BuyMarket:   opportunity.BuyMarket,
+		SellMarket:  opportunity.SellMarket,
+		SellPair:    opportunity.Opportunity.SellMarket.Pair,
+		BuyQuote:    opportunity.Opportunity.BuyMarket.BaseCurrency,
+		SellQuote:   opportunity.Opportunity.SellMarket.BaseCurrency,
+	})
+	if err != nil {
+		opportunity.log().Warn("⚠️ Could not journal execution", "currency", opportunity.Currency, "error", err)
+	}
+	return id
+}
+
+// placeOrder places an order of the execution's through its venue. The
+// order's client order ID is journaled before the create is sent and the
+// exchange's ID once it returns, so a process that dies in between leaves
+// enough for the next one to look the order up.
+func (e *Engine) placeOrder(opportunity RealTimeOpportunity, req coindcx.OrderRequest) (*coindcx.Order, error) {
+	if req.ClientOrderID == "" {
+		req.ClientOrderID = coindcx.NewClientOrderID()
+	}
+	e.journalAdvance(opportunity.journalID, req.Side, func(orderIDs, clientOrderIDs *[]string) {
+		*clientOrderIDs = append(*clientOrderIDs, req.ClientOrderID)
+	})
+
+	order, err := e.venueFor(opportunity).CreateOrder(req)
+	if err != nil {
+		return nil, err
+	}
+	e.journalOrder(opportunity.journalID, req.Side, order.ID)
+	return order, nil
+}
+
+// journalOrder records an order a leg placed
+func (e *Engine) journalOrder(id, side, orderID string) {
+	e.journalAdvance(id, side, func(orderIDs, clientOrderIDs *[]string) {
+		*orderIDs = append(*orderIDs, orderID)
+	})
+}
+
+// journalAdvance moves the entry to the side's leg, updating that leg's
+// order IDs
+func (e *Engine) journalAdvance(id, side string, update func(orderIDs, clientOrderIDs *[]string)) {
+	if id == "" {
+		return
+	}
+	var err error
+	if side == "buy" {
+		err = e.journal.Advance(id, journal.Buying, func(entry *journal.Entry) {
+			update(&entry.BuyOrderIDs, &entry.BuyClientOrderIDs)
+		})
+	} else {
+		err = e.journal.Advance(id, journal.Selling, func(entry *journal.Entry) {
+			update(&entry.SellOrderIDs, &entry.SellClientOrderIDs)
+		})
+	}
+	if err != nil {
+		logger.Warn("⚠️ Could not journal order", "side", side, "error", err)
+	}
+}
+
+// journalBought records the buy leg's fill
+func (e *Engine) journalBought(id string, bought, buyPrice, buyFee float64) {
+	if id == "" {
+		return
+	}
+	err := e.journal.Advance(id, journal.Bought, func(entry *journal.Entry) {
+		entry.Bought, entry.BuyPrice, entry.BuyFee = bought, buyPrice, buyFee
+	})
+	if err != nil {
+		logger.Warn("⚠️ Could not journal buy fill", "error", err)
+	}
+}
+
+// journalFinish closes a settled entry
+func (e *Engine) journalFinish(id string) {
+	if id == "" {
+		return
+	}
+	if err := e.journal.Finish(id); err != nil {
+		logger.Warn("⚠️ Could not close journal entry", "id", id, "error", err)
+	}
+}
+
+// ResumeJournal settles the arbitrages a previous process left between
+// their legs. Each journaled order is settled (a working one cancelled) to
+// learn what is still held, which is sold on the planned sell market while
+// its bid still covers the buy's cost and fees, else recovered to USDT.
+// Entries whose orders can't be read stay for the next attempt. Call it
+// before executing anything.
+func (e *Engine) ResumeJournal() []types.ExecutedOrder {
+	results := []types.ExecutedOrder{}
+	for _, entry := range e.journal.Entries() {
+		logger.Warn("🩹 Resuming interrupted arbitrage", "execution_id", entry.ExecutionID, "currency", entry.Currency, "state", entry.State,
+			"buy_market", entry.BuyMarket, "sell_market", entry.SellMarket, "started_at", entry.StartedAt)
+
+		executedOrder, settled := e.resumeEntry(entry)
+		results = append(results, executedOrder)
+		if settled {
+			e.journalFinish(entry.ID)
+		}
+	}
+	return results
+}
+
+// resumeEntry settles one interrupted arbitrage under its original execution
+// ID; settled is false when the venue couldn't say what its orders did
+func (e *Engine) resumeEntry(entry journal.Entry) (executedOrder types.ExecutedOrder, settled bool) {
+	opportunity := RealTimeOpportunity{
+		Currency:    entry.Currency,
+		BuyMarket:   entry.BuyMarket,
+		SellMarket:  entry.SellMarket,
+		ExecutionID: entry.ExecutionID,
+	}
+	opportunity.Opportunity.TargetCurrency = entry.Currency
+	opportunity.Opportunity.BuyMarket.Symbol, opportunity.Opportunity.BuyMarket.BaseCurrency = entry.BuyMarket, entry.BuyQuote
+	opportunity.Opportunity.SellMarket.Symbol, opportunity.Opportunity.SellMarket.Pair = entry.SellMarket, entry.SellPair
+	opportunity.Opportunity.SellMarket.BaseCurrency = entry.SellQuote
+
+	executedOrder = types.ExecutedOrder{
+		ExecutionID: entry.ExecutionID,
+		OrderNumber: 1,
+		Currency:    entry.Currency,
+		BuyMarket:   entry.BuyMarket,
+		SellMarket:  entry.SellMarket,
+		StartTime:   time.Now(),
+	}
+	defer func() {
+		executedOrder.EndTime = time.Now()
+		executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+	}()
+
+	// A create that was sent but never returned left only its client order ID
+	buyOrderIDs, err := e.journaledOrders(opportunity, entry.BuyOrderIDs, entry.BuyClientOrderIDs)
+	if err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("buy leg state unknown: %v", err)
+		return executedOrder, false
+	}
+	sellOrderIDs, err := e.journaledOrders(opportunity, entry.SellOrderIDs, entry.SellClientOrderIDs)
+	if err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("sell leg state unknown: %v", err)
+		return executedOrder, false
+	}
+
+	if len(buyOrderIDs) == 0 {
+		executedOrder.ErrorMessage = "interrupted before a buy order reached the exchange"
+		opportunity.log().Info("⏭️ Nothing to resume", "currency", entry.Currency, "reason", executedOrder.ErrorMessage)
+		return executedOrder, true
+	}
+
+	buy, err := e.settleOrders(opportunity, buyOrderIDs)
+	if err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("buy leg state unknown: %v", err)
+		return executedOrder, false
+	}
+	sell, err := e.settleOrders(opportunity, sellOrderIDs)
+	if err != nil {
+		executedOrder.ErrorMessage = fmt.Sprintf("sell leg state unknown: %v", err)
+		return executedOrder, false
+	}
+
+	executedOrder.BuyOrderID = buyOrderIDs[0]
+	if buy.volume <= 0 {
+		executedOrder.ErrorMessage = "buy not filled"
+		return executedOrder, true
+	}
+	bought, buyPrice := buy.volume, buy.avgPrice()
+	executedOrder.VolumeExecuted = bought
+	executedOrder.BuyPrice = buyPrice
+
+	if e.roundQuantity(entry.SellMarket, bought-sell.volume) <= bought*1e-9 {
+		e.settleSell(opportunity, &executedOrder, bought, buyPrice, buy.fee, sell, nil)
+		return executedOrder, true
+	}
+
+	if err := e.sellStillCovers(entry, buy); err != nil {
+		e.recoverUnsold(opportunity, &executedOrder, bought, buyPrice, buy.fee,
+			soldOn(opportunity, sell.volume, sell.value, sell.fee), fmt.Sprintf("resumed after restart: %v", err))
+		return executedOrder, true
+	}
+
+	opportunity.log().Info("▶️ Resuming the sell leg", "currency", entry.Currency, "market", entry.SellMarket,
+		"remaining", bought-sell.volume)
+	err = e.sellRestAtMarket(opportunity, bought, &sell)
+	e.settleSell(opportunity, &executedOrder, bought, buyPrice, buy.fee, sell, err)
+	return executedOrder, true
+}
+
+// journaledOrders adds the orders found by a leg's journaled client order
+// IDs to the order IDs its creates returned. A client order ID the venue has
+// no order for never reached the exchange; one it can't look up leaves the
+// leg's state unknown.
+func (e *Engine) journaledOrders(opportunity RealTimeOpportunity, orderIDs, clientOrderIDs []string) ([]string, error) {
+	found := append([]string(nil), orderIDs...)
+	for _, clientOrderID := range clientOrderIDs {
+		order, ok, err := executor.FindOrder(e.venueFor(opportunity), clientOrderID)
+		if err != nil {
+			return nil, fmt.Errorf("client order %s: %v", clientOrderID, err)
+		}
+		if ok && !slices.Contains(found, order.ID) {
+			found = append(found, order.ID)
+		}
+	}
+	return found, nil
+}
+
+// settleOrders brings each order to a final state, cancelling any still
+// working, and totals what they filled
+func (e *Engine) settleOrders(opportunity RealTimeOpportunity, orderIDs []string) (limitFill, error) {
+	fill := limitFill{}
+	for _, orderID := range orderIDs {
+		final, err := executor.WaitOrCancel(e.venueFor(opportunity), &coindcx.Order{ID: orderID}, 0)
+		if final != nil && err == nil {
+			fill.orderIDs = append(fill.orderIDs, orderID)
+			fill.add(final)
+			continue
+		}
+		if err == nil {
+			err = fmt.Errorf("order %s state unknown", orderID)
+		}
+		return fill, err
+	}
+	return fill, nil
+}
+
+// sellStillCovers errors unless the sell market's best bid, after the sell
+// fee, still pays back what the buy cost per coin
+func (e *Engine) sellStillCovers(entry journal.Entry, buy limitFill) error {
+	book, err := e.fetcher.GetOrderBook(entry.SellPair)
+	if err != nil {
+		return fmt.Errorf("sell market book unavailable: %v", err)
+	}
+	bid, ok := book.BestBid()
+	if !ok {
+		return fmt.Errorf("no bids on %s", entry.SellMarket)
+	}
+	buyRate, sellRate, err := e.rateManager.NormalizePrices(1, entry.BuyQuote, 1, entry.SellQuote)
+	if err != nil {
+		return err
+	}
+
+	costINR := (buy.value + buy.fee) / buy.volume * buyRate
+	proceedsINR := bid.Price * sellRate * (1 - e.feeRate(entry.SellMarket))
+	if proceedsINR < costINR {
+		return fmt.Errorf("%s bid nets ₹%.4f, below the ₹%.4f each coin cost", entry.SellMarket, proceedsINR, costINR)
+	}
+	return nil
+}
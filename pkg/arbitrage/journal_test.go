@@ -0,0 +1,87 @@
+package arbitrage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/b-thark/cdcx-api/internal/journal"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+func TestResumeJournalSellsInterruptedBuy(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+
+	// A run that died after its buy filled, before placing the sell
+	opportunity := limitOpportunity(1000, 1.00, 90.0)
+	id := engine.journalBegin(opportunity)
+	buy, err := venue.CreateOrder(coindcx.OrderRequest{Side: "buy", OrderType: "market_order", Market: "XYZUSDT", TotalQuantity: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.journalOrder(id, "buy", buy.ID)
+	engine.journalBought(id, 1000, 1.00, 1)
+	if err := engine.journal.Advance(id, journal.Buying, nil); err == nil {
+		t.Error("a bought entry moved back to buying")
+	}
+
+	// The next process only has what was persisted
+	engine.journal = journal.NewJournal("execution_journal.json")
+	entries, err := engine.journal.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].State != journal.Bought {
+		t.Fatalf("journal = %+v, want one bought entry", entries)
+	}
+
+	// ₹85.085 a coin with the buy fee; the 90 bid nets ₹88.2 after the sell fee
+	results := engine.ResumeJournal()
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("resume = %+v, want one successful sell", results)
+	}
+	if results[0].VolumeExecuted != 1000 || math.Abs(results[0].SellPrice-90) > 1e-9 {
+		t.Errorf("resumed %g sold at %g, want 1000 at 90", results[0].VolumeExecuted, results[0].SellPrice)
+	}
+	if left := engine.journal.Entries(); len(left) != 0 {
+		t.Errorf("journal still holds %+v", left)
+	}
+}
+
+func TestResumeJournalFindsOrderByClientOrderID(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+
+	// A run that died after sending its buy, before the create returned
+	opportunity := limitOpportunity(1000, 1.00, 90.0)
+	opportunity.journalID = engine.journalBegin(opportunity)
+	clientOrderID := coindcx.NewClientOrderID()
+	engine.journalAdvance(opportunity.journalID, "buy", func(orderIDs, clientOrderIDs *[]string) {
+		*clientOrderIDs = append(*clientOrderIDs, clientOrderID)
+	})
+	if _, err := venue.CreateOrder(coindcx.OrderRequest{Side: "buy", OrderType: "market_order", Market: "XYZUSDT",
+		TotalQuantity: 1000, ClientOrderID: clientOrderID}); err != nil {
+		t.Fatal(err)
+	}
+
+	engine.journal = journal.NewJournal("execution_journal.json")
+	entries, err := engine.journal.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].BuyOrderIDs) != 0 || len(entries[0].BuyClientOrderIDs) != 1 {
+		t.Fatalf("journal = %+v, want one entry with only a client order ID", entries)
+	}
+
+	results := engine.ResumeJournal()
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("resume = %+v, want the found buy sold", results)
+	}
+	if results[0].VolumeExecuted != 1000 {
+		t.Errorf("resumed %g, want 1000", results[0].VolumeExecuted)
+	}
+	if results[0].EndTime.IsZero() {
+		t.Error("resumed order has no end time")
+	}
+	if left := engine.journal.Entries(); len(left) != 0 {
+		t.Errorf("journal still holds %+v", left)
+	}
+}
@@ -0,0 +1,75 @@
+package arbitrage
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// latencyPhases lists execution phases in the order they happen
+var latencyPhases = []string{"validation", "buy_place", "buy_fill", "sell_place", "sell_fill"}
+
+// SummarizeLatency aggregates per-phase latency percentiles across executed
+// orders. A phase an order never reached (a failed buy has no sell fill)
+// records no time and is left out of that phase's samples.
+func SummarizeLatency(orders []types.ExecutedOrder) map[string]types.LatencyPercentiles {
+	samples := make(map[string][]int64)
+	add := func(phase string, ms int64) {
+		if ms > 0 {
+			samples[phase] = append(samples[phase], ms)
+		}
+	}
+	for _, order := range orders {
+		l := order.Latency
+		add("validation", l.ValidationMs)
+		add("buy_place", l.BuyPlaceMs)
+		add("buy_fill", l.BuyFillMs)
+		add("sell_place", l.SellPlaceMs)
+		add("sell_fill", l.SellFillMs)
+	}
+
+	summary := make(map[string]types.LatencyPercentiles)
+	for phase, values := range samples {
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		summary[phase] = types.LatencyPercentiles{
+			Count: len(values),
+			P50:   percentile(values, 50),
+			P90:   percentile(values, 90),
+			P99:   percentile(values, 99),
+			Max:   values[len(values)-1],
+		}
+	}
+
+	return summary
+}
+
+// percentile uses nearest-rank on already sorted values
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func displayLatencySummary(summary map[string]types.LatencyPercentiles) {
+	if len(summary) == 0 {
+		return
+	}
+
+	fmt.Printf("\n⏱️ Latency by Phase (p50 / p90 / p99 / max ms):\n")
+	for _, phase := range latencyPhases {
+		stats, ok := summary[phase]
+		if !ok {
+			continue
+		}
+		fmt.Printf("   %-11s %6d / %6d / %6d / %6d\n", phase, stats.P50, stats.P90, stats.P99, stats.Max)
+	}
+}
@@ -0,0 +1,22 @@
+package arbitrage
+
+import (
+	"testing"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestSummarizeLatencySkipsPhasesNeverReached(t *testing.T) {
+	orders := []types.ExecutedOrder{
+		{Latency: types.PhaseLatency{ValidationMs: 20, BuyPlaceMs: 40, BuyFillMs: 100, SellPlaceMs: 30, SellFillMs: 200}},
+		{Latency: types.PhaseLatency{ValidationMs: 10, BuyPlaceMs: 50}}, // The buy never filled
+	}
+
+	summary := SummarizeLatency(orders)
+	if got := summary["validation"]; got.Count != 2 || got.P50 != 10 || got.Max != 20 {
+		t.Errorf("validation = %+v, want both samples", got)
+	}
+	if got := summary["sell_fill"]; got.Count != 1 || got.P50 != 200 {
+		t.Errorf("sell_fill = %+v, want only the order that reached it", got)
+	}
+}
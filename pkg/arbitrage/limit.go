@@ -0,0 +1,205 @@
+package arbitrage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// limitFill is what one leg's limit orders filled between them
+type limitFill struct {
+	orderIDs []string
+	volume   float64
+	value    float64 // Σ quantity × price, for the average
+	fee      float64
+}
+
+// add counts what order filled
+func (f *limitFill) add(order *coindcx.Order) {
+	if filled := order.TotalQuantity - order.RemainingQuantity; filled > 0 {
+		f.volume += filled
+		f.value += filled * order.AvgPrice
+		f.fee += order.FeeAmount
+	}
+}
+
+func (f limitFill) avgPrice() float64 {
+	if f.volume <= 0 {
+		return 0
+	}
+	return f.value / f.volume
+}
+
+// executeLimitOrder buys then sells with limit orders at the validated level
+// prices instead of market orders, so a thin book can't fill either leg
+// worse than MaxRepricePct past what was validated. A partly filled buy
+// sells what it got; what the sell leg leaves goes through recovery.
+func (e *Engine) executeLimitOrder(opportunity RealTimeOpportunity, executedOrder types.ExecutedOrder) types.ExecutedOrder {
+	opportunity.log().Info("📌 LIMIT", "currency", opportunity.Currency, "volume", opportunity.Volume,
+		"buy_price", opportunity.BuyPrice, "sell_price", opportunity.SellPrice)
+
+	phaseStart := time.Now()
+	buy, err := e.workLimitLeg(opportunity, "buy", opportunity.BuyMarket, opportunity.Opportunity.BuyMarket.Pair, opportunity.Volume, opportunity.BuyPrice)
+	executedOrder.Latency.BuyFillMs = time.Since(phaseStart).Milliseconds()
+	executedOrder.LimitOrderIDs = append(executedOrder.LimitOrderIDs, buy.orderIDs...)
+	if len(buy.orderIDs) > 0 {
+		executedOrder.BuyOrderID = buy.orderIDs[0]
+	}
+	if buy.volume <= 0 {
+		executedOrder.ErrorMessage = "buy not filled"
+		if err != nil {
+			executedOrder.ErrorMessage = fmt.Sprintf("buy not filled: %v", err)
+		}
+		executedOrder.EndTime = time.Now()
+		return executedOrder
+	}
+	if err != nil {
+		opportunity.log().Warn("✂️ Bought part", "currency", opportunity.Currency, "bought", buy.volume,
+			"volume", opportunity.Volume, "error", err)
+	}
+
+	bought, buyPrice := buy.volume, buy.avgPrice()
+	stopID := e.placeProtectiveStop(opportunity, bought, buyPrice)
+	executedOrder.VolumeExecuted = bought
+	executedOrder.BuyPrice = buyPrice
+	e.journalBought(opportunity.journalID, bought, buyPrice, buy.fee)
+
+	if !e.releaseStop(opportunity, &executedOrder, stopID, bought, buyPrice, buy.fee) {
+		executedOrder.EndTime = time.Now()
+		executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+		return executedOrder
+	}
+
+	phaseStart = time.Now()
+	sell, err := e.workLimitLeg(opportunity, "sell", opportunity.SellMarket, opportunity.Opportunity.SellMarket.Pair, bought, opportunity.SellPrice)
+	executedOrder.Latency.SellFillMs = time.Since(phaseStart).Milliseconds()
+	executedOrder.LimitOrderIDs = append(executedOrder.LimitOrderIDs, sell.orderIDs...)
+	if len(sell.orderIDs) > 0 {
+		executedOrder.SellOrderID = sell.orderIDs[0]
+	}
+
+	if err == nil {
+		executedOrder.SellPrice = sell.avgPrice()
+		e.settleProfit(opportunity, &executedOrder, bought, buyPrice, buy.fee,
+			soldOn(opportunity, bought, sell.value, sell.fee))
+	} else {
+		e.recoverUnsold(opportunity, &executedOrder, bought, buyPrice, buy.fee,
+			soldOn(opportunity, sell.volume, sell.value, sell.fee), fmt.Sprintf("sell leg failed: %v", err))
+	}
+
+	executedOrder.EndTime = time.Now()
+	executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+	return executedOrder
+}
+
+// workLimitLeg trades quantity on symbol with limit orders starting at
+// price. Each order works for LimitOrderTimeoutSeconds (or takes what the
+// level holds at once under immediate_or_cancel); the remainder is then
+// re-placed at the current best level of pair's book, up to MaxReprices
+// times. An error says why the leg stopped short of quantity; the fill
+// returned covers every order it placed, each recorded in opportunity's
+// journal entry.
+func (e *Engine) workLimitLeg(opportunity RealTimeOpportunity, side, symbol, pair string, quantity, price float64) (limitFill, error) {
+	venue := e.venueFor(opportunity)
+	fill := limitFill{}
+	limit := price
+	for reprices := 0; ; reprices++ {
+		// What float error or the market's precision leaves over is not worth an order
+		remaining := e.roundQuantity(symbol, quantity-fill.volume)
+		if remaining <= quantity*1e-9 {
+			return fill, nil
+		}
+
+		order, err := e.placeOrder(opportunity, coindcx.OrderRequest{
+			Side:          side,
+			OrderType:     "limit_order",
+			Market:        symbol,
+			TotalQuantity: remaining,
+			PricePerUnit:  limit,
+			TimeInForce:   e.config.LimitTimeInForce,
+		})
+		if err != nil {
+			return fill, fmt.Errorf("%s order failed: %v", side, err)
+		}
+		fill.orderIDs = append(fill.orderIDs, order.ID)
+		e.publish(opportunity, events.NewOrderPlaced(order.ID, symbol, side, remaining))
+
+		final, err := executor.WaitOrCancel(venue, order, e.limitOrderTimeout())
+		if final == nil {
+			return fill, err
+		}
+		fill.add(final)
+		if filled := final.TotalQuantity - final.RemainingQuantity; filled > 0 {
+			e.publish(opportunity, events.NewOrderFilled(order.ID, symbol, side, filled, final.AvgPrice, final.FeeAmount))
+		}
+		if err != nil {
+			// Repricing while this order may still fill could trade twice
+			return fill, err
+		}
+		if final.Status == "filled" {
+			return fill, nil
+		}
+
+		if reprices >= e.config.MaxReprices {
+			return fill, fmt.Errorf("%s unfilled after %d reprice(s)",
+				market.FormatQuantity(symbol, quantity-fill.volume), reprices)
+		}
+		next, err := e.repricedLimit(side, pair, price)
+		if err != nil {
+			return fill, err
+		}
+		opportunity.log().Info("🔁 Repricing", "side", side, "market", symbol, "remaining", quantity-fill.volume,
+			"price", next, "was", limit)
+		limit = next
+	}
+}
+
+// repricedLimit is the best level on the side of pair's book an order to
+// side takes from, or an error when it has moved more than MaxRepricePct
+// against validated
+func (e *Engine) repricedLimit(side, pair string, validated float64) (float64, error) {
+	book, err := e.fetcher.GetOrderBook(pair)
+	if err != nil {
+		return 0, fmt.Errorf("reprice: %v", err)
+	}
+
+	bookSide := "asks"
+	if side == "sell" {
+		bookSide = "bids"
+	}
+	best, ok := book.Best(bookSide)
+	if !ok {
+		return 0, fmt.Errorf("reprice: no %s on %s", bookSide, pair)
+	}
+
+	// Positive when the price moved against the order: asks up for a buy, bids down for a sell
+	movedPct := (best.Price - validated) / validated * 100
+	if side == "sell" {
+		movedPct = -movedPct
+	}
+	if movedPct > e.config.MaxRepricePct {
+		return 0, fmt.Errorf("%s price moved %.2f%% from %g, past the %.2f%% protection",
+			side, movedPct, validated, e.config.MaxRepricePct)
+	}
+	return best.Price, nil
+}
+
+// roundQuantity rounds down to the market's quantity precision when known
+func (e *Engine) roundQuantity(symbol string, quantity float64) float64 {
+	if planner := e.routePlanner(); planner != nil {
+		if detail, ok := planner.Market(symbol); ok {
+			return utils.RoundToPrecision(quantity, detail.TargetCurrencyPrecision, utils.RoundDown)
+		}
+	}
+	return quantity
+}
+
+func (e *Engine) limitOrderTimeout() time.Duration {
+	return time.Duration(e.config.LimitOrderTimeoutSeconds) * time.Second
+}
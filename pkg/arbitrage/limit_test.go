@@ -0,0 +1,88 @@
+package arbitrage
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func limitOpportunity(volume, buyPrice, sellPrice float64) RealTimeOpportunity {
+	return RealTimeOpportunity{
+		Currency:       "XYZ",
+		BuyMarket:      "XYZUSDT",
+		SellMarket:     "XYZINR",
+		BuyPrice:       buyPrice,
+		SellPrice:      sellPrice,
+		Volume:         volume,
+		ExpectedMargin: 0.01,
+		Opportunity:    fakeOpportunity(),
+		ValidatedAt:    time.Now(),
+	}
+}
+
+func TestLimitLegsRepricePartialFills(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.UseMarketOrders = false
+
+	// Each best level holds 20000, so both legs fill in two orders at unchanged prices
+	order := engine.executeRealTimeOrder(limitOpportunity(25000, 1.00, 90.0))
+	if !order.Success {
+		t.Fatalf("execution failed: %s", order.ErrorMessage)
+	}
+	if order.VolumeExecuted != 25000 {
+		t.Errorf("executed %.2f, want 25000", order.VolumeExecuted)
+	}
+	if len(order.LimitOrderIDs) != 4 {
+		t.Errorf("placed %d limit orders, want 2 per leg: %v", len(order.LimitOrderIDs), order.LimitOrderIDs)
+	}
+	if math.Abs(order.BuyPrice-1.00) > 1e-9 || math.Abs(order.SellPrice-90.0) > 1e-9 {
+		t.Errorf("filled at %g → %g, want the limit prices 1 → 90", order.BuyPrice, order.SellPrice)
+	}
+}
+
+func TestLimitLegRepriceStopsAtProtection(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+	engine.config.UseMarketOrders = false
+	engine.config.MaxRepricePct = 0.3
+
+	// Validated at 0.99, but the best ask is 1.00: 1% away, past the protection
+	order := engine.executeRealTimeOrder(limitOpportunity(1000, 0.99, 90.0))
+	if order.Success || !strings.Contains(order.ErrorMessage, "protection") {
+		t.Fatalf("want the buy stopped by price protection, got success=%v %q", order.Success, order.ErrorMessage)
+	}
+	if len(order.LimitOrderIDs) != 1 {
+		t.Errorf("placed %d limit orders, want only the unfilled first one", len(order.LimitOrderIDs))
+	}
+
+	balances, err := venue.GetBalances()
+	if err != nil {
+		t.Fatalf("GetBalances: %v", err)
+	}
+	for _, balance := range balances {
+		if balance.Currency == "XYZ" && balance.Balance > 0 {
+			t.Errorf("bought %.2f XYZ above the protected price", balance.Balance)
+		}
+	}
+}
+
+func TestSettledProfitIsInINR(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.UseMarketOrders = false
+
+	// 100 XYZ bought for 100 USDT (₹8500) and sold for ₹9000, 0.1% fee each side
+	order := engine.executeRealTimeOrder(limitOpportunity(100, 1.00, 90.0))
+	if !order.Success {
+		t.Fatalf("execution failed: %s", order.ErrorMessage)
+	}
+	want := 9000*0.999 - 8500*1.001
+	if math.Abs(order.ActualProfit-want) > 1e-6 {
+		t.Errorf("profit ₹%.2f, want ₹%.2f", order.ActualProfit, want)
+	}
+	if math.Abs(order.ActualMarginPct-want/8500*100) > 1e-6 {
+		t.Errorf("margin %.4f%%, want %.4f%%", order.ActualMarginPct, want/8500*100)
+	}
+	if order.Attribution == nil || math.Abs(order.Attribution.Realized-want) > 1e-6 {
+		t.Errorf("attribution %+v, want ₹%.2f realized", order.Attribution, want)
+	}
+}
@@ -0,0 +1,149 @@
+package arbitrage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// sellLockedIn sells the bought volume with a limit order at the price that
+// still nets LockInMarginPct, so bids above it fill as a market order would
+// and the rest waits LockInSeconds for the book to come back instead of
+// walking down it. What is left then sells at market; what that leaves goes
+// through recovery. It settles executedOrder.
+func (e *Engine) sellLockedIn(opportunity RealTimeOpportunity, executedOrder *types.ExecutedOrder, bought, buyPrice, buyFee float64) {
+	phaseStart := time.Now()
+	sell, err := e.workLockIn(opportunity, bought, buyPrice, buyFee)
+	executedOrder.Latency.SellFillMs = time.Since(phaseStart).Milliseconds()
+	e.settleSell(opportunity, executedOrder, bought, buyPrice, buyFee, sell, err)
+}
+
+// settleSell settles executedOrder from the sell leg's fill, sending what
+// it left through recovery when err says it stopped short
+func (e *Engine) settleSell(opportunity RealTimeOpportunity, executedOrder *types.ExecutedOrder, bought, buyPrice, buyFee float64, sell limitFill, err error) {
+	if len(sell.orderIDs) > 0 {
+		executedOrder.SellOrderID = sell.orderIDs[0]
+	}
+
+	if err != nil {
+		e.recoverUnsold(opportunity, executedOrder, bought, buyPrice, buyFee,
+			soldOn(opportunity, sell.volume, sell.value, sell.fee), fmt.Sprintf("sell leg failed: %v", err))
+		return
+	}
+
+	executedOrder.SellPrice = sell.avgPrice()
+	e.settleProfit(opportunity, executedOrder, bought, buyPrice, buyFee,
+		soldOn(opportunity, bought, sell.value, sell.fee))
+}
+
+// workLockIn places the lock-in limit sell, then market-sells its remainder.
+// Without the INR rates to price the lock-in it goes straight to market. An
+// error says why the leg stopped short of quantity.
+func (e *Engine) workLockIn(opportunity RealTimeOpportunity, quantity, buyPrice, buyFee float64) (limitFill, error) {
+	venue := e.venueFor(opportunity)
+	fill := limitFill{}
+	symbol := opportunity.SellMarket
+
+	if price, ok := e.lockInPrice(opportunity, quantity, buyPrice, buyFee); ok {
+		opportunity.log().Info("🔒 Lock-in sell", "currency", opportunity.Currency, "volume", quantity,
+			"price", price, "margin_pct", e.config.LockInMarginPct, "seconds", e.config.LockInSeconds)
+
+		order, err := e.placeOrder(opportunity, coindcx.OrderRequest{
+			Side:          "sell",
+			OrderType:     "limit_order",
+			Market:        symbol,
+			TotalQuantity: quantity,
+			PricePerUnit:  price,
+			TimeInForce:   coindcx.TimeInForceGTC,
+		})
+		if err != nil {
+			return fill, fmt.Errorf("lock-in sell failed: %v", err)
+		}
+		fill.orderIDs = append(fill.orderIDs, order.ID)
+		e.publish(opportunity, events.NewOrderPlaced(order.ID, symbol, "sell", quantity))
+
+		final, err := executor.WaitOrCancel(venue, order, e.lockInTimeout())
+		if final != nil {
+			fill.add(final)
+			if filled := final.TotalQuantity - final.RemainingQuantity; filled > 0 {
+				e.publish(opportunity, events.NewOrderFilled(order.ID, symbol, "sell", filled, final.AvgPrice, final.FeeAmount))
+			}
+		}
+		if err != nil {
+			// Selling at market while the limit may still fill could sell twice
+			return fill, err
+		}
+	}
+
+	if len(fill.orderIDs) > 0 && e.roundQuantity(symbol, quantity-fill.volume) > quantity*1e-9 {
+		opportunity.log().Info("⏱️ Lock-in expired, selling the rest at market", "currency", opportunity.Currency,
+			"sold", fill.volume, "remaining", quantity-fill.volume)
+	}
+	return fill, e.sellRestAtMarket(opportunity, quantity, &fill)
+}
+
+// sellRestAtMarket market-sells what fill hasn't of quantity on the
+// opportunity's sell market, adding the order to fill
+func (e *Engine) sellRestAtMarket(opportunity RealTimeOpportunity, quantity float64, fill *limitFill) error {
+	venue := e.venueFor(opportunity)
+	symbol := opportunity.SellMarket
+	remaining := e.roundQuantity(symbol, quantity-fill.volume)
+	if remaining <= quantity*1e-9 {
+		return nil
+	}
+
+	order, err := e.placeOrder(opportunity, coindcx.OrderRequest{
+		Side:          "sell",
+		OrderType:     "market_order",
+		Market:        symbol,
+		TotalQuantity: remaining,
+	})
+	if err != nil {
+		return fmt.Errorf("sell order failed: %v", err)
+	}
+	fill.orderIDs = append(fill.orderIDs, order.ID)
+	e.publish(opportunity, events.NewOrderPlaced(order.ID, symbol, "sell", remaining))
+
+	final, err := executor.WaitForFill(venue, order.ID, e.orderTimeout())
+	if err != nil {
+		if final != nil {
+			fill.add(final)
+		}
+		return err
+	}
+	fill.add(final)
+	e.publish(opportunity, events.NewOrderFilled(order.ID, symbol, "sell", remaining, final.AvgPrice, final.FeeAmount))
+	return nil
+}
+
+// lockInPrice is the sell market price at which selling quantity, bought at
+// buyPrice for buyFee, nets LockInMarginPct after the sell fee, rounded up
+// to the market's price precision. Both legs are compared in INR at the
+// decision's rates; ok is false when the opportunity lacks them.
+func (e *Engine) lockInPrice(opportunity RealTimeOpportunity, quantity, buyPrice, buyFee float64) (float64, bool) {
+	sellRate := decisionSellRate(opportunity)
+	if opportunity.BuyPrice <= 0 || opportunity.BuyPriceINR <= 0 || sellRate <= 0 || quantity <= 0 {
+		return 0, false
+	}
+	buyRate := opportunity.BuyPriceINR / opportunity.BuyPrice
+	sellFeeRate := e.feeRate(opportunity.SellMarket)
+
+	costINR := (quantity*buyPrice + buyFee) * buyRate
+	price := costINR * (1 + e.config.LockInMarginPct/100) / (quantity * sellRate * (1 - sellFeeRate))
+
+	if planner := e.routePlanner(); planner != nil {
+		if detail, ok := planner.Market(opportunity.SellMarket); ok {
+			price = utils.RoundToPrecision(price, detail.BaseCurrencyPrecision, utils.RoundUp)
+		}
+	}
+	return price, true
+}
+
+func (e *Engine) lockInTimeout() time.Duration {
+	return time.Duration(e.config.LockInSeconds) * time.Second
+}
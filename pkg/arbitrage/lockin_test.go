@@ -0,0 +1,35 @@
+package arbitrage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLockInSellFallsBackToMarket(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+	engine.config.LockInSeconds = 1
+	engine.config.LockInMarginPct = 3.2
+
+	// The buy walks to ₹85.255 a coin with its fee; netting 3.2% after the
+	// assumed 2% sell fee needs ₹89.78, so the 90 bids fill and the 89.5
+	// ones are left to the market sell
+	opportunity := limitOpportunity(25000, 1.00, 90.0)
+	opportunity.BuyPriceINR, opportunity.SellPriceINR = 85, 90
+	order := engine.executeRealTimeOrder(opportunity)
+	if !order.Success {
+		t.Fatalf("execution failed: %s", order.ErrorMessage)
+	}
+	// The simulated book isn't depleted by fills, so the market sell gets 90 too
+	if math.Abs(order.SellPrice-90) > 1e-9 {
+		t.Errorf("sold at %g, want 90", order.SellPrice)
+	}
+
+	lockIn, err := venue.GetOrderStatus(order.SellOrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockIn.OrderType != "limit_order" || lockIn.PricePerUnit != 89.78 || lockIn.RemainingQuantity != 5000 {
+		t.Errorf("lock-in order = %s at %g with %g left, want a limit at 89.78 with 5000 left",
+			lockIn.OrderType, lockIn.PricePerUnit, lockIn.RemainingQuantity)
+	}
+}
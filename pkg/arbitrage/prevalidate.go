@@ -0,0 +1,125 @@
+package arbitrage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/audit"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// ExecutionLock is the lock queued executions take turns on, through
+// PrevalidateUntilLocked, so only one trades at a time
+func (e *Engine) ExecutionLock() sync.Locker {
+	return &e.executing
+}
+
+// PrevalidateUntilLocked keeps re-validating queued opportunities in the
+// background while another execution holds lock, so the caller starts with
+// fresh market data the moment capital frees up. The lock is held when this
+// returns; the caller must unlock it.
+func (e *Engine) PrevalidateUntilLocked(lock sync.Locker, opportunities []types.ArbitrageOpportunity) []RealTimeOpportunity {
+	validated, _ := e.PrevalidateUntilLockedContext(context.Background(), lock, opportunities)
+	return validated
+}
+
+// PrevalidateUntilLockedContext is PrevalidateUntilLocked, giving up the
+// wait when ctx is done first. It then returns ctx's error without holding
+// lock, which is released as soon as the abandoned wait acquires it.
+func (e *Engine) PrevalidateUntilLockedContext(ctx context.Context, lock sync.Locker, opportunities []types.ArbitrageOpportunity) ([]RealTimeOpportunity, error) {
+	acquired := make(chan struct{})
+	go func() {
+		lock.Lock()
+		close(acquired)
+	}()
+	abandon := func() error {
+		go func() {
+			<-acquired
+			lock.Unlock()
+		}()
+		return ctx.Err()
+	}
+
+	interval := time.Duration(e.config.PrevalidationIntervalMs) * time.Millisecond
+	validated := make([]RealTimeOpportunity, len(opportunities))
+
+	for {
+		select {
+		case <-acquired:
+			return e.refreshStale(opportunities, validated), nil
+		case <-ctx.Done():
+			return nil, abandon()
+		default:
+		}
+
+		for i, opp := range opportunities {
+			validated[i] = e.validateTimed(opp)
+		}
+
+		select {
+		case <-acquired:
+			return e.refreshStale(opportunities, validated), nil
+		case <-ctx.Done():
+			return nil, abandon()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// RefreshIfStale re-validates an opportunity whose last validation is older
+// than the configured maximum age
+func (e *Engine) RefreshIfStale(liveOpp RealTimeOpportunity) RealTimeOpportunity {
+	maxAge := time.Duration(e.config.MaxValidationAgeMs) * time.Millisecond
+	if liveOpp.ValidatedAt.IsZero() || time.Since(liveOpp.ValidatedAt) > maxAge {
+		return e.validateTimed(liveOpp.Opportunity)
+	}
+	return liveOpp
+}
+
+func (e *Engine) refreshStale(opportunities []types.ArbitrageOpportunity, validated []RealTimeOpportunity) []RealTimeOpportunity {
+	for i := range validated {
+		if validated[i].ValidatedAt.IsZero() {
+			validated[i].Opportunity = opportunities[i]
+		}
+		validated[i] = e.RefreshIfStale(validated[i])
+	}
+	return validated
+}
+
+func (e *Engine) validateTimed(opp types.ArbitrageOpportunity) RealTimeOpportunity {
+	e.recordDetection(opp)
+
+	start := time.Now()
+	liveOpp := e.analyzeAndValidateRealTime(opp)
+	liveOpp.ValidationMs = time.Since(start).Milliseconds()
+	liveOpp.ValidatedAt = time.Now()
+
+	e.recordValidation(liveOpp)
+	return liveOpp
+}
+
+// recordDetection snapshots an opportunity in the audit trail as it reaches the engine
+func (e *Engine) recordDetection(opp types.ArbitrageOpportunity) {
+	summary := fmt.Sprintf("%s → %s at %.2f%% net", opp.BuyMarket.Symbol, opp.SellMarket.Symbol, opp.NetMarginPct)
+	if !opp.Timestamp.IsZero() {
+		summary += fmt.Sprintf(", detected %s ago", time.Since(opp.Timestamp).Round(time.Millisecond))
+	}
+	e.audit.Record(audit.Entry{Kind: audit.KindDetection, Currency: opp.TargetCurrency, Summary: summary}, opp)
+}
+
+// recordValidation records the live re-check of an opportunity in the audit trail
+func (e *Engine) recordValidation(liveOpp RealTimeOpportunity) {
+	summary := fmt.Sprintf("rejected: %s (%dms)", liveOpp.Reason, liveOpp.ValidationMs)
+	if liveOpp.Viable {
+		summary = fmt.Sprintf("viable: buy %.8f, sell %.8f, %.2f%% net, volume %.6f (%dms)",
+			liveOpp.BuyPrice, liveOpp.SellPrice, liveOpp.MarginPct, liveOpp.Volume, liveOpp.ValidationMs)
+	}
+	e.audit.Record(audit.Entry{
+		Time:     liveOpp.ValidatedAt,
+		Kind:     audit.KindValidation,
+		Currency: liveOpp.Currency,
+		Summary:  summary,
+	}, liveOpp)
+}
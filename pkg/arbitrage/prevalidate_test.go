@@ -0,0 +1,43 @@
+package arbitrage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestPrevalidateUntilLockedContextGivesUpOnCancel(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+
+	// Another execution is trading
+	var lock sync.Mutex
+	lock.Lock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	validated, err := engine.PrevalidateUntilLockedContext(ctx, &lock, []types.ArbitrageOpportunity{fakeOpportunity()})
+	if err != context.Canceled || validated != nil {
+		t.Fatalf("PrevalidateUntilLockedContext = %v, %v; want a cancelled wait", validated, err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("gave up after %s", waited)
+	}
+
+	// The abandoned wait hands the lock back once the trade releases it
+	lock.Unlock()
+	acquired := make(chan struct{})
+	go func() {
+		lock.Lock()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("the abandoned wait kept the lock")
+	}
+}
@@ -0,0 +1,100 @@
+package arbitrage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+)
+
+// TradePreview is what an execution is about to place, shown for
+// confirmation once sizing against the spendable balance is final
+type TradePreview struct {
+	Currency     string
+	BuyQuote     string // Currency the buy leg is paid in
+	SellQuote    string // Currency the sell leg is paid in
+	BuyMarket    string
+	SellMarket   string
+	Volume       float64
+	BuyPrice     float64 // Expected average fill, walking the book
+	SellPrice    float64
+	Policy       string // sequential or atomic
+	Sliced       bool   // Split into timed slices, each re-validated
+	MarginPct    float64
+	BuyCostINR   float64
+	ExpectedINR  float64 // Expected profit
+	WorstCaseINR float64 // Loss if the sell leg fails and the coin is unwound at the stop
+	StopPct      float64
+}
+
+// Confirm decides whether a previewed trade is placed
+type Confirm func(preview TradePreview) bool
+
+// SetConfirm asks confirm before placing every trade; nil places them unasked
+func (e *Engine) SetConfirm(confirm Confirm) {
+	e.confirm = confirm
+}
+
+// preview describes a sized opportunity. The worst case assumes the sell leg
+// fails and the coin is sold at the protective stop (or StopLossPct when no
+// stop is set) below its cost, paying fees on both legs.
+func (e *Engine) preview(opportunity RealTimeOpportunity) TradePreview {
+	buyPrice, sellPrice := opportunity.BuyPrice, opportunity.SellPrice
+	if opportunity.BuyImpact.EffectivePrice > 0 {
+		buyPrice = opportunity.BuyImpact.EffectivePrice
+	}
+	if opportunity.SellImpact.EffectivePrice > 0 {
+		sellPrice = opportunity.SellImpact.EffectivePrice
+	}
+
+	buyCostINR := opportunity.Volume * opportunity.BuyPriceINR
+	if opportunity.BuyPrice > 0 {
+		buyCostINR *= buyPrice / opportunity.BuyPrice
+	}
+
+	stopPct := e.config.ProtectiveStopPct
+	if stopPct <= 0 {
+		stopPct = e.config.StopLossPct
+	}
+	feeRate := e.feeRate(opportunity.BuyMarket)
+
+	policy := e.config.ExecutionPolicy
+	if policy == "" {
+		policy = "sequential"
+	}
+
+	return TradePreview{
+		Currency:     opportunity.Currency,
+		BuyQuote:     opportunity.Opportunity.BuySymbol().Quote,
+		SellQuote:    opportunity.Opportunity.SellSymbol().Quote,
+		BuyMarket:    opportunity.BuyMarket,
+		SellMarket:   opportunity.SellMarket,
+		Volume:       opportunity.Volume,
+		BuyPrice:     buyPrice,
+		SellPrice:    sellPrice,
+		Policy:       policy,
+		Sliced:       e.sliced(opportunity),
+		MarginPct:    opportunity.MarginPct,
+		BuyCostINR:   buyCostINR,
+		ExpectedINR:  opportunity.ExpectedMargin * opportunity.Volume,
+		WorstCaseINR: buyCostINR * (stopPct/100 + 2*feeRate),
+		StopPct:      stopPct,
+	}
+}
+
+// String renders the preview as the terminal prompt shows it
+func (p TradePreview) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 %s %s arbitrage (%s", p.Currency, market.FormatDecimal(p.Volume, -1), p.Policy)
+	if p.Sliced {
+		b.WriteString(", sliced")
+	}
+	b.WriteString(")\n")
+	fmt.Fprintf(&b, "   🟢 BUY  %s %s on %s @ ~%s %s (₹%.2f)\n", market.FormatDecimal(p.Volume, -1), p.Currency,
+		p.BuyMarket, market.FormatDecimal(p.BuyPrice, -1), p.BuyQuote, p.BuyCostINR)
+	fmt.Fprintf(&b, "   🔴 SELL %s %s on %s @ ~%s %s\n", market.FormatDecimal(p.Volume, -1), p.Currency,
+		p.SellMarket, market.FormatDecimal(p.SellPrice, -1), p.SellQuote)
+	fmt.Fprintf(&b, "   💵 Expected: ₹%.2f (%.2f%% margin)\n", p.ExpectedINR, p.MarginPct)
+	fmt.Fprintf(&b, "   ⚠️ Worst case: -₹%.2f (sell leg fails, unwound %.1f%% below cost)", p.WorstCaseINR, p.StopPct)
+	return b.String()
+}
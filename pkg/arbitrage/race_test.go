@@ -0,0 +1,224 @@
+package arbitrage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Run with go test -race: these tests exist to catch unsynchronized state
+// shared between detection goroutines, queued executions and the engine.
+
+var fakeMarkets = []types.MarketDetail{
+	{Symbol: "XYZUSDT", Pair: "B-XYZ_USDT", BaseCurrencyShortName: "USDT", TargetCurrencyShortName: "XYZ",
+		TargetCurrencyPrecision: 2, BaseCurrencyPrecision: 4, OrderTypes: []string{"market_order", "limit_order", "stop_limit"}, Status: "active"},
+	{Symbol: "XYZINR", Pair: "I-XYZ_INR", BaseCurrencyShortName: "INR", TargetCurrencyShortName: "XYZ",
+		TargetCurrencyPrecision: 2, BaseCurrencyPrecision: 2, OrderTypes: []string{"market_order", "limit_order"}, Status: "active"},
+}
+
+// XYZ costs 1 USDT (₹85) on the USDT market and sells for ₹90 on the INR market
+var fakeBooks = map[string]string{
+	"B-XYZ_USDT": `{"bids": {"0.99": "20000"}, "asks": {"1.00": "20000", "1.01": "20000"}}`,
+	"I-XYZ_INR":  `{"bids": {"90.0": "20000", "89.5": "20000"}, "asks": {"91.0": "20000"}}`,
+}
+
+const fakeTicker = `[
+	{"market": "USDTINR", "last_price": "85.0"},
+	{"market": "XYZUSDT", "last_price": "1.0"},
+	{"market": "XYZINR", "last_price": "90.0", "bid": "90.0", "ask": "91.0"}
+]`
+
+// fakeExchange serves market details, order books and tickers for every
+// request the engine's fetcher and rate manager make
+type fakeExchange struct{}
+
+func (fakeExchange) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/markets_details"):
+		data, _ := json.Marshal(fakeMarkets)
+		body = string(data)
+	case strings.HasSuffix(req.URL.Path, "/orderbook"):
+		book, ok := fakeBooks[req.URL.Query().Get("pair")]
+		if !ok {
+			return nil, fmt.Errorf("unknown pair %s", req.URL.Query().Get("pair"))
+		}
+		body = book
+	case strings.HasSuffix(req.URL.Path, "/ticker"):
+		body = fakeTicker
+	default:
+		return nil, fmt.Errorf("unexpected request %s", req.URL)
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+// newRaceEngine builds an engine trading against a simulated venue backed by
+// the fake exchange, with its state files in a temporary directory
+func newRaceEngine(t *testing.T) (*Engine, *executor.SimulatedExecutor) {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+	transport := http.DefaultTransport
+	http.DefaultTransport = fakeExchange{}
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	execConfig := types.DefaultExecutionConfig()
+	execConfig.StopLossPct = 1.0
+	execConfig.DelayBetweenOrders = 0
+	execConfig.PrevalidationIntervalMs = 5
+	execConfig.MaxValidationAgeMs = 50
+	execConfig.OrderTimeoutSeconds = 5
+
+	venue := executor.NewSimulatedExecutor(market.NewFetcher(), fakeMarkets, 0.001, map[string]float64{"USDT": 1000000})
+	return NewEngineWithVenue(&config.Config{}, execConfig, venue), venue
+}
+
+func fakeOpportunity() types.ArbitrageOpportunity {
+	opp := types.ArbitrageOpportunity{TargetCurrency: "XYZ", Viable: true, Timestamp: time.Now()}
+	opp.BuyMarket.Symbol, opp.BuyMarket.Pair, opp.BuyMarket.BaseCurrency = "XYZUSDT", "B-XYZ_USDT", "USDT"
+	opp.SellMarket.Symbol, opp.SellMarket.Pair, opp.SellMarket.BaseCurrency = "XYZINR", "I-XYZ_INR", "INR"
+	return opp
+}
+
+func TestConcurrentQueuedExecutions(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+
+	const executions = 6
+	var executionMutex sync.Mutex
+	var wg sync.WaitGroup
+	results := make([]*types.ExecutionResult, executions)
+
+	// Queued executions, each prevalidating while another holds the lock
+	for i := 0; i < executions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prevalidated := engine.PrevalidateUntilLocked(&executionMutex, []types.ArbitrageOpportunity{fakeOpportunity()})
+			defer executionMutex.Unlock()
+			results[i] = engine.ExecutePrevalidated(prevalidated[0])
+		}(i)
+	}
+
+	// Detection goroutines sharing the engine's rate cache, as the scan loop does
+	stop := make(chan struct{})
+	var detectors sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		detectors.Add(1)
+		go func() {
+			defer detectors.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, _, err := engine.rateManager.NormalizePrices(1, "USDT", 90, "INR"); err != nil {
+					t.Errorf("NormalizePrices: %v", err)
+					return
+				}
+				engine.rateManager.SaveCache()
+				engine.ValueInventory()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(stop)
+	detectors.Wait()
+
+	for i, result := range results {
+		if result == nil || len(result.Orders) != 1 {
+			t.Fatalf("execution %d: want one order, got %+v", i, result)
+		}
+		order := result.Orders[0]
+		if !order.Success {
+			t.Errorf("execution %d failed: %s", i, order.ErrorMessage)
+		}
+		if order.VolumeExecuted <= 0 {
+			t.Errorf("execution %d executed no volume", i)
+		}
+	}
+
+	if reserved := engine.reserved.Reserved("USDT"); reserved != 0 {
+		t.Errorf("reservations leaked: %.6f USDT still reserved", reserved)
+	}
+
+	balances, err := venue.GetBalances()
+	if err != nil {
+		t.Fatalf("GetBalances: %v", err)
+	}
+	for _, balance := range balances {
+		if balance.Balance < -1e-9 {
+			t.Errorf("%s balance went negative: %.8f", balance.Currency, balance.Balance)
+		}
+		if balance.Currency == "XYZ" && balance.Balance > 1e-9 {
+			t.Errorf("sell legs left %.8f XYZ behind", balance.Balance)
+		}
+	}
+}
+
+func TestConcurrentReservationsShareCapital(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+
+	const buyers = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex // Guards the test's own tallies
+	reservedVolume := 0.0
+	releases := []func(){}
+
+	// Opportunities sized to the whole balance race for the same capital;
+	// the ledger must never hand out more than the account holds
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			liveOpp := RealTimeOpportunity{
+				Currency:    "XYZ",
+				BuyMarket:   "XYZUSDT",
+				SellMarket:  "XYZINR",
+				BuyPrice:    1.0,
+				Volume:      500000,
+				Opportunity: fakeOpportunity(),
+			}
+
+			release, err := engine.reserveFunds(&liveOpp)
+			if err != nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			reservedVolume += liveOpp.Volume
+			releases = append(releases, release)
+		}()
+	}
+	wg.Wait()
+
+	unitCost := 1.0 * (1 + types.DefaultConfig().FeeRate)
+	if spent := reservedVolume * unitCost; spent > 1000000+1e-6 {
+		t.Errorf("reserved %.6f USDT against a 1000000 USDT balance", spent)
+	}
+
+	for _, release := range releases {
+		release()
+	}
+	if reserved := engine.reserved.Reserved("USDT"); reserved != 0 {
+		t.Errorf("reservations leaked: %.6f USDT still reserved", reserved)
+	}
+}
@@ -0,0 +1,31 @@
+package arbitrage
+
+import "testing"
+
+func TestTimedOutSellIsCancelledBeforeRecovery(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+	engine.config.OrderTimeoutSeconds = 1
+
+	// The INR market only bids for 10000, so the market sell stalls part filled
+	book := fakeBooks["I-XYZ_INR"]
+	fakeBooks["I-XYZ_INR"] = `{"bids": {"90.0": "10000"}, "asks": {"91.0": "20000"}}`
+	t.Cleanup(func() { fakeBooks["I-XYZ_INR"] = book })
+
+	order := engine.executeRealTimeOrder(limitOpportunity(15000, 1.00, 90.0))
+	if !order.Success {
+		t.Fatalf("want the 5000 the sell left recovered, got %q", order.ErrorMessage)
+	}
+	if positions := engine.inventory.Positions(); len(positions) != 0 {
+		t.Errorf("inventory tracks %+v, want nothing stranded", positions)
+	}
+
+	balances, err := venue.GetBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, balance := range balances {
+		if balance.Currency == "XYZ" && balance.Balance > 1e-9 {
+			t.Errorf("%g XYZ left after recovery", balance.Balance)
+		}
+	}
+}
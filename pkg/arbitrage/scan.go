@@ -0,0 +1,221 @@
+package arbitrage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/exchange"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// PriceInfo is a pair's top of book with both sides converted to INR
+type PriceInfo struct {
+	Pair         types.PairInfo
+	BestBid      float64
+	BestAsk      float64
+	BidVolume    float64
+	AskVolume    float64
+	BestBidINR   float64
+	BestAskINR   float64
+	HasLiquidity bool
+}
+
+// Scanner prices each currency's pairs from the books a live scan pass
+// fetched and pairs them up into opportunities. It remembers each pair's
+// last prices, so pairs whose books a pass didn't refetch can still be
+// compared with the ones it did. A Scanner is used by one scan loop at a time.
+type Scanner struct {
+	config      *types.Config
+	rateManager *exchange.RateManager
+	anomalies   *market.AnomalyFilter
+	feeds       *market.PriceFeeds
+	lastPrices  map[string]PriceInfo
+}
+
+// NewScanner creates a scanner converting prices with rateManager and
+// skipping books anomalies or feeds reject
+func NewScanner(config *types.Config, rateManager *exchange.RateManager, anomalies *market.AnomalyFilter, feeds *market.PriceFeeds) *Scanner {
+	return &Scanner{
+		config:      config,
+		rateManager: rateManager,
+		anomalies:   anomalies,
+		feeds:       feeds,
+		lastPrices:  make(map[string]PriceInfo),
+	}
+}
+
+// NeedsRefresh reports whether a pass refetches the pair's book: it is
+// quoted in quotes (all when nil) or has no last price to fall back on
+func (s *Scanner) NeedsRefresh(pair types.PairInfo, quotes map[string]bool) bool {
+	_, cached := s.lastPrices[pair.Pair]
+	return !cached || quotes == nil || quotes[pair.BaseCurrency]
+}
+
+// AnalyzeCurrency finds the currency's opportunities. Pairs NeedsRefresh
+// picks are priced from books, fetched for the pass; the others from their
+// last prices, and a currency with no pair refetched has nothing new to find.
+// Near misses are reported to shortfall.
+func (s *Scanner) AnalyzeCurrency(ctx context.Context, currency string, pairs []types.PairInfo, quotes map[string]bool, books map[string]market.BookResult, shortfall *opportunity.Shortfall) ([]types.ArbitrageOpportunity, error) {
+	// Get current prices for all pairs
+	pairPrices := make(map[string]PriceInfo)
+	refreshed := false
+
+	for _, pair := range pairs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		priceInfo := s.lastPrices[pair.Pair]
+		if s.NeedsRefresh(pair, quotes) {
+			var err error
+			priceInfo, err = s.priceInfo(ctx, pair, books[pair.Pair])
+			if err != nil {
+				delete(s.lastPrices, pair.Pair)
+				logger.Warn("⚠️ Pair not priced", "symbol", pair.Symbol, "error", err)
+				continue
+			}
+			s.lastPrices[pair.Pair] = priceInfo
+			refreshed = true
+		}
+
+		// Check liquidity
+		bidLiquidityINR := priceInfo.BidVolume * priceInfo.BestBidINR
+		askLiquidityINR := priceInfo.AskVolume * priceInfo.BestAskINR
+
+		if bidLiquidityINR < s.config.MinLiquidity || askLiquidityINR < s.config.MinLiquidity {
+			logger.Info("📉 Low liquidity", "symbol", pair.Symbol, "bid_liquidity_inr", bidLiquidityINR,
+				"ask_liquidity_inr", askLiquidityINR)
+			shortfall.ObserveLiquidity(pair.Symbol, min(bidLiquidityINR, askLiquidityINR))
+			continue
+		}
+
+		priceInfo.HasLiquidity = true
+		pairPrices[pair.Symbol] = priceInfo
+	}
+
+	if !refreshed {
+		return nil, nil
+	}
+	if len(pairPrices) < 2 {
+		return nil, fmt.Errorf("insufficient liquid pairs")
+	}
+
+	// Find arbitrage opportunities between all pair combinations
+	opportunities := []types.ArbitrageOpportunity{}
+
+	for buySymbol, buyPrice := range pairPrices {
+		for sellSymbol, sellPrice := range pairPrices {
+			if buySymbol == sellSymbol || !buyPrice.HasLiquidity || !sellPrice.HasLiquidity {
+				continue
+			}
+
+			opp, err := calculateArbitrage(currency, buyPrice, sellPrice, s.config)
+			if err != nil {
+				logger.Warn("⚠️ Route not priced", "buy_market", buySymbol, "sell_market", sellSymbol, "error", err)
+				continue
+			}
+			if opp.NetMarginPct >= s.config.MinNetMargin {
+				opp.Viable = true
+				logger.Info("🎯 VIABLE", "buy_market", buySymbol, "sell_market", sellSymbol, "net_margin_pct", opp.NetMarginPct)
+			} else {
+				logger.Info("❌ Below threshold", "buy_market", buySymbol, "sell_market", sellSymbol,
+					"net_margin_pct", opp.NetMarginPct, "min_net_margin", s.config.MinNetMargin)
+				shortfall.ObserveOpportunity(opp)
+			}
+
+			opportunities = append(opportunities, opp)
+		}
+	}
+
+	return opportunities, nil
+}
+
+// priceInfo reads the pair's top of book from its fetched book
+func (s *Scanner) priceInfo(ctx context.Context, pair types.PairInfo, fetched market.BookResult) (PriceInfo, error) {
+	orderBook, err := fetched.Book, fetched.Err
+	if err != nil {
+		return PriceInfo{}, err
+	}
+	if orderBook == nil {
+		return PriceInfo{}, fmt.Errorf("%s book not fetched", pair.Pair)
+	}
+
+	priceInfo := PriceInfo{Pair: pair}
+
+	// Bids (buy orders)
+	if bestBid, ok := orderBook.BestBid(); ok {
+		priceInfo.BestBid, priceInfo.BidVolume = bestBid.Price, bestBid.Volume
+	}
+
+	// Asks (sell orders)
+	priceInfo.BestAsk = 999999999.0
+	if bestAsk, ok := orderBook.BestAsk(); ok {
+		priceInfo.BestAsk, priceInfo.AskVolume = bestAsk.Price, bestAsk.Volume
+	}
+
+	// A bogus quote would look like a huge opportunity; skip the book instead
+	if err := s.anomalies.Check(pair.Symbol, priceInfo.BestBid, priceInfo.BestAsk); err != nil {
+		return PriceInfo{}, err
+	}
+
+	// A spread between prices that are still moving is likely gone before orders land
+	if err := s.feeds.CheckStable(pair.Symbol, priceInfo.BestBid, priceInfo.BestAsk); err != nil {
+		return PriceInfo{}, err
+	}
+
+	// Convert to INR
+	if priceInfo.BestBid > 0 {
+		if priceInfo.BestBidINR, err = s.rateManager.ConvertToINRContext(ctx, priceInfo.BestBid, pair.BaseCurrency); err != nil {
+			return PriceInfo{}, fmt.Errorf("INR conversion: %v", err)
+		}
+	}
+	if priceInfo.BestAsk < 999999999.0 {
+		if priceInfo.BestAskINR, err = s.rateManager.ConvertToINRContext(ctx, priceInfo.BestAsk, pair.BaseCurrency); err != nil {
+			return PriceInfo{}, fmt.Errorf("INR conversion: %v", err)
+		}
+	}
+
+	return priceInfo, nil
+}
+
+// calculateArbitrage prices buying at buyPrice's ask and selling at
+// sellPrice's bid; the caller decides viability
+func calculateArbitrage(currency string, buyPrice, sellPrice PriceInfo, config *types.Config) (types.ArbitrageOpportunity, error) {
+	// Both sides must be quoted; an empty book side would divide by zero below
+	if err := types.RequirePositive(buyPrice.Pair.Symbol+" ask INR", buyPrice.BestAskINR); err != nil {
+		return types.ArbitrageOpportunity{}, err
+	}
+	if err := types.RequirePositive(sellPrice.Pair.Symbol+" bid INR", sellPrice.BestBidINR); err != nil {
+		return types.ArbitrageOpportunity{}, err
+	}
+
+	// Calculate margins in INR terms
+	grossMargin := sellPrice.BestBidINR - buyPrice.BestAskINR
+	grossMarginPct := (grossMargin / buyPrice.BestAskINR) * 100
+
+	// Estimate fees, honouring per-market overrides
+	estimatedFees := buyPrice.BestAskINR*config.FeeRateFor(buyPrice.Pair.Symbol) +
+		sellPrice.BestBidINR*config.FeeRateFor(sellPrice.Pair.Symbol)
+
+	// Calculate net margins
+	netMargin := grossMargin - estimatedFees
+	netMarginPct := (netMargin / buyPrice.BestAskINR) * 100
+
+	opp := types.ArbitrageOpportunity{
+		TargetCurrency: currency,
+		BuyPriceINR:    buyPrice.BestAskINR,
+		SellPriceINR:   sellPrice.BestBidINR,
+		GrossMargin:    grossMargin,
+		GrossMarginPct: grossMarginPct,
+		EstimatedFees:  estimatedFees,
+		NetMargin:      netMargin,
+		NetMarginPct:   netMarginPct,
+		Viable:         false, // Set by caller
+		Timestamp:      time.Now(),
+	}
+	opp.BuyMarket.Symbol, opp.BuyMarket.Pair, opp.BuyMarket.BaseCurrency = buyPrice.Pair.Symbol, buyPrice.Pair.Pair, buyPrice.Pair.BaseCurrency
+	opp.SellMarket.Symbol, opp.SellMarket.Pair, opp.SellMarket.BaseCurrency = sellPrice.Pair.Symbol, sellPrice.Pair.Pair, sellPrice.Pair.BaseCurrency
+	return opp, nil
+}
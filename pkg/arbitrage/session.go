@@ -0,0 +1,90 @@
+package arbitrage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Session bounds a supervised trading run by duration and realized P&L.
+// Once stopped it stays stopped.
+type Session struct {
+	started      time.Time
+	deadline     time.Time // Zero when only P&L targets apply
+	profitTarget float64
+	lossLimit    float64
+
+	mu         sync.Mutex
+	realized   float64
+	trades     int
+	stopReason string
+}
+
+// NewSession starts a session from the execution config, or returns nil when
+// no session limits are configured and RunForever isn't set
+func NewSession(config *types.ExecutionConfig) *Session {
+	if config.SessionMinutes <= 0 && config.SessionProfitTargetINR <= 0 && config.SessionLossLimitINR <= 0 && !config.RunForever {
+		return nil
+	}
+
+	session := &Session{
+		started:      time.Now(),
+		profitTarget: config.SessionProfitTargetINR,
+		lossLimit:    config.SessionLossLimitINR,
+	}
+	if config.SessionMinutes > 0 {
+		session.deadline = session.started.Add(time.Duration(config.SessionMinutes) * time.Minute)
+	}
+	return session
+}
+
+// Record adds an execution's realized P&L to the session; a nil session
+// ignores it
+func (s *Session) Record(result *types.ExecutionResult) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, order := range result.Orders {
+		if order.Success {
+			s.realized += order.ActualProfit
+			s.trades++
+		}
+	}
+
+	switch {
+	case s.stopReason != "":
+	case s.profitTarget > 0 && s.realized >= s.profitTarget:
+		s.stopReason = fmt.Sprintf("profit target ₹%.2f reached (₹%.2f)", s.profitTarget, s.realized)
+	case s.lossLimit > 0 && s.realized <= -s.lossLimit:
+		s.stopReason = fmt.Sprintf("loss limit ₹%.2f reached (₹%.2f)", s.lossLimit, s.realized)
+	}
+}
+
+// Stopped reports whether the session is over and why; a nil session never stops
+func (s *Session) Stopped() (bool, string) {
+	if s == nil {
+		return false, ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopReason == "" && !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopReason = fmt.Sprintf("%s session time elapsed", s.deadline.Sub(s.started).Round(time.Minute))
+	}
+	return s.stopReason != "", s.stopReason
+}
+
+// Summary describes the session's progress for display
+func (s *Session) Summary() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fmt.Sprintf("%d trades, ₹%.2f realized in %s", s.trades, s.realized, time.Since(s.started).Round(time.Second))
+}
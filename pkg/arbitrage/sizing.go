@@ -0,0 +1,109 @@
+package arbitrage
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// sizingLeg is one leg's market and the price it trades at, in its quote
+type sizingLeg struct {
+	detail types.MarketDetail
+	price  float64
+}
+
+// sizePosition converts MaxPositionUSDT into a token quantity by walking
+// both books (asks to buy, bids to sell, rates converting their quotes to
+// INR): levels are taken while they still clear the margin floor after
+// fees and the budget lasts. The capped quantity is then fitted to both
+// markets' order rules.
+func (e *Engine) sizePosition(liveOpp *RealTimeOpportunity, buyLevels, sellLevels []types.OrderLevel, buyRate, sellRate float64) error {
+	usdtINR, err := e.rateManager.ConvertToINR(1, "USDT")
+	if err != nil {
+		return fmt.Errorf("position sizing needs the USDT rate: %v", err)
+	}
+	budgetINR := e.config.MaxPositionUSDT * usdtINR
+	buyFeeRate, sellFeeRate := e.feeRate(liveOpp.BuyMarket), e.feeRate(liveOpp.SellMarket)
+
+	quantity := 0.0
+	buyIdx, sellIdx := 0, 0
+	buyLeft, sellLeft := 0.0, 0.0
+	for buyIdx < len(buyLevels) && sellIdx < len(sellLevels) && budgetINR > 0 {
+		buy, sell := buyLevels[buyIdx], sellLevels[sellIdx]
+		if buyLeft <= 0 {
+			buyLeft = buy.Volume
+		}
+		if sellLeft <= 0 {
+			sellLeft = sell.Volume
+		}
+
+		unitCost := buy.Price * buyRate * (1 + buyFeeRate)
+		unitProceeds := sell.Price * sellRate * (1 - sellFeeRate)
+		if unitCost <= 0 || (unitProceeds-unitCost)/unitCost*100 < e.config.StopLossPct {
+			break
+		}
+
+		volume := min(min(buyLeft, sellLeft), budgetINR/unitCost)
+		quantity += volume
+		budgetINR -= volume * unitCost
+		buyLeft -= volume
+		sellLeft -= volume
+		if buyLeft <= 0 {
+			buyIdx++
+		}
+		if sellLeft <= 0 {
+			sellIdx++
+		}
+	}
+
+	sized, err := fitToMarkets(quantity, e.sizingLegs(*liveOpp)...)
+	if err != nil {
+		return err
+	}
+	liveOpp.Volume = sized
+	return nil
+}
+
+// sizingLegs are the opportunity's two markets at its prices; none when
+// market details are unavailable
+func (e *Engine) sizingLegs(opportunity RealTimeOpportunity) []sizingLeg {
+	legs := []sizingLeg{}
+	if planner := e.routePlanner(); planner != nil {
+		if detail, ok := planner.Market(opportunity.BuyMarket); ok {
+			legs = append(legs, sizingLeg{detail: detail, price: opportunity.BuyPrice})
+		}
+		if detail, ok := planner.Market(opportunity.SellMarket); ok {
+			legs = append(legs, sizingLeg{detail: detail, price: opportunity.SellPrice})
+		}
+	}
+	return legs
+}
+
+// fitToMarkets rounds quantity down to every leg's maximum, step and
+// quantity precision, then errors when the result is below a leg's minimum
+// quantity or its notional below the minimum order value
+func fitToMarkets(quantity float64, legs ...sizingLeg) (float64, error) {
+	for _, leg := range legs {
+		if leg.detail.MaxQuantity > 0 {
+			quantity = min(quantity, leg.detail.MaxQuantity)
+		}
+		quantity = utils.RoundToStep(quantity, leg.detail.Step, utils.RoundDown)
+		quantity = utils.RoundToPrecision(quantity, leg.detail.TargetCurrencyPrecision, utils.RoundDown)
+	}
+	if quantity <= 0 {
+		return 0, fmt.Errorf("position rounds to zero")
+	}
+
+	for _, leg := range legs {
+		if quantity < leg.detail.MinQuantity {
+			return 0, fmt.Errorf("position %.8f below %s minimum quantity %.8f",
+				quantity, leg.detail.Symbol, leg.detail.MinQuantity)
+		}
+		if notional := quantity * leg.price; notional < leg.detail.MinNotional {
+			return 0, fmt.Errorf("position worth %.8f %s below %s minimum notional %.8f",
+				notional, leg.detail.BaseCurrencyShortName, leg.detail.Symbol, leg.detail.MinNotional)
+		}
+	}
+	return quantity, nil
+}
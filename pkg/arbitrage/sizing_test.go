@@ -0,0 +1,155 @@
+package arbitrage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestSizePositionWalksBothBooks(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.MaxPositionUSDT = 100
+	trading := types.DefaultConfig()
+	trading.FeeRate = 0.001
+	engine.SetTradingConfig(trading)
+
+	levels := func(prices ...float64) []types.OrderLevel {
+		book := []types.OrderLevel{}
+		for i := 0; i < len(prices); i += 2 {
+			book = append(book, types.OrderLevel{Price: prices[i], Volume: prices[i+1]})
+		}
+		return book
+	}
+
+	// USDT asks at ₹85 each, INR bids; 0.1% fee each side, 1% margin floor
+	cases := []struct {
+		name      string
+		asks      []types.OrderLevel
+		bids      []types.OrderLevel
+		want      float64
+		wantError bool
+	}{
+		// ₹8500 buys 99.90 coins at ₹85 and the fee, at 2 decimals
+		{name: "budget", asks: levels(1.00, 20000), bids: levels(90, 20000), want: 99.9},
+		// The second ask no longer clears the floor
+		{name: "thin top", asks: levels(1.00, 40.456, 1.20, 1000), bids: levels(90, 20000), want: 40.45},
+		// 30 then 20 at the top ask, the rest of the budget at 1.01
+		{name: "deeper levels", asks: levels(1.00, 50, 1.01, 1000), bids: levels(90, 30, 89.5, 1000), want: 99.4},
+		// 1.05 against 89.5 nets under 1%
+		{name: "margin floor", asks: levels(1.00, 50, 1.05, 1000), bids: levels(90, 30, 89.5, 1000), want: 50},
+		{name: "nothing profitable", asks: levels(1.10, 1000), bids: levels(90, 1000), wantError: true},
+	}
+	for _, tc := range cases {
+		opp := limitOpportunity(0, tc.asks[0].Price, tc.bids[0].Price)
+		err := engine.sizePosition(&opp, tc.asks, tc.bids, 85, 1)
+		if tc.wantError {
+			if err == nil {
+				t.Errorf("%s: sized to %g, want an error", tc.name, opp.Volume)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if opp.Volume != tc.want {
+			t.Errorf("%s: sized to %g, want %g", tc.name, opp.Volume, tc.want)
+		}
+	}
+}
+
+func TestFitToMarkets(t *testing.T) {
+	buy := sizingLeg{price: 2, detail: types.MarketDetail{Symbol: "XYZUSDT", BaseCurrencyShortName: "USDT",
+		MinQuantity: 5, MaxQuantity: 1000, MinNotional: 20, Step: 0.5, TargetCurrencyPrecision: 1}}
+	sell := sizingLeg{price: 170, detail: types.MarketDetail{Symbol: "XYZINR", BaseCurrencyShortName: "INR",
+		MinQuantity: 1, MinNotional: 100, TargetCurrencyPrecision: 2}}
+
+	cases := []struct {
+		quantity float64
+		want     float64
+		err      string
+	}{
+		{quantity: 12.74, want: 12.5},
+		{quantity: 5000, want: 1000},
+		{quantity: 5.9, err: "minimum notional"},
+		{quantity: 4.99, err: "minimum quantity"},
+		{quantity: 0.2, err: "rounds to zero"},
+	}
+	for _, tc := range cases {
+		got, err := fitToMarkets(tc.quantity, buy, sell)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("fitToMarkets(%g) = %g, %v; want an error about %s", tc.quantity, got, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("fitToMarkets(%g) = %g, %v; want %g", tc.quantity, got, err, tc.want)
+		}
+	}
+}
+
+func TestReserveFundsFitsShrunkVolumeToMarkets(t *testing.T) {
+	markets := fakeMarkets
+	fakeMarkets = append([]types.MarketDetail(nil), markets...)
+	fakeMarkets[0].Step, fakeMarkets[0].MinNotional = 0.5, 5
+	t.Cleanup(func() { fakeMarkets = markets })
+
+	cases := []struct {
+		usdt float64
+		want float64
+		err  string
+	}{
+		// 10.3 USDT affords 10.098 at 1.00 and the 2% default fee, 10 at the 0.5 step
+		{usdt: 10.3, want: 10},
+		// 3.5 coins are worth less than the 5 USDT minimum order
+		{usdt: 4, err: "minimum notional"},
+	}
+	for _, tc := range cases {
+		engine, _ := newRaceEngine(t)
+		engine.venue = executor.NewSimulatedExecutor(market.NewFetcher(), fakeMarkets, 0.001, map[string]float64{"USDT": tc.usdt})
+
+		opp := limitOpportunity(1000, 1.00, 90.0)
+		release, err := engine.reserveFunds(&opp)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%g USDT: sized to %g, %v; want an error about %s", tc.usdt, opp.Volume, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%g USDT: %v", tc.usdt, err)
+		}
+		release()
+		if opp.Volume != tc.want {
+			t.Errorf("%g USDT: sized to %g, want %g", tc.usdt, opp.Volume, tc.want)
+		}
+	}
+}
+
+func TestReserveFundsPaysFromFundingCurrency(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.FundingCurrency = "INR"
+	engine.venue = executor.NewSimulatedExecutor(market.NewFetcher(), fakeMarkets, 0.001,
+		map[string]float64{"USDT": 1000, "INR": 100000})
+
+	opp := limitOpportunity(100, 1.00, 90.0)
+	if _, err := engine.reserveFunds(&opp); err == nil || !strings.Contains(err.Error(), "paid from INR") {
+		t.Fatalf("USDT buy under INR funding: %v, want it refused", err)
+	}
+
+	opp = limitOpportunity(100, 90.0, 1.10)
+	opp.BuyMarket, opp.SellMarket = "XYZINR", "XYZUSDT"
+	opp.Opportunity.BuyMarket, opp.Opportunity.SellMarket = opp.Opportunity.SellMarket, opp.Opportunity.BuyMarket
+	release, err := engine.reserveFunds(&opp)
+	if err != nil {
+		t.Fatalf("INR buy: %v", err)
+	}
+	defer release()
+	if engine.reserved.Reserved("INR") <= 0 || engine.reserved.Reserved("USDT") != 0 {
+		t.Errorf("reserved %v, want INR only", engine.reserved.All())
+	}
+}
@@ -0,0 +1,272 @@
+package arbitrage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// executeOpportunity runs a validated opportunity and publishes its outcome.
+// Everything the attempt does carries its execution ID, new unless the
+// caller set one.
+func (e *Engine) executeOpportunity(opportunity RealTimeOpportunity) types.ExecutedOrder {
+	if opportunity.ExecutionID == "" {
+		opportunity.ExecutionID = utils.NewUUID()
+	}
+	executedOrder := e.dispatchOpportunity(opportunity)
+	executedOrder.ExecutionID = opportunity.ExecutionID
+
+	e.events.Publish(events.ExecutionCompleted{
+		Base:      events.Base{Time: executedOrder.EndTime, ExecutionID: executedOrder.ExecutionID},
+		Currency:  executedOrder.Currency,
+		Success:   executedOrder.Success,
+		Volume:    executedOrder.VolumeExecuted,
+		Profit:    executedOrder.ActualProfit,
+		MarginPct: executedOrder.ActualMarginPct,
+		Error:     executedOrder.ErrorMessage,
+	})
+	e.snapshotFailure(opportunity, executedOrder)
+	return executedOrder
+}
+
+// dispatchOpportunity picks single-shot or sliced execution for a validated opportunity
+func (e *Engine) dispatchOpportunity(opportunity RealTimeOpportunity) types.ExecutedOrder {
+	// Size against spendable funds, not the raw balance, and hold them until done
+	release, err := e.reserveFunds(&opportunity)
+	if err != nil {
+		return types.ExecutedOrder{
+			OrderNumber:   1,
+			Currency:      opportunity.Currency,
+			BuyMarket:     opportunity.BuyMarket,
+			SellMarket:    opportunity.SellMarket,
+			ErrorMessage:  err.Error(),
+			StartTime:     time.Now(),
+			EndTime:       time.Now(),
+			DecisionBooks: opportunity.Books,
+		}
+	}
+	defer func() { release() }()
+
+	if e.confirm != nil {
+		if !e.confirm(e.preview(opportunity)) {
+			return types.ExecutedOrder{
+				OrderNumber:   1,
+				Currency:      opportunity.Currency,
+				BuyMarket:     opportunity.BuyMarket,
+				SellMarket:    opportunity.SellMarket,
+				PlannedVolume: opportunity.Volume,
+				ErrorMessage:  "declined at preview",
+				StartTime:     time.Now(),
+				EndTime:       time.Now(),
+				DecisionBooks: opportunity.Books,
+			}
+		}
+
+		// The books moved while the preview waited; trade the confirmed
+		// volume only if it still validates, at no more than fresh sizing allows
+		fresh := e.analyzeAndValidateRealTime(opportunity.Opportunity)
+		if !fresh.Viable {
+			return types.ExecutedOrder{
+				OrderNumber:   1,
+				Currency:      opportunity.Currency,
+				BuyMarket:     opportunity.BuyMarket,
+				SellMarket:    opportunity.SellMarket,
+				PlannedVolume: opportunity.Volume,
+				ErrorMessage:  fmt.Sprintf("no longer valid after confirmation: %s", fresh.Reason),
+				StartTime:     time.Now(),
+				EndTime:       time.Now(),
+				DecisionBooks: fresh.Books,
+			}
+		}
+		fresh.ExecutionID, fresh.level = opportunity.ExecutionID, opportunity.level
+		fresh.Volume = min(fresh.Volume, opportunity.Volume)
+
+		// The reservation was costed at the preview's buy price; reserve
+		// again at the fresh one so a higher ask can't outspend it
+		release()
+		release, err = e.reserveFunds(&fresh)
+		if err != nil {
+			release = func() {}
+			return types.ExecutedOrder{
+				OrderNumber:   1,
+				Currency:      fresh.Currency,
+				BuyMarket:     fresh.BuyMarket,
+				SellMarket:    fresh.SellMarket,
+				PlannedVolume: fresh.Volume,
+				ErrorMessage:  err.Error(),
+				StartTime:     time.Now(),
+				EndTime:       time.Now(),
+				DecisionBooks: fresh.Books,
+			}
+		}
+		opportunity = fresh
+	}
+
+	if e.sliced(opportunity) {
+		return e.executeSliced(opportunity)
+	}
+	return e.executeRealTimeOrder(opportunity)
+}
+
+// sliced reports whether an opportunity is too large for the first book level
+func (e *Engine) sliced(opportunity RealTimeOpportunity) bool {
+	return opportunity.level == nil && e.config.MaxSlices > 1 && opportunity.TopOfBookVolume > 0 &&
+		opportunity.Volume > opportunity.TopOfBookVolume
+}
+
+// executeSliced splits a trade larger than the first book level into timed
+// slices. Each slice re-validates the margin against fresh books and is sized
+// to the top level at that moment, instead of one market order walking the book.
+func (e *Engine) executeSliced(opportunity RealTimeOpportunity) types.ExecutedOrder {
+	executedOrder := types.ExecutedOrder{
+		OrderNumber:    1,
+		Currency:       opportunity.Currency,
+		BuyMarket:      opportunity.BuyMarket,
+		SellMarket:     opportunity.SellMarket,
+		PlannedVolume:  opportunity.Volume,
+		ExpectedProfit: opportunity.ExpectedMargin * opportunity.Volume,
+		StartTime:      time.Now(),
+		Latency:        types.PhaseLatency{ValidationMs: opportunity.ValidationMs},
+		DecisionBooks:  opportunity.Books, // Slices re-validate; this is the book that started it
+	}
+
+	opportunity.log().Info("🔪 SLICING: exceeds top of book", "currency", opportunity.Currency, "volume", opportunity.Volume,
+		"top_of_book", opportunity.TopOfBookVolume, "max_slices", e.config.MaxSlices)
+
+	remaining := opportunity.Volume
+	current := opportunity
+	totalBuyValue, totalSellValue := 0.0, 0.0
+
+	for slice := 1; slice <= e.config.MaxSlices && remaining > 0; slice++ {
+		if slice > 1 {
+			time.Sleep(time.Duration(e.config.SliceIntervalMs) * time.Millisecond)
+
+			validationStart := time.Now()
+			current = e.analyzeAndValidateRealTime(opportunity.Opportunity)
+			current.ExecutionID = opportunity.ExecutionID
+			executedOrder.Latency.ValidationMs += time.Since(validationStart).Milliseconds()
+			if !current.Viable {
+				opportunity.log().Info("⏹️ Slice skipped", "currency", opportunity.Currency, "slice", slice, "reason", current.Reason)
+				break
+			}
+		}
+
+		sliceOpp := current
+		sliceOpp.Volume = min(remaining, current.TopOfBookVolume)
+		sliceOpp.ValidationMs = 0
+		fill := e.executeRealTimeOrder(sliceOpp)
+		executedOrder.Latency.BuyPlaceMs += fill.Latency.BuyPlaceMs
+		executedOrder.Latency.BuyFillMs += fill.Latency.BuyFillMs
+		executedOrder.Latency.SellPlaceMs += fill.Latency.SellPlaceMs
+		executedOrder.Latency.SellFillMs += fill.Latency.SellFillMs
+
+		executedOrder.Slices = append(executedOrder.Slices, types.SliceFill{
+			Slice:          slice,
+			PlannedVolume:  sliceOpp.Volume,
+			VolumeExecuted: fill.VolumeExecuted,
+			BuyPrice:       fill.BuyPrice,
+			SellPrice:      fill.SellPrice,
+			MarginPct:      fill.ActualMarginPct,
+			ActualProfit:   fill.ActualProfit,
+			Success:        fill.Success,
+			ErrorMessage:   fill.ErrorMessage,
+			Timestamp:      fill.EndTime,
+		})
+
+		opportunity.log().Info("🔹 Slice filled", "currency", opportunity.Currency, "slice", slice, "volume", fill.VolumeExecuted,
+			"profit_inr", fill.ActualProfit, "margin_pct", fill.ActualMarginPct)
+
+		executedOrder.VolumeExecuted += fill.VolumeExecuted
+		executedOrder.ActualProfit += fill.ActualProfit
+		totalBuyValue += fill.VolumeExecuted * fill.BuyPrice
+		totalSellValue += fill.VolumeExecuted * fill.SellPrice
+		remaining -= fill.VolumeExecuted
+
+		if a := fill.Attribution; a != nil {
+			if executedOrder.Attribution == nil {
+				executedOrder.Attribution = &types.ProfitAttribution{}
+			}
+			executedOrder.Attribution.SpreadCaptured += a.SpreadCaptured
+			executedOrder.Attribution.BuyDrift += a.BuyDrift
+			executedOrder.Attribution.SellDrift += a.SellDrift
+			executedOrder.Attribution.Fees += a.Fees
+			executedOrder.Attribution.Realized += a.Realized
+		}
+
+		if !fill.Success {
+			executedOrder.ErrorMessage = fmt.Sprintf("slice %d: %s", slice, fill.ErrorMessage)
+			break
+		}
+	}
+
+	if executedOrder.VolumeExecuted > 0 {
+		executedOrder.BuyPrice = totalBuyValue / executedOrder.VolumeExecuted
+		executedOrder.SellPrice = totalSellValue / executedOrder.VolumeExecuted
+		totalBuyINR, err := e.rateManager.ConvertToINR(totalBuyValue, opportunity.Opportunity.BuySymbol().Quote)
+		if err == nil {
+			executedOrder.ActualMarginPct, err = types.PercentOf(executedOrder.ActualProfit, totalBuyINR, "buy value")
+		}
+		if err != nil {
+			executedOrder.ErrorMessage = fmt.Sprintf("margin unavailable: %v", err)
+		}
+		executedOrder.Success = executedOrder.ErrorMessage == ""
+	}
+
+	executedOrder.EndTime = time.Now()
+	executedOrder.ExecutionTimeMs = executedOrder.EndTime.Sub(executedOrder.StartTime).Milliseconds()
+	return executedOrder
+}
+
+// reserveFunds shrinks the opportunity to what the quote balance can pay for,
+// net of locked funds and other reservations, and reserves the buy cost
+func (e *Engine) reserveFunds(opportunity *RealTimeOpportunity) (func(), error) {
+	quote := opportunity.Opportunity.BuyMarket.BaseCurrency
+	if quote == "" || opportunity.BuyPrice <= 0 {
+		return func() {}, nil
+	}
+	if funding := e.fundingCurrency(); quote != funding {
+		err := fmt.Errorf("%s is bought with %s; buys are paid from %s", opportunity.Currency, quote, funding)
+		e.publish(*opportunity, events.NewRiskTripped("funding_currency", opportunity.Currency, err.Error()))
+		return nil, err
+	}
+
+	// Concurrent callers must not size off the same unreserved balance
+	e.fundsMu.Lock()
+	defer e.fundsMu.Unlock()
+
+	balances, err := e.venueFor(*opportunity).GetBalances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %v", err)
+	}
+
+	funds := e.balanceView(balances).Get(quote)
+	unitCost := opportunity.BuyPrice * (1 + e.feeRate(opportunity.BuyMarket))
+
+	if affordable := funds.Available / unitCost; affordable < opportunity.Volume {
+		if affordable <= 0 {
+			err := fmt.Errorf("no spendable %s: free %.6f, locked %.6f, reserved %.6f",
+				quote, funds.Free, funds.Locked, funds.Reserved)
+			e.publish(*opportunity, events.NewRiskTripped("available_funds", opportunity.Currency, err.Error()))
+			return nil, err
+		}
+		e.publish(*opportunity, events.NewRiskTripped("available_funds", opportunity.Currency,
+			fmt.Sprintf("sized down to %.4f: only %.6f %s spendable (%.6f locked, %.6f reserved)",
+				affordable, funds.Available, quote, funds.Locked, funds.Reserved)))
+		opportunity.Volume = affordable
+	}
+
+	// What is affordable must still be an order both markets accept, rounded
+	// down to their steps and precision the way sizing does
+	sized, err := fitToMarkets(opportunity.Volume, e.sizingLegs(*opportunity)...)
+	if err != nil {
+		err = fmt.Errorf("%s sized to spendable %s: %v", opportunity.Currency, quote, err)
+		e.publish(*opportunity, events.NewRiskTripped("available_funds", opportunity.Currency, err.Error()))
+		return nil, err
+	}
+	opportunity.Volume = sized
+
+	return e.reserved.Reserve(quote, opportunity.Volume*unitCost), nil
+}
@@ -0,0 +1,125 @@
+package arbitrage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestOversizedOpportunityIsSliced(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	engine.config.SliceIntervalMs = 0
+	engine.config.MaxPositionUSDT = 30000
+	engine.config.MinSellDepthRatio = 1
+	trading := types.DefaultConfig()
+	trading.FeeRate = 0.001
+	engine.SetTradingConfig(trading)
+
+	// The budget walks past the 20000 at the top of both books
+	live := engine.analyzeAndValidateRealTime(fakeOpportunity())
+	if !live.Viable {
+		t.Fatalf("validation failed: %s", live.Reason)
+	}
+	if !engine.sliced(live) {
+		t.Fatalf("sized to %g against %g at the top, want it sliced", live.Volume, live.TopOfBookVolume)
+	}
+
+	order := engine.dispatchOpportunity(live)
+	if !order.Success {
+		t.Fatalf("execution failed: %s", order.ErrorMessage)
+	}
+	if len(order.Slices) < 2 {
+		t.Fatalf("executed in %d slices, want at least 2", len(order.Slices))
+	}
+	for _, slice := range order.Slices {
+		if slice.PlannedVolume > live.TopOfBookVolume {
+			t.Errorf("slice %d planned %g, more than the %g at the top", slice.Slice, slice.PlannedVolume, live.TopOfBookVolume)
+		}
+	}
+	if order.VolumeExecuted != live.Volume {
+		t.Errorf("executed %g, want the sized %g", order.VolumeExecuted, live.Volume)
+	}
+}
+
+func TestConfirmedOpportunityIsRevalidated(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+	live := engine.analyzeAndValidateRealTime(fakeOpportunity())
+	if !live.Viable {
+		t.Fatalf("validation failed: %s", live.Reason)
+	}
+
+	// The INR bids fall below the USDT price while the preview waits
+	book := fakeBooks["I-XYZ_INR"]
+	t.Cleanup(func() { fakeBooks["I-XYZ_INR"] = book })
+	engine.SetConfirm(func(TradePreview) bool {
+		fakeBooks["I-XYZ_INR"] = `{"bids": {"80.0": "20000"}, "asks": {"91.0": "20000"}}`
+		return true
+	})
+
+	order := engine.dispatchOpportunity(live)
+	if order.Success || !strings.Contains(order.ErrorMessage, "no longer valid") {
+		t.Fatalf("want the trade dropped after confirmation, got success=%v %q", order.Success, order.ErrorMessage)
+	}
+	balances, err := venue.GetBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, balance := range balances {
+		if balance.Currency == "XYZ" && balance.Balance > 0 {
+			t.Errorf("bought %g XYZ after the opportunity went away", balance.Balance)
+		}
+	}
+}
+
+// reservationSpy records how much quote the engine held reserved when each
+// buy was placed
+type reservationSpy struct {
+	*executor.SimulatedExecutor
+	engine   *Engine
+	reserved float64
+}
+
+func (r *reservationSpy) CreateOrder(req coindcx.OrderRequest) (*coindcx.Order, error) {
+	if req.Side == "buy" {
+		r.reserved = r.engine.reserved.Reserved("USDT")
+	}
+	return r.SimulatedExecutor.CreateOrder(req)
+}
+
+func TestConfirmedOpportunityIsReservedAtFreshPrice(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+	spy := &reservationSpy{SimulatedExecutor: venue}
+	engine = NewEngineWithVenue(&config.Config{}, engine.config, spy)
+	spy.engine = engine
+	live := engine.analyzeAndValidateRealTime(fakeOpportunity())
+	if !live.Viable {
+		t.Fatalf("validation failed: %s", live.Reason)
+	}
+
+	// The USDT ask rises while the preview waits, after funds were reserved at 1.00
+	book := fakeBooks["B-XYZ_USDT"]
+	t.Cleanup(func() { fakeBooks["B-XYZ_USDT"] = book })
+	engine.SetConfirm(func(TradePreview) bool {
+		fakeBooks["B-XYZ_USDT"] = `{"bids": {"0.99": "20000"}, "asks": {"1.005": "40000"}}`
+		return true
+	})
+
+	order := engine.dispatchOpportunity(live)
+	if !order.Success {
+		t.Fatalf("execution failed: %s", order.ErrorMessage)
+	}
+	if order.BuyPrice != 1.005 {
+		t.Fatalf("bought at %g, want the fresh 1.005 ask", order.BuyPrice)
+	}
+	cost := order.VolumeExecuted * order.BuyPrice * (1 + engine.feeRate(live.BuyMarket))
+	if cost > spy.reserved+1e-9 {
+		t.Errorf("bought %g costing %.4f USDT with only %.4f reserved", order.VolumeExecuted, cost, spy.reserved)
+	}
+	if engine.reserved.Reserved("USDT") != 0 {
+		t.Errorf("%g USDT still reserved after the trade", engine.reserved.Reserved("USDT"))
+	}
+}
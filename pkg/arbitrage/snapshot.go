@@ -0,0 +1,121 @@
+package arbitrage
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/audit"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// FailureSnapshot is the market and account state captured right after an
+// execution failed or fell well short of its expected profit
+type FailureSnapshot struct {
+	Reason     string              `json:"reason"`
+	CapturedAt time.Time           `json:"captured_at"`
+	Order      types.ExecutedOrder `json:"order"` // Its decision books show the state it was validated against
+	BuyBook    *market.OrderBook   `json:"buy_book,omitempty"`
+	SellBook   *market.OrderBook   `json:"sell_book,omitempty"`
+	Balances   []coindcx.Balance   `json:"balances,omitempty"` // The traded currency and both quotes
+	Orders     []coindcx.Order     `json:"orders,omitempty"`   // Every order the execution placed, as the venue sees it now
+	Missing    []string            `json:"missing,omitempty"`  // What couldn't be captured, and why
+}
+
+// snapshotReason says why an execution deserves a snapshot, empty when it
+// doesn't. Executions that never placed an order have nothing to debug.
+func (e *Engine) snapshotReason(order types.ExecutedOrder) string {
+	if order.BuyOrderID == "" && len(order.LimitOrderIDs) == 0 {
+		return ""
+	}
+	if !order.Success {
+		if !e.config.FailureSnapshots {
+			return ""
+		}
+		return "execution failed: " + order.ErrorMessage
+	}
+
+	threshold := e.config.SnapshotShortfallPct
+	if threshold <= 0 || order.ExpectedProfit <= 0 {
+		return ""
+	}
+	shortfallPct := (order.ExpectedProfit - order.ActualProfit) / math.Abs(order.ExpectedProfit) * 100
+	if shortfallPct < threshold {
+		return ""
+	}
+	return fmt.Sprintf("profit ₹%.2f vs ₹%.2f expected (%.0f%% short)", order.ActualProfit, order.ExpectedProfit, shortfallPct)
+}
+
+// snapshotFailure captures fresh books for both markets, the balances
+// involved and the state of every order placed when an execution failed or
+// deviated, and saves them under the snapshot directory. Capturing never
+// affects the execution: failures are logged and recorded in the snapshot.
+func (e *Engine) snapshotFailure(opportunity RealTimeOpportunity, order types.ExecutedOrder) {
+	reason := e.snapshotReason(order)
+	if reason == "" {
+		return
+	}
+	venue := e.venueFor(opportunity)
+
+	snapshot := FailureSnapshot{Reason: reason, CapturedAt: time.Now(), Order: order}
+	missing := func(what string, err error) {
+		snapshot.Missing = append(snapshot.Missing, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	buy, sell := opportunity.Opportunity.BuyMarket, opportunity.Opportunity.SellMarket
+	var err error
+	if snapshot.BuyBook, err = e.fetcher.GetOrderBook(buy.Pair); err != nil {
+		missing(buy.Symbol+" book", err)
+	}
+	if snapshot.SellBook, err = e.fetcher.GetOrderBook(sell.Pair); err != nil {
+		missing(sell.Symbol+" book", err)
+	}
+
+	if balances, err := venue.GetBalances(); err != nil {
+		missing("balances", err)
+	} else {
+		involved := map[string]bool{order.Currency: true, buy.BaseCurrency: true, sell.BaseCurrency: true}
+		for _, balance := range balances {
+			if involved[balance.Currency] {
+				snapshot.Balances = append(snapshot.Balances, balance)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range append([]string{order.BuyOrderID, order.SellOrderID, order.StopOrderID}, order.LimitOrderIDs...) {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if status, err := venue.GetOrderStatus(id); err != nil {
+			missing("order "+id, err)
+		} else {
+			snapshot.Orders = append(snapshot.Orders, *status)
+		}
+	}
+
+	if err := os.MkdirAll(e.snapshotDir, 0755); err != nil {
+		opportunity.log().Warn("⚠️ Snapshot not saved", "currency", order.Currency, "error", err)
+		return
+	}
+	path := filepath.Join(e.snapshotDir, fmt.Sprintf("snapshot_%s_%d.json", order.Currency, snapshot.CapturedAt.UnixMilli()))
+	if err := utils.SaveJSON(snapshot, path); err != nil {
+		opportunity.log().Warn("⚠️ Snapshot not saved", "currency", order.Currency, "error", err)
+		return
+	}
+
+	opportunity.log().Info("📸 Snapshot saved", "currency", order.Currency, "reason", reason, "path", path)
+	e.audit.Record(audit.Entry{
+		Kind:        audit.KindSnapshot,
+		Currency:    order.Currency,
+		OrderID:     order.BuyOrderID,
+		ExecutionID: order.ExecutionID,
+		Summary:     reason + " → " + path,
+	}, nil)
+}
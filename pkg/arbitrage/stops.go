@@ -0,0 +1,78 @@
+package arbitrage
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// placeProtectiveStop guards a fresh buy fill with a stop-limit sell on the
+// opportunity's buy market until the sell leg is placed. Returns the stop
+// order ID, or "" when disabled or placement failed.
+func (e *Engine) placeProtectiveStop(opportunity RealTimeOpportunity, volume, entryPrice float64) string {
+	if e.config.ProtectiveStopPct <= 0 || volume <= 0 {
+		return ""
+	}
+
+	symbol := opportunity.BuyMarket
+	stop, err := executor.PlaceProtectiveStop(e.venueFor(opportunity), symbol, volume, entryPrice, e.config.ProtectiveStopPct)
+	if err != nil {
+		opportunity.log().Warn("⚠️ Protective stop failed", "market", symbol, "error", err)
+		return ""
+	}
+
+	opportunity.log().Info("🛡️ Protective stop", "order_id", stop.ID, "market", symbol, "volume", volume,
+		"stop_pct", e.config.ProtectiveStopPct)
+	return stop.ID
+}
+
+// liftProtectiveStop cancels the buy fill's stop before the sell leg takes
+// the coins the stop locks. A stop that triggered first has sold on the buy
+// market; what it sold is returned. An error means it may still be working.
+func (e *Engine) liftProtectiveStop(opportunity RealTimeOpportunity, stopID string) (sale, error) {
+	if stopID == "" {
+		return sale{}, nil
+	}
+
+	stop, err := executor.CancelAndConfirm(e.venueFor(opportunity), stopID)
+	if err != nil {
+		return sale{}, fmt.Errorf("protective stop %s: %v", stopID, err)
+	}
+
+	sold := filledQuantity(stop)
+	if sold > 0 {
+		e.publish(opportunity, events.NewOrderFilled(stopID, opportunity.BuyMarket, "sell", sold, stop.AvgPrice, stop.FeeAmount))
+	}
+	return sale{volume: sold, value: sold * stop.AvgPrice, fee: stop.FeeAmount,
+		quote: opportunity.Opportunity.BuySymbol().Quote}, nil
+}
+
+// releaseStop lifts the buy fill's stop before the sell leg, reporting
+// whether the leg may go ahead. A stop in an unknown state leaves the coins
+// to an operator; one that triggered already sold some, and the rest goes
+// straight to recovery. Either way executedOrder is settled here.
+func (e *Engine) releaseStop(opportunity RealTimeOpportunity, executedOrder *types.ExecutedOrder, stopID string, bought, buyPrice, buyFee float64) bool {
+	stopped, err := e.liftProtectiveStop(opportunity, stopID)
+	if err != nil {
+		executedOrder.StopOrderID = stopID
+		executedOrder.ErrorMessage = fmt.Sprintf("protective stop state unknown, manual action required: %v", err)
+		e.publish(opportunity, events.NewRecoveryFailed(opportunity.Currency, bought, err.Error(), true))
+		return false
+	}
+	if stopped.volume <= 0 {
+		return true
+	}
+
+	executedOrder.StopOrderID = stopID
+	opportunity.log().Warn("🛡️ Protective stop triggered before the sell leg", "currency", opportunity.Currency,
+		"sold", stopped.volume, "price", stopped.value/stopped.volume)
+	if unsold := bought - stopped.volume; unsold <= bought*1e-9 {
+		executedOrder.SellPrice = stopped.value / stopped.volume
+		e.settleProfit(opportunity, executedOrder, bought, buyPrice, buyFee, stopped)
+		return false
+	}
+	e.recoverUnsold(opportunity, executedOrder, bought, buyPrice, buyFee, stopped, "protective stop triggered")
+	return false
+}
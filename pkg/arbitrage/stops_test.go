@@ -0,0 +1,87 @@
+package arbitrage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+)
+
+// stuckStops is a venue that can't cancel stop orders
+type stuckStops struct {
+	*executor.SimulatedExecutor
+	stops map[string]bool
+}
+
+func (s *stuckStops) CreateOrder(req coindcx.OrderRequest) (*coindcx.Order, error) {
+	order, err := s.SimulatedExecutor.CreateOrder(req)
+	if err == nil && req.OrderType == "stop_limit" {
+		s.stops[order.ID] = true
+	}
+	return order, err
+}
+
+func (s *stuckStops) CancelOrder(orderID string) error {
+	if s.stops[orderID] {
+		return fmt.Errorf("exchange unavailable")
+	}
+	return s.SimulatedExecutor.CancelOrder(orderID)
+}
+
+// stopOrder returns the stop-limit order the simulator recorded
+func stopOrder(t *testing.T, venue executor.Executor) *coindcx.Order {
+	t.Helper()
+	for i := 1; i <= 10; i++ {
+		order, err := venue.GetOrderStatus(fmt.Sprintf("sim-%d", i))
+		if err == nil && order.OrderType == "stop_limit" {
+			return order
+		}
+	}
+	t.Fatal("no stop-limit order placed")
+	return nil
+}
+
+func TestProtectiveStopGuardsBuyFillUntilSell(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+	engine.config.ProtectiveStopPct = 2
+
+	order := engine.executeRealTimeOrder(limitOpportunity(100, 1.00, 90.0))
+	if !order.Success {
+		t.Fatalf("execution failed: %q", order.ErrorMessage)
+	}
+
+	stop := stopOrder(t, venue)
+	if stop.Market != "XYZUSDT" || stop.TotalQuantity != 100 {
+		t.Errorf("stop on %s for %g, want the buy market for the 100 bought", stop.Market, stop.TotalQuantity)
+	}
+	if stop.Status != "cancelled" {
+		t.Errorf("stop %s after the sell, want it cancelled before the sell leg", stop.Status)
+	}
+	if order.StopOrderID != "" {
+		t.Errorf("stop %s reported on a completed round trip", order.StopOrderID)
+	}
+}
+
+func TestUncancelledStopHoldsSellLeg(t *testing.T) {
+	engine, _ := newRaceEngine(t)
+	venue := &stuckStops{
+		SimulatedExecutor: executor.NewSimulatedExecutor(engine.fetcher, fakeMarkets, 0.001, map[string]float64{"USDT": 1000000}),
+		stops:             map[string]bool{},
+	}
+	engine = NewEngineWithVenue(&config.Config{}, engine.config, venue)
+	engine.config.ProtectiveStopPct = 2
+
+	order := engine.executeRealTimeOrder(limitOpportunity(100, 1.00, 90.0))
+	if order.Success || !strings.Contains(order.ErrorMessage, "manual action required") {
+		t.Fatalf("got success=%v %q, want a manual stop failure", order.Success, order.ErrorMessage)
+	}
+	if order.SellOrderID != "" {
+		t.Errorf("sell %s placed while the stop may still hold the coins", order.SellOrderID)
+	}
+	if order.StopOrderID != stopOrder(t, venue).ID {
+		t.Errorf("stop order %q not reported", order.StopOrderID)
+	}
+}
@@ -0,0 +1,98 @@
+package arbitrage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+)
+
+// fundingCurrencies are what the engine trades with; balances in anything
+// else are left over from arbitrages and worth recovering
+var fundingCurrencies = map[string]bool{"USDT": true, "INR": true}
+
+// StrandedAsset is an account balance outside the funding currencies and
+// how it would best be sold back to USDT
+type StrandedAsset struct {
+	Currency     string  `json:"currency"`
+	Quantity     float64 `json:"quantity"`  // Available balance, excluding what open orders lock
+	ValueINR     float64 `json:"value_inr"` // Marked at the best bid; 0 when unpriced
+	Route        string  `json:"route"`     // Best recovery route, empty when none can fill
+	ExpectedUSDT float64 `json:"expected_usdt"`
+	Reason       string  `json:"reason,omitempty"` // Why it can't be liquidated
+}
+
+// FindStranded scans the account's balances for coins other than USDT and
+// INR worth at least minValueINR (0 lists everything), planning the best
+// recovery route for each, most valuable first. Coins without a route or
+// below the minimum order size are listed with the reason.
+func (e *Engine) FindStranded(minValueINR float64) ([]StrandedAsset, error) {
+	view, err := e.BalanceView()
+	if err != nil {
+		return nil, err
+	}
+	mark := e.markINR()
+	planner := e.routePlanner()
+
+	assets := []StrandedAsset{}
+	for _, currency := range view.Currencies() {
+		funds := view.Get(currency)
+		if fundingCurrencies[currency] || funds.Available <= 0 {
+			continue
+		}
+		asset := StrandedAsset{Currency: currency, Quantity: funds.Available}
+		if price, err := mark(currency); err == nil {
+			asset.ValueINR = funds.Available * price
+		} else if minValueINR > 0 {
+			continue // Unpriced: only listed when everything is asked for
+		}
+		if asset.ValueINR < minValueINR {
+			continue
+		}
+
+		if planner != nil {
+			if minQty, ok := planner.MinQuantity(currency); ok && asset.Quantity < minQty {
+				asset.Reason = fmt.Sprintf("below the minimum order size %.8f", minQty)
+			} else if routes := planner.Plan(currency, asset.Quantity, e.marketGuard()); len(routes) > 0 {
+				asset.Route, asset.ExpectedUSDT = routes[0].String(), routes[0].ExpectedUSDT
+			} else {
+				asset.Reason = "no tradable route to USDT"
+			}
+		}
+		assets = append(assets, asset)
+	}
+
+	sort.Slice(assets, func(i, j int) bool { return assets[i].ValueINR > assets[j].ValueINR })
+	return assets, nil
+}
+
+// LiquidateStranded sells a stranded balance through the best recovery
+// route. The balance already holds any dust and tracked inventory of the
+// coin, so on success both are cleared rather than sold again.
+func (e *Engine) LiquidateStranded(asset StrandedAsset) executor.RecoveryResult {
+	e.events.Publish(events.NewRecoveryTriggered(asset.Currency, asset.Quantity, "stranded balance"))
+
+	var recovered executor.RecoveryResult
+	if planner := e.routePlanner(); planner != nil {
+		recovered = planner.Recover(e.venue, e.marketGuard(), asset.Currency, asset.Quantity, 15*time.Second)
+	} else {
+		recovered = executor.RecoverInventory(e.venue, e.marketGuard(), asset.Currency, asset.Quantity, 15*time.Second)
+	}
+	if !recovered.Success {
+		logger.Warn("⚠️ Not sold", "currency", asset.Currency, "reason", recovered.Reason)
+		e.events.Publish(events.NewRecoveryFailed(asset.Currency, asset.Quantity, recovered.Reason, recovered.ManualRequired))
+		return recovered
+	}
+
+	if e.dust.Quantity(asset.Currency) > 0 {
+		if err := e.dust.Clear(asset.Currency); err != nil {
+			logger.Warn("⚠️ Could not update dust ledger", "currency", asset.Currency, "error", err)
+		}
+	}
+	if err := e.inventory.Reduce(asset.Currency, asset.Quantity); err != nil {
+		logger.Warn("⚠️ Could not update inventory", "error", err)
+	}
+	return recovered
+}
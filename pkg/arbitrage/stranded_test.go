@@ -0,0 +1,47 @@
+package arbitrage
+
+import (
+	"testing"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+func TestLiquidateStrandedBalance(t *testing.T) {
+	engine, venue := newRaceEngine(t)
+
+	// Coins a crashed run left behind, part of them tracked as inventory
+	if _, err := venue.CreateOrder(coindcx.OrderRequest{Side: "buy", OrderType: "market_order", Market: "XYZUSDT", TotalQuantity: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.inventory.Add("XYZ", 300, 25500, "XYZUSDT"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 500 coins at the 90 bid are worth ₹45000
+	if assets, err := engine.FindStranded(50000); err != nil || len(assets) != 0 {
+		t.Fatalf("FindStranded(50000) = %+v, %v; want nothing above the threshold", assets, err)
+	}
+	assets, err := engine.FindStranded(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 || assets[0].Currency != "XYZ" || assets[0].Quantity != 500 || assets[0].Route == "" {
+		t.Fatalf("stranded = %+v, want 500 XYZ with a route", assets)
+	}
+
+	if result := engine.LiquidateStranded(assets[0]); !result.Success {
+		t.Fatalf("liquidation failed: %s", result.Reason)
+	}
+	balances, err := venue.GetBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, balance := range balances {
+		if balance.Currency == "XYZ" && balance.Balance > 1e-9 {
+			t.Errorf("%g XYZ left after liquidation", balance.Balance)
+		}
+	}
+	if positions := engine.inventory.Positions(); len(positions) != 0 {
+		t.Errorf("inventory still holds %+v", positions)
+	}
+}
@@ -0,0 +1,270 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+	"github.com/b-thark/cdcx-api/pkg/recorder"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Model is how simulated executions depart from the prices detection saw
+type Model struct {
+	Latency     time.Duration // From detection to the buy, and again from the buy to the sell
+	FillShare   float64       // Of each level's displayed volume an order actually gets; others race for the rest
+	SlippagePct float64       // Adverse move on every fill beyond walking the book
+}
+
+// DefaultModel is a cautious execution: a quarter second per leg, half of
+// every level and a few basis points lost to moves between snapshots
+func DefaultModel() Model {
+	return Model{Latency: 250 * time.Millisecond, FillShare: 0.5, SlippagePct: 0.05}
+}
+
+// Trade is one viable opportunity the backtest acted on
+type Trade struct {
+	Currency          string    `json:"currency"`
+	BuyMarket         string    `json:"buy_market"`
+	SellMarket        string    `json:"sell_market"`
+	DetectedAt        time.Time `json:"detected_at"`
+	DetectedMarginPct float64   `json:"detected_margin_pct"`
+	Executed          bool      `json:"executed"`
+	Reason            string    `json:"reason,omitempty"` // Why it wasn't executed
+	MarginPct         float64   `json:"margin_pct"`       // At the buy, what StopLossPct is checked against
+	Quantity          float64   `json:"quantity"`         // Sized from MaxPositionUSDT
+	Bought            float64   `json:"bought"`
+	Sold              float64   `json:"sold"`
+	CostINR           float64   `json:"cost_inr"`     // Paid for the buy, fees and GST included
+	ProceedsINR       float64   `json:"proceeds_inr"` // Received for the sell after fees, GST and TDS
+	StrandedINR       float64   `json:"stranded_inr"` // Unsold coins valued at the buy market's bids
+	ProfitINR         float64   `json:"profit_inr"`
+}
+
+// Partial reports whether either leg filled less than asked
+func (t Trade) Partial() bool {
+	return t.Executed && (t.Bought < t.Quantity || t.Sold < t.Bought)
+}
+
+// Result is one backtest run's P&L
+type Result struct {
+	MinNetMargin   float64 `json:"min_net_margin"`
+	StopLossPct    float64 `json:"stop_loss_pct"`
+	Frames         int     `json:"frames"`
+	Detected       int     `json:"detected"` // Viable opportunities across every frame
+	Executed       int     `json:"executed"`
+	Partial        int     `json:"partial"`
+	Wins           int     `json:"wins"`
+	Losses         int     `json:"losses"`
+	ProfitINR      float64 `json:"profit_inr"`
+	BestTradeINR   float64 `json:"best_trade_inr"`
+	WorstTradeINR  float64 `json:"worst_trade_inr"`
+	MaxDrawdownINR float64 `json:"max_drawdown_inr"` // Largest fall of cumulative P&L from its peak
+	Trades         []Trade `json:"trades"`
+}
+
+// Backtester replays a recording through the opportunity detector and a
+// simulated execution. Like the engine it trades one opportunity at a time:
+// those detected while a trade is still in flight are skipped.
+type Backtester struct {
+	recording *recorder.Recording
+	pairs     map[string]types.ArbitragePairs
+	model     Model
+}
+
+// NewBacktester backtests pairs against a recording
+func NewBacktester(recording *recorder.Recording, pairs map[string]types.ArbitragePairs, model Model) *Backtester {
+	return &Backtester{recording: recording, pairs: pairs, model: model}
+}
+
+// Run backtests one configuration
+func (b *Backtester) Run(config *types.Config, execution *types.ExecutionConfig) Result {
+	result := Result{MinNetMargin: config.MinNetMargin, StopLossPct: execution.StopLossPct, Frames: len(b.recording.Frames)}
+	var busyUntil time.Time
+	cumulative, peak := 0.0, 0.0
+
+	for _, frame := range b.recording.Frames {
+		// Errors are per currency and already skipped; none is returned
+		opportunities, _ := opportunity.NewSnapshotDetector(config, frame).FindOpportunities(b.pairs)
+		viable := []types.ArbitrageOpportunity{}
+		for _, opp := range opportunities {
+			if opp.Viable {
+				viable = append(viable, opp)
+			}
+		}
+		// Pairs come from a map; the best margin goes first, as the live queue orders them
+		sort.Slice(viable, func(i, j int) bool {
+			if viable[i].NetMarginPct != viable[j].NetMarginPct {
+				return viable[i].NetMarginPct > viable[j].NetMarginPct
+			}
+			return viable[i].TargetCurrency < viable[j].TargetCurrency
+		})
+
+		for _, opp := range viable {
+			result.Detected++
+			if opp.Timestamp.Before(busyUntil) {
+				result.Trades = append(result.Trades, skipped(opp, "another trade in flight"))
+				continue
+			}
+
+			trade := b.execute(opp, config, execution)
+			result.Trades = append(result.Trades, trade)
+			if !trade.Executed {
+				continue
+			}
+			busyUntil = opp.Timestamp.Add(2 * b.model.Latency)
+
+			result.Executed++
+			if trade.Partial() {
+				result.Partial++
+			}
+			if trade.ProfitINR > 0 {
+				result.Wins++
+			} else {
+				result.Losses++
+			}
+			if result.Executed == 1 {
+				result.BestTradeINR, result.WorstTradeINR = trade.ProfitINR, trade.ProfitINR
+			}
+			result.BestTradeINR = max(result.BestTradeINR, trade.ProfitINR)
+			result.WorstTradeINR = min(result.WorstTradeINR, trade.ProfitINR)
+
+			cumulative += trade.ProfitINR
+			peak = max(peak, cumulative)
+			result.MaxDrawdownINR = max(result.MaxDrawdownINR, peak-cumulative)
+		}
+	}
+	result.ProfitINR = cumulative
+	return result
+}
+
+// Sweep backtests every combination of minimum net margin and stop loss
+func (b *Backtester) Sweep(config *types.Config, execution *types.ExecutionConfig, margins, stopLosses []float64) []Result {
+	results := make([]Result, 0, len(margins)*len(stopLosses))
+	for _, margin := range margins {
+		for _, stopLoss := range stopLosses {
+			runConfig, runExecution := *config, *execution
+			runConfig.MinNetMargin, runExecution.StopLossPct = margin, stopLoss
+			results = append(results, b.Run(&runConfig, &runExecution))
+		}
+	}
+	return results
+}
+
+// execute simulates trading opp: the buy against the books one latency
+// after detection, the sell against those one latency later
+func (b *Backtester) execute(opp types.ArbitrageOpportunity, config *types.Config, execution *types.ExecutionConfig) Trade {
+	buyFrame := b.recording.At(opp.Timestamp.Add(b.model.Latency))
+	sellFrame := b.recording.At(opp.Timestamp.Add(2 * b.model.Latency))
+	trade, err := Simulate(opp, buyFrame, sellFrame, config, execution, b.model)
+	if err != nil {
+		trade.Reason = err.Error()
+	}
+	return trade
+}
+
+// Simulate executes opp with the buy on buyFrame's books and the sell on
+// sellFrame's. Like the engine's re-validation, a margin at the buy below
+// StopLossPct is refused; a refusal or missing data is returned as an error.
+func Simulate(opp types.ArbitrageOpportunity, buyFrame, sellFrame *market.Snapshot, config *types.Config, execution *types.ExecutionConfig, model Model) (Trade, error) {
+	trade := skipped(opp, "")
+	buy, sell := opp.BuyMarket, opp.SellMarket
+
+	asks, err := frameLevels(buyFrame, buy.Pair, "asks")
+	if err != nil {
+		return trade, err
+	}
+	bids, err := frameLevels(buyFrame, sell.Pair, "bids")
+	if err != nil {
+		return trade, err
+	}
+	buyRate, sellRate := buyFrame.Rates[buy.BaseCurrency], buyFrame.Rates[sell.BaseCurrency]
+	usdtRate := buyFrame.Rates["USDT"]
+	if buyRate <= 0 || sellRate <= 0 || usdtRate <= 0 {
+		return trade, fmt.Errorf("no INR rate for %s, %s or USDT", buy.BaseCurrency, sell.BaseCurrency)
+	}
+
+	buyFee, sellFee := config.FeeRateFor(buy.Symbol), config.FeeRateFor(sell.Symbol)
+	buyPriceINR, sellPriceINR := asks[0].Price*buyRate, bids[0].Price*sellRate
+	trade.MarginPct = (sellPriceINR - buyPriceINR - buyPriceINR*buyFee - sellPriceINR*sellFee) / buyPriceINR * 100
+	if trade.MarginPct < execution.StopLossPct {
+		return trade, fmt.Errorf("margin %.2f%% below stop loss %.1f%% at the buy", trade.MarginPct, execution.StopLossPct)
+	}
+
+	// Buy leg
+	trade.Quantity = execution.MaxPositionUSDT * usdtRate / buyPriceINR
+	bought := market.EstimateImpact(model.available(asks), trade.Quantity)
+	if bought.Filled <= 0 {
+		return trade, fmt.Errorf("nothing filled on %s", buy.Symbol)
+	}
+	trade.Executed, trade.Reason = true, ""
+	trade.Bought = bought.Filled
+	buyPrice := bought.EffectivePrice * (1 + model.SlippagePct/100)
+	trade.CostINR = market.CostTrade("buy", trade.Bought*buyPrice, buyFee, config.GSTRate, config.TDSRate).Net * buyRate
+
+	// Sell leg, on the books one latency later
+	sellRate = rateOr(sellFrame, sell.BaseCurrency, sellRate)
+	if sellBids, err := frameLevels(sellFrame, sell.Pair, "bids"); err == nil {
+		sold := market.EstimateImpact(model.available(sellBids), trade.Bought)
+		trade.Sold = sold.Filled
+		sellPrice := sold.EffectivePrice * (1 - model.SlippagePct/100)
+		trade.ProceedsINR = market.CostTrade("sell", trade.Sold*sellPrice, sellFee, config.GSTRate, config.TDSRate).Net * sellRate
+	}
+
+	// What the sell couldn't take is marked at what the buy market would pay back
+	if stranded := trade.Bought - trade.Sold; stranded > 0 {
+		if buyBids, err := frameLevels(sellFrame, buy.Pair, "bids"); err == nil {
+			recovered := market.EstimateImpact(buyBids, stranded)
+			trade.StrandedINR = market.CostTrade("sell", recovered.Filled*recovered.EffectivePrice, buyFee, config.GSTRate, config.TDSRate).Net *
+				rateOr(sellFrame, buy.BaseCurrency, buyRate)
+		}
+	}
+
+	trade.ProfitINR = trade.ProceedsINR + trade.StrandedINR - trade.CostINR
+	return trade, nil
+}
+
+// available is the part of each level an order is modelled to get
+func (m Model) available(levels []types.OrderLevel) []types.OrderLevel {
+	if m.FillShare <= 0 || m.FillShare >= 1 {
+		return levels
+	}
+	shared := make([]types.OrderLevel, len(levels))
+	for i, level := range levels {
+		shared[i] = types.OrderLevel{Price: level.Price, Volume: level.Volume * m.FillShare}
+	}
+	return shared
+}
+
+func skipped(opp types.ArbitrageOpportunity, reason string) Trade {
+	return Trade{
+		Currency:          opp.TargetCurrency,
+		BuyMarket:         opp.BuyMarket.Symbol,
+		SellMarket:        opp.SellMarket.Symbol,
+		DetectedAt:        opp.Timestamp,
+		DetectedMarginPct: opp.NetMarginPct,
+		Reason:            reason,
+	}
+}
+
+// frameLevels is one side of a recorded book, erroring when it is empty
+func frameLevels(frame *market.Snapshot, pair, side string) ([]types.OrderLevel, error) {
+	book, err := frame.GetOrderBook(pair)
+	if err != nil {
+		return nil, err
+	}
+	levels := book.Levels(side)
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("%s has no %s", pair, side)
+	}
+	return levels, nil
+}
+
+func rateOr(frame *market.Snapshot, currency string, fallback float64) float64 {
+	if rate := frame.Rates[currency]; rate > 0 {
+		return rate
+	}
+	return fallback
+}
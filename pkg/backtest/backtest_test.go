@@ -0,0 +1,74 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// frame records a B-INR buy market and a B-USDT sell market
+func frame(ask, bid types.OrderLevel) *market.Snapshot {
+	return &market.Snapshot{
+		CapturedAt: time.Now(),
+		Books: map[string]*market.OrderBook{
+			"B-X_INR":  {Asks: []types.OrderLevel{ask}, Bids: []types.OrderLevel{{Price: 95, Volume: 1000}}},
+			"B-X_USDT": {Bids: []types.OrderLevel{bid}},
+		},
+		Rates: map[string]float64{"INR": 1, "USDT": 100},
+	}
+}
+
+func opportunityXINRUSDT() types.ArbitrageOpportunity {
+	var opp types.ArbitrageOpportunity
+	opp.TargetCurrency = "X"
+	opp.BuyMarket.Symbol, opp.BuyMarket.Pair, opp.BuyMarket.BaseCurrency = "XINR", "B-X_INR", "INR"
+	opp.SellMarket.Symbol, opp.SellMarket.Pair, opp.SellMarket.BaseCurrency = "XUSDT", "B-X_USDT", "USDT"
+	return opp
+}
+
+func testConfigs() (*types.Config, *types.ExecutionConfig) {
+	config, execution := types.DefaultConfig(), types.DefaultExecutionConfig()
+	config.FeeRate, config.FeeOverrides, config.FeeSchedule = 0.001, nil, nil
+	config.GSTRate, config.TDSRate = 0, 0
+	execution.MaxPositionUSDT, execution.StopLossPct = 100, 1 // ₹10,000: 100 coins at ₹100
+	return config, execution
+}
+
+func TestSimulatePartialFillsStrandTheRest(t *testing.T) {
+	config, execution := testConfigs()
+	buyFrame := frame(types.OrderLevel{Price: 100, Volume: 1000}, types.OrderLevel{Price: 1.1, Volume: 1000})
+	// The sell's bids thinned to 40 coins, half of which the model gets
+	sellFrame := frame(types.OrderLevel{Price: 100, Volume: 1000}, types.OrderLevel{Price: 1.1, Volume: 40})
+
+	trade, err := Simulate(opportunityXINRUSDT(), buyFrame, sellFrame, config, execution, Model{FillShare: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trade.Bought != 100 || trade.Sold != 20 || !trade.Partial() {
+		t.Fatalf("bought %v sold %v partial %v, want 100, 20, true", trade.Bought, trade.Sold, trade.Partial())
+	}
+	// 80 stranded coins recovered at the buy market's ₹95 bid, less fees
+	if want := 80 * 95 * 0.999; trade.StrandedINR < want-0.01 || trade.StrandedINR > want+0.01 {
+		t.Fatalf("stranded ₹%.2f, want ₹%.2f", trade.StrandedINR, want)
+	}
+	if want := trade.ProceedsINR + trade.StrandedINR - trade.CostINR; trade.ProfitINR != want {
+		t.Fatalf("profit ₹%.2f, want ₹%.2f", trade.ProfitINR, want)
+	}
+}
+
+func TestSimulateRefusesMarginBelowStopLoss(t *testing.T) {
+	config, execution := testConfigs()
+	// 0.8% gross at the buy leaves well under the 1% stop loss after fees
+	buyFrame := frame(types.OrderLevel{Price: 100, Volume: 1000}, types.OrderLevel{Price: 1.008, Volume: 1000})
+
+	trade, err := Simulate(opportunityXINRUSDT(), buyFrame, buyFrame, config, execution, DefaultModel())
+	if err == nil || !strings.Contains(err.Error(), "stop loss") {
+		t.Fatalf("err = %v, want a stop loss refusal", err)
+	}
+	if trade.Executed {
+		t.Fatal("refused trade marked executed")
+	}
+}
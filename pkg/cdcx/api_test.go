@@ -0,0 +1,168 @@
+package cdcx_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/cdcx"
+)
+
+// Compile-time checks: these fail to build if a public signature changes
+var (
+	_ func(*cdcx.Config) *cdcx.Scanner                                                = cdcx.NewScanner
+	_ func(string, string, *cdcx.ExecutionConfig) *cdcx.Engine                        = cdcx.NewEngine
+	_ func(*cdcx.ExecutionConfig, map[string]float64) (*cdcx.Engine, error)           = cdcx.NewPaperEngine
+	_ func() *cdcx.Config                                                             = cdcx.DefaultConfig
+	_ func() *cdcx.ExecutionConfig                                                    = cdcx.DefaultExecutionConfig
+	_ func(*cdcx.Scanner) ([]cdcx.Opportunity, error)                                 = (*cdcx.Scanner).Scan
+	_ func(*cdcx.Scanner, map[string]cdcx.ArbitragePairs) ([]cdcx.Opportunity, error) = (*cdcx.Scanner).ScanPairs
+	_ func(*cdcx.Engine, []cdcx.Opportunity) (*cdcx.ExecutionResult, error)           = (*cdcx.Engine).Execute
+	_ func(*cdcx.Engine) (bool, error)                                                = (*cdcx.Engine).CheckAccountReadiness
+)
+
+// jsonKeys returns the top-level keys v marshals to
+func jsonKeys(t *testing.T, v interface{}) map[string]bool {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	keys := make(map[string]bool)
+	for key := range fields {
+		keys[key] = true
+	}
+	return keys
+}
+
+// Saved configs, execution logs and profiles rely on these keys; new keys may
+// be added but none of these may be renamed or removed
+func TestConfigJSONKeysAreStable(t *testing.T) {
+	cases := []struct {
+		name  string
+		value interface{}
+		keys  []string
+	}{
+		{"Config", cdcx.DefaultConfig(), []string{
+			"min_net_margin", "min_liquidity", "fee_rate", "max_order_levels",
+			"cache_duration", "rate_cache_file", "valid_currencies", "enable_all_pairs",
+		}},
+		{"ExecutionConfig", cdcx.DefaultExecutionConfig(), []string{
+			"max_position_usdt", "min_required_usdt", "stop_loss_pct", "order_timeout_seconds",
+			"delay_between_orders", "use_market_orders", "max_orders_per_run", "risk_tolerance_level",
+			"execution_policy", "atomic_time_in_force", "protective_stop_pct", "max_slices",
+			"slice_interval_ms", "prevalidation_interval_ms", "max_validation_age_ms",
+			"queue_ttl_seconds", "funding_currency",
+		}},
+		{"ExecutedOrder", cdcx.ExecutedOrder{}, []string{
+			"order_number", "currency", "buy_market", "sell_market", "buy_order_id", "sell_order_id",
+			"planned_volume", "volume_executed", "buy_price", "sell_price", "expected_profit",
+			"actual_profit", "actual_margin_pct", "success", "start_time", "end_time", "execution_time_ms",
+		}},
+		{"ExecutionResult", cdcx.ExecutionResult{}, []string{
+			"currency", "buy_market", "sell_market", "start_time", "end_time", "total_profit",
+			"total_volume", "total_investment", "orders", "successful", "timestamp", "config",
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys := jsonKeys(t, tc.value)
+			for _, key := range tc.keys {
+				if !keys[key] {
+					t.Errorf("%s lost JSON key %q", tc.name, key)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultsAreUsable(t *testing.T) {
+	cfg := cdcx.DefaultConfig()
+	if cfg.MinNetMargin <= 0 || cfg.FeeRate <= 0 || cfg.CacheDuration < time.Minute {
+		t.Errorf("unexpected trading defaults: %+v", cfg)
+	}
+
+	execConfig := cdcx.DefaultExecutionConfig()
+	if execConfig.ExecutionPolicy != "sequential" || execConfig.FundingCurrency != "USDT" {
+		t.Errorf("unexpected execution defaults: %+v", execConfig)
+	}
+}
+
+func TestConstructorsAcceptNilConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if cdcx.NewScanner(nil) == nil {
+		t.Error("NewScanner(nil) returned nil")
+	}
+	if cdcx.NewEngine("key", "secret", nil) == nil {
+		t.Error("NewEngine with nil config returned nil")
+	}
+}
+
+// publicPackage reports whether a named type's package is covered by the
+// stability promise: this package, pkg/types or the standard library
+func publicPackage(pkgPath string) bool {
+	return pkgPath == "" || !strings.Contains(pkgPath, ".") ||
+		strings.HasSuffix(pkgPath, "/pkg/cdcx") || strings.HasSuffix(pkgPath, "/pkg/types")
+}
+
+// checkPublic fails for any type reachable from t, through signatures and
+// exported fields, that comes from a package outside the promise
+func checkPublic(t *testing.T, where string, typ reflect.Type, seen map[reflect.Type]bool) {
+	t.Helper()
+	if seen[typ] {
+		return
+	}
+	seen[typ] = true
+
+	if typ.Name() != "" && !publicPackage(typ.PkgPath()) {
+		t.Errorf("%s exposes %s", where, typ)
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Chan:
+		checkPublic(t, where, typ.Elem(), seen)
+	case reflect.Map:
+		checkPublic(t, where, typ.Key(), seen)
+		checkPublic(t, where, typ.Elem(), seen)
+	case reflect.Func:
+		for i := 0; i < typ.NumIn(); i++ {
+			checkPublic(t, where, typ.In(i), seen)
+		}
+		for i := 0; i < typ.NumOut(); i++ {
+			checkPublic(t, where, typ.Out(i), seen)
+		}
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if field := typ.Field(i); field.IsExported() {
+				checkPublic(t, where+"."+field.Name, field.Type, seen)
+			}
+		}
+	}
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		checkPublic(t, where+"."+method.Name, method.Type, seen)
+	}
+}
+
+func TestPublicAPIExposesOnlyPublicPackages(t *testing.T) {
+	seen := map[reflect.Type]bool{}
+	for name, fn := range map[string]interface{}{
+		"NewScanner":             cdcx.NewScanner,
+		"NewEngine":              cdcx.NewEngine,
+		"NewPaperEngine":         cdcx.NewPaperEngine,
+		"DefaultConfig":          cdcx.DefaultConfig,
+		"DefaultExecutionConfig": cdcx.DefaultExecutionConfig,
+	} {
+		checkPublic(t, name, reflect.TypeOf(fn), seen)
+	}
+}
@@ -0,0 +1,152 @@
+// Package cdcx is the entry point for embedding CoinDCX arbitrage detection
+// and execution in other Go programs. It wires the market, pairs, opportunity
+// and arbitrage packages together so callers work with three constructors
+// instead of the JSON files the command-line pipeline passes between steps:
+//
+//	scanner := cdcx.NewScanner(cdcx.DefaultConfig())
+//	opportunities, err := scanner.Scan()
+//
+//	engine := cdcx.NewEngine(apiKey, apiSecret, cdcx.DefaultExecutionConfig())
+//	result, err := engine.Execute(opportunities)
+//
+// Nothing here reads .env files; credentials are passed in directly. The
+// engine still keeps its state files (rate cache, inventory, dust ledger) in
+// the working directory. Every scanner and engine in the process shares one
+// exchange call budget at the default rate, so together they stay within
+// the exchange's limits.
+//
+// # API stability
+//
+// The public API is this package and pkg/types, whose configs and results it
+// passes through. Within a major version, exported identifiers there are not
+// removed or changed incompatibly and JSON field names of saved configs and
+// execution logs are not renamed; api_test.go enforces the surface. The other
+// packages under pkg/ are what the command-line tools are built from and may
+// change between minor versions, as may anything under internal/.
+package cdcx
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/pkg/arbitrage"
+	"github.com/b-thark/cdcx-api/pkg/exchange"
+	"github.com/b-thark/cdcx-api/pkg/executor"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+	"github.com/b-thark/cdcx-api/pkg/pairs"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Re-exported types so callers need not import pkg/types
+type (
+	Config          = types.Config
+	ExecutionConfig = types.ExecutionConfig
+	ArbitragePairs  = types.ArbitragePairs
+	Opportunity     = types.ArbitrageOpportunity
+	ExecutionResult = types.ExecutionResult
+	ExecutedOrder   = types.ExecutedOrder
+)
+
+// transport is the call budget every scanner and engine sends through
+var transport = sync.OnceValue(func() http.RoundTripper {
+	return exchange.NewTransport(nil, types.DefaultConfig())
+})
+
+// DefaultConfig returns the detection defaults used by the CLI
+func DefaultConfig() *Config {
+	return types.DefaultConfig()
+}
+
+// DefaultExecutionConfig returns the execution defaults used by the CLI
+func DefaultExecutionConfig() *ExecutionConfig {
+	return types.DefaultExecutionConfig()
+}
+
+// Scanner finds arbitrage opportunities straight from live market data
+type Scanner struct {
+	pairs    *pairs.Analyzer
+	detector *opportunity.Detector
+}
+
+// NewScanner creates a scanner; a nil config uses DefaultConfig
+func NewScanner(cfg *Config) *Scanner {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	scanner := &Scanner{
+		pairs:    pairs.NewAnalyzer(cfg),
+		detector: opportunity.NewDetector(cfg),
+	}
+	scanner.pairs.SetTransport(transport())
+	scanner.detector.SetTransport(transport())
+	return scanner
+}
+
+// Pairs fetches the currencies listed on two or more quote markets
+func (s *Scanner) Pairs() (map[string]ArbitragePairs, error) {
+	return s.pairs.ExtractArbitragePairs()
+}
+
+// Scan fetches pairs and prices them, returning every opportunity found;
+// check Opportunity.Viable for the ones that clear the configured margin
+func (s *Scanner) Scan() ([]Opportunity, error) {
+	arbitragePairs, err := s.Pairs()
+	if err != nil {
+		return nil, err
+	}
+	return s.ScanPairs(arbitragePairs)
+}
+
+// ScanPairs prices previously fetched pairs
+func (s *Scanner) ScanPairs(arbitragePairs map[string]ArbitragePairs) ([]Opportunity, error) {
+	return s.detector.FindOpportunities(arbitragePairs)
+}
+
+// Engine executes opportunities found by a Scanner
+type Engine struct {
+	engine *arbitrage.Engine
+}
+
+// NewEngine creates an execution engine trading live on CoinDCX; a nil
+// execConfig uses DefaultExecutionConfig
+func NewEngine(apiKey, apiSecret string, execConfig *ExecutionConfig) *Engine {
+	if execConfig == nil {
+		execConfig = DefaultExecutionConfig()
+	}
+	return &Engine{engine: arbitrage.NewEngine(&config.Config{APIKey: apiKey, APISecret: apiSecret}, execConfig, transport())}
+}
+
+// NewPaperEngine creates an engine that fills orders against live order books
+// without trading, starting from the given balances (currency → amount)
+func NewPaperEngine(execConfig *ExecutionConfig, balances map[string]float64) (*Engine, error) {
+	if execConfig == nil {
+		execConfig = DefaultExecutionConfig()
+	}
+
+	fetcher := market.NewFetcher()
+	fetcher.SetTransport(transport())
+	markets, err := fetcher.GetMarketDetails()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load markets: %v", err)
+	}
+
+	venue := executor.NewSimulatedExecutor(fetcher, markets, DefaultConfig().FeeRate, balances)
+	engine := arbitrage.NewEngineWithVenue(&config.Config{}, execConfig, venue)
+	engine.SetTransport(transport())
+	return &Engine{engine: engine}, nil
+}
+
+// CheckAccountReadiness checks the account holds enough USDT to trade,
+// lowering the position limit to what the balance supports
+func (e *Engine) CheckAccountReadiness() (bool, error) {
+	return e.engine.CheckAccountReadiness()
+}
+
+// Execute validates each viable opportunity against live order books just
+// before trading it, best margin first, until the position limit is reached
+func (e *Engine) Execute(opportunities []Opportunity) (*ExecutionResult, error) {
+	return e.engine.Execute(opportunities)
+}
@@ -2,21 +2,32 @@ package coindcx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
+	"github.com/b-thark/cdcx-api/internal/logging"
 	"github.com/b-thark/cdcx-api/pkg/types"
 )
 
+var logger = logging.For("coindcx")
+
 // Client represents the CoinDCX API client
 type Client struct {
 	APIKey     string
 	APISecret  string
 	BaseURL    string
 	HTTPClient *http.Client
+
+	// An order create that fails in transit is resubmitted, up to
+	// PlaceAttempts in all, only once a lookup finds it was never placed;
+	// PlaceBackoff is the wait before each lookup
+	PlaceAttempts int
+	PlaceBackoff  time.Duration
 }
 
 // NewClient creates a new CoinDCX client
@@ -26,11 +37,20 @@ func NewClient(apiKey, apiSecret string) *Client {
 		APISecret:  apiSecret,
 		BaseURL:    "https://api.coindcx.com",
 		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+
+		PlaceAttempts: 3,
+		PlaceBackoff:  time.Second,
 	}
 }
 
+// SetTransport sends the client's requests through transport, e.g. the
+// process's shared exchange call budget
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.HTTPClient.Transport = transport
+}
+
 // makeAuthenticatedRequest handles the authenticated API requests
-func (c *Client) makeAuthenticatedRequest(endpoint string, requestBody map[string]interface{}) ([]byte, error) {
+func (c *Client) makeAuthenticatedRequest(ctx context.Context, endpoint string, requestBody map[string]interface{}) ([]byte, error) {
 	requestBody["timestamp"] = time.Now().UnixMilli()
 
 	jsonBody, err := json.Marshal(requestBody)
@@ -41,7 +61,7 @@ func (c *Client) makeAuthenticatedRequest(endpoint string, requestBody map[strin
 	signature := c.generateSignature(string(jsonBody))
 
 	url := c.BaseURL + endpoint
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
@@ -52,26 +72,29 @@ func (c *Client) makeAuthenticatedRequest(endpoint string, requestBody map[strin
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, &TransportError{Err: fmt.Errorf("error making request: %v", err)}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
+		return nil, &TransportError{Err: fmt.Errorf("error reading response: %v", err)}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, &APIError{Status: resp.StatusCode, Body: string(body)}
 	}
 
 	return body, nil
 }
 
 // makePublicRequest handles public API requests (no authentication needed)
-func (c *Client) makePublicRequest(endpoint string) ([]byte, error) {
-	url := c.BaseURL + endpoint
-	resp, err := c.HTTPClient.Get(url)
+func (c *Client) makePublicRequest(ctx context.Context, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %v", err)
 	}
@@ -91,9 +114,14 @@ func (c *Client) makePublicRequest(endpoint string) ([]byte, error) {
 
 // GetBalances fetches account balances
 func (c *Client) GetBalances() ([]Balance, error) {
+	return c.GetBalancesContext(context.Background())
+}
+
+// GetBalancesContext is GetBalances, cancelled with ctx
+func (c *Client) GetBalancesContext(ctx context.Context) ([]Balance, error) {
 	requestBody := make(map[string]interface{})
 
-	responseBody, err := c.makeAuthenticatedRequest("/exchange/v1/users/balances", requestBody)
+	responseBody, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/users/balances", requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -108,9 +136,14 @@ func (c *Client) GetBalances() ([]Balance, error) {
 
 // GetUserInfo fetches user account information
 func (c *Client) GetUserInfo() (*UserInfo, error) {
+	return c.GetUserInfoContext(context.Background())
+}
+
+// GetUserInfoContext is GetUserInfo, cancelled with ctx
+func (c *Client) GetUserInfoContext(ctx context.Context) (*UserInfo, error) {
 	requestBody := make(map[string]interface{})
 
-	responseBody, err := c.makeAuthenticatedRequest("/exchange/v1/users/info", requestBody)
+	responseBody, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/users/info", requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +158,12 @@ func (c *Client) GetUserInfo() (*UserInfo, error) {
 
 // GetMarketDetails fetches market details (public endpoint)
 func (c *Client) GetMarketDetails() ([]types.MarketDetail, error) {
-	responseBody, err := c.makePublicRequest("/exchange/v1/markets_details")
+	return c.GetMarketDetailsContext(context.Background())
+}
+
+// GetMarketDetailsContext is GetMarketDetails, cancelled with ctx
+func (c *Client) GetMarketDetailsContext(ctx context.Context) ([]types.MarketDetail, error) {
+	responseBody, err := c.makePublicRequest(ctx, "/exchange/v1/markets_details")
 	if err != nil {
 		return nil, err
 	}
@@ -139,22 +177,83 @@ func (c *Client) GetMarketDetails() ([]types.MarketDetail, error) {
 }
 
 // GetTicker fetches ticker data (public endpoint)
-func (c *Client) GetTicker() ([]map[string]interface{}, error) {
-	responseBody, err := c.makePublicRequest("/exchange/ticker")
+func (c *Client) GetTicker() (types.Tickers, error) {
+	return c.GetTickerContext(context.Background())
+}
+
+// GetTickerContext is GetTicker, cancelled with ctx
+func (c *Client) GetTickerContext(ctx context.Context) (types.Tickers, error) {
+	responseBody, err := c.makePublicRequest(ctx, "/exchange/ticker")
 	if err != nil {
 		return nil, err
 	}
 
-	var ticker []map[string]interface{}
-	if err := json.Unmarshal(responseBody, &ticker); err != nil {
+	tickers, err := types.ParseTickers(responseBody)
+	if err != nil {
 		return nil, fmt.Errorf("error parsing ticker response: %v", err)
 	}
 
-	return ticker, nil
+	return tickers, nil
 }
 
 // CreateOrder creates a new order
 func (c *Client) CreateOrder(orderRequest OrderRequest) (*OrderResponse, error) {
+	return c.CreateOrderContext(context.Background(), orderRequest)
+}
+
+// CreateOrderContext is CreateOrder, cancelled with ctx. An order without a
+// ClientOrderID is given one, so that when the create fails in transit,
+// leaving it unknown whether the exchange placed it, the order can be looked
+// up before it is sent again instead of risking a duplicate.
+func (c *Client) CreateOrderContext(ctx context.Context, orderRequest OrderRequest) (*OrderResponse, error) {
+	requestBody, err := orderRequestBody(orderRequest)
+	if err != nil {
+		return nil, err
+	}
+	if orderRequest.ClientOrderID == "" {
+		orderRequest.ClientOrderID = NewClientOrderID()
+	}
+	requestBody["client_order_id"] = orderRequest.ClientOrderID
+
+	for attempt := 1; ; attempt++ {
+		response, err := c.createOrder(ctx, requestBody)
+		if err == nil {
+			return response, nil
+		}
+
+		var transport *TransportError
+		switch {
+		case errors.As(err, &transport):
+		case attempt > 1:
+			// A resubmission the exchange refused may be a duplicate of an
+			// earlier create that landed after it was looked up
+			if order, found, lookupErr := c.findOrder(ctx, orderRequest.ClientOrderID); lookupErr == nil && found {
+				return &OrderResponse{Orders: []Order{*order}}, nil
+			}
+			return nil, err
+		default:
+			return nil, err
+		}
+
+		order, found, lookupErr := c.findOrder(ctx, orderRequest.ClientOrderID)
+		switch {
+		case lookupErr != nil:
+			return nil, fmt.Errorf("%v; order %s may have been placed: %v", err, orderRequest.ClientOrderID, lookupErr)
+		case found:
+			logger.Info("🔎 Order found placed", "side", orderRequest.Side, "market", orderRequest.Market,
+				"order_id", order.ID, "error", err)
+			return &OrderResponse{Orders: []Order{*order}}, nil
+		case attempt >= c.PlaceAttempts:
+			return nil, err
+		}
+		logger.Warn("🔁 Order not placed, resubmitting", "side", orderRequest.Side, "market", orderRequest.Market,
+			"error", err, "attempt", attempt, "retries", c.PlaceAttempts-1)
+	}
+}
+
+// orderRequestBody is the create request for an order, without its client
+// order ID
+func orderRequestBody(orderRequest OrderRequest) (map[string]interface{}, error) {
 	requestBody := map[string]interface{}{
 		"side":           orderRequest.Side,
 		"order_type":     orderRequest.OrderType,
@@ -162,22 +261,35 @@ func (c *Client) CreateOrder(orderRequest OrderRequest) (*OrderResponse, error)
 		"total_quantity": orderRequest.TotalQuantity,
 	}
 
-	// Add price for limit orders
-	if orderRequest.OrderType == "limit_order" && orderRequest.PricePerUnit > 0 {
+	// Add price for limit and stop-limit orders
+	if (orderRequest.OrderType == "limit_order" || orderRequest.OrderType == "stop_limit") && orderRequest.PricePerUnit > 0 {
 		requestBody["price_per_unit"] = orderRequest.PricePerUnit
 	}
 
+	if orderRequest.OrderType == "stop_limit" && orderRequest.StopPrice <= 0 {
+		return nil, fmt.Errorf("stop_limit order requires a stop_price")
+	}
+
+	// Add time in force for limit orders; market orders always execute immediately
+	if orderRequest.TimeInForce != "" {
+		if orderRequest.OrderType != "limit_order" {
+			return nil, fmt.Errorf("time_in_force %s requires a limit_order, got %s",
+				orderRequest.TimeInForce, orderRequest.OrderType)
+		}
+		requestBody["time_in_force"] = orderRequest.TimeInForce
+	}
+
 	// Add stop price for stop orders
 	if orderRequest.StopPrice > 0 {
 		requestBody["stop_price"] = orderRequest.StopPrice
 	}
 
-	// Add client order ID if provided
-	if orderRequest.ClientOrderID != "" {
-		requestBody["client_order_id"] = orderRequest.ClientOrderID
-	}
+	return requestBody, nil
+}
 
-	responseBody, err := c.makeAuthenticatedRequest("/exchange/v1/orders/create", requestBody)
+// createOrder sends one create request
+func (c *Client) createOrder(ctx context.Context, requestBody map[string]interface{}) (*OrderResponse, error) {
+	responseBody, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/orders/create", requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -190,13 +302,69 @@ func (c *Client) CreateOrder(orderRequest OrderRequest) (*OrderResponse, error)
 	return &orderResponse, nil
 }
 
+// findOrder waits PlaceBackoff, then looks the order up by its client order
+// ID, trying up to PlaceAttempts times while the lookup itself fails in
+// transit. found is false only when the exchange answered it has no such
+// order.
+func (c *Client) findOrder(ctx context.Context, clientOrderID string) (order *Order, found bool, err error) {
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(c.PlaceBackoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, false, ctx.Err()
+		}
+
+		order, err = c.GetOrderByClientIDContext(ctx, clientOrderID)
+		var transport *TransportError
+		switch {
+		case err == nil:
+			return order, true, nil
+		case IsOrderNotFound(err):
+			return nil, false, nil
+		case !errors.As(err, &transport) || attempt >= c.PlaceAttempts:
+			return nil, false, err
+		}
+	}
+}
+
+// GetOrderByClientID fetches an order by the client order ID it was created with
+func (c *Client) GetOrderByClientID(clientOrderID string) (*Order, error) {
+	return c.GetOrderByClientIDContext(context.Background(), clientOrderID)
+}
+
+// GetOrderByClientIDContext is GetOrderByClientID, cancelled with ctx
+func (c *Client) GetOrderByClientIDContext(ctx context.Context, clientOrderID string) (*Order, error) {
+	requestBody := map[string]interface{}{
+		"client_order_id": clientOrderID,
+	}
+
+	responseBody, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/orders/status", requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var order Order
+	if err := json.Unmarshal(responseBody, &order); err != nil {
+		return nil, fmt.Errorf("error parsing order status response: %v", err)
+	}
+
+	return &order, nil
+}
+
 // GetOrderStatus fetches the status of a specific order
 func (c *Client) GetOrderStatus(orderID string) (*Order, error) {
+	return c.GetOrderStatusContext(context.Background(), orderID)
+}
+
+// GetOrderStatusContext is GetOrderStatus, cancelled with ctx
+func (c *Client) GetOrderStatusContext(ctx context.Context, orderID string) (*Order, error) {
 	requestBody := map[string]interface{}{
 		"id": orderID,
 	}
 
-	responseBody, err := c.makeAuthenticatedRequest("/exchange/v1/orders/status", requestBody)
+	responseBody, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/orders/status", requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -209,13 +377,42 @@ func (c *Client) GetOrderStatus(orderID string) (*Order, error) {
 	return &order, nil
 }
 
+// GetOrderStatuses fetches the status of several orders in one request
+func (c *Client) GetOrderStatuses(orderIDs []string) ([]Order, error) {
+	return c.GetOrderStatusesContext(context.Background(), orderIDs)
+}
+
+// GetOrderStatusesContext is GetOrderStatuses, cancelled with ctx
+func (c *Client) GetOrderStatusesContext(ctx context.Context, orderIDs []string) ([]Order, error) {
+	requestBody := map[string]interface{}{
+		"ids": orderIDs,
+	}
+
+	responseBody, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/orders/status_multiple", requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []Order
+	if err := json.Unmarshal(responseBody, &orders); err != nil {
+		return nil, fmt.Errorf("error parsing order statuses response: %v", err)
+	}
+
+	return orders, nil
+}
+
 // GetActiveOrders fetches all active orders for a specific market
 func (c *Client) GetActiveOrders(market string) ([]Order, error) {
+	return c.GetActiveOrdersContext(context.Background(), market)
+}
+
+// GetActiveOrdersContext is GetActiveOrders, cancelled with ctx
+func (c *Client) GetActiveOrdersContext(ctx context.Context, market string) ([]Order, error) {
 	requestBody := map[string]interface{}{
 		"market": market,
 	}
 
-	responseBody, err := c.makeAuthenticatedRequest("/exchange/v1/orders/active_orders", requestBody)
+	responseBody, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/orders/active_orders", requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -228,12 +425,44 @@ func (c *Client) GetActiveOrders(market string) ([]Order, error) {
 	return orders, nil
 }
 
+// GetTradeHistory fetches up to limit of the account's fills between from and to
+func (c *Client) GetTradeHistory(from, to time.Time, limit int) ([]Trade, error) {
+	return c.GetTradeHistoryContext(context.Background(), from, to, limit)
+}
+
+// GetTradeHistoryContext is GetTradeHistory, cancelled with ctx
+func (c *Client) GetTradeHistoryContext(ctx context.Context, from, to time.Time, limit int) ([]Trade, error) {
+	requestBody := map[string]interface{}{
+		"from_timestamp": from.UnixMilli(),
+		"to_timestamp":   to.UnixMilli(),
+		"limit":          limit,
+		"sort":           "desc",
+	}
+
+	responseBody, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/orders/trade_history", requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []Trade
+	if err := json.Unmarshal(responseBody, &trades); err != nil {
+		return nil, fmt.Errorf("error parsing trade history response: %v", err)
+	}
+
+	return trades, nil
+}
+
 // CancelOrder cancels a specific order
 func (c *Client) CancelOrder(orderID string) error {
+	return c.CancelOrderContext(context.Background(), orderID)
+}
+
+// CancelOrderContext is CancelOrder, cancelled with ctx
+func (c *Client) CancelOrderContext(ctx context.Context, orderID string) error {
 	requestBody := map[string]interface{}{
 		"id": orderID,
 	}
 
-	_, err := c.makeAuthenticatedRequest("/exchange/v1/orders/cancel", requestBody)
+	_, err := c.makeAuthenticatedRequest(ctx, "/exchange/v1/orders/cancel", requestBody)
 	return err
 }
@@ -0,0 +1,112 @@
+package coindcx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeExchange places creates, optionally dropping the connection on the
+// first one after (placeThenDrop) or instead of (dropUnplaced) placing it
+type fakeExchange struct {
+	mu            sync.Mutex
+	creates       []string // Client order IDs of every create received
+	placed        map[string]Order
+	placeThenDrop bool
+	dropUnplaced  bool
+	lookupStatus  int // Non-zero answers every lookup with this status
+}
+
+func (f *fakeExchange) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	id, _ := body["client_order_id"].(string)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.URL.Path {
+	case "/exchange/v1/orders/create":
+		f.creates = append(f.creates, id)
+		first := len(f.creates) == 1
+		if !(first && f.dropUnplaced) {
+			f.placed[id] = Order{ID: "order-" + id, ClientOrderID: id, Status: "open"}
+		}
+		if first && (f.placeThenDrop || f.dropUnplaced) {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		json.NewEncoder(w).Encode(OrderResponse{Orders: []Order{f.placed[id]}})
+	case "/exchange/v1/orders/status":
+		order, ok := f.placed[id]
+		switch {
+		case f.lookupStatus != 0:
+			w.WriteHeader(f.lookupStatus)
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"Order not found"}`))
+		default:
+			json.NewEncoder(w).Encode(order)
+		}
+	}
+}
+
+func newTestClient(exchange *fakeExchange) (*Client, func()) {
+	exchange.placed = map[string]Order{}
+	server := httptest.NewServer(exchange)
+	client := NewClient("key", "secret")
+	client.BaseURL = server.URL
+	client.PlaceBackoff = 0
+	return client, server.Close
+}
+
+func TestCreateOrderFoundAfterDroppedResponse(t *testing.T) {
+	exchange := &fakeExchange{placeThenDrop: true}
+	client, done := newTestClient(exchange)
+	defer done()
+
+	response, err := client.CreateOrder(OrderRequest{Side: "buy", OrderType: "market_order", Market: "BTCINR", TotalQuantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchange.creates) != 1 {
+		t.Errorf("sent %d creates, want 1: the order was placed", len(exchange.creates))
+	}
+	if response.Orders[0].ClientOrderID != exchange.creates[0] || !strings.HasPrefix(exchange.creates[0], "cdcx-") {
+		t.Errorf("returned %+v for client order %q", response.Orders[0], exchange.creates[0])
+	}
+}
+
+func TestCreateOrderResubmittedWhenNotPlaced(t *testing.T) {
+	exchange := &fakeExchange{dropUnplaced: true}
+	client, done := newTestClient(exchange)
+	defer done()
+
+	response, err := client.CreateOrder(OrderRequest{Side: "buy", OrderType: "market_order", Market: "BTCINR", TotalQuantity: 1, ClientOrderID: "mine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchange.creates) != 2 || exchange.creates[1] != "mine" {
+		t.Errorf("creates = %v, want the same ID twice", exchange.creates)
+	}
+	if len(exchange.placed) != 1 || response.Orders[0].ID != "order-mine" {
+		t.Errorf("placed %v, returned %+v", exchange.placed, response.Orders[0])
+	}
+}
+
+func TestCreateOrderNotResubmittedWhileUnconfirmed(t *testing.T) {
+	exchange := &fakeExchange{dropUnplaced: true, lookupStatus: http.StatusInternalServerError}
+	client, done := newTestClient(exchange)
+	defer done()
+
+	_, err := client.CreateOrder(OrderRequest{Side: "buy", OrderType: "market_order", Market: "BTCINR", TotalQuantity: 1, ClientOrderID: "mine"})
+	if err == nil || !strings.Contains(err.Error(), "mine may have been placed") {
+		t.Errorf("err = %v", err)
+	}
+	if len(exchange.creates) != 1 {
+		t.Errorf("sent %d creates without confirming the first wasn't placed", len(exchange.creates))
+	}
+}
@@ -0,0 +1,51 @@
+package coindcx
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TransportError is a request that failed without a complete response: the
+// exchange may or may not have acted on it
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// APIError is a request the exchange answered with a failure status
+type APIError struct {
+	Status int
+	Body   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.Status, e.Body)
+}
+
+// IsOrderNotFound reports whether the exchange answered that an order
+// lookup matched no order
+func IsOrderNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Status == http.StatusNotFound || strings.Contains(strings.ToLower(apiErr.Body), "not found")
+}
+
+// NewClientOrderID returns a unique client order ID for an order create
+func NewClientOrderID() string {
+	id := make([]byte, 12)
+	rand.Read(id)
+	return "cdcx-" + hex.EncodeToString(id)
+}
@@ -2,7 +2,11 @@ package coindcx
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
 )
 
 // Balance represents account balance for a currency
@@ -24,15 +28,23 @@ type UserInfo struct {
 // OrderRequest represents a request to create an order
 type OrderRequest struct {
 	Side          string  `json:"side"`                      // "buy" or "sell"
-	OrderType     string  `json:"order_type"`                // "market_order" or "limit_order"
+	OrderType     string  `json:"order_type"`                // "market_order", "limit_order" or "stop_limit"
 	Market        string  `json:"market"`                    // e.g., "BTCINR"
 	TotalQuantity float64 `json:"total_quantity"`            // Amount to trade
 	PricePerUnit  float64 `json:"price_per_unit,omitempty"`  // Price for limit orders
 	StopPrice     float64 `json:"stop_price,omitempty"`      // Stop price for stop orders
+	TimeInForce   string  `json:"time_in_force,omitempty"`   // Limit orders only: good_till_cancel, immediate_or_cancel, fill_or_kill
 	ClientOrderID string  `json:"client_order_id,omitempty"` // Optional client order ID
 	Timestamp     int64   `json:"timestamp"`                 // Unix timestamp in milliseconds
 }
 
+// Time-in-force values accepted for limit orders
+const (
+	TimeInForceGTC = "good_till_cancel"
+	TimeInForceIOC = "immediate_or_cancel"
+	TimeInForceFOK = "fill_or_kill"
+)
+
 // FlexibleTimestamp handles both string and int timestamps
 type FlexibleTimestamp string
 
@@ -61,6 +73,48 @@ func (ft *FlexibleTimestamp) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, (*string)(ft))
 }
 
+// Time parses the timestamp; an empty or malformed value is an error
+func (ft FlexibleTimestamp) Time() (time.Time, error) {
+	return utils.ParseTimestamp(string(ft))
+}
+
+// FlexibleFloat handles numbers sent either as JSON numbers or strings
+type FlexibleFloat float64
+
+func (ff *FlexibleFloat) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err == nil {
+		*ff = FlexibleFloat(f)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*ff = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %v", s, err)
+	}
+	*ff = FlexibleFloat(f)
+	return nil
+}
+
+// Trade is one fill from the account's trade history
+type Trade struct {
+	OrderID   string            `json:"order_id"`
+	Side      string            `json:"side"`
+	FeeAmount FlexibleFloat     `json:"fee_amount"` // In the market's quote currency
+	Quantity  float64           `json:"quantity"`
+	Price     float64           `json:"price"`
+	Symbol    string            `json:"symbol"`
+	Timestamp FlexibleTimestamp `json:"timestamp"`
+}
+
 // Order represents an order returned by the API
 type Order struct {
 	ID                string            `json:"id"`
@@ -2,33 +2,73 @@ package depth
 
 import (
 	"fmt"
-	"log"
+	"net/http"
 	"sort"
-	"strconv"
 	"time"
 
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/internal/version"
 	"github.com/b-thark/cdcx-api/pkg/exchange"
 	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/recorder"
 	"github.com/b-thark/cdcx-api/pkg/types"
-	"github.com/b-thark/cdcx-api/pkg/utils"
 )
 
+var logger = logging.For("depth")
+
 type Analyzer struct {
 	fetcher     *market.Fetcher
+	books       market.BookFetcher  // The fetcher, or the replayed frame
+	recording   *recorder.Recording // Set when replaying
+	capturedAt  time.Time           // The replayed frame's capture time
 	rateManager *exchange.RateManager
 	config      *types.Config
 }
 
 func NewAnalyzer(config *types.Config) *Analyzer {
+	fetcher := market.NewFetcher()
 	return &Analyzer{
-		fetcher:     market.NewFetcher(),
+		fetcher:     fetcher,
+		books:       fetcher,
 		rateManager: exchange.NewRateManager(config),
 		config:      config,
 	}
 }
 
+// SetTransport sends the analyzer's requests through transport
+func (a *Analyzer) SetTransport(transport http.RoundTripper) {
+	a.fetcher.SetTransport(transport)
+	a.rateManager.SetTransport(transport)
+}
+
+// NewReplayAnalyzer creates an analyzer that walks the books and INR rates
+// a recording held when each opportunity was detected, instead of the live
+// exchange, so the analysis of a recorded run is reproducible
+func NewReplayAnalyzer(config *types.Config, recording *recorder.Recording) *Analyzer {
+	a := NewAnalyzer(config)
+	a.recording = recording
+	return a
+}
+
+// replayFrame switches to the recorded frame the opportunity was detected in
+func (a *Analyzer) replayFrame(opp types.ArbitrageOpportunity) {
+	frame := a.recording.At(opp.Timestamp)
+	a.books = frame
+	a.capturedAt = frame.CapturedAt
+	a.rateManager.Pin(frame.Rates)
+}
+
+// now is when books are read: the replayed frame's time when replaying
+func (a *Analyzer) now() time.Time {
+	if a.recording != nil {
+		return a.capturedAt
+	}
+	return time.Now()
+}
+
 func (a *Analyzer) AnalyzeDepth(opportunities []types.ArbitrageOpportunity) ([]types.ArbitrageDepthAnalysis, error) {
-	log.Println("🔬 Starting order book depth analysis...")
+	logger.Info("🔬 Starting order book depth analysis...")
 
 	// Filter only viable opportunities
 	viableOpps := []types.ArbitrageOpportunity{}
@@ -42,26 +82,33 @@ func (a *Analyzer) AnalyzeDepth(opportunities []types.ArbitrageOpportunity) ([]t
 		return nil, fmt.Errorf("no viable opportunities to analyze")
 	}
 
-	log.Printf("📊 Analyzing depth for %d viable opportunities...", len(viableOpps))
+	logger.Info("📊 Analyzing depth", "opportunities", len(viableOpps))
+
+	// Market details supply the precision volumes are displayed with; replays stay offline
+	if a.recording == nil {
+		if _, err := a.fetcher.GetMarketDetails(); err != nil {
+			logger.Warn("⚠️ Market precision unavailable", "error", err)
+		}
+	}
 
 	analyses := []types.ArbitrageDepthAnalysis{}
 
 	for _, opp := range viableOpps {
-		log.Printf("🔍 Analyzing %s: %s → %s",
-			opp.TargetCurrency, opp.BuyMarket.Symbol, opp.SellMarket.Symbol)
+		logger.Info("🔍 Analyzing", "currency", opp.TargetCurrency, "buy_market", opp.BuyMarket.Symbol,
+			"sell_market", opp.SellMarket.Symbol)
 
 		analysis, err := a.analyzeOpportunityDepth(opp)
 		if err != nil {
-			log.Printf("❌ %s: %v", opp.TargetCurrency, err)
+			logger.Warn("❌ Depth not analyzed", "currency", opp.TargetCurrency, "error", err)
 			continue
 		}
 
 		if analysis.MaxProfitableOrders > 0 {
 			analyses = append(analyses, analysis)
-			log.Printf("✅ %s: %d profitable orders, ₹%.2f total profit",
-				opp.TargetCurrency, analysis.MaxProfitableOrders, analysis.TotalEstimatedProfit)
+			logger.Info("✅ Profitable depth", "currency", opp.TargetCurrency, "orders", analysis.MaxProfitableOrders,
+				"profit_inr", analysis.TotalEstimatedProfit)
 		} else {
-			log.Printf("⚠️ %s: No profitable depth found", opp.TargetCurrency)
+			logger.Info("⚠️ No profitable depth found", "currency", opp.TargetCurrency)
 		}
 	}
 
@@ -72,6 +119,10 @@ func (a *Analyzer) AnalyzeDepth(opportunities []types.ArbitrageOpportunity) ([]t
 }
 
 func (a *Analyzer) analyzeOpportunityDepth(opp types.ArbitrageOpportunity) (types.ArbitrageDepthAnalysis, error) {
+	if a.recording != nil {
+		a.replayFrame(opp)
+	}
+
 	// Create PairInfo from opportunity data with base currencies
 	buyPair := types.PairInfo{
 		Symbol:         opp.BuyMarket.Symbol,
@@ -102,7 +153,7 @@ func (a *Analyzer) analyzeOpportunityDepth(opp types.ArbitrageOpportunity) (type
 }
 
 func (a *Analyzer) getEnhancedOrderBook(pair types.PairInfo) (types.EnhancedOrderBook, error) {
-	rawOrderBook, err := a.fetcher.GetOrderBook(pair.Pair)
+	book, err := a.books.GetOrderBook(pair.Pair)
 	if err != nil {
 		return types.EnhancedOrderBook{}, err
 	}
@@ -111,25 +162,21 @@ func (a *Analyzer) getEnhancedOrderBook(pair types.PairInfo) (types.EnhancedOrde
 		Symbol:       pair.Symbol,
 		Pair:         pair.Pair,
 		BaseCurrency: pair.BaseCurrency,
-		Timestamp:    time.Now(),
+		Timestamp:    a.now(),
 	}
 
 	// Process bids
-	if bids, ok := rawOrderBook["bids"].(map[string]interface{}); ok {
-		orderBook.BidLevels = a.processOrderBookSide(bids, pair.BaseCurrency, "bid")
-		if len(orderBook.BidLevels) > 0 {
-			orderBook.BestBid = orderBook.BidLevels[0].Price
-			orderBook.BestBidINR = orderBook.BidLevels[0].PriceINR
-		}
+	orderBook.BidLevels = a.processOrderBookSide(book.Bids, pair.BaseCurrency)
+	if len(orderBook.BidLevels) > 0 {
+		orderBook.BestBid = orderBook.BidLevels[0].Price
+		orderBook.BestBidINR = orderBook.BidLevels[0].PriceINR
 	}
 
 	// Process asks
-	if asks, ok := rawOrderBook["asks"].(map[string]interface{}); ok {
-		orderBook.AskLevels = a.processOrderBookSide(asks, pair.BaseCurrency, "ask")
-		if len(orderBook.AskLevels) > 0 {
-			orderBook.BestAsk = orderBook.AskLevels[0].Price
-			orderBook.BestAskINR = orderBook.AskLevels[0].PriceINR
-		}
+	orderBook.AskLevels = a.processOrderBookSide(book.Asks, pair.BaseCurrency)
+	if len(orderBook.AskLevels) > 0 {
+		orderBook.BestAsk = orderBook.AskLevels[0].Price
+		orderBook.BestAskINR = orderBook.AskLevels[0].PriceINR
 	}
 
 	// Calculate spread and totals
@@ -148,44 +195,8 @@ func (a *Analyzer) getEnhancedOrderBook(pair types.PairInfo) (types.EnhancedOrde
 	return orderBook, nil
 }
 
-func (a *Analyzer) processOrderBookSide(orders map[string]interface{}, baseCurrency, side string) []types.OrderBookLevel {
-	type priceLevel struct {
-		price  float64
-		volume float64
-	}
-
-	levels := []priceLevel{}
-
-	for priceStr, volumeInterface := range orders {
-		price, err := strconv.ParseFloat(priceStr, 64)
-		if err != nil {
-			continue
-		}
-
-		var volume float64
-		switch v := volumeInterface.(type) {
-		case string:
-			volume, _ = strconv.ParseFloat(v, 64)
-		case float64:
-			volume = v
-		}
-
-		if volume > 0 {
-			levels = append(levels, priceLevel{price: price, volume: volume})
-		}
-	}
-
-	// Sort levels
-	if side == "bid" {
-		sort.Slice(levels, func(i, j int) bool {
-			return levels[i].price > levels[j].price
-		})
-	} else {
-		sort.Slice(levels, func(i, j int) bool {
-			return levels[i].price < levels[j].price
-		})
-	}
-
+// processOrderBookSide prices parsed levels (best first) in INR with cumulative volume
+func (a *Analyzer) processOrderBookSide(levels []types.OrderLevel, baseCurrency string) []types.OrderBookLevel {
 	// Convert to enhanced levels
 	enhanced := []types.OrderBookLevel{}
 	cumulative := 0.0
@@ -198,20 +209,20 @@ func (a *Analyzer) processOrderBookSide(orders map[string]interface{}, baseCurre
 	for i := 0; i < maxLevels; i++ {
 		level := levels[i]
 
-		priceINR, err := a.rateManager.ConvertToINR(level.price, baseCurrency)
+		priceINR, err := a.rateManager.ReportINR(level.Price, baseCurrency)
 		if err != nil {
-			log.Printf("      ⚠️ Price conversion failed for %f %s: %v", level.price, baseCurrency, err)
+			logger.Warn("⚠️ Price conversion failed", "price", level.Price, "currency", baseCurrency, "error", err)
 			continue
 		}
 
-		cumulative += level.volume
+		cumulative += level.Volume
 
 		enhanced = append(enhanced, types.OrderBookLevel{
-			Price:      level.price,
-			Volume:     level.volume,
+			Price:      level.Price,
+			Volume:     level.Volume,
 			PriceINR:   priceINR,
 			Cumulative: cumulative,
-			VolumeINR:  level.volume * priceINR,
+			VolumeINR:  level.Volume * priceINR,
 		})
 	}
 
@@ -219,26 +230,27 @@ func (a *Analyzer) processOrderBookSide(orders map[string]interface{}, baseCurre
 }
 
 func (a *Analyzer) simulateArbitrageDepth(currency string, buyMarket, sellMarket types.EnhancedOrderBook) types.ArbitrageDepthAnalysis {
-	log.Printf("   🧮 SIMULATING: %s", currency)
-	log.Printf("      🟢 BUY from %s (best: ₹%.4f)", buyMarket.Symbol, buyMarket.BestAskINR)
-	log.Printf("      🔴 SELL to %s (best: ₹%.4f)", sellMarket.Symbol, sellMarket.BestBidINR)
+	logger.Debug("🧮 SIMULATING", "currency", currency, "buy_market", buyMarket.Symbol, "best_ask_inr", buyMarket.BestAskINR,
+		"sell_market", sellMarket.Symbol, "best_bid_inr", sellMarket.BestBidINR)
 
 	analysis := types.ArbitrageDepthAnalysis{
 		Currency:   currency,
 		BuyMarket:  buyMarket,
 		SellMarket: sellMarket,
-		Timestamp:  time.Now(),
+		Timestamp:  a.now(),
+		BooksAt:    buyMarket.Timestamp,
+	}
+	if sellMarket.Timestamp.Before(analysis.BooksAt) {
+		analysis.BooksAt = sellMarket.Timestamp
 	}
 
 	if buyMarket.BestAskINR >= sellMarket.BestBidINR {
-		log.Printf("      ❌ No arbitrage: buy ₹%.4f >= sell ₹%.4f",
-			buyMarket.BestAskINR, sellMarket.BestBidINR)
+		logger.Debug("❌ No arbitrage", "buy_inr", buyMarket.BestAskINR, "sell_inr", sellMarket.BestBidINR)
 		return analysis
 	}
 
-	log.Printf("      ✅ Initial margin: ₹%.4f (%.2f%%)",
-		sellMarket.BestBidINR-buyMarket.BestAskINR,
-		((sellMarket.BestBidINR-buyMarket.BestAskINR)/buyMarket.BestAskINR)*100)
+	logger.Debug("✅ Initial margin", "margin_inr", sellMarket.BestBidINR-buyMarket.BestAskINR,
+		"margin_pct", ((sellMarket.BestBidINR-buyMarket.BestAskINR)/buyMarket.BestAskINR)*100)
 
 	// Simulate step by step order execution
 	buyLevelIdx := 0
@@ -249,6 +261,10 @@ func (a *Analyzer) simulateArbitrageDepth(currency string, buyMarket, sellMarket
 	cumulativeVolumeINR := 0.0
 	cumulativeNetProfit := 0.0
 
+	// The fee buffer is charged on the buy value; with per-market overrides
+	// it becomes the mean of the two legs' rates
+	feeRate := (a.config.FeeRateFor(buyMarket.Symbol) + a.config.FeeRateFor(sellMarket.Symbol)) / 2
+
 	for buyLevelIdx < len(buyMarket.AskLevels) && sellLevelIdx < len(sellMarket.BidLevels) {
 		buyLevel := buyMarket.AskLevels[buyLevelIdx]
 		sellLevel := sellMarket.BidLevels[sellLevelIdx]
@@ -268,12 +284,12 @@ func (a *Analyzer) simulateArbitrageDepth(currency string, buyMarket, sellMarket
 
 		// Calculate fees and net margin
 		tradeValueINR := tradeableVolume * buyPriceINR
-		estimatedFees := tradeValueINR * a.config.FeeRate
+		estimatedFees := tradeValueINR * feeRate
 		netMargin := (grossMargin * tradeableVolume) - estimatedFees
 		netMarginPct := (netMargin / tradeValueINR) * 100
 
-		log.Printf("      📋 Order %d: Vol %.4f, Buy ₹%.4f, Sell ₹%.4f, Net %.2f%%",
-			orderNumber, tradeableVolume, buyPriceINR, sellPriceINR, netMarginPct)
+		logger.Debug("📋 Order", "order", orderNumber, "volume", market.FormatQuantity(buyMarket.Symbol, tradeableVolume),
+			"buy_inr", buyPriceINR, "sell_inr", sellPriceINR, "net_margin_pct", netMarginPct)
 
 		// Check if still profitable
 		profitable := netMarginPct >= a.config.MinNetMargin
@@ -295,6 +311,8 @@ func (a *Analyzer) simulateArbitrageDepth(currency string, buyMarket, sellMarket
 				NetMargin:      netMargin,
 				NetMarginPct:   netMarginPct,
 				Profitable:     true,
+				BuyLevelPrice:  buyLevel.Price,
+				SellLevelPrice: sellLevel.Price,
 			}
 			simulation.Cumulative.Volume = cumulativeVolume
 			simulation.Cumulative.VolumeINR = cumulativeVolumeINR
@@ -303,9 +321,9 @@ func (a *Analyzer) simulateArbitrageDepth(currency string, buyMarket, sellMarket
 			analysis.OrderSimulations = append(analysis.OrderSimulations, simulation)
 			analysis.MaxProfitableOrders = orderNumber
 
-			log.Printf("         ✅ Profitable! Net: ₹%.2f, Cumulative: ₹%.2f", netMargin, cumulativeNetProfit)
+			logger.Debug("✅ Profitable", "net_margin_inr", netMargin, "cumulative_inr", cumulativeNetProfit)
 		} else {
-			log.Printf("         ❌ No longer profitable (%.2f%% < %.1f%%)", netMarginPct, a.config.MinNetMargin)
+			logger.Debug("❌ No longer profitable", "net_margin_pct", netMarginPct, "min_net_margin", a.config.MinNetMargin)
 			break
 		}
 
@@ -339,13 +357,17 @@ func (a *Analyzer) simulateArbitrageDepth(currency string, buyMarket, sellMarket
 		analysis.OpportunityRating = "poor"
 	}
 
-	log.Printf("      🎯 RESULT: %d profitable orders, ₹%.2f total profit, %s rating",
-		analysis.MaxProfitableOrders, analysis.TotalEstimatedProfit, analysis.OpportunityRating)
+	logger.Info("🎯 RESULT", "currency", currency, "orders", analysis.MaxProfitableOrders,
+		"profit_inr", analysis.TotalEstimatedProfit, "rating", analysis.OpportunityRating)
 
 	return analysis
 }
 
 func (a *Analyzer) SaveAnalyses(analyses []types.ArbitrageDepthAnalysis, filename string) error {
+	run := version.Current()
+	for i := range analyses {
+		analyses[i].Run = run
+	}
 	return utils.SaveJSON(analyses, filename)
 }
 
@@ -372,8 +394,8 @@ func (a *Analyzer) DisplayResults(analyses []types.ArbitrageDepthAnalysis) {
 	for i, analysis := range analyses {
 		fmt.Printf("\n%d. 💎 %s (%s)\n", i+1, analysis.Currency, analysis.OpportunityRating)
 		fmt.Printf("   🟢 BUY:  %s → 🔴 SELL: %s\n", analysis.BuyMarket.Symbol, analysis.SellMarket.Symbol)
-		fmt.Printf("   📊 Max Orders: %d | Total Volume: %.4f tokens\n",
-			analysis.MaxProfitableOrders, analysis.TotalProfitableVolume)
+		fmt.Printf("   📊 Max Orders: %d | Total Volume: %s tokens\n",
+			analysis.MaxProfitableOrders, market.FormatQuantity(analysis.BuyMarket.Symbol, analysis.TotalProfitableVolume))
 
 		if len(analysis.OrderSimulations) > 0 {
 			lastSim := analysis.OrderSimulations[len(analysis.OrderSimulations)-1]
@@ -387,8 +409,8 @@ func (a *Analyzer) DisplayResults(analyses []types.ArbitrageDepthAnalysis) {
 			fmt.Printf("   📋 Order Breakdown:\n")
 			for j, sim := range analysis.OrderSimulations {
 				if j < 3 { // Show first 3 orders
-					fmt.Printf("      %d. Vol: %.4f @ ₹%.4f→₹%.4f = ₹%.2f profit (%.2f%%)\n",
-						sim.OrderNumber, sim.Volume, sim.BuyPrice, sim.SellPrice,
+					fmt.Printf("      %d. Vol: %s @ ₹%.4f→₹%.4f = ₹%.2f profit (%.2f%%)\n",
+						sim.OrderNumber, market.FormatQuantity(analysis.BuyMarket.Symbol, sim.Volume), sim.BuyPrice, sim.SellPrice,
 						sim.NetMargin, sim.NetMarginPct)
 				}
 			}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+)
+
+var logger = logging.For("events")
+
+// Bus fans events out to every subscriber synchronously, in subscription
+// order. Notification backends, webhooks, stores and metrics all attach here
+// instead of hooking engine code.
+type Bus struct {
+	subscribers []func(Event)
+	mu          sync.RWMutex
+}
+
+// NewBus creates a bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for every published event
+func (b *Bus) Subscribe(handler func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, handler)
+}
+
+// Publish delivers an event to all subscribers. A nil bus drops events.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, handler := range subscribers {
+		handler(event)
+	}
+}
+
+// LogSink writes events as structured records in the engine's log style:
+// the message reads as before and every value, plus the event kind and any
+// execution ID, is an attribute
+func LogSink(event Event) {
+	kind := slog.String("kind", event.Kind())
+	log := logger
+	if id := event.Execution(); id != "" {
+		log = logger.With("execution_id", id)
+	}
+	switch e := event.(type) {
+	case OpportunityDetected:
+		log.Info("🎯 DETECTED", kind, "currency", e.Currency, "buy_market", e.BuyMarket,
+			"sell_market", e.SellMarket, "margin_pct", e.MarginPct)
+	case OpportunityDropped:
+		log.Warn("🗑️ Dropped before execution", kind, "currency", e.Currency, "buy_market", e.BuyMarket,
+			"sell_market", e.SellMarket, "margin_pct", e.MarginPct, "reason", e.Reason)
+	case OrderPlaced:
+		log.Info("📤 Order placed", kind, "order_id", e.OrderID, "market", e.Market, "side", e.Side, "quantity", e.Quantity)
+	case OrderFilled:
+		log.Info("✅ Order filled", kind, "order_id", e.OrderID, "market", e.Market, "side", e.Side,
+			"quantity", e.Quantity, "avg_price", e.AvgPrice, "fee", e.Fee)
+	case ExecutionCompleted:
+		if e.Success {
+			log.Info("💰 COMPLETE", kind, "currency", e.Currency, "volume", e.Volume,
+				"profit_inr", e.Profit, "margin_pct", e.MarginPct)
+		} else {
+			log.Error("❌ FAILED", kind, "currency", e.Currency, "volume", e.Volume, "error", e.Error)
+		}
+	case RecoveryTriggered:
+		log.Warn("⚠️ Recovering", kind, "currency", e.Currency, "volume", e.Volume, "reason", e.Reason)
+	case RecoveryFailed:
+		log.Error("🚨 Recovery failed", kind, "currency", e.Currency, "volume", e.Volume,
+			"reason", e.Reason, "manual_required", e.ManualRequired)
+	case RiskTripped:
+		log.Warn("🛑 Blocked", kind, "rule", e.Rule, "subject", e.Subject, "detail", e.Detail)
+	case BalanceMismatch:
+		log.Error("🚨 Balance moved outside the bot", kind, "currency", e.Currency, "expected", e.Expected,
+			"actual", e.Actual, "unexplained", e.Unexplained)
+	case LowBalance:
+		log.Warn("🪫 Balance low", kind, "currency", e.Currency, "available", e.Available,
+			"available_usdt", e.AvailableUSDT, "required_usdt", e.RequiredUSDT)
+	}
+}
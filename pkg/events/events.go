@@ -0,0 +1,228 @@
+package events
+
+import "time"
+
+// Event kinds
+const (
+	KindOpportunityDetected = "opportunity_detected"
+	KindOpportunityDropped  = "opportunity_dropped"
+	KindOrderPlaced         = "order_placed"
+	KindOrderFilled         = "order_filled"
+	KindExecutionCompleted  = "execution_completed"
+	KindRecoveryTriggered   = "recovery_triggered"
+	KindRecoveryFailed      = "recovery_failed"
+	KindRiskTripped         = "risk_tripped"
+	KindBalanceMismatch     = "balance_mismatch"
+	KindLowBalance          = "low_balance"
+)
+
+// Event is anything published on the bus. Sinks switch on the concrete type
+// or Kind; every event carries the time it happened and, when it belongs to
+// one, the execution attempt.
+type Event interface {
+	Kind() string
+	At() time.Time
+	Execution() string
+}
+
+// Base carries the timestamp and execution shared by all events
+type Base struct {
+	Time        time.Time `json:"time"`
+	ExecutionID string    `json:"execution_id,omitempty"`
+}
+
+func (b Base) At() time.Time { return b.Time }
+
+func (b Base) Execution() string { return b.ExecutionID }
+
+func now() Base { return Base{Time: time.Now()} }
+
+// OpportunityDetected is a viable opportunity found by a scan
+type OpportunityDetected struct {
+	Base
+	Currency   string  `json:"currency"`
+	BuyMarket  string  `json:"buy_market"`
+	SellMarket string  `json:"sell_market"`
+	MarginPct  float64 `json:"margin_pct"`
+}
+
+func (OpportunityDetected) Kind() string { return KindOpportunityDetected }
+
+// OpportunityDropped is a detected opportunity let go before execution
+// because executions fell behind detections
+type OpportunityDropped struct {
+	Base
+	Currency   string  `json:"currency"`
+	BuyMarket  string  `json:"buy_market"`
+	SellMarket string  `json:"sell_market"`
+	MarginPct  float64 `json:"margin_pct"`
+	Reason     string  `json:"reason"` // stale, evicted or full
+}
+
+func (OpportunityDropped) Kind() string { return KindOpportunityDropped }
+
+// OrderPlaced is an order accepted by the venue
+type OrderPlaced struct {
+	Base
+	OrderID  string  `json:"order_id"`
+	Market   string  `json:"market"`
+	Side     string  `json:"side"`
+	Quantity float64 `json:"quantity"`
+}
+
+func (OrderPlaced) Kind() string { return KindOrderPlaced }
+
+// OrderFilled is an order that completed
+type OrderFilled struct {
+	Base
+	OrderID  string  `json:"order_id"`
+	Market   string  `json:"market"`
+	Side     string  `json:"side"`
+	Quantity float64 `json:"quantity"`
+	AvgPrice float64 `json:"avg_price"`
+	Fee      float64 `json:"fee"`
+}
+
+func (OrderFilled) Kind() string { return KindOrderFilled }
+
+// ExecutionCompleted is the outcome of one opportunity's execution
+type ExecutionCompleted struct {
+	Base
+	Currency  string  `json:"currency"`
+	Success   bool    `json:"success"`
+	Volume    float64 `json:"volume"`
+	Profit    float64 `json:"profit"`
+	MarginPct float64 `json:"margin_pct"`
+	Error     string  `json:"error,omitempty"`
+}
+
+func (ExecutionCompleted) Kind() string { return KindExecutionCompleted }
+
+// RecoveryTriggered is a failed arbitrage whose inventory is being liquidated
+type RecoveryTriggered struct {
+	Base
+	Currency string  `json:"currency"`
+	Volume   float64 `json:"volume"`
+	Reason   string  `json:"reason"`
+}
+
+func (RecoveryTriggered) Kind() string { return KindRecoveryTriggered }
+
+// RecoveryFailed is inventory recovery couldn't sell; it stays held
+type RecoveryFailed struct {
+	Base
+	Currency       string  `json:"currency"`
+	Volume         float64 `json:"volume"`
+	Reason         string  `json:"reason"`
+	ManualRequired bool    `json:"manual_required"` // No tradable route back
+}
+
+func (RecoveryFailed) Kind() string { return KindRecoveryFailed }
+
+// RiskTripped is a guard that blocked or shrank a trade
+type RiskTripped struct {
+	Base
+	Rule    string `json:"rule"`
+	Subject string `json:"subject"`
+	Detail  string `json:"detail"`
+}
+
+func (RiskTripped) Kind() string { return KindRiskTripped }
+
+// BalanceMismatch is a balance that moved by more than the bot's own fills
+// explain, e.g. a manual trade or withdrawal on the same account
+type BalanceMismatch struct {
+	Base
+	Currency    string  `json:"currency"`
+	Previous    float64 `json:"previous"`
+	Expected    float64 `json:"expected"` // Previous plus the bot's fills since
+	Actual      float64 `json:"actual"`
+	Unexplained float64 `json:"unexplained"`
+}
+
+func (BalanceMismatch) Kind() string { return KindBalanceMismatch }
+
+// LowBalance is spendable funding that dropped below the minimum needed to
+// trade, valued in USDT
+type LowBalance struct {
+	Base
+	Currency      string  `json:"currency"`
+	Available     float64 `json:"available"` // In Currency
+	AvailableUSDT float64 `json:"available_usdt"`
+	RequiredUSDT  float64 `json:"required_usdt"`
+}
+
+func (LowBalance) Kind() string { return KindLowBalance }
+
+// Tag attributes an event to an execution attempt. Events that never belong
+// to one (detections, balance checks) are returned as they are.
+func Tag(event Event, executionID string) Event {
+	switch e := event.(type) {
+	case OrderPlaced:
+		e.ExecutionID = executionID
+		return e
+	case OrderFilled:
+		e.ExecutionID = executionID
+		return e
+	case ExecutionCompleted:
+		e.ExecutionID = executionID
+		return e
+	case RecoveryTriggered:
+		e.ExecutionID = executionID
+		return e
+	case RecoveryFailed:
+		e.ExecutionID = executionID
+		return e
+	case RiskTripped:
+		e.ExecutionID = executionID
+		return e
+	}
+	return event
+}
+
+// NewOpportunityDetected stamps a detection with the current time
+func NewOpportunityDetected(currency, buyMarket, sellMarket string, marginPct float64) OpportunityDetected {
+	return OpportunityDetected{Base: now(), Currency: currency, BuyMarket: buyMarket, SellMarket: sellMarket, MarginPct: marginPct}
+}
+
+// NewOpportunityDropped stamps a dropped opportunity with the current time
+func NewOpportunityDropped(currency, buyMarket, sellMarket string, marginPct float64, reason string) OpportunityDropped {
+	return OpportunityDropped{Base: now(), Currency: currency, BuyMarket: buyMarket, SellMarket: sellMarket,
+		MarginPct: marginPct, Reason: reason}
+}
+
+// NewOrderPlaced stamps a placement with the current time
+func NewOrderPlaced(orderID, market, side string, quantity float64) OrderPlaced {
+	return OrderPlaced{Base: now(), OrderID: orderID, Market: market, Side: side, Quantity: quantity}
+}
+
+// NewOrderFilled stamps a fill with the current time
+func NewOrderFilled(orderID, market, side string, quantity, avgPrice, fee float64) OrderFilled {
+	return OrderFilled{Base: now(), OrderID: orderID, Market: market, Side: side, Quantity: quantity, AvgPrice: avgPrice, Fee: fee}
+}
+
+// NewRecoveryTriggered stamps a recovery with the current time
+func NewRecoveryTriggered(currency string, volume float64, reason string) RecoveryTriggered {
+	return RecoveryTriggered{Base: now(), Currency: currency, Volume: volume, Reason: reason}
+}
+
+// NewRecoveryFailed stamps a failed recovery with the current time
+func NewRecoveryFailed(currency string, volume float64, reason string, manualRequired bool) RecoveryFailed {
+	return RecoveryFailed{Base: now(), Currency: currency, Volume: volume, Reason: reason, ManualRequired: manualRequired}
+}
+
+// NewBalanceMismatch stamps an unexplained balance change with the current time
+func NewBalanceMismatch(currency string, previous, expected, actual float64) BalanceMismatch {
+	return BalanceMismatch{Base: now(), Currency: currency, Previous: previous, Expected: expected,
+		Actual: actual, Unexplained: actual - expected}
+}
+
+// NewLowBalance stamps a low funding balance with the current time
+func NewLowBalance(currency string, available, availableUSDT, requiredUSDT float64) LowBalance {
+	return LowBalance{Base: now(), Currency: currency, Available: available, AvailableUSDT: availableUSDT, RequiredUSDT: requiredUSDT}
+}
+
+// NewRiskTripped stamps a tripped guard with the current time
+func NewRiskTripped(rule, subject, detail string) RiskTripped {
+	return RiskTripped{Base: now(), Rule: rule, Subject: subject, Detail: detail}
+}
@@ -0,0 +1,216 @@
+package exchange
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	budgetWindow = time.Minute
+
+	// A 429 without a usable Retry-After pauses the budget this long
+	defaultRetryAfter  = 5 * time.Second
+	maxRetryAfter      = time.Minute
+	maxThrottleRetries = 3
+)
+
+// DefaultCallWeights is how many budget tokens a request to each endpoint
+// path costs; the heavy full-exchange listings cost more than a single
+// book or order call. Paths not listed (and not prefixed by a listed one)
+// cost 1.
+var DefaultCallWeights = map[string]int{
+	"/exchange/v1/markets_details":        5,
+	"/exchange/ticker":                    5,
+	"/market_data/candles":                2,
+	"/exchange/v1/orders/status_multiple": 2,
+}
+
+// CallBudget is an http.RoundTripper pacing requests to the exchange with a
+// token bucket: burst tokens are available at once and they refill at
+// perMinute per minute, each request taking its endpoint's weight. Clients
+// given the same budget (fetcher, trading client, rate and status polling)
+// are bounded together. Requests over the budget queue in arrival order
+// instead of failing, and a 429 pauses the whole budget for its Retry-After
+// before the request is sent again.
+type CallBudget struct {
+	next      http.RoundTripper
+	perMinute int
+	burst     float64
+	weights   map[string]int
+
+	mu          sync.Mutex
+	tokens      float64   // Negative while requests are queued for tokens not yet refilled
+	refilled    time.Time // When tokens was last brought up to date
+	pausedUntil time.Time // Set by a 429's Retry-After
+	throttled   time.Time // Last time a wait was logged
+}
+
+// NewCallBudget wraps next (http.DefaultTransport when nil). weights
+// override DefaultCallWeights per path; burst below 1 means 1.
+func NewCallBudget(next http.RoundTripper, perMinute, burst int, weights map[string]int) *CallBudget {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	burst = max(burst, 1)
+
+	merged := make(map[string]int, len(DefaultCallWeights)+len(weights))
+	for path, weight := range DefaultCallWeights {
+		merged[path] = weight
+	}
+	for path, weight := range weights {
+		merged[path] = weight
+	}
+
+	return &CallBudget{
+		next:      next,
+		perMinute: max(perMinute, 1),
+		burst:     float64(burst),
+		weights:   merged,
+		tokens:    float64(burst),
+		refilled:  time.Now(),
+	}
+}
+
+// RoundTrip waits for tokens when the request goes to the exchange, then
+// sends it, resending after the pause when the exchange answers 429
+func (b *CallBudget) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !IsExchangeHost(req.URL.Hostname()) {
+		return b.next.RoundTrip(req)
+	}
+
+	weight := b.Weight(req.URL.Path)
+	for attempt := 0; ; attempt++ {
+		if err := b.wait(req, weight); err != nil {
+			return nil, err
+		}
+		resp, err := b.next.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+
+		b.pause(req.URL.Path, retryAfter(resp.Header.Get("Retry-After"), time.Now()))
+
+		// A body already consumed cannot be sent again; the caller sees the 429
+		if attempt == maxThrottleRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// Weight is the number of tokens a request to path costs: the weight of the
+// longest configured path it starts with, at least 1 and at most the burst
+func (b *CallBudget) Weight(path string) float64 {
+	weight, matched := 1, 0
+	for prefix, w := range b.weights {
+		if len(prefix) > matched && strings.HasPrefix(path, prefix) {
+			weight, matched = w, len(prefix)
+		}
+	}
+	return min(float64(max(weight, 1)), b.burst)
+}
+
+// wait reserves weight tokens and blocks until they have refilled and any
+// pause is over, or the request is cancelled. Reservations are taken in
+// arrival order, so queued requests go out first come, first served.
+func (b *CallBudget) wait(req *http.Request, weight float64) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.refill(now)
+	b.tokens -= weight
+	ready := now
+	if b.tokens < 0 {
+		ready = now.Add(time.Duration(-b.tokens / b.perSecond() * float64(time.Second)))
+	}
+	b.mu.Unlock()
+
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		if b.pausedUntil.After(ready) {
+			ready = b.pausedUntil
+		}
+		delay := ready.Sub(now)
+		if delay <= 0 {
+			b.mu.Unlock()
+			return nil
+		}
+		if now.Sub(b.throttled) > budgetWindow {
+			b.throttled = now
+			logger.Warn("🐢 Exchange call budget reached, holding requests", "per_minute", b.perMinute,
+				"delay", delay.Round(time.Millisecond))
+		}
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			// Hand the reservation back so the requests behind this one move up
+			b.mu.Lock()
+			b.refill(time.Now())
+			b.tokens = min(b.tokens+weight, b.burst)
+			b.mu.Unlock()
+			return req.Context().Err()
+		}
+	}
+}
+
+// pause holds every exchange request for delay after a 429
+func (b *CallBudget) pause(path string, delay time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	until := time.Now().Add(delay)
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+		logger.Warn("⛔ Exchange rate limited, pausing all requests", "path", path, "delay", delay.Round(time.Millisecond))
+	}
+}
+
+// refill adds the tokens earned since the last refill, up to the burst.
+// Callers hold mu.
+func (b *CallBudget) refill(now time.Time) {
+	elapsed := now.Sub(b.refilled).Seconds()
+	if elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed*b.perSecond(), b.burst)
+		b.refilled = now
+	}
+}
+
+func (b *CallBudget) perSecond() float64 {
+	return float64(b.perMinute) / budgetWindow.Seconds()
+}
+
+// retryAfter reads a Retry-After header given in seconds or as an HTTP
+// date, falling back to defaultRetryAfter and capped at maxRetryAfter
+func retryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	delay := defaultRetryAfter
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(header); err == nil {
+		delay = max(at.Sub(now), 0)
+	}
+	return min(delay, maxRetryAfter)
+}
+
+// IsExchangeHost reports whether host is one of CoinDCX's API hosts, as
+// opposed to notification webhooks or announcement feeds
+func IsExchangeHost(host string) bool {
+	return host == "coindcx.com" || strings.HasSuffix(host, ".coindcx.com")
+}
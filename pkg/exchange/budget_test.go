@@ -0,0 +1,94 @@
+package exchange
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// throttledOnce answers 429 to the first request and 200 afterwards
+type throttledOnce struct {
+	calls atomic.Int32
+}
+
+func (t *throttledOnce) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := http.StatusOK
+	if t.calls.Add(1) == 1 {
+		status = http.StatusTooManyRequests
+	}
+	header := make(http.Header)
+	header.Set("Retry-After", "0")
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Header:     header,
+		Request:    req,
+	}, nil
+}
+
+func TestCallBudgetQueuesBeyondBurst(t *testing.T) {
+	// 600/min refills a token every 100ms; the burst of 2 goes out at once
+	transport := &throttledOnce{}
+	transport.calls.Store(1) // Past the 429
+	budget := NewCallBudget(transport, 600, 2, nil)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", "https://api.coindcx.com/market_data/orderbook?pair=B-BTC_USDT", nil)
+		if _, err := budget.RoundTrip(req); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("third request went out after %s, want it held for a refill", elapsed)
+	}
+}
+
+func TestCallBudgetWeights(t *testing.T) {
+	budget := NewCallBudget(nil, 600, 4, map[string]int{"/exchange/v1/orders": 3})
+
+	cases := map[string]float64{
+		"/exchange/v1/markets_details":        4, // 5, capped at the burst
+		"/exchange/v1/orders/create":          3,
+		"/exchange/v1/orders/status_multiple": 2, // Longer built-in prefix wins
+		"/market_data/orderbook":              1,
+	}
+	for path, want := range cases {
+		if got := budget.Weight(path); got != want {
+			t.Errorf("Weight(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestCallBudgetRetriesAfter429(t *testing.T) {
+	transport := &throttledOnce{}
+	budget := NewCallBudget(transport, 600, 5, nil)
+
+	req, _ := http.NewRequest("POST", "https://api.coindcx.com/exchange/v1/orders/create", strings.NewReader(`{"side":"buy"}`))
+	resp, err := budget.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || transport.calls.Load() != 2 {
+		t.Errorf("got %d after %d calls, want 200 after 2", resp.StatusCode, transport.calls.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"3":                             3 * time.Second,
+		"":                              defaultRetryAfter,
+		"soon":                          defaultRetryAfter,
+		"3600":                          maxRetryAfter,
+		"Thu, 01 Jan 2026 12:00:10 GMT": 10 * time.Second,
+	}
+	for header, want := range cases {
+		if got := retryAfter(header, now); got != want {
+			t.Errorf("retryAfter(%q) = %s, want %s", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CoinDCX serves order books and candles from the public host and
+// everything else, signed trading calls included, from the API host; either
+// can fail while the other keeps answering
+const (
+	PublicHost = "public.coindcx.com"
+	APIHost    = "api.coindcx.com"
+)
+
+// probeURLs are cheap requests that show whether a down host answers again
+var probeURLs = map[string]string{
+	PublicHost: "https://public.coindcx.com/market_data/orderbook?pair=B-BTC_USDT",
+	APIHost:    "https://api.coindcx.com/exchange/ticker",
+}
+
+// Host health states
+const (
+	HostHealthy  = "healthy"
+	HostDegraded = "degraded" // Failing, but not yet for downAfter requests in a row
+	HostDown     = "down"
+)
+
+// HostStatus is what HostHealth knows about one host
+type HostStatus struct {
+	Host        string    `json:"host"`
+	State       string    `json:"state"`
+	Failures    int       `json:"consecutive_failures"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	LastFailure time.Time `json:"last_failure,omitempty"`
+	Since       time.Time `json:"since"` // When State was entered
+}
+
+// HostHealth tracks each exchange host from the responses its requests
+// get: a transport error or 5xx is a failure, anything else (4xx included:
+// a rejected order says nothing about the host) a success. downAfter
+// failures in a row mark the host down until a request succeeds again.
+type HostHealth struct {
+	downAfter int
+
+	mu       sync.Mutex
+	hosts    map[string]*HostStatus
+	onChange []func(HostStatus)
+}
+
+// NewHostHealth marks a host down after downAfter failures in a row (at least 1)
+func NewHostHealth(downAfter int) *HostHealth {
+	return &HostHealth{downAfter: max(downAfter, 1), hosts: make(map[string]*HostStatus)}
+}
+
+// OnChange registers a handler called with a host's status whenever its state changes
+func (h *HostHealth) OnChange(handler func(HostStatus)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onChange = append(h.onChange, handler)
+}
+
+// Transport wraps next so every exchange response updates its host's
+// health; other hosts pass through untracked
+func (h *HostHealth) Transport(next http.RoundTripper) http.RoundTripper {
+	return healthTransport{health: h, next: next}
+}
+
+type healthTransport struct {
+	health *HostHealth
+	next   http.RoundTripper
+}
+
+func (t healthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	host := req.URL.Hostname()
+	if !IsExchangeHost(host) || errors.Is(req.Context().Err(), context.Canceled) {
+		// A cancelled request is the caller giving up, not the host failing;
+		// a client timeout (deadline exceeded) does count
+		return resp, err
+	}
+	switch {
+	case err != nil:
+		t.health.Record(host, err)
+	case resp.StatusCode >= 500:
+		t.health.Record(host, fmt.Errorf("%s answered %s", req.URL.Path, resp.Status))
+	default:
+		t.health.Record(host, nil)
+	}
+	return resp, err
+}
+
+// Record counts a request to host as a success (nil) or a failure
+func (h *HostHealth) Record(host string, failure error) {
+	h.mu.Lock()
+	status, ok := h.hosts[host]
+	if !ok {
+		status = &HostStatus{Host: host, State: HostHealthy, Since: time.Now()}
+		h.hosts[host] = status
+	}
+
+	now := time.Now()
+	previous := status.State
+	if failure == nil {
+		status.Failures = 0
+		status.LastSuccess = now
+		status.State = HostHealthy
+	} else {
+		status.Failures++
+		status.LastFailure = now
+		status.LastError = failure.Error()
+		status.State = HostDegraded
+		if status.Failures >= h.downAfter {
+			status.State = HostDown
+		}
+	}
+
+	changed := status.State != previous
+	if changed {
+		status.Since = now
+	}
+	snapshot := *status
+	handlers := h.onChange
+	h.mu.Unlock()
+
+	if changed {
+		for _, handler := range handlers {
+			handler(snapshot)
+		}
+	}
+}
+
+// Up reports whether host isn't down; a host not seen yet is presumed up
+func (h *HostHealth) Up(host string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status, ok := h.hosts[host]
+	return !ok || status.State != HostDown
+}
+
+// Statuses returns every host seen so far
+func (h *HostHealth) Statuses() map[string]HostStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	statuses := make(map[string]HostStatus, len(h.hosts))
+	for host, status := range h.hosts {
+		statuses[host] = *status
+	}
+	return statuses
+}
+
+// Guard errors while any of hosts is down
+func (h *HostHealth) Guard(hosts ...string) error {
+	for _, host := range hosts {
+		if !h.Up(host) {
+			h.mu.Lock()
+			lastError := h.hosts[host].LastError
+			h.mu.Unlock()
+			return fmt.Errorf("%s is down (%s)", host, lastError)
+		}
+	}
+	return nil
+}
+
+// Probe sends a HEAD request to each down host, so a host nothing else
+// calls while it is down (execution paused) can come back up. Probes
+// bypass the call budget; they are rare and only sent to down hosts.
+func (h *HostHealth) Probe(ctx context.Context) {
+	probe := h.Transport(http.DefaultTransport)
+	for host, url := range probeURLs {
+		if h.Up(host) {
+			continue
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			continue
+		}
+		if resp, err := probe.RoundTrip(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+// LogChanges logs each host state change and what it means for trading
+func (h *HostHealth) LogChanges() {
+	h.OnChange(func(status HostStatus) {
+		switch status.State {
+		case HostDown:
+			logger.Error("🩺 Host down: execution paused, analysis continues", "host", status.Host,
+				"failures", status.Failures, "error", status.LastError)
+		case HostDegraded:
+			logger.Warn("🩺 Host degraded", "host", status.Host, "error", status.LastError)
+		case HostHealthy:
+			logger.Info("🩺 Host healthy again", "host", status.Host)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package exchange
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// hostAnswers fails every request to down hosts and answers status elsewhere
+type hostAnswers struct {
+	down   map[string]bool
+	status int
+}
+
+func (h *hostAnswers) RoundTrip(req *http.Request) (*http.Response, error) {
+	if h.down[req.URL.Hostname()] {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: h.status, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+}
+
+func TestHostHealthTracksHostsApart(t *testing.T) {
+	answers := &hostAnswers{down: map[string]bool{APIHost: true}, status: http.StatusBadRequest}
+	health := NewHostHealth(2)
+	transport := health.Transport(answers)
+
+	changes := []string{}
+	health.OnChange(func(status HostStatus) { changes = append(changes, status.Host+" "+status.State) })
+
+	call := func(url string) {
+		req, _ := http.NewRequest("GET", url, nil)
+		transport.RoundTrip(req)
+	}
+	for i := 0; i < 2; i++ {
+		call("https://api.coindcx.com/exchange/v1/orders/create")
+		call("https://public.coindcx.com/market_data/orderbook?pair=B-BTC_USDT")
+	}
+
+	// A 400 from the public host is an answer, not an outage
+	if !health.Up(PublicHost) || health.Up(APIHost) {
+		t.Fatalf("statuses %+v, want only the API host down", health.Statuses())
+	}
+	if err := health.Guard(PublicHost); err != nil {
+		t.Errorf("analysis guard: %v", err)
+	}
+	if err := health.Guard(APIHost, PublicHost); err == nil || !strings.Contains(err.Error(), APIHost) {
+		t.Errorf("execution guard = %v, want the API host named", err)
+	}
+
+	answers.down = nil
+	call("https://api.coindcx.com/exchange/v1/users/balances")
+	if !health.Up(APIHost) {
+		t.Error("API host still down after a successful request")
+	}
+
+	want := []string{APIHost + " degraded", APIHost + " down", APIHost + " healthy"}
+	if strings.Join(changes, ", ") != strings.Join(want, ", ") {
+		t.Errorf("changes %v, want %v", changes, want)
+	}
+}
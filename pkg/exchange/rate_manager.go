@@ -1,32 +1,50 @@
 package exchange
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"os"
-	"strconv"
+	"sync"
 	"time"
 
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
 	"github.com/b-thark/cdcx-api/pkg/types"
 )
 
+var logger = logging.For("exchange")
+
 type RateManager struct {
 	cache  *types.ExchangeRateCache
 	config *types.Config
 	client *http.Client
+	pinned bool       // Rates set by Pin never expire, refetch or persist
+	mu     sync.Mutex // Guards cache; detection goroutines share one manager
+
+	staleWarned map[string]bool // Cache keys whose stale fallback has been logged
 }
 
 func NewRateManager(config *types.Config) *RateManager {
 	rm := &RateManager{
 		config: config,
 		client: &http.Client{Timeout: 10 * time.Second},
+
+		staleWarned: make(map[string]bool),
 	}
 	rm.loadCache()
 	return rm
 }
 
+// SetTransport sends rate requests through transport, e.g. the process's
+// shared exchange call budget
+func (rm *RateManager) SetTransport(transport http.RoundTripper) {
+	rm.client.Transport = transport
+}
+
 func (rm *RateManager) loadCache() {
 	rm.cache = &types.ExchangeRateCache{
 		Rates:       make(map[string]types.ExchangeRate),
@@ -42,43 +60,171 @@ func (rm *RateManager) loadCache() {
 }
 
 func (rm *RateManager) SaveCache() error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if rm.pinned {
+		return nil
+	}
+
 	rm.cache.LastUpdated = time.Now()
-	data, err := json.MarshalIndent(rm.cache, "", "  ")
-	if err != nil {
-		return err
+	return utils.SaveJSON(rm.cache, rm.config.RateCacheFile)
+}
+
+// Pin replaces every rate with fixed INR rates (currency → INR), e.g. from a
+// recorded snapshot; currencies without a pinned rate fail to convert
+func (rm *RateManager) Pin(rates map[string]float64) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.pinned = true
+	rm.cache.Rates = make(map[string]types.ExchangeRate, len(rates))
+	for currency, rate := range rates {
+		rm.cache.Rates[fmt.Sprintf("%s_INR", currency)] = types.ExchangeRate{
+			FromCurrency: currency,
+			ToCurrency:   "INR",
+			Rate:         rate,
+			Timestamp:    time.Now(),
+			Source:       "pinned",
+		}
 	}
-	return os.WriteFile(rm.config.RateCacheFile, data, 0644)
 }
 
+// RateUse says what a conversion is for, and so whether a rate the exchange
+// can't currently refresh may stand in for a fresh one
+type RateUse int
+
+const (
+	// ForExecution needs a fresh rate: sizing and validating orders on a
+	// stale rate could trade at a loss, so a failed refresh fails the conversion
+	ForExecution RateUse = iota
+	// ForReporting may fall back to a cached rate up to MaxStaleRateMinutes
+	// old, so inventory marks and P&L keep working through exchange hiccups
+	ForReporting
+)
+
+// ConvertToINR converts with a fresh rate, for detection and execution
 func (rm *RateManager) ConvertToINR(price float64, fromCurrency string) (float64, error) {
+	return rm.Convert(ForExecution, price, fromCurrency)
+}
+
+// ConvertToINRContext is ConvertToINR, giving up on a rate fetch when ctx is done
+func (rm *RateManager) ConvertToINRContext(ctx context.Context, price float64, fromCurrency string) (float64, error) {
+	return rm.ConvertContext(ctx, ForExecution, price, fromCurrency)
+}
+
+// ReportINR converts for display and bookkeeping, tolerating a stale rate
+// while the exchange is unreachable
+func (rm *RateManager) ReportINR(price float64, fromCurrency string) (float64, error) {
+	return rm.Convert(ForReporting, price, fromCurrency)
+}
+
+// Convert converts price in fromCurrency to INR under use's fallback policy
+func (rm *RateManager) Convert(use RateUse, price float64, fromCurrency string) (float64, error) {
+	return rm.ConvertContext(context.Background(), use, price, fromCurrency)
+}
+
+// ConvertContext is Convert, giving up on a rate fetch when ctx is done
+func (rm *RateManager) ConvertContext(ctx context.Context, use RateUse, price float64, fromCurrency string) (float64, error) {
+	if math.IsNaN(price) || math.IsInf(price, 0) {
+		return 0, &types.InvalidValueError{Field: fromCurrency + " amount", Value: price}
+	}
 	if fromCurrency == "INR" {
 		return price, nil
 	}
 
-	// Check cache first
+	// Check cache first; a non-positive rate (corrupt cache file) is refetched
 	cacheKey := fmt.Sprintf("%s_INR", fromCurrency)
-	if rate, exists := rm.cache.Rates[cacheKey]; exists {
-		if time.Since(rate.Timestamp) < rm.config.CacheDuration {
-			return price * rate.Rate, nil
-		}
+	rm.mu.Lock()
+	cached, exists := rm.cache.Rates[cacheKey]
+	pinned := rm.pinned
+	rm.mu.Unlock()
+	exists = exists && cached.Rate > 0
+	if exists && (pinned || time.Since(cached.Timestamp) < rm.config.CacheDuration) {
+		return price * cached.Rate, nil
+	}
+	if pinned {
+		return 0, fmt.Errorf("no pinned %s rate", fromCurrency)
 	}
 
 	// Fetch new rate
-	rate, err := rm.fetchExchangeRate(fromCurrency, "INR")
+	rate, err := rm.fetchExchangeRate(ctx, fromCurrency, "INR")
 	if err != nil {
+		maxAge := time.Duration(rm.config.MaxStaleRateMinutes) * time.Minute
+		if use == ForReporting && exists && time.Since(cached.Timestamp) < maxAge {
+			rm.warnStale(cacheKey, cached, err)
+			return price * cached.Rate, nil
+		}
 		return 0, err
 	}
 
 	// Update cache
+	rm.mu.Lock()
 	rm.cache.Rates[cacheKey] = rate
+	delete(rm.staleWarned, cacheKey)
+	rm.mu.Unlock()
 	return price * rate.Rate, nil
 }
 
-func (rm *RateManager) fetchExchangeRate(fromCurrency, toCurrency string) (types.ExchangeRate, error) {
-	pair := fmt.Sprintf("%s%s", fromCurrency, toCurrency)
+// warnStale logs the first fallback to each cached rate
+func (rm *RateManager) warnStale(cacheKey string, rate types.ExchangeRate, err error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.staleWarned[cacheKey] {
+		return
+	}
+	rm.staleWarned[cacheKey] = true
+	logger.Warn("⚠️ Rate refresh failed; reporting with the cached rate", "rate", cacheKey, "error", err,
+		"age", time.Since(rate.Timestamp).Round(time.Second))
+}
+
+// NormalizePrices converts a buy and a sell price quoted in possibly
+// different currencies to INR so they can be compared directly. An error
+// means no rate is known for one side and the cross-base pair is unsupported.
+func (rm *RateManager) NormalizePrices(buyPrice float64, buyQuote string, sellPrice float64, sellQuote string) (float64, float64, error) {
+	if err := types.RequirePositive("buy price", buyPrice); err != nil {
+		return 0, 0, err
+	}
+	if err := types.RequirePositive("sell price", sellPrice); err != nil {
+		return 0, 0, err
+	}
+
+	buyINR, err := rm.ConvertToINR(buyPrice, buyQuote)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unsupported quote currency %s: %v", buyQuote, err)
+	}
+
+	sellINR, err := rm.ConvertToINR(sellPrice, sellQuote)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unsupported quote currency %s: %v", sellQuote, err)
+	}
+
+	return buyINR, sellINR, nil
+}
+
+// fetchExchangeRate derives a fresh rate from the exchange's tickers (see
+// rateFromTickers), else from the FX API when one is configured
+func (rm *RateManager) fetchExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (types.ExchangeRate, error) {
+	rate, err := rm.fetchTickerRate(ctx, fromCurrency, toCurrency)
+	if err == nil || rm.config.FXRateURL == "" {
+		return rate, err
+	}
+
+	fxRate, fxErr := rm.fetchFXRate(ctx, fromCurrency, toCurrency)
+	if fxErr != nil {
+		return types.ExchangeRate{}, fmt.Errorf("%v; FX: %v", err, fxErr)
+	}
+	return fxRate, nil
+}
+
+func (rm *RateManager) fetchTickerRate(ctx context.Context, fromCurrency, toCurrency string) (types.ExchangeRate, error) {
 	url := "https://api.coindcx.com/exchange/ticker"
 
-	resp, err := rm.client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return types.ExchangeRate{}, err
+	}
+	resp, err := rm.client.Do(req)
 	if err != nil {
 		return types.ExchangeRate{}, err
 	}
@@ -89,27 +235,16 @@ func (rm *RateManager) fetchExchangeRate(fromCurrency, toCurrency string) (types
 		return types.ExchangeRate{}, err
 	}
 
-	var tickers []map[string]interface{}
-	if err := json.Unmarshal(body, &tickers); err != nil {
+	tickers, err := types.ParseTickers(body)
+	if err != nil {
 		return types.ExchangeRate{}, err
 	}
 
-	for _, ticker := range tickers {
-		if market, ok := ticker["market"].(string); ok && market == pair {
-			if lastPriceStr, ok := ticker["last_price"].(string); ok {
-				rate, err := strconv.ParseFloat(lastPriceStr, 64)
-				if err == nil {
-					return types.ExchangeRate{
-						FromCurrency: fromCurrency,
-						ToCurrency:   toCurrency,
-						Rate:         rate,
-						Timestamp:    time.Now(),
-						Source:       "ticker",
-					}, nil
-				}
-			}
-		}
+	maxAge := time.Duration(rm.config.MaxTickerAgeMinutes) * time.Minute
+	rate, err := rateFromTickers(tickers, fromCurrency, toCurrency, maxAge, time.Now())
+	if err == nil && rate.Source != "ticker" {
+		logger.Info("🔀 Rate not from a direct ticker", "from", fromCurrency, "to", toCurrency, "rate", rate.Rate,
+			"source", rate.Source, "confidence", rate.Confidence)
 	}
-
-	return types.ExchangeRate{}, fmt.Errorf("exchange rate not found for %s/%s", fromCurrency, toCurrency)
+	return rate, err
 }
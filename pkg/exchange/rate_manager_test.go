@@ -0,0 +1,283 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// fixedTicker serves a canned /exchange/ticker response
+type fixedTicker string
+
+func (f fixedTicker) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(string(f))),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+const tickerFixture = `[
+	{"market": "USDTINR", "last_price": "85.0"},
+	{"market": "BTCINR", "last_price": "5000000.0"},
+	{"market": "ETHINR", "last_price": "250000.0"}
+]`
+
+func newTestRateManager(t *testing.T) *RateManager {
+	t.Helper()
+
+	config := types.DefaultConfig()
+	config.RateCacheFile = filepath.Join(t.TempDir(), "rates.json")
+
+	rm := NewRateManager(config)
+	rm.client = &http.Client{Transport: fixedTicker(tickerFixture)}
+	return rm
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func TestConvertToINRNormalizesAllQuotes(t *testing.T) {
+	// A token worth exactly ₹100 quoted in every supported base currency
+	const tokenINR = 100.0
+
+	cases := []struct {
+		quote string
+		price float64
+	}{
+		{"INR", tokenINR},
+		{"USDT", tokenINR / 85.0},
+		{"BTC", tokenINR / 5000000.0},
+		{"ETH", tokenINR / 250000.0},
+	}
+
+	rm := newTestRateManager(t)
+
+	for _, tc := range cases {
+		t.Run(tc.quote, func(t *testing.T) {
+			got, err := rm.ConvertToINR(tc.price, tc.quote)
+			if err != nil {
+				t.Fatalf("ConvertToINR(%v, %s): %v", tc.price, tc.quote, err)
+			}
+			if !approxEqual(got, tokenINR) {
+				t.Errorf("ConvertToINR(%v, %s) = %v, want %v", tc.price, tc.quote, got, tokenINR)
+			}
+		})
+	}
+}
+
+func TestNormalizePricesAcrossBases(t *testing.T) {
+	cases := []struct {
+		name      string
+		buyPrice  float64
+		buyQuote  string
+		sellPrice float64
+		sellQuote string
+		wantBuy   float64
+		wantSell  float64
+	}{
+		{"same base", 1.0, "USDT", 1.02, "USDT", 85.0, 86.7},
+		{"USDT to INR", 1.0, "USDT", 87.0, "INR", 85.0, 87.0},
+		{"INR to BTC", 100.0, "INR", 0.0000204, "BTC", 100.0, 102.0},
+		{"BTC to ETH", 0.00002, "BTC", 0.00041, "ETH", 100.0, 102.5},
+		{"ETH to USDT", 0.0004, "ETH", 1.2, "USDT", 100.0, 102.0},
+	}
+
+	rm := newTestRateManager(t)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			buy, sell, err := rm.NormalizePrices(tc.buyPrice, tc.buyQuote, tc.sellPrice, tc.sellQuote)
+			if err != nil {
+				t.Fatalf("NormalizePrices: %v", err)
+			}
+			if !approxEqual(buy, tc.wantBuy) {
+				t.Errorf("buy = %v, want %v", buy, tc.wantBuy)
+			}
+			if !approxEqual(sell, tc.wantSell) {
+				t.Errorf("sell = %v, want %v", sell, tc.wantSell)
+			}
+		})
+	}
+}
+
+func TestNormalizePricesRejectsUnknownQuote(t *testing.T) {
+	rm := newTestRateManager(t)
+
+	if _, _, err := rm.NormalizePrices(1.0, "USDT", 1.0, "DOGE"); err == nil {
+		t.Fatal("expected an error for a quote currency without an INR rate")
+	}
+}
+
+func TestCachedRateIsReused(t *testing.T) {
+	rm := newTestRateManager(t)
+	rm.cache.Rates["USDT_INR"] = types.ExchangeRate{
+		FromCurrency: "USDT",
+		ToCurrency:   "INR",
+		Rate:         90.0,
+		Timestamp:    time.Now(),
+		Source:       "test",
+	}
+
+	got, err := rm.ConvertToINR(2.0, "USDT")
+	if err != nil {
+		t.Fatalf("ConvertToINR: %v", err)
+	}
+	if !approxEqual(got, 180.0) {
+		t.Errorf("ConvertToINR used %v, want the fresh cached rate (180)", got)
+	}
+}
+
+func TestConcurrentConversionsShareCache(t *testing.T) {
+	rm := newTestRateManager(t)
+
+	// Detection goroutines convert and persist through one shared manager
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			quote := []string{"USDT", "BTC", "ETH"}[i%3]
+			for j := 0; j < 50; j++ {
+				if _, err := rm.ConvertToINR(1.0, quote); err != nil {
+					t.Errorf("ConvertToINR(%s): %v", quote, err)
+					return
+				}
+				if j%10 == 0 {
+					if err := rm.SaveCache(); err != nil {
+						t.Errorf("SaveCache: %v", err)
+						return
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got, err := rm.ConvertToINR(1.0, "USDT")
+	if err != nil || !approxEqual(got, 85.0) {
+		t.Errorf("ConvertToINR after concurrent use = %v, %v; want 85", got, err)
+	}
+}
+
+// unreachable fails every request, like the exchange during an outage
+type unreachable struct{}
+
+func (unreachable) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestStaleRateFallbackIsReportingOnly(t *testing.T) {
+	rm := newTestRateManager(t)
+	rm.client = &http.Client{Transport: unreachable{}}
+	rm.cache.Rates["USDT_INR"] = types.ExchangeRate{
+		FromCurrency: "USDT",
+		ToCurrency:   "INR",
+		Rate:         90.0,
+		Timestamp:    time.Now().Add(-30 * time.Minute), // Expired, within the 60 minute cap
+		Source:       "test",
+	}
+	rm.cache.Rates["BTC_INR"] = types.ExchangeRate{
+		FromCurrency: "BTC",
+		ToCurrency:   "INR",
+		Rate:         5000000.0,
+		Timestamp:    time.Now().Add(-2 * time.Hour), // Past the cap
+		Source:       "test",
+	}
+
+	if _, err := rm.ConvertToINR(1.0, "USDT"); err == nil {
+		t.Error("execution conversion used a stale rate")
+	}
+	if got, err := rm.ReportINR(2.0, "USDT"); err != nil || !approxEqual(got, 180.0) {
+		t.Errorf("ReportINR = %v, %v; want the stale rate (180)", got, err)
+	}
+	if _, err := rm.ReportINR(1.0, "BTC"); err == nil {
+		t.Error("reporting conversion used a rate older than MaxStaleRateMinutes")
+	}
+
+	rm.config.MaxStaleRateMinutes = 0
+	if _, err := rm.ReportINR(1.0, "USDT"); err == nil {
+		t.Error("reporting fell back with the fallback disabled")
+	}
+}
+
+func TestRateFallbackSources(t *testing.T) {
+	now := time.Now()
+	stale := now.Add(-time.Hour).Unix()
+	tickers, err := types.ParseTickers([]byte(fmt.Sprintf(`[
+		{"market": "USDTINR", "last_price": "85.0"},
+		{"market": "XYZINR", "bid": "99", "ask": "101"},
+		{"market": "ABCUSDT", "last_price": "2.0"},
+		{"market": "OLDINR", "last_price": "500", "timestamp": %d},
+		{"market": "OLDUSDT", "last_price": "5.0"}
+	]`, stale)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		currency   string
+		rate       float64
+		source     string
+		confidence string
+	}{
+		{"USDT", 85, "ticker", types.RateConfidenceHigh},
+		{"XYZ", 100, "book_mid", types.RateConfidenceMedium},
+		{"ABC", 170, "cross_usdt", types.RateConfidenceMedium},
+		{"OLD", 425, "cross_usdt", types.RateConfidenceMedium}, // Its INR ticker is past the age limit
+	}
+	for _, tc := range cases {
+		rate, err := rateFromTickers(tickers, tc.currency, "INR", 10*time.Minute, now)
+		if err != nil {
+			t.Errorf("%s: %v", tc.currency, err)
+			continue
+		}
+		if !approxEqual(rate.Rate, tc.rate) || rate.Source != tc.source || rate.Confidence != tc.confidence {
+			t.Errorf("%s = %v from %s (%s), want %v from %s (%s)", tc.currency, rate.Rate, rate.Source,
+				rate.Confidence, tc.rate, tc.source, tc.confidence)
+		}
+	}
+	if _, err := rateFromTickers(tickers, "DOGE", "INR", 10*time.Minute, now); err == nil {
+		t.Error("derived a rate for an unlisted currency")
+	}
+}
+
+// fxServer answers ticker requests with the fixture and FX requests with a EUR rate
+type fxServer struct{}
+
+func (fxServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := tickerFixture
+	if req.URL.Host == "fx.test" {
+		body = `{"base": "EUR", "rates": {"INR": 90.5, "USD": 1.08}}`
+	}
+	return fixedTicker(body).RoundTrip(req)
+}
+
+func TestFXRateForUnlistedFiat(t *testing.T) {
+	rm := newTestRateManager(t)
+	rm.client = &http.Client{Transport: fxServer{}}
+
+	if _, err := rm.ConvertToINR(1, "EUR"); err == nil {
+		t.Error("converted EUR without an FX API configured")
+	}
+
+	rm.config.FXRateURL = "https://fx.test/latest/{from}"
+	got, err := rm.ConvertToINR(2, "EUR")
+	if err != nil || !approxEqual(got, 181) {
+		t.Fatalf("ConvertToINR(2 EUR) = %v, %v; want 181", got, err)
+	}
+	if rate := rm.cache.Rates["EUR_INR"]; rate.Source != "fx" || rate.Confidence != types.RateConfidenceLow {
+		t.Errorf("cached %+v, want an fx rate of low confidence", rate)
+	}
+}
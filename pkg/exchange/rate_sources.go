@@ -0,0 +1,111 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// rateFromTickers derives from/to from one ticker response, trying in turn
+// the direct market's last trade, its top-of-book mid and a cross through
+// USDT. Tickers older than maxAge (0 disables) are passed over.
+func rateFromTickers(tickers types.Tickers, from, to string, maxAge time.Duration, now time.Time) (types.ExchangeRate, error) {
+	rate := types.ExchangeRate{FromCurrency: from, ToCurrency: to, Timestamp: now}
+
+	direct, directErr := directRate(tickers, from, to, maxAge, now)
+	if directErr == nil {
+		rate.Rate, rate.Source, rate.Confidence = direct.Rate, direct.Source, direct.Confidence
+		return rate, nil
+	}
+	if from == "USDT" || to == "USDT" {
+		return types.ExchangeRate{}, directErr
+	}
+
+	toUSDT, err := directRate(tickers, from, "USDT", maxAge, now)
+	if err != nil {
+		return types.ExchangeRate{}, fmt.Errorf("%v; via USDT: %v", directErr, err)
+	}
+	fromUSDT, err := directRate(tickers, "USDT", to, maxAge, now)
+	if err != nil {
+		return types.ExchangeRate{}, fmt.Errorf("%v; via USDT: %v", directErr, err)
+	}
+
+	rate.Rate = toUSDT.Rate * fromUSDT.Rate
+	rate.Source = "cross_usdt"
+	rate.Confidence = types.RateConfidenceMedium
+	if toUSDT.Confidence == types.RateConfidenceLow || fromUSDT.Confidence == types.RateConfidenceLow {
+		rate.Confidence = types.RateConfidenceLow
+	}
+	return rate, nil
+}
+
+// directRate reads from/to off its own market's ticker: the last trade, or
+// the mid of its best bid and ask when there's no last price
+func directRate(tickers types.Tickers, from, to string, maxAge time.Duration, now time.Time) (types.ExchangeRate, error) {
+	pair := types.NewSymbol(from, to).Code()
+	stats, listed := tickers.Get(pair)
+	if !listed {
+		return types.ExchangeRate{}, fmt.Errorf("exchange rate not found for %s/%s", from, to)
+	}
+	if maxAge > 0 && stats.Timestamp > 0 {
+		if age := now.Sub(time.Unix(stats.Timestamp, 0)); age > maxAge {
+			return types.ExchangeRate{}, fmt.Errorf("%s ticker is %s old", pair, age.Round(time.Second))
+		}
+	}
+
+	if last, ok := tickers.Last(pair); ok {
+		return types.ExchangeRate{Rate: last, Source: "ticker", Confidence: types.RateConfidenceHigh}, nil
+	}
+	if stats.Bid > 0 && stats.Ask >= stats.Bid {
+		return types.ExchangeRate{Rate: (stats.Bid + stats.Ask) / 2, Source: "book_mid", Confidence: types.RateConfidenceMedium}, nil
+	}
+	return types.ExchangeRate{}, fmt.Errorf("%s ticker: no last price or book", pair)
+}
+
+// fetchFXRate asks the configured FX API for a fiat rate the exchange
+// doesn't list. The response must hold {"rates": {"<to>": rate}}.
+func (rm *RateManager) fetchFXRate(ctx context.Context, from, to string) (types.ExchangeRate, error) {
+	url := strings.ReplaceAll(rm.config.FXRateURL, "{from}", from)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return types.ExchangeRate{}, err
+	}
+	resp, err := rm.client.Do(req)
+	if err != nil {
+		return types.ExchangeRate{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return types.ExchangeRate{}, fmt.Errorf("FX API returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return types.ExchangeRate{}, err
+	}
+	var response struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return types.ExchangeRate{}, fmt.Errorf("FX API response: %v", err)
+	}
+	rate, ok := response.Rates[to]
+	if !ok || rate <= 0 {
+		return types.ExchangeRate{}, fmt.Errorf("FX API has no %s/%s rate", from, to)
+	}
+
+	return types.ExchangeRate{
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         rate,
+		Timestamp:    time.Now(),
+		Source:       "fx",
+		Confidence:   types.RateConfidenceLow,
+	}, nil
+}
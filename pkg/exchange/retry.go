@@ -0,0 +1,123 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// neverRetried are paths whose requests may have taken effect even when
+// they failed: a create that timed out can still have been placed, and
+// sending it again could double the position. The CoinDCX client looks
+// the order up by its client order ID before resubmitting instead.
+var neverRetried = map[string]bool{
+	"/exchange/v1/orders/create": true,
+}
+
+// RetryPolicy is an http.RoundTripper resending exchange requests that
+// failed transiently (a transport error such as a timeout, or one of the
+// retryable statuses) with exponential backoff and jitter, so a blip during
+// a live run doesn't fail an execution halfway through an arbitrage.
+// NewTransport chains it above the call budget, so each attempt spends
+// tokens.
+type RetryPolicy struct {
+	next       http.RoundTripper
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	statuses   map[int]bool
+}
+
+// NewRetryPolicy wraps next (http.DefaultTransport when nil), making up to
+// attempts tries per request (at least 1). The wait before retry n is drawn
+// between half and all of backoff·2^(n-1), capped at maxBackoff.
+func NewRetryPolicy(next http.RoundTripper, attempts int, backoff, maxBackoff time.Duration, statuses []int) *RetryPolicy {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	retryable := make(map[int]bool, len(statuses))
+	for _, status := range statuses {
+		retryable[status] = true
+	}
+	return &RetryPolicy{
+		next:       next,
+		attempts:   max(attempts, 1),
+		backoff:    backoff,
+		maxBackoff: max(maxBackoff, backoff),
+		statuses:   retryable,
+	}
+}
+
+// RoundTrip sends the request, resending it while it fails transiently and
+// attempts remain; the last failure is returned as it came
+func (p *RetryPolicy) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !IsExchangeHost(req.URL.Hostname()) || neverRetried[req.URL.Path] {
+		return p.next.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := p.next.RoundTrip(req)
+		failure := p.transient(req, resp, err)
+		// A body already consumed cannot be sent again
+		if failure == nil || attempt == p.attempts || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		delay := p.Delay(attempt)
+		logger.Warn("🔁 Retrying", "method", req.Method, "path", req.URL.Path, "error", failure,
+			"attempt", attempt, "retries", p.attempts-1, "delay", delay.Round(time.Millisecond))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// transient explains why a response is worth retrying, nil when it isn't.
+// Requests whose context is done are never retried: the caller gave up or
+// its client timeout is spent.
+func (p *RetryPolicy) transient(req *http.Request, resp *http.Response, err error) error {
+	if req.Context().Err() != nil || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if p.statuses[resp.StatusCode] {
+		return fmt.Errorf("answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Delay is the jittered wait before retry attempt (counted from 1)
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.backoff
+	for i := 1; i < attempt && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.maxBackoff)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
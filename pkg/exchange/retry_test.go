@@ -0,0 +1,65 @@
+package exchange
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// unavailableTwice answers 503 to the first two requests and 200 afterwards
+type unavailableTwice struct {
+	calls atomic.Int32
+}
+
+func (u *unavailableTwice) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := http.StatusOK
+	if u.calls.Add(1) <= 2 {
+		status = http.StatusServiceUnavailable
+	}
+	body, _ := io.ReadAll(req.Body)
+	return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: io.NopCloser(strings.NewReader(string(body))), Request: req}, nil
+}
+
+func TestRetryPolicyResendsTransientFailures(t *testing.T) {
+	next := &unavailableTwice{}
+	policy := NewRetryPolicy(next, 3, time.Millisecond, 2*time.Millisecond, []int{http.StatusServiceUnavailable})
+
+	req, _ := http.NewRequest("POST", "https://api.coindcx.com/exchange/v1/orders/status", strings.NewReader(`{"id":"1"}`))
+	resp, err := policy.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != `{"id":"1"}` {
+		t.Errorf("got %d %q, want 200 with the body resent", resp.StatusCode, body)
+	}
+	if calls := next.calls.Load(); calls != 3 {
+		t.Errorf("%d calls, want 3", calls)
+	}
+}
+
+func TestRetryPolicyNeverResendsOrderCreation(t *testing.T) {
+	next := &unavailableTwice{}
+	policy := NewRetryPolicy(next, 3, time.Millisecond, time.Millisecond, []int{http.StatusServiceUnavailable})
+
+	req, _ := http.NewRequest("POST", "https://api.coindcx.com/exchange/v1/orders/create", strings.NewReader(`{}`))
+	resp, err := policy.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || next.calls.Load() != 1 {
+		t.Errorf("got %d after %d calls, want the first 503 returned", resp.StatusCode, next.calls.Load())
+	}
+}
+
+func TestRetryDelayBackoff(t *testing.T) {
+	policy := NewRetryPolicy(nil, 5, 100*time.Millisecond, 300*time.Millisecond, nil)
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 300 * time.Millisecond} {
+		if delay := policy.Delay(attempt); delay < want/2 || delay > want {
+			t.Errorf("Delay(%d) = %s, want between %s and %s", attempt, delay, want/2, want)
+		}
+	}
+}
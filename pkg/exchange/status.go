@@ -0,0 +1,252 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Status event kinds
+const (
+	EventMarketSuspended = "market_suspended"
+	EventMarketResumed   = "market_resumed"
+	EventMaintenance     = "maintenance"
+)
+
+// StatusEvent is a change in exchange status relevant to trading
+type StatusEvent struct {
+	Kind     string    `json:"kind"`
+	Market   string    `json:"market,omitempty"`
+	Currency string    `json:"currency,omitempty"`
+	Detail   string    `json:"detail"`
+	Time     time.Time `json:"time"`
+}
+
+// MarketSource provides market metadata including each market's status
+type MarketSource interface {
+	GetMarketDetails() ([]types.MarketDetail, error)
+}
+
+// Announcement is a single entry from an announcements feed
+type Announcement struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// StatusMonitor polls market status (and optionally an announcements feed)
+// and guards execution on markets that are suspended or under maintenance.
+type StatusMonitor struct {
+	source           MarketSource
+	client           *http.Client
+	announcementsURL string
+
+	markets     map[string]types.MarketDetail
+	names       *market.Markets   // Resolves pairs and canonical symbols to markets
+	maintenance map[string]string // currency -> announcement title
+	handlers    []func(StatusEvent)
+	mu          sync.RWMutex
+}
+
+// NewStatusMonitor creates a monitor; announcementsURL may be empty to only track market status
+func NewStatusMonitor(source MarketSource, announcementsURL string) *StatusMonitor {
+	return &StatusMonitor{
+		source:           source,
+		client:           &http.Client{Timeout: 10 * time.Second},
+		announcementsURL: announcementsURL,
+		markets:          make(map[string]types.MarketDetail),
+		maintenance:      make(map[string]string),
+	}
+}
+
+// OnEvent registers a handler called for every status change
+func (sm *StatusMonitor) OnEvent(handler func(StatusEvent)) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.handlers = append(sm.handlers, handler)
+}
+
+// Poll refreshes market status and announcements once, emitting events for changes
+func (sm *StatusMonitor) Poll() error {
+	details, err := sm.source.GetMarketDetails()
+	if err != nil {
+		return fmt.Errorf("market status poll failed: %v", err)
+	}
+
+	events := sm.updateMarkets(details)
+
+	if sm.announcementsURL != "" {
+		announcements, err := sm.fetchAnnouncements()
+		if err != nil {
+			logger.Warn("⚠️ Announcements poll failed", "error", err)
+		} else {
+			events = append(events, sm.updateMaintenance(announcements)...)
+		}
+	}
+
+	sm.emit(events)
+	return nil
+}
+
+// Start polls on the given interval until stop is closed
+func (sm *StatusMonitor) Start(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := sm.Poll(); err != nil {
+				logger.Warn("⚠️ Status poll failed", "error", err)
+			}
+		}
+	}
+}
+
+// Guard returns an error when the market should not be traded right now.
+// Markets the monitor has never seen are allowed so a failed first poll
+// doesn't block all execution.
+func (sm *StatusMonitor) Guard(symbol string) error {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	detail, known := sm.markets[symbol]
+	if !known && sm.names != nil {
+		// Callers may name the market by its pair or canonical symbol
+		if listed, ok := sm.names.Resolve(symbol); ok {
+			detail, known = sm.markets[listed.Symbol], true
+		}
+	}
+	if !known {
+		return nil
+	}
+
+	if detail.Status != "active" {
+		return fmt.Errorf("market %s is %s", symbol, detail.Status)
+	}
+
+	if title, ok := sm.maintenance[detail.TargetCurrencyShortName]; ok {
+		return fmt.Errorf("%s under maintenance: %s", detail.TargetCurrencyShortName, title)
+	}
+
+	return nil
+}
+
+func (sm *StatusMonitor) updateMarkets(details []types.MarketDetail) []StatusEvent {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	events := []StatusEvent{}
+	firstPoll := len(sm.markets) == 0
+	sm.names = market.NewMarkets(details)
+
+	for _, market := range details {
+		previous, known := sm.markets[market.Symbol]
+		sm.markets[market.Symbol] = market
+
+		if firstPoll || !known || previous.Status == market.Status {
+			continue
+		}
+
+		kind := EventMarketSuspended
+		if market.Status == "active" {
+			kind = EventMarketResumed
+		}
+		events = append(events, StatusEvent{
+			Kind:     kind,
+			Market:   market.Symbol,
+			Currency: market.TargetCurrencyShortName,
+			Detail:   fmt.Sprintf("status %s → %s", previous.Status, market.Status),
+			Time:     time.Now(),
+		})
+	}
+
+	return events
+}
+
+// updateMaintenance marks currencies mentioned in maintenance or suspension announcements
+func (sm *StatusMonitor) updateMaintenance(announcements []Announcement) []StatusEvent {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	currencies := make(map[string]bool)
+	for _, market := range sm.markets {
+		currencies[market.TargetCurrencyShortName] = true
+	}
+
+	current := make(map[string]string)
+	for _, a := range announcements {
+		text := strings.ToLower(a.Title + " " + a.Description)
+		if !strings.Contains(text, "maintenance") && !strings.Contains(text, "suspen") {
+			continue
+		}
+
+		for _, word := range strings.FieldsFunc(a.Title+" "+a.Description, isNotAlnum) {
+			if currencies[word] {
+				current[word] = a.Title
+			}
+		}
+	}
+
+	events := []StatusEvent{}
+	for currency, title := range current {
+		if _, seen := sm.maintenance[currency]; !seen {
+			events = append(events, StatusEvent{
+				Kind:     EventMaintenance,
+				Currency: currency,
+				Detail:   title,
+				Time:     time.Now(),
+			})
+		}
+	}
+	sm.maintenance = current
+
+	return events
+}
+
+func (sm *StatusMonitor) fetchAnnouncements() ([]Announcement, error) {
+	resp, err := sm.client.Get(sm.announcementsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var announcements []Announcement
+	if err := json.Unmarshal(body, &announcements); err != nil {
+		return nil, fmt.Errorf("error parsing announcements: %v", err)
+	}
+	return announcements, nil
+}
+
+func (sm *StatusMonitor) emit(events []StatusEvent) {
+	sm.mu.RLock()
+	handlers := sm.handlers
+	sm.mu.RUnlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+func isNotAlnum(r rune) bool {
+	return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+}
@@ -0,0 +1,19 @@
+package exchange
+
+import (
+	"net/http"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// NewTransport builds the http.RoundTripper exchange clients send through:
+// the retry policy from config, over the call budget, over base
+// (http.DefaultTransport when nil). Retries sit above the budget so every
+// attempt waits for its tokens. The budget only bounds the clients that
+// share it, so build one per process and hand it to every fetcher, rate
+// manager and trading client.
+func NewTransport(base http.RoundTripper, config *types.Config) http.RoundTripper {
+	budget := NewCallBudget(base, config.APICallBudget(), config.APICallBurst, config.APICallWeights)
+	backoff, maxBackoff := config.HTTPRetryBackoff()
+	return NewRetryPolicy(budget, config.HTTPRetryAttempts, backoff, maxBackoff, config.HTTPRetryStatuses)
+}
@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// emptyTicker answers every request with an empty ticker list
+type emptyTicker struct {
+	calls atomic.Int32
+}
+
+func (e *emptyTicker) RoundTrip(req *http.Request) (*http.Response, error) {
+	e.calls.Add(1)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("[]")), Request: req}, nil
+}
+
+func TestTransportBudgetsInjectedClients(t *testing.T) {
+	before := http.DefaultTransport
+	wire := &emptyTicker{}
+	config := types.DefaultConfig()
+	config.APICallBurst = 10
+
+	transport := NewTransport(wire, config)
+	fetcher := market.NewFetcher()
+	fetcher.SetTransport(transport)
+	if _, err := fetcher.GetTicker(); err != nil {
+		t.Fatal(err)
+	}
+
+	if wire.calls.Load() != 1 {
+		t.Errorf("%d requests reached the wire, want 1", wire.calls.Load())
+	}
+	budget := transport.(*RetryPolicy).next.(*CallBudget)
+	if spent := budget.burst - budget.tokens; spent < budget.Weight("/exchange/ticker")-0.1 {
+		t.Errorf("budget spent %.2f tokens on the ticker, want %.0f", spent, budget.Weight("/exchange/ticker"))
+	}
+	if http.DefaultTransport != before {
+		t.Error("building a transport replaced http.DefaultTransport")
+	}
+
+	// A second transport is a separate budget over the same wire, not one stacked on the first
+	if again := NewTransport(wire, config).(*RetryPolicy).next.(*CallBudget); again.next != wire {
+		t.Errorf("second transport sends through %T, want the wire", again.next)
+	}
+}
+
+func TestTransportRetriesAboveBudget(t *testing.T) {
+	wire := &unavailableTwice{}
+	config := types.DefaultConfig()
+	config.APICallBurst = 10
+	config.HTTPRetryAttempts = 3
+	config.HTTPRetryBackoffMs, config.HTTPRetryMaxBackoffMs = 1, 1
+	config.HTTPRetryStatuses = []int{http.StatusServiceUnavailable}
+
+	// Retry policy, then call budget, then the wire: fixed by NewTransport, not by install order
+	transport := NewTransport(wire, config)
+	policy, ok := transport.(*RetryPolicy)
+	if !ok {
+		t.Fatalf("transport is %T, want the retry policy outermost", transport)
+	}
+	budget, ok := policy.next.(*CallBudget)
+	if !ok || budget.next != wire {
+		t.Fatalf("retry policy sends through %T, want the call budget over the wire", policy.next)
+	}
+
+	req, _ := http.NewRequest("POST", "https://api.coindcx.com/exchange/v1/orders/status", strings.NewReader(`{"id":"1"}`))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || wire.calls.Load() != 3 {
+		t.Errorf("got %d after %d calls, want 200 after 3", resp.StatusCode, wire.calls.Load())
+	}
+	// Every attempt went through the budget
+	weight := budget.Weight("/exchange/v1/orders/status")
+	if spent := budget.burst - budget.tokens; spent < 3*weight-0.1 {
+		t.Errorf("budget spent %.2f tokens on 3 attempts, want %.0f", spent, 3*weight)
+	}
+}
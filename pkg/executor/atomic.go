@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+// AtomicResult is the outcome of submitting both legs of an arbitrage together
+type AtomicResult struct {
+	Buy       *coindcx.Order
+	Sell      *coindcx.Order
+	Cancelled []string
+	Err       error
+}
+
+// Filled reports whether both legs completed
+func (r AtomicResult) Filled() bool {
+	return r.Err == nil && r.Buy != nil && r.Sell != nil &&
+		r.Buy.Status == "filled" && r.Sell.Status == "filled"
+}
+
+type legOutcome struct {
+	order *coindcx.Order
+	err   error
+}
+
+// SubmitAtomic places the buy and sell legs near-simultaneously and cancels
+// the surviving leg when the other fails to place or fill within the timeout.
+// The sell leg assumes the inventory is already held, so callers trade a
+// missed opportunity for never carrying one-sided exposure.
+func SubmitAtomic(ex Executor, buy, sell coindcx.OrderRequest, timeout time.Duration) AtomicResult {
+	var wg sync.WaitGroup
+	var buyPlaced, sellPlaced legOutcome
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		buyPlaced.order, buyPlaced.err = ex.CreateOrder(buy)
+	}()
+	go func() {
+		defer wg.Done()
+		sellPlaced.order, sellPlaced.err = ex.CreateOrder(sell)
+	}()
+	wg.Wait()
+
+	result := AtomicResult{}
+
+	// Placement failure on either side: cancel whatever made it to the book
+	if buyPlaced.err != nil || sellPlaced.err != nil {
+		if buyPlaced.err == nil {
+			result.Cancelled = append(result.Cancelled, cancelSurvivor(ex, buyPlaced.order)...)
+			result.Buy = buyPlaced.order
+		}
+		if sellPlaced.err == nil {
+			result.Cancelled = append(result.Cancelled, cancelSurvivor(ex, sellPlaced.order)...)
+			result.Sell = sellPlaced.order
+		}
+		result.Err = fmt.Errorf("leg placement failed: buy=%v sell=%v", buyPlaced.err, sellPlaced.err)
+		return result
+	}
+
+	var buyFilled, sellFilled legOutcome
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		buyFilled.order, buyFilled.err = WaitForFill(ex, buyPlaced.order.ID, timeout)
+	}()
+	go func() {
+		defer wg.Done()
+		sellFilled.order, sellFilled.err = WaitForFill(ex, sellPlaced.order.ID, timeout)
+	}()
+	wg.Wait()
+
+	result.Buy = latest(buyPlaced.order, buyFilled.order)
+	result.Sell = latest(sellPlaced.order, sellFilled.order)
+
+	if buyFilled.err != nil || sellFilled.err != nil {
+		result.Cancelled = append(result.Cancelled, cancelSurvivor(ex, result.Buy)...)
+		result.Cancelled = append(result.Cancelled, cancelSurvivor(ex, result.Sell)...)
+		result.Err = fmt.Errorf("leg fill failed: buy=%v sell=%v", buyFilled.err, sellFilled.err)
+	}
+
+	return result
+}
+
+// cancelSurvivor cancels an order that is still working on the book and
+// refreshes it, so fills that landed before the cancel are counted
+func cancelSurvivor(ex Executor, order *coindcx.Order) []string {
+	if order == nil {
+		return nil
+	}
+	if isTerminal(order.Status) {
+		return nil
+	}
+
+	if err := ex.CancelOrder(order.ID); err != nil {
+		logger.Warn("⚠️ Failed to cancel surviving leg", "order_id", order.ID, "error", err)
+		return nil
+	}
+
+	logger.Info("🚫 Cancelled surviving leg", "order_id", order.ID, "market", order.Market)
+	order.Status = "cancelled"
+	if polled, err := ex.GetOrderStatus(order.ID); err == nil && isTerminal(polled.Status) {
+		*order = *polled
+	}
+	return []string{order.ID}
+}
+
+func latest(placed, polled *coindcx.Order) *coindcx.Order {
+	if polled != nil {
+		return polled
+	}
+	return placed
+}
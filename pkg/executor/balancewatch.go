@@ -0,0 +1,194 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+const (
+	balanceTolerancePct = 0.1 // Movement within this share of the balance is rounding, not outside activity
+	fillMemory          = time.Hour
+)
+
+// BalanceDrift is a currency whose balance moved by more than the bot's own
+// fills explain
+type BalanceDrift struct {
+	Currency string
+	Previous float64 // Free + locked at the previous check
+	Expected float64 // Previous plus the bot's fills since
+	Actual   float64
+}
+
+type countedFill struct {
+	quantity float64
+	fee      float64
+	seenAt   time.Time
+}
+
+// BalanceWatcher wraps a venue and keeps a running tally of what the bot's
+// own fills should have done to each balance, from every order it places or
+// polls (trades, recoveries, stops). Check compares that tally with the
+// balances the venue reports, so manual trades or withdrawals on the same
+// account, which invalidate reservations and exposure figures, don't go
+// unnoticed.
+type BalanceWatcher struct {
+	Executor
+	markets *marketIndex
+
+	mu       sync.Mutex
+	baseline map[string]float64 // Nil until the first check
+	expected map[string]float64
+	counted  map[string]countedFill // Order ID → fill already in expected
+}
+
+// NewBalanceWatcher wraps venue; source resolves markets to their currencies
+func NewBalanceWatcher(venue Executor, source MarketDetailSource) *BalanceWatcher {
+	return &BalanceWatcher{
+		Executor: venue,
+		markets:  newMarketIndex(source),
+		expected: make(map[string]float64),
+		counted:  make(map[string]countedFill),
+	}
+}
+
+// CreateOrder places the order, counting anything filled on placement
+func (w *BalanceWatcher) CreateOrder(req coindcx.OrderRequest) (*coindcx.Order, error) {
+	order, err := w.Executor.CreateOrder(req)
+	if err == nil {
+		w.observe(order)
+	}
+	return order, err
+}
+
+// GetOrderStatus fetches the order, counting any fill since it was last seen
+func (w *BalanceWatcher) GetOrderStatus(orderID string) (*coindcx.Order, error) {
+	order, err := w.Executor.GetOrderStatus(orderID)
+	if err == nil {
+		w.observe(order)
+	}
+	return order, err
+}
+
+// FindOrder looks the order up, counting any fill since it was last seen
+func (w *BalanceWatcher) FindOrder(clientOrderID string) (*coindcx.Order, bool, error) {
+	order, found, err := FindOrder(w.Executor, clientOrderID)
+	if found {
+		w.observe(order)
+	}
+	return order, found, err
+}
+
+// Check fetches balances and returns the currencies whose total (free +
+// locked) moved by more than the fills seen since the previous check; that
+// check's figures become the new baseline. The first call only records the
+// baseline. Call it while no orders are in flight: a fill the venue has
+// applied but the bot hasn't polled yet would read as unexplained.
+func (w *BalanceWatcher) Check() ([]BalanceDrift, error) {
+	balances, err := w.Executor.GetBalances()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching balances: %v", err)
+	}
+
+	current := make(map[string]float64, len(balances))
+	for _, balance := range balances {
+		current[balance.Currency] = balance.Balance + balance.Locked
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	drifts := []BalanceDrift{}
+	if w.baseline != nil {
+		currencies := make(map[string]bool)
+		for _, m := range []map[string]float64{w.baseline, w.expected, current} {
+			for currency := range m {
+				currencies[currency] = true
+			}
+		}
+
+		for currency := range currencies {
+			expected := w.baseline[currency] + w.expected[currency]
+			actual := current[currency]
+			tolerance := 1e-8 + math.Max(math.Abs(expected), math.Abs(actual))*balanceTolerancePct/100
+			if math.Abs(actual-expected) > tolerance {
+				drifts = append(drifts, BalanceDrift{
+					Currency: currency,
+					Previous: w.baseline[currency],
+					Expected: expected,
+					Actual:   actual,
+				})
+			}
+		}
+		sort.Slice(drifts, func(i, j int) bool { return drifts[i].Currency < drifts[j].Currency })
+	}
+
+	w.baseline = current
+	w.expected = make(map[string]float64)
+	for id, fill := range w.counted {
+		if time.Since(fill.seenAt) > fillMemory {
+			delete(w.counted, id)
+		}
+	}
+	return drifts, nil
+}
+
+// Pending returns the net quantity the bot's own fills moved each currency
+// by since the last check: what the next check expects balances to show
+func (w *BalanceWatcher) Pending() map[string]float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pending := make(map[string]float64, len(w.expected))
+	for currency, quantity := range w.expected {
+		if quantity != 0 {
+			pending[currency] = quantity
+		}
+	}
+	return pending
+}
+
+// observe adds the part of an order's fill not yet counted to the tally
+func (w *BalanceWatcher) observe(order *coindcx.Order) {
+	if order == nil || order.ID == "" {
+		return
+	}
+	filled := order.TotalQuantity - order.RemainingQuantity
+	if filled <= 0 && order.FeeAmount <= 0 {
+		return
+	}
+
+	detail, ok := w.markets.get(order.Market)
+	if !ok {
+		return
+	}
+	price := order.AvgPrice
+	if price <= 0 {
+		price = order.PricePerUnit
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	counted := w.counted[order.ID]
+	quantity, fee := filled-counted.quantity, order.FeeAmount-counted.fee
+	if quantity <= 0 && fee <= 0 {
+		return
+	}
+
+	coin, quote := detail.TargetCurrencyShortName, detail.BaseCurrencyShortName
+	switch order.Side {
+	case "buy":
+		w.expected[coin] += quantity
+		w.expected[quote] -= quantity*price + fee
+	case "sell":
+		w.expected[coin] -= quantity
+		w.expected[quote] += quantity*price - fee
+	default:
+		return
+	}
+	w.counted[order.ID] = countedFill{quantity: filled, fee: order.FeeAmount, seenAt: time.Now()}
+}
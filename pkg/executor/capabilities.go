@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// MarketDetailSource supplies exchange market details (market.Fetcher)
+type MarketDetailSource interface {
+	GetMarketDetails() ([]types.MarketDetail, error)
+}
+
+// CheckOrderType returns an error unless the market lists the order type.
+// Markets whose details list no types are not checked.
+func CheckOrderType(market types.MarketDetail, orderType string) error {
+	if len(market.OrderTypes) == 0 {
+		return nil
+	}
+	for _, supported := range market.OrderTypes {
+		if supported == orderType {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not support %s orders (supported: %s)",
+		market.Symbol, orderType, strings.Join(market.OrderTypes, ", "))
+}
+
+// CapabilityGuard wraps a venue and rejects orders whose type the market
+// doesn't support before they are sent. Market details load on the first
+// order; while they are unavailable orders pass through unchecked.
+type CapabilityGuard struct {
+	Executor
+	markets *marketIndex
+}
+
+// NewCapabilityGuard wraps venue with order-type checks from source
+func NewCapabilityGuard(venue Executor, source MarketDetailSource) *CapabilityGuard {
+	return &CapabilityGuard{Executor: venue, markets: newMarketIndex(source)}
+}
+
+// Over returns a guard in front of another venue sharing these market details
+func (g *CapabilityGuard) Over(venue Executor) *CapabilityGuard {
+	return &CapabilityGuard{Executor: venue, markets: g.markets}
+}
+
+// CreateOrder places the order if its market supports the order type
+func (g *CapabilityGuard) CreateOrder(req coindcx.OrderRequest) (*coindcx.Order, error) {
+	if market, ok := g.markets.get(req.Market); ok {
+		if err := CheckOrderType(market, req.OrderType); err != nil {
+			return nil, err
+		}
+	}
+	return g.Executor.CreateOrder(req)
+}
+
+// FindOrder looks the order up on the guarded venue
+func (g *CapabilityGuard) FindOrder(clientOrderID string) (*coindcx.Order, bool, error) {
+	return FindOrder(g.Executor, clientOrderID)
+}
+
+// marketIndex loads market details on first use and resolves any of a
+// market's names, retrying on the next lookup if the source is unavailable
+type marketIndex struct {
+	source  MarketDetailSource
+	mu      sync.Mutex
+	markets *market.Markets
+}
+
+func newMarketIndex(source MarketDetailSource) *marketIndex {
+	return &marketIndex{source: source}
+}
+
+func (m *marketIndex) get(symbol string) (types.MarketDetail, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.markets == nil {
+		details, err := m.source.GetMarketDetails()
+		if err != nil {
+			logger.Warn("⚠️ Market details unavailable", "error", err)
+			return types.MarketDetail{}, false
+		}
+
+		m.markets = market.NewMarkets(details)
+	}
+
+	return m.markets.Resolve(symbol)
+}
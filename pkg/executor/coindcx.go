@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+// CoinDCXExecutor routes orders to the live CoinDCX exchange
+type CoinDCXExecutor struct {
+	client *coindcx.Client
+}
+
+// NewCoinDCXExecutor wraps an authenticated CoinDCX client
+func NewCoinDCXExecutor(client *coindcx.Client) *CoinDCXExecutor {
+	return &CoinDCXExecutor{client: client}
+}
+
+func (c *CoinDCXExecutor) Name() string {
+	return "coindcx"
+}
+
+func (c *CoinDCXExecutor) CreateOrder(req coindcx.OrderRequest) (*coindcx.Order, error) {
+	response, err := c.client.CreateOrder(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Orders) == 0 {
+		return nil, fmt.Errorf("no order returned")
+	}
+
+	return &response.Orders[0], nil
+}
+
+func (c *CoinDCXExecutor) GetOrderStatus(orderID string) (*coindcx.Order, error) {
+	return c.client.GetOrderStatus(orderID)
+}
+
+// GetOrderStatuses fetches several orders' statuses in one request
+func (c *CoinDCXExecutor) GetOrderStatuses(orderIDs []string) ([]coindcx.Order, error) {
+	return c.client.GetOrderStatuses(orderIDs)
+}
+
+// FindOrder fetches the order created with clientOrderID
+func (c *CoinDCXExecutor) FindOrder(clientOrderID string) (*coindcx.Order, bool, error) {
+	order, err := c.client.GetOrderByClientID(clientOrderID)
+	if coindcx.IsOrderNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return order, true, nil
+}
+
+func (c *CoinDCXExecutor) CancelOrder(orderID string) error {
+	return c.client.CancelOrder(orderID)
+}
+
+func (c *CoinDCXExecutor) GetBalances() ([]coindcx.Balance, error) {
+	return c.client.GetBalances()
+}
@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"net/http"
+
+	"github.com/b-thark/cdcx-api/internal/config"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// DryRunBalances seed a dry run when the account's balances can't be read
+var DryRunBalances = map[string]float64{"USDT": 1000, "INR": 100000}
+
+// NewDryRunVenue creates a venue that fills orders against live order books,
+// walking the levels for slippage and charging the trading fee, without
+// placing anything. It starts from the account's real balances when the
+// credentials can read them; the read is the only authenticated call.
+func NewDryRunVenue(apiConfig *config.Config, transport http.RoundTripper) Executor {
+	fetcher := market.NewFetcher()
+	fetcher.SetTransport(transport)
+	markets, err := fetcher.GetMarketDetails()
+	if err != nil {
+		// Orders fail as unknown markets; nothing can reach the exchange either way
+		logger.Warn("⚠️ Dry run: error loading markets", "error", err)
+	}
+
+	balances := DryRunBalances
+	if apiConfig.APIKey != "" {
+		client := coindcx.NewClient(apiConfig.APIKey, apiConfig.APISecret)
+		client.SetTransport(transport)
+		if real, err := client.GetBalances(); err == nil {
+			balances = make(map[string]float64, len(real))
+			for _, balance := range real {
+				balances[balance.Currency] = balance.Balance
+			}
+		} else {
+			logger.Warn("⚠️ Dry run: using default balances, account balances unavailable", "error", err)
+		}
+	}
+
+	return NewSimulatedExecutor(fetcher, markets, types.DefaultConfig().FeeRate, balances)
+}
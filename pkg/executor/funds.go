@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+// Funds is the spendable view of one currency's balance
+type Funds struct {
+	Currency  string  `json:"currency"`
+	Free      float64 `json:"free"`      // Balance reported by the venue
+	Locked    float64 `json:"locked"`    // Tied up in open orders (stops, resting limits)
+	Reserved  float64 `json:"reserved"`  // Committed locally to orders the venue may not reflect yet
+	Pending   float64 `json:"pending"`   // Net of the bot's own fills since balances were last reconciled
+	Available float64 `json:"available"` // Free minus Reserved, never negative
+}
+
+// Reservations tracks funds committed to in-flight orders so sizing right
+// after a placement doesn't count the same balance twice while the venue's
+// balance figure catches up
+type Reservations struct {
+	reserved map[string]float64
+	mu       sync.Mutex
+}
+
+// NewReservations creates an empty reservation book
+func NewReservations() *Reservations {
+	return &Reservations{reserved: make(map[string]float64)}
+}
+
+// Reserve commits amount of currency and returns a func that releases it
+func (r *Reservations) Reserve(currency string, amount float64) func() {
+	r.mu.Lock()
+	r.reserved[currency] += amount
+	r.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.reserved[currency] -= amount
+			if r.reserved[currency] <= 0 {
+				delete(r.reserved, currency)
+			}
+		})
+	}
+}
+
+// All returns every reserved amount by currency
+func (r *Reservations) All() map[string]float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := make(map[string]float64, len(r.reserved))
+	for currency, amount := range r.reserved {
+		all[currency] = amount
+	}
+	return all
+}
+
+// Reserved returns the amount of currency currently reserved
+func (r *Reservations) Reserved(currency string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reserved[currency]
+}
+
+// BalanceView is the one account picture readiness checks, sizing and the
+// control API read availability from: the venue's free and locked
+// balances, local reservations for in-flight orders and the bot's fills
+// not yet reconciled with the venue
+type BalanceView struct {
+	At    time.Time        `json:"at"`
+	Funds map[string]Funds `json:"funds"`
+}
+
+// NewBalanceView combines the venue's balances with reservations and
+// pending fills (currency → net quantity); either may be nil
+func NewBalanceView(balances []coindcx.Balance, reservations *Reservations, pending map[string]float64) *BalanceView {
+	view := &BalanceView{At: time.Now(), Funds: make(map[string]Funds)}
+	for _, balance := range balances {
+		view.Funds[balance.Currency] = Funds{Currency: balance.Currency, Free: balance.Balance, Locked: balance.Locked}
+	}
+	if reservations != nil {
+		for currency, amount := range reservations.All() {
+			funds := view.Get(currency)
+			funds.Reserved = amount
+			view.Funds[currency] = funds
+		}
+	}
+	for currency, quantity := range pending {
+		funds := view.Get(currency)
+		funds.Pending = quantity
+		view.Funds[currency] = funds
+	}
+
+	for currency, funds := range view.Funds {
+		funds.Available = max(funds.Free-funds.Reserved, 0)
+		view.Funds[currency] = funds
+	}
+	return view
+}
+
+// Get returns a currency's funds, zero when the account has none
+func (v *BalanceView) Get(currency string) Funds {
+	if funds, ok := v.Funds[currency]; ok {
+		return funds
+	}
+	return Funds{Currency: currency}
+}
+
+// Currencies lists the currencies in the view, sorted
+func (v *BalanceView) Currencies() []string {
+	currencies := make([]string, 0, len(v.Funds))
+	for currency := range v.Funds {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
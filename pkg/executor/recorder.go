@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+// APICall is one request the engine made to a venue
+type APICall struct {
+	Time        time.Time
+	Method      string
+	Market      string
+	Side        string
+	OrderID     string
+	Status      string // Order status the venue reported, if any
+	Duration    time.Duration
+	Err         error
+	ExecutionID string // Execution attempt that made the call, if any
+}
+
+// Recorder wraps a venue and reports every call made through it, for the
+// audit trail's per-execution timelines
+type Recorder struct {
+	Executor
+	record func(APICall)
+}
+
+// NewRecorder wraps venue, passing each call to record once it returns
+func NewRecorder(venue Executor, record func(APICall)) *Recorder {
+	return &Recorder{Executor: venue, record: record}
+}
+
+// For returns a recorder over the same venue that attributes every call to
+// an execution attempt
+func (r *Recorder) For(executionID string) *Recorder {
+	return &Recorder{Executor: r.Executor, record: func(call APICall) {
+		call.ExecutionID = executionID
+		r.record(call)
+	}}
+}
+
+// CreateOrder places the order and records the request
+func (r *Recorder) CreateOrder(req coindcx.OrderRequest) (*coindcx.Order, error) {
+	start := time.Now()
+	order, err := r.Executor.CreateOrder(req)
+
+	call := APICall{Time: start, Method: "create_order", Market: req.Market, Side: req.Side, Duration: time.Since(start), Err: err}
+	if order != nil {
+		call.OrderID, call.Status = order.ID, order.Status
+	}
+	r.record(call)
+	return order, err
+}
+
+// GetOrderStatus fetches the order and records the request
+func (r *Recorder) GetOrderStatus(orderID string) (*coindcx.Order, error) {
+	start := time.Now()
+	order, err := r.Executor.GetOrderStatus(orderID)
+
+	call := APICall{Time: start, Method: "get_order_status", OrderID: orderID, Duration: time.Since(start), Err: err}
+	if order != nil {
+		call.Market, call.Side, call.Status = order.Market, order.Side, order.Status
+	}
+	r.record(call)
+	return order, err
+}
+
+// FindOrder looks the order up by its client order ID and records the request
+func (r *Recorder) FindOrder(clientOrderID string) (*coindcx.Order, bool, error) {
+	start := time.Now()
+	order, found, err := FindOrder(r.Executor, clientOrderID)
+
+	call := APICall{Time: start, Method: "find_order", Duration: time.Since(start), Err: err}
+	if order != nil {
+		call.Market, call.Side, call.OrderID, call.Status = order.Market, order.Side, order.ID, order.Status
+	}
+	r.record(call)
+	return order, found, err
+}
+
+// CancelOrder cancels the order and records the request
+func (r *Recorder) CancelOrder(orderID string) error {
+	start := time.Now()
+	err := r.Executor.CancelOrder(orderID)
+
+	r.record(APICall{Time: start, Method: "cancel_order", OrderID: orderID, Duration: time.Since(start), Err: err})
+	return err
+}
+
+// GetBalances fetches balances and records the request
+func (r *Recorder) GetBalances() ([]coindcx.Balance, error) {
+	start := time.Now()
+	balances, err := r.Executor.GetBalances()
+
+	r.record(APICall{Time: start, Method: "get_balances", Duration: time.Since(start), Err: err})
+	return balances, err
+}
@@ -0,0 +1,318 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// MarketGuard reports whether a market can currently be traded
+type MarketGuard interface {
+	Guard(symbol string) error
+}
+
+// recoveryQuotes are tried in order when liquidating stranded inventory
+var recoveryQuotes = []string{"USDT", "INR"}
+
+// RecoverInventory sells stranded inventory on the first quote market the
+// guard allows, falling back from USDT to INR. When every route is suspended
+// it returns immediately with ManualRequired instead of retrying a market
+// that cannot fill. guard may be nil.
+func RecoverInventory(ex Executor, guard MarketGuard, currency string, volume float64, timeout time.Duration) RecoveryResult {
+	blocked := []string{}
+
+	for _, quote := range recoveryQuotes {
+		market := types.NewSymbol(currency, quote).Code()
+		if guard != nil {
+			if err := guard.Guard(market); err != nil {
+				blocked = append(blocked, err.Error())
+				continue
+			}
+		}
+
+		result := sellAtMarket(ex, market, quote, volume, timeout)
+		if result.Success {
+			return result
+		}
+		logger.Warn("⚠️ Recovery failed", "market", market, "reason", result.Reason)
+		if result.Sold > 0 {
+			return result
+		}
+		blocked = append(blocked, fmt.Sprintf("%s: %s", market, result.Reason))
+	}
+
+	return manualRecovery(currency, volume, blocked)
+}
+
+// RouteLeg is one market order on a recovery route
+type RouteLeg struct {
+	Market string `json:"market"`
+	Side   string `json:"side"`
+	From   string `json:"from"` // Currency spent
+	To     string `json:"to"`   // Currency received
+}
+
+// RecoveryRoute is a path from a coin back to USDT with its estimated proceeds
+type RecoveryRoute struct {
+	Legs         []RouteLeg `json:"legs"`
+	ExpectedUSDT float64    `json:"expected_usdt"`
+}
+
+func (r RecoveryRoute) String() string {
+	markets := make([]string, len(r.Legs))
+	for i, leg := range r.Legs {
+		markets[i] = leg.Market
+	}
+	return strings.Join(markets, "→")
+}
+
+// recoveryIntermediates are the quotes a coin may be sold into before USDT
+var recoveryIntermediates = []string{"INR", "BTC"}
+
+// RoutePlanner picks the recovery route with the best expected proceeds
+// after fees across COIN/USDT, COIN/INR→INR/USDT and COIN/BTC→BTC/USDT.
+type RoutePlanner struct {
+	books   BookSource
+	markets *market.Markets
+	feeRate func(market string) float64
+}
+
+// NewRoutePlanner creates a planner over the given markets; feeRate gives
+// each leg's market fee as a fraction
+func NewRoutePlanner(books BookSource, markets []types.MarketDetail, feeRate func(market string) float64) *RoutePlanner {
+	return &RoutePlanner{
+		books:   books,
+		markets: market.NewMarkets(markets),
+		feeRate: feeRate,
+	}
+}
+
+// symbol is the exchange symbol of the base/quote market, falling back to
+// CoinDCX's usual form when it isn't listed (estimate then rejects the leg)
+func (p *RoutePlanner) symbol(base, quote string) string {
+	if detail, ok := p.markets.Find(base, quote); ok {
+		return detail.Symbol
+	}
+	return types.NewSymbol(base, quote).Code()
+}
+
+// Plan returns every tradable route for the volume, best expected proceeds first
+func (p *RoutePlanner) Plan(currency string, volume float64, guard MarketGuard) []RecoveryRoute {
+	candidates := [][]RouteLeg{{{Market: p.symbol(currency, "USDT"), Side: "sell", From: currency, To: "USDT"}}}
+
+	for _, quote := range recoveryIntermediates {
+		first := RouteLeg{Market: p.symbol(currency, quote), Side: "sell", From: currency, To: quote}
+
+		// The quote may be listed against USDT (BTC/USDT) or USDT against it (USDT/INR)
+		if detail, ok := p.markets.Find(quote, "USDT"); ok {
+			candidates = append(candidates, []RouteLeg{first, {Market: detail.Symbol, Side: "sell", From: quote, To: "USDT"}})
+		} else if detail, ok := p.markets.Find("USDT", quote); ok {
+			candidates = append(candidates, []RouteLeg{first, {Market: detail.Symbol, Side: "buy", From: quote, To: "USDT"}})
+		}
+	}
+
+	routes := []RecoveryRoute{}
+	for _, legs := range candidates {
+		expected, err := p.estimate(legs, volume, guard)
+		if err != nil {
+			continue
+		}
+		routes = append(routes, RecoveryRoute{Legs: legs, ExpectedUSDT: expected})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].ExpectedUSDT > routes[j].ExpectedUSDT
+	})
+	return routes
+}
+
+// Market returns the details of a listed market by any of its names
+func (p *RoutePlanner) Market(symbol string) (types.MarketDetail, bool) {
+	return p.markets.Resolve(symbol)
+}
+
+// MinQuantity returns the smallest order size that any direct market for the
+// currency accepts; ok is false when the currency has no listed market
+func (p *RoutePlanner) MinQuantity(currency string) (float64, bool) {
+	minQty, ok := 0.0, false
+	for _, quote := range append([]string{"USDT"}, recoveryIntermediates...) {
+		market, listed := p.markets.Find(currency, quote)
+		if !listed {
+			continue
+		}
+		if !ok || market.MinQuantity < minQty {
+			minQty, ok = market.MinQuantity, true
+		}
+	}
+	return minQty, ok
+}
+
+// Recover executes the best route, falling back to the next on failure
+func (p *RoutePlanner) Recover(ex Executor, guard MarketGuard, currency string, volume float64, timeout time.Duration) RecoveryResult {
+	routes := p.Plan(currency, volume, guard)
+	if len(routes) == 0 {
+		return manualRecovery(currency, volume, []string{"no listed, tradable market with liquidity"})
+	}
+
+	failures := []string{}
+	for _, route := range routes {
+		logger.Info("🧭 Recovery route", "route", route, "expected_usdt", route.ExpectedUSDT)
+
+		result := p.executeRoute(ex, route, volume, timeout)
+		if result.Success {
+			return result
+		}
+
+		logger.Warn("⚠️ Route failed", "route", route, "reason", result.Reason)
+		failures = append(failures, fmt.Sprintf("%s: %s", route, result.Reason))
+
+		// Inventory already left the coin on a multi-leg route; stop rather than sell twice
+		if result.Market != route.Legs[0].Market {
+			result.ManualRequired = true
+			return result
+		}
+		// Part of it sold; the caller keeps tracking only what is left
+		if result.Sold > 0 {
+			return result
+		}
+	}
+
+	return manualRecovery(currency, volume, failures)
+}
+
+// estimate walks each leg's book to the expected USDT out, net of fees
+func (p *RoutePlanner) estimate(legs []RouteLeg, volume float64, guard MarketGuard) (float64, error) {
+	amount := volume
+
+	for _, leg := range legs {
+		detail, ok := p.markets.Resolve(leg.Market)
+		if !ok {
+			return 0, fmt.Errorf("market %s not listed", leg.Market)
+		}
+		if guard != nil {
+			if err := guard.Guard(leg.Market); err != nil {
+				return 0, err
+			}
+		}
+
+		orderBook, err := p.books.GetOrderBook(detail.Pair)
+		if err != nil {
+			return 0, err
+		}
+
+		side := "asks"
+		if leg.Side == "sell" {
+			side = "bids"
+		}
+		levels := orderBook.Levels(side)
+
+		if leg.Side == "sell" {
+			amount, err = walkSell(levels, amount)
+		} else {
+			amount, err = walkBuy(levels, amount)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", leg.Market, err)
+		}
+		amount *= 1 - p.feeRate(leg.Market)
+	}
+
+	return amount, nil
+}
+
+func (p *RoutePlanner) executeRoute(ex Executor, route RecoveryRoute, volume float64, timeout time.Duration) RecoveryResult {
+	first := route.Legs[0]
+	firstMarket, _ := p.markets.Resolve(first.Market)
+	volume = utils.RoundToPrecision(volume, firstMarket.TargetCurrencyPrecision, utils.RoundDown)
+	result := sellAtMarket(ex, first.Market, first.To, volume, timeout)
+	if !result.Success || len(route.Legs) == 1 {
+		return result
+	}
+
+	// Second leg converts the intermediate proceeds to USDT; the coin is
+	// sold whatever happens to it
+	proceeds := volume*result.SellPrice - result.FeeAmount
+	second := route.Legs[1]
+	secondMarket, _ := p.markets.Resolve(second.Market)
+
+	var order coindcx.OrderRequest
+	if second.Side == "sell" {
+		order = coindcx.OrderRequest{Side: "sell", OrderType: "market_order", Market: second.Market, TotalQuantity: proceeds}
+	} else {
+		orderBook, err := p.books.GetOrderBook(secondMarket.Pair)
+		if err != nil {
+			return RecoveryResult{Market: second.Market, Quote: "USDT", Sold: volume, Reason: err.Error()}
+		}
+		usdt, err := walkBuy(orderBook.Asks, proceeds*(1-p.feeRate(second.Market)))
+		if err != nil {
+			return RecoveryResult{Market: second.Market, Quote: "USDT", Sold: volume, Reason: err.Error()}
+		}
+		order = coindcx.OrderRequest{Side: "buy", OrderType: "market_order", Market: second.Market, TotalQuantity: usdt}
+	}
+	order.TotalQuantity = utils.RoundToPrecision(order.TotalQuantity, secondMarket.TargetCurrencyPrecision, utils.RoundDown)
+
+	placed, err := ex.CreateOrder(order)
+	if err != nil {
+		return RecoveryResult{Market: second.Market, Quote: "USDT", Sold: volume, Reason: err.Error()}
+	}
+	filled, err := WaitForFill(ex, placed.ID, timeout)
+	if err != nil {
+		return RecoveryResult{OrderID: placed.ID, Market: second.Market, Quote: "USDT", Sold: volume, Reason: err.Error()}
+	}
+
+	usdt := filled.TotalQuantity
+	if second.Side == "sell" {
+		usdt = filled.TotalQuantity*filled.AvgPrice - filled.FeeAmount
+	}
+
+	// SellPrice is the effective USDT per coin, already net of both legs' fees
+	return RecoveryResult{
+		Success:   true,
+		SellPrice: usdt / volume,
+		OrderID:   placed.ID,
+		Market:    route.String(),
+		Quote:     "USDT",
+	}
+}
+
+func manualRecovery(currency string, volume float64, failures []string) RecoveryResult {
+	reason := fmt.Sprintf("no tradable route for %s (%s)", currency, strings.Join(failures, "; "))
+	logger.Error("🚨 MANUAL ACTION REQUIRED: stranded", "volume", volume, "currency", currency, "reason", reason)
+
+	return RecoveryResult{Success: false, ManualRequired: true, Reason: reason}
+}
+
+// walkSell returns the quote received for selling qty into the bids
+func walkSell(bids []types.OrderLevel, qty float64) (float64, error) {
+	received := 0.0
+	for _, level := range bids {
+		fill := math.Min(level.Volume, qty)
+		received += fill * level.Price
+		qty -= fill
+		if qty <= 0 {
+			return received, nil
+		}
+	}
+	return 0, fmt.Errorf("insufficient bid depth")
+}
+
+// walkBuy returns the quantity bought by spending quote into the asks
+func walkBuy(asks []types.OrderLevel, spend float64) (float64, error) {
+	bought := 0.0
+	for _, level := range asks {
+		cost := level.Volume * level.Price
+		if cost >= spend {
+			return bought + spend/level.Price, nil
+		}
+		bought += level.Volume
+		spend -= cost
+	}
+	return 0, fmt.Errorf("insufficient ask depth")
+}
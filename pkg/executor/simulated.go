@@ -0,0 +1,221 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// BookSource supplies order books for the simulated exchange
+type BookSource interface {
+	GetOrderBook(pair string) (*market.OrderBook, error)
+}
+
+// SimulatedExecutor fills orders against live or recorded order books
+// without touching a real account. Used for backtests and dry runs.
+type SimulatedExecutor struct {
+	books    BookSource
+	markets  *market.Markets
+	feeRate  float64
+	balances map[string]float64
+	orders   map[string]*coindcx.Order
+	nextID   int
+	mu       sync.Mutex
+}
+
+// NewSimulatedExecutor creates a simulated venue seeded with starting balances
+func NewSimulatedExecutor(books BookSource, markets []types.MarketDetail, feeRate float64, balances map[string]float64) *SimulatedExecutor {
+	s := &SimulatedExecutor{
+		books:    books,
+		markets:  market.NewMarkets(markets),
+		feeRate:  feeRate,
+		balances: make(map[string]float64),
+		orders:   make(map[string]*coindcx.Order),
+	}
+	for currency, amount := range balances {
+		s.balances[currency] = amount
+	}
+	return s
+}
+
+func (s *SimulatedExecutor) Name() string {
+	return "simulated"
+}
+
+func (s *SimulatedExecutor) CreateOrder(req coindcx.OrderRequest) (*coindcx.Order, error) {
+	detail, ok := s.markets.Resolve(req.Market)
+	if !ok {
+		return nil, fmt.Errorf("unknown market %s", req.Market)
+	}
+	if req.TotalQuantity <= 0 {
+		return nil, fmt.Errorf("invalid quantity %.8f", req.TotalQuantity)
+	}
+	if err := CheckOrderType(detail, req.OrderType); err != nil {
+		return nil, err
+	}
+
+	// Stop orders rest untriggered; the simulator does not model triggering
+	if req.OrderType == "stop_limit" {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.recordOrder(req, "untriggered", 0, 0, 0), nil
+	}
+
+	orderBook, err := s.books.GetOrderBook(detail.Pair)
+	if err != nil {
+		return nil, fmt.Errorf("order book unavailable: %v", err)
+	}
+
+	side := "asks"
+	if req.Side == "sell" {
+		side = "bids"
+	}
+	levels := orderBook.Levels(side)
+
+	limit := 0.0
+	if req.OrderType == "limit_order" {
+		limit = req.PricePerUnit
+	}
+
+	filledQty, filledValue := 0.0, 0.0
+	for _, level := range levels {
+		if filledQty >= req.TotalQuantity {
+			break
+		}
+		if limit > 0 && ((req.Side == "buy" && level.Price > limit) || (req.Side == "sell" && level.Price < limit)) {
+			break
+		}
+		qty := min(level.Volume, req.TotalQuantity-filledQty)
+		filledQty += qty
+		filledValue += qty * level.Price
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Fill-or-kill orders that cannot complete never touch the book
+	if req.TimeInForce == coindcx.TimeInForceFOK && filledQty < req.TotalQuantity {
+		return s.recordOrder(req, "cancelled", 0, 0, 0), nil
+	}
+
+	quote := detail.BaseCurrencyShortName
+	coin := detail.TargetCurrencyShortName
+	fee := filledValue * s.feeRate
+
+	if req.Side == "buy" {
+		if s.balances[quote] < filledValue+fee {
+			return nil, fmt.Errorf("insufficient %s balance: %.8f < %.8f", quote, s.balances[quote], filledValue+fee)
+		}
+		s.balances[quote] -= filledValue + fee
+		s.balances[coin] += filledQty
+	} else {
+		if s.balances[coin] < filledQty {
+			return nil, fmt.Errorf("insufficient %s balance: %.8f < %.8f", coin, s.balances[coin], filledQty)
+		}
+		s.balances[coin] -= filledQty
+		s.balances[quote] += filledValue - fee
+	}
+
+	status := "filled"
+	if filledQty < req.TotalQuantity {
+		switch {
+		case req.TimeInForce == coindcx.TimeInForceIOC && filledQty > 0:
+			status = "partially_cancelled"
+		case req.TimeInForce == coindcx.TimeInForceIOC:
+			status = "cancelled"
+		case filledQty > 0:
+			status = "partially_filled"
+		default:
+			status = "open"
+		}
+	}
+
+	return s.recordOrder(req, status, filledQty, filledValue, fee), nil
+}
+
+// recordOrder stores a simulated order and returns a copy. Callers hold s.mu.
+func (s *SimulatedExecutor) recordOrder(req coindcx.OrderRequest, status string, filledQty, filledValue, fee float64) *coindcx.Order {
+	s.nextID++
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	order := &coindcx.Order{
+		ID:                fmt.Sprintf("sim-%d", s.nextID),
+		ClientOrderID:     req.ClientOrderID,
+		Market:            req.Market,
+		OrderType:         req.OrderType,
+		Side:              req.Side,
+		Status:            status,
+		FeeAmount:         fee,
+		TotalQuantity:     req.TotalQuantity,
+		RemainingQuantity: req.TotalQuantity - filledQty,
+		PricePerUnit:      req.PricePerUnit,
+		CreatedAt:         coindcx.FlexibleTimestamp(now),
+		UpdatedAt:         coindcx.FlexibleTimestamp(now),
+	}
+	if filledQty > 0 {
+		order.AvgPrice = filledValue / filledQty
+	}
+
+	s.orders[order.ID] = order
+	copied := *order
+	return &copied
+}
+
+func (s *SimulatedExecutor) GetOrderStatus(orderID string) (*coindcx.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (s *SimulatedExecutor) FindOrder(clientOrderID string) (*coindcx.Order, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, order := range s.orders {
+		if clientOrderID != "" && order.ClientOrderID == clientOrderID {
+			copied := *order
+			return &copied, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (s *SimulatedExecutor) CancelOrder(orderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[orderID]
+	if !ok {
+		return fmt.Errorf("order %s not found", orderID)
+	}
+	if order.Status == "filled" {
+		return fmt.Errorf("order %s already filled", orderID)
+	}
+	order.Status = "cancelled"
+	return nil
+}
+
+func (s *SimulatedExecutor) GetBalances() ([]coindcx.Balance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balances := []coindcx.Balance{}
+	for currency, amount := range s.balances {
+		balances = append(balances, coindcx.Balance{Currency: currency, Balance: amount})
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances, nil
+}
@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+const (
+	statusTTL         = 750 * time.Millisecond // Shorter than WaitForFill's poll interval
+	terminalStatusTTL = time.Minute            // Finished orders don't change
+	watchExpiry       = 10 * time.Second       // Orders not polled for this long leave the batch
+)
+
+// BatchStatusSource is a venue that can fetch several orders' statuses in
+// one request (CoinDCX's status_multiple)
+type BatchStatusSource interface {
+	GetOrderStatuses(orderIDs []string) ([]coindcx.Order, error)
+}
+
+type cachedStatus struct {
+	order     coindcx.Order
+	fetchedAt time.Time
+}
+
+// StatusCache wraps a venue and cuts authenticated status requests while
+// legs wait for fills: a status is reused for a short TTL, and when the
+// venue supports batching every order being waited on is refreshed in one
+// request.
+type StatusCache struct {
+	Executor
+
+	mu      sync.Mutex
+	fetch   sync.Mutex // Coalesces concurrent misses into one request
+	cache   map[string]cachedStatus
+	watched map[string]time.Time // Order ID → last time it was polled
+}
+
+// NewStatusCache wraps venue with order status caching
+func NewStatusCache(venue Executor) *StatusCache {
+	return &StatusCache{
+		Executor: venue,
+		cache:    make(map[string]cachedStatus),
+		watched:  make(map[string]time.Time),
+	}
+}
+
+// GetOrderStatus returns a fresh-enough cached status or refreshes it,
+// together with every other order being waited on when the venue batches
+func (s *StatusCache) GetOrderStatus(orderID string) (*coindcx.Order, error) {
+	if order, ok := s.lookup(orderID, true); ok {
+		return order, nil
+	}
+
+	s.fetch.Lock()
+	defer s.fetch.Unlock()
+
+	// Another leg may have refreshed this order while we waited
+	if order, ok := s.lookup(orderID, false); ok {
+		return order, nil
+	}
+
+	batch, ok := s.Executor.(BatchStatusSource)
+	if !ok {
+		order, err := s.Executor.GetOrderStatus(orderID)
+		if err != nil {
+			return nil, err
+		}
+		s.store(*order)
+		return order, nil
+	}
+
+	orders, err := batch.GetOrderStatuses(s.pending(orderID))
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		s.store(order)
+	}
+
+	if order, ok := s.lookup(orderID, false); ok {
+		return order, nil
+	}
+	// Not in the batch response; ask for it directly
+	order, err := s.Executor.GetOrderStatus(orderID)
+	if err != nil {
+		return nil, err
+	}
+	s.store(*order)
+	return order, nil
+}
+
+// CancelOrder cancels the order and forgets its cached status
+func (s *StatusCache) CancelOrder(orderID string) error {
+	err := s.Executor.CancelOrder(orderID)
+
+	s.mu.Lock()
+	delete(s.cache, orderID)
+	s.mu.Unlock()
+
+	return err
+}
+
+// FindOrder looks the order up on the wrapped venue
+func (s *StatusCache) FindOrder(clientOrderID string) (*coindcx.Order, bool, error) {
+	order, found, err := FindOrder(s.Executor, clientOrderID)
+	if found {
+		s.store(*order)
+	}
+	return order, found, err
+}
+
+// lookup returns the cached status if it is still fresh, optionally
+// recording the order as one being waited on
+func (s *StatusCache) lookup(orderID string, watch bool) (*coindcx.Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if watch {
+		s.watched[orderID] = now
+	}
+
+	cached, ok := s.cache[orderID]
+	if !ok {
+		return nil, false
+	}
+
+	ttl := statusTTL
+	if isTerminal(cached.order.Status) {
+		ttl = terminalStatusTTL
+	}
+	if now.Sub(cached.fetchedAt) > ttl {
+		return nil, false
+	}
+	order := cached.order
+	return &order, true
+}
+
+func (s *StatusCache) store(order coindcx.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.cache[order.ID] = cachedStatus{order: order, fetchedAt: now}
+	if isTerminal(order.Status) {
+		delete(s.watched, order.ID)
+	}
+
+	for id, cached := range s.cache {
+		if now.Sub(cached.fetchedAt) > terminalStatusTTL {
+			delete(s.cache, id)
+		}
+	}
+}
+
+// pending lists orderID plus every other order polled recently that hasn't finished
+func (s *StatusCache) pending(orderID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []string{orderID}
+	now := time.Now()
+	for id, polledAt := range s.watched {
+		if now.Sub(polledAt) > watchExpiry {
+			delete(s.watched, id)
+			continue
+		}
+		if id != orderID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// isTerminal reports whether an order status can no longer change
+func isTerminal(status string) bool {
+	switch status {
+	case "filled", "cancelled", "partially_cancelled", "rejected":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+// stopLimitSlippagePct is how far below the stop the limit is placed so a
+// triggered protective stop still fills in a falling market
+const stopLimitSlippagePct = 0.5
+
+// PlaceStopLimit places a stop-limit order that rests untriggered until the stop price trades
+func PlaceStopLimit(ex Executor, side, market string, quantity, stopPrice, limitPrice float64) (*coindcx.Order, error) {
+	if stopPrice <= 0 || limitPrice <= 0 {
+		return nil, fmt.Errorf("stop-limit requires positive stop and limit prices (stop %.8f, limit %.8f)", stopPrice, limitPrice)
+	}
+
+	return ex.CreateOrder(coindcx.OrderRequest{
+		Side:          side,
+		OrderType:     "stop_limit",
+		Market:        market,
+		TotalQuantity: quantity,
+		StopPrice:     stopPrice,
+		PricePerUnit:  limitPrice,
+	})
+}
+
+// ProtectiveStopPrices returns the stop and limit prices for a long position
+// entered at entryPrice that should be exited stopPct percent lower
+func ProtectiveStopPrices(entryPrice, stopPct float64) (float64, float64) {
+	stop := entryPrice * (1 - stopPct/100)
+	limit := stop * (1 - stopLimitSlippagePct/100)
+	return stop, limit
+}
+
+// PlaceProtectiveStop guards freshly bought inventory with a sell stop-limit
+func PlaceProtectiveStop(ex Executor, market string, quantity, entryPrice, stopPct float64) (*coindcx.Order, error) {
+	stop, limit := ProtectiveStopPrices(entryPrice, stopPct)
+	return PlaceStopLimit(ex, "sell", market, quantity, stop, limit)
+}
@@ -0,0 +1,156 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+)
+
+var logger = logging.For("executor")
+
+// Executor abstracts order placement on a trading venue so the same
+// opportunity pipeline can route orders to CoinDCX, the simulated exchange
+// or any future venue without touching detection code.
+type Executor interface {
+	// Name identifies the venue in logs and execution records
+	Name() string
+	// CreateOrder places an order and returns the venue's view of it
+	CreateOrder(req coindcx.OrderRequest) (*coindcx.Order, error)
+	// GetOrderStatus fetches the latest state of an order
+	GetOrderStatus(orderID string) (*coindcx.Order, error)
+	// CancelOrder cancels an open order
+	CancelOrder(orderID string) error
+	// GetBalances returns the account balances held on the venue
+	GetBalances() ([]coindcx.Balance, error)
+}
+
+// ClientOrderFinder is a venue that can look an order up by the client
+// order ID it was created with
+type ClientOrderFinder interface {
+	// FindOrder returns the order; found is false when the venue has none
+	FindOrder(clientOrderID string) (order *coindcx.Order, found bool, err error)
+}
+
+// FindOrder looks up the order ex created with clientOrderID, through any
+// wrapping venues; it errors when ex can't look orders up that way
+func FindOrder(ex Executor, clientOrderID string) (*coindcx.Order, bool, error) {
+	finder, ok := ex.(ClientOrderFinder)
+	if !ok {
+		return nil, false, fmt.Errorf("%s can't look orders up by client order ID", ex.Name())
+	}
+	return finder.FindOrder(clientOrderID)
+}
+
+// RecoveryResult describes the outcome of liquidating stranded inventory
+type RecoveryResult struct {
+	Success        bool
+	SellPrice      float64
+	FeeAmount      float64
+	OrderID        string
+	Market         string  // Market the inventory was sold on
+	Quote          string  // Currency SellPrice is quoted in
+	ManualRequired bool    // No tradable route; an operator has to handle it
+	Dust           bool    // Below the minimum order size; tracked until it can be sold
+	Sold           float64 // Coins a failed recovery sold before stopping, no longer held
+	Reason         string
+}
+
+// WaitForFill polls an order until it is filled, rejected or the timeout expires
+func WaitForFill(ex Executor, orderID string, timeout time.Duration) (*coindcx.Order, error) {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		order, err := ex.GetOrderStatus(orderID)
+		if err == nil {
+			switch order.Status {
+			case "filled":
+				return order, nil
+			case "cancelled", "partially_cancelled", "rejected":
+				return order, fmt.Errorf("order %s", order.Status)
+			}
+		}
+
+		select {
+		case <-deadline:
+			return order, fmt.Errorf("timeout")
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitOrCancel waits for order like WaitForFill, then cancels it if it is
+// still working at the timeout and returns its final state, partial fill
+// included. An error means the order may still be working or its state is
+// unknown; the order returned is the last state seen, nil when none was.
+func WaitOrCancel(ex Executor, order *coindcx.Order, timeout time.Duration) (*coindcx.Order, error) {
+	final, err := WaitForFill(ex, order.ID, timeout)
+	if err == nil || (final != nil && isTerminal(final.Status)) {
+		return final, nil
+	}
+
+	polled, err := CancelAndConfirm(ex, order.ID)
+	if polled == nil {
+		return final, err
+	}
+	return polled, err
+}
+
+// CancelAndConfirm cancels an order and returns its final state, partial
+// fill included. An error means the order may still be working or its
+// state is unknown, in which case the order returned may be nil.
+func CancelAndConfirm(ex Executor, orderID string) (*coindcx.Order, error) {
+	// A cancel that fails because the order just filled is settled by the status below
+	cancelErr := ex.CancelOrder(orderID)
+	polled, err := ex.GetOrderStatus(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order %s state unknown after cancelling: %v", orderID, err)
+	}
+	if cancelErr != nil && !isTerminal(polled.Status) {
+		return polled, fmt.Errorf("order %s still %s, cancel failed: %v", orderID, polled.Status, cancelErr)
+	}
+	return polled, nil
+}
+
+// RecoverToUSDT market-sells inventory on the currency's USDT market
+func RecoverToUSDT(ex Executor, currency string, volume float64, timeout time.Duration) RecoveryResult {
+	return sellAtMarket(ex, currency+"USDT", "USDT", volume, timeout)
+}
+
+func sellAtMarket(ex Executor, market, quote string, volume float64, timeout time.Duration) RecoveryResult {
+	order, err := ex.CreateOrder(coindcx.OrderRequest{
+		Side:          "sell",
+		OrderType:     "market_order",
+		Market:        market,
+		TotalQuantity: volume,
+	})
+	if err != nil {
+		return RecoveryResult{Success: false, Market: market, Quote: quote, Reason: err.Error()}
+	}
+
+	// An order still working at the timeout is cancelled, so what it sold is known
+	filled, err := WaitOrCancel(ex, order, timeout)
+	if err != nil || filled.Status != "filled" {
+		result := RecoveryResult{Success: false, OrderID: order.ID, Market: market, Quote: quote}
+		if filled != nil {
+			result.Sold = filled.TotalQuantity - filled.RemainingQuantity
+			result.Reason = "order " + filled.Status
+		}
+		if err != nil {
+			result.Reason = err.Error()
+		}
+		return result
+	}
+
+	return RecoveryResult{
+		Success:   true,
+		SellPrice: filled.AvgPrice,
+		FeeAmount: filled.FeeAmount,
+		OrderID:   order.ID,
+		Market:    market,
+		Quote:     quote,
+	}
+}
@@ -0,0 +1,100 @@
+package fees
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/coindcx"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Window is how far back the account's trades are read: CoinDCX sets fee
+// tiers by 30-day volume
+const Window = 30 * 24 * time.Hour
+
+const (
+	maxTrades         = 5000 // Most trades one history request returns
+	minDetectedTrades = 3    // Fewest trades of a market type a rate is derived from
+)
+
+// TradeHistory is the part of the exchange client fee detection reads
+type TradeHistory interface {
+	GetTradeHistoryContext(ctx context.Context, from, to time.Time, limit int) ([]coindcx.Trade, error)
+}
+
+// Rate is the taker rate the account paid on one market type
+type Rate struct {
+	MarketType string
+	Rate       float64
+	Trades     int
+	Configured bool // FEE_SCHEDULE already set it, so it was left alone
+}
+
+// Service resolves the account's real fee rates into a configuration, so
+// every FeeRateFor caller (detection, depth analysis, execution) charges them
+type Service struct {
+	config  *types.Config
+	history TradeHistory
+}
+
+// NewService detects rates for config from history
+func NewService(config *types.Config, history TradeHistory) *Service {
+	return &Service{config: config, history: history}
+}
+
+// Detect reads the account's trades over the last Window and sets each
+// market type's taker rate in FeeSchedule to the median fee rate its trades
+// paid. Rates set by FEE_SCHEDULE are kept, and a market type with fewer
+// than a handful of trades keeps falling back to FeeRate.
+func (s *Service) Detect(ctx context.Context) ([]Rate, error) {
+	now := time.Now()
+	trades, err := s.history.GetTradeHistoryContext(ctx, now.Add(-Window), now, maxTrades)
+	if err != nil {
+		return nil, fmt.Errorf("trade history unavailable: %v", err)
+	}
+
+	// Per-trade rates: the median shrugs off the odd fee charged in another currency
+	byType := make(map[string][]float64)
+	for _, trade := range trades {
+		notional := trade.Quantity * trade.Price
+		if notional <= 0 || trade.FeeAmount < 0 {
+			continue
+		}
+		marketType := types.FeeMarketType(trade.Symbol)
+		byType[marketType] = append(byType[marketType], float64(trade.FeeAmount)/notional)
+	}
+
+	if s.config.FeeSchedule == nil {
+		s.config.FeeSchedule = make(map[string]float64)
+	}
+	rates := []Rate{}
+	for _, marketType := range []string{types.MarketTypeINR, types.MarketTypeC2C} {
+		samples := byType[marketType]
+		if len(samples) < minDetectedTrades {
+			continue
+		}
+		rate := Rate{MarketType: marketType, Rate: median(samples), Trades: len(samples)}
+
+		key := marketType + "_TAKER"
+		if configured, ok := s.config.FeeSchedule[key]; ok {
+			rate.Configured = true
+			rate.Rate = configured
+		} else {
+			s.config.FeeSchedule[key] = rate.Rate
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,163 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// rebaselineAfter consistent books in a row that all stray from the
+// references are taken as a genuine price move rather than a bogus quote
+const rebaselineAfter = 3
+
+// AnomalyFilter quarantines order books whose best prices stray too far from
+// the ticker's last price or from the previous accepted snapshot. A single
+// bogus quote otherwise looks like a huge arbitrage opportunity; a move that
+// holds for rebaselineAfter books becomes the new reference.
+type AnomalyFilter struct {
+	maxDeviationPct float64
+
+	mu          sync.Mutex
+	lastPrices  map[string]float64
+	snapshots   map[string]quoteSnapshot
+	rejected    map[string]rejectedQuotes // The quarantined books since the last accepted one
+	quarantined map[string]string         // symbol → reason
+}
+
+// rejectedQuotes is the latest quarantined book and how many in a row agreed with it
+type rejectedQuotes struct {
+	quote      quoteSnapshot
+	consistent int
+}
+
+type quoteSnapshot struct {
+	bid float64
+	ask float64
+}
+
+// NewAnomalyFilter creates a filter; a maxDeviationPct of 0 disables it
+func NewAnomalyFilter(maxDeviationPct float64) *AnomalyFilter {
+	return &AnomalyFilter{
+		maxDeviationPct: maxDeviationPct,
+		lastPrices:      make(map[string]float64),
+		snapshots:       make(map[string]quoteSnapshot),
+		rejected:        make(map[string]rejectedQuotes),
+		quarantined:     make(map[string]string),
+	}
+}
+
+// UpdateTicker records last traded prices from a /exchange/ticker response
+func (f *AnomalyFilter) UpdateTicker(tickers types.Tickers) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for symbol := range tickers {
+		if last, ok := tickers.Last(symbol); ok {
+			f.lastPrices[symbol] = last
+		}
+	}
+}
+
+// Seed takes a market's last candle close as its reference price until a
+// ticker arrives, so the first book is checked against recorded history
+func (f *AnomalyFilter) Seed(symbol string, history []types.Candle) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := len(history) - 1; i >= 0; i-- {
+		if isQuoted(history[i].Close) {
+			if _, ok := f.lastPrices[symbol]; !ok {
+				f.lastPrices[symbol] = history[i].Close
+			}
+			return
+		}
+	}
+}
+
+// Check validates a book's best bid and ask, returning an error and
+// quarantining the market while they look bogus. Sides with no quotes
+// (zero or sentinel prices) are not checked.
+func (f *AnomalyFilter) Check(symbol string, bestBid, bestAsk float64) error {
+	if f == nil || f.maxDeviationPct <= 0 {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reason := ""
+	if last, ok := f.lastPrices[symbol]; ok {
+		reason = f.compare("ticker last", last, bestBid, bestAsk)
+	}
+	if previous, ok := f.snapshots[symbol]; ok && reason == "" {
+		if r := f.compare("previous bid", previous.bid, bestBid, 0); r != "" {
+			reason = r
+		} else {
+			reason = f.compare("previous ask", previous.ask, 0, bestAsk)
+		}
+	}
+
+	if reason != "" && !f.rebaseline(symbol, quoteSnapshot{bid: bestBid, ask: bestAsk}) {
+		f.quarantined[symbol] = reason
+		return fmt.Errorf("quarantined: %s", reason)
+	}
+
+	// Only accepted books become the reference for the next snapshot
+	delete(f.quarantined, symbol)
+	delete(f.rejected, symbol)
+	f.snapshots[symbol] = quoteSnapshot{bid: bestBid, ask: bestAsk}
+	return nil
+}
+
+// rebaseline counts a quarantined book against the one before it, reporting
+// whether enough in a row agree to accept the move. Callers hold mu.
+func (f *AnomalyFilter) rebaseline(symbol string, quote quoteSnapshot) bool {
+	previous, ok := f.rejected[symbol]
+	consistent := 1
+	if ok && f.compare("quarantined bid", previous.quote.bid, quote.bid, 0) == "" &&
+		f.compare("quarantined ask", previous.quote.ask, 0, quote.ask) == "" {
+		consistent = previous.consistent + 1
+	}
+	f.rejected[symbol] = rejectedQuotes{quote: quote, consistent: consistent}
+	return consistent >= rebaselineAfter
+}
+
+// Quarantined returns the markets currently excluded and why
+func (f *AnomalyFilter) Quarantined() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	quarantined := make(map[string]string, len(f.quarantined))
+	for symbol, reason := range f.quarantined {
+		quarantined[symbol] = reason
+	}
+	return quarantined
+}
+
+// compare reports the first of bid and ask deviating from reference by more
+// than the limit. Callers hold mu.
+func (f *AnomalyFilter) compare(label string, reference, bid, ask float64) string {
+	if !isQuoted(reference) {
+		return ""
+	}
+	for _, quote := range []struct {
+		side  string
+		price float64
+	}{{"bid", bid}, {"ask", ask}} {
+		if !isQuoted(quote.price) {
+			continue
+		}
+		deviation := math.Abs(quote.price-reference) / reference * 100
+		if deviation > f.maxDeviationPct {
+			return fmt.Sprintf("best %s %.8g is %.1f%% from %s %.8g", quote.side, quote.price, deviation, label, reference)
+		}
+	}
+	return ""
+}
+
+// isQuoted skips empty sides: zero bids and the 999999999 no-ask sentinel
+func isQuoted(price float64) bool {
+	return price > 0 && price < 999999999.0
+}
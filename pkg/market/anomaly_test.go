@@ -0,0 +1,69 @@
+package market
+
+import (
+	"testing"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestAnomalyFilterRebaselinesAfterGenuineMove(t *testing.T) {
+	filter := NewAnomalyFilter(30)
+	if err := filter.Check("XYZINR", 100, 101); err != nil {
+		t.Fatal(err)
+	}
+
+	// A lone bogus quote is quarantined and the next normal book is accepted
+	if err := filter.Check("XYZINR", 300, 301); err == nil {
+		t.Fatal("a 200% jump was accepted")
+	}
+	if err := filter.Check("XYZINR", 100.5, 101.5); err != nil {
+		t.Fatalf("normal book after a spike: %v", err)
+	}
+
+	// The price steps up and stays there
+	for i := 1; i < rebaselineAfter; i++ {
+		if err := filter.Check("XYZINR", 150+float64(i), 151+float64(i)); err == nil {
+			t.Fatalf("book %d of the move accepted before it held", i)
+		}
+	}
+	if err := filter.Check("XYZINR", 152, 153); err != nil {
+		t.Fatalf("move held for %d books but is still quarantined: %v", rebaselineAfter, err)
+	}
+	if _, ok := filter.Quarantined()["XYZINR"]; ok {
+		t.Error("market still listed as quarantined")
+	}
+	if err := filter.Check("XYZINR", 151, 152); err != nil {
+		t.Errorf("book at the new level: %v", err)
+	}
+
+	// Bogus quotes that disagree with each other never add up to a move
+	for _, bid := range []float64{400, 40, 400, 40} {
+		if err := filter.Check("XYZINR", bid, bid+1); err == nil {
+			t.Errorf("erratic bid %g accepted", bid)
+		}
+	}
+}
+
+func TestSeededAnomalyFilterChecksFirstBook(t *testing.T) {
+	history := []types.Candle{{Close: 99}, {Close: 100}}
+
+	// Unseeded, the first book has nothing to be compared with
+	if err := NewAnomalyFilter(30).Check("XYZINR", 300, 301); err != nil {
+		t.Fatalf("unseeded filter rejected its first book: %v", err)
+	}
+
+	filter := NewAnomalyFilter(30)
+	filter.Seed("XYZINR", history)
+	if err := filter.Check("XYZINR", 300, 301); err == nil {
+		t.Error("a first book 200% from the last close was accepted")
+	}
+	if err := filter.Check("XYZUSDT", 300, 301); err != nil {
+		t.Errorf("market without history: %v", err)
+	}
+
+	filter = NewAnomalyFilter(30)
+	filter.Seed("XYZINR", history)
+	if err := filter.Check("XYZINR", 100.5, 101.5); err != nil {
+		t.Errorf("first book near the last close: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// OrderBook is an order book parsed once from the exchange's shape,
+// {"bids": {"price": "volume", ...}, "asks": {...}}, each side sorted best
+// price first. It marshals back to that shape, so snapshots and recordings
+// saved before it existed still load.
+type OrderBook struct {
+	Bids []types.OrderLevel // Highest price first
+	Asks []types.OrderLevel // Lowest price first
+}
+
+// NewOrderBook parses a raw order book. Empty levels are dropped; a
+// malformed price or volume fails the book instead of reading as a 0 price.
+func NewOrderBook(raw map[string]interface{}) (*OrderBook, error) {
+	bids, err := parseLevels(raw["bids"], "bids")
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseLevels(raw["asks"], "asks")
+	if err != nil {
+		return nil, err
+	}
+	return &OrderBook{Bids: bids, Asks: asks}, nil
+}
+
+// parseLevels parses one side of a raw order book, sorted best price first
+func parseLevels(raw interface{}, side string) ([]types.OrderLevel, error) {
+	levels := []types.OrderLevel{}
+	if raw == nil {
+		return levels, nil
+	}
+	orders, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: unexpected %T", side, raw)
+	}
+
+	for priceStr, volumeValue := range orders {
+		price, err := utils.ParsePrice(priceStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", side, err)
+		}
+		volume, err := utils.ParseQuantity(volumeValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s at %s: %v", side, priceStr, err)
+		}
+
+		if volume > 0 {
+			levels = append(levels, types.OrderLevel{Price: price, Volume: volume})
+		}
+	}
+
+	if side == "bids" {
+		sort.Slice(levels, func(i, j int) bool { return levels[i].Price > levels[j].Price })
+	} else {
+		sort.Slice(levels, func(i, j int) bool { return levels[i].Price < levels[j].Price })
+	}
+
+	return levels, nil
+}
+
+// UnmarshalJSON parses the exchange's shape
+func (b *OrderBook) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	book, err := NewOrderBook(raw)
+	if err != nil {
+		return err
+	}
+	*b = *book
+	return nil
+}
+
+// MarshalJSON writes the exchange's shape
+func (b OrderBook) MarshalJSON() ([]byte, error) {
+	side := func(levels []types.OrderLevel) map[string]string {
+		raw := make(map[string]string, len(levels))
+		for _, level := range levels {
+			raw[strconv.FormatFloat(level.Price, 'f', -1, 64)] = strconv.FormatFloat(level.Volume, 'f', -1, 64)
+		}
+		return raw
+	}
+	return json.Marshal(map[string]map[string]string{"bids": side(b.Bids), "asks": side(b.Asks)})
+}
+
+// Levels returns one side, "bids" or "asks", best price first
+func (b *OrderBook) Levels(side string) []types.OrderLevel {
+	if b == nil {
+		return nil
+	}
+	if side == "bids" {
+		return b.Bids
+	}
+	return b.Asks
+}
+
+// BestBid returns the highest bid; ok is false when there are none
+func (b *OrderBook) BestBid() (types.OrderLevel, bool) {
+	return best(b.Levels("bids"))
+}
+
+// BestAsk returns the lowest ask; ok is false when there are none
+func (b *OrderBook) BestAsk() (types.OrderLevel, bool) {
+	return best(b.Levels("asks"))
+}
+
+// Best returns the best level of one side, "bids" or "asks"
+func (b *OrderBook) Best(side string) (types.OrderLevel, bool) {
+	return best(b.Levels(side))
+}
+
+func best(levels []types.OrderLevel) (types.OrderLevel, bool) {
+	if len(levels) == 0 {
+		return types.OrderLevel{}, false
+	}
+	return levels[0], true
+}
+
+// DepthWithin totals the volume resting within pct percent of each side's
+// best price: bids down to best·(1−pct/100), asks up to best·(1+pct/100)
+func (b *OrderBook) DepthWithin(pct float64) (bidVolume, askVolume float64) {
+	if bid, ok := b.BestBid(); ok {
+		floor := bid.Price * (1 - pct/100)
+		for _, level := range b.Bids {
+			if level.Price < floor {
+				break
+			}
+			bidVolume += level.Volume
+		}
+	}
+	if ask, ok := b.BestAsk(); ok {
+		ceiling := ask.Price * (1 + pct/100)
+		for _, level := range b.Asks {
+			if level.Price > ceiling {
+				break
+			}
+			askVolume += level.Volume
+		}
+	}
+	return bidVolume, askVolume
+}
@@ -0,0 +1,55 @@
+package market
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOrderBookJSON(t *testing.T) {
+	// The exchange's shape: unsorted, string volumes, empty levels and extra fields
+	raw := `{"bids": {"99.5": "2", "100": "1.5", "98": "0"}, "asks": {"102": 4, "101": "3"}, "timestamp": 1}`
+	var book OrderBook
+	if err := json.Unmarshal([]byte(raw), &book); err != nil {
+		t.Fatal(err)
+	}
+	if len(book.Bids) != 2 || book.Bids[0].Price != 100 || book.Bids[1].Volume != 2 {
+		t.Errorf("bids = %+v", book.Bids)
+	}
+	if best, ok := book.BestAsk(); !ok || best.Price != 101 || best.Volume != 3 {
+		t.Errorf("best ask = %+v, %v", best, ok)
+	}
+
+	saved, err := json.Marshal(book)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reloaded OrderBook
+	if err := json.Unmarshal(saved, &reloaded); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Asks) != 2 || reloaded.Asks[1] != book.Asks[1] || reloaded.Bids[0] != book.Bids[0] {
+		t.Errorf("round trip = %+v, want %+v", reloaded, book)
+	}
+
+	if err := json.Unmarshal([]byte(`{"bids": {"abc": "1"}}`), &book); err == nil {
+		t.Error("malformed price parsed")
+	}
+}
+
+func TestDepthWithin(t *testing.T) {
+	book, err := NewOrderBook(map[string]interface{}{
+		"bids": map[string]interface{}{"100": "1", "99.5": "2", "98": "5"},
+		"asks": map[string]interface{}{"101": "3", "102": "4"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bids, asks := book.DepthWithin(1); bids != 3 || asks != 7 {
+		t.Errorf("within 1%% = %v bids, %v asks; want 3, 7", bids, asks)
+	}
+
+	var empty *OrderBook
+	if _, ok := empty.BestBid(); ok {
+		t.Error("nil book has a best bid")
+	}
+}
@@ -0,0 +1,26 @@
+package market
+
+// TradeCost splits one fill's notional into exchange fee, taxes and what
+// actually changes hands, all in the market's quote currency
+type TradeCost struct {
+	Notional float64 `json:"notional"` // Quantity × fill price
+	Fee      float64 `json:"fee"`
+	GST      float64 `json:"gst"` // Charged on the fee
+	TDS      float64 `json:"tds"` // Withheld from sell proceeds
+	Net      float64 `json:"net"` // Sell: received after every deduction; buy: paid including them
+}
+
+// CostTrade applies feeRate to the notional, gstRate to the fee and, on a
+// sell, tdsRate to the proceeds
+func CostTrade(side string, notional, feeRate, gstRate, tdsRate float64) TradeCost {
+	cost := TradeCost{Notional: notional, Fee: notional * feeRate}
+	cost.GST = cost.Fee * gstRate
+
+	if side == "sell" {
+		cost.TDS = notional * tdsRate
+		cost.Net = notional - cost.Fee - cost.GST - cost.TDS
+	} else {
+		cost.Net = notional + cost.Fee + cost.GST
+	}
+	return cost
+}
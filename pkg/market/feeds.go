@@ -0,0 +1,181 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// PriceFeeds keeps rolling statistics of each scanned market's best bid and
+// ask: a simple moving average over the last averagePeriod observations and
+// an EMA over emaPeriod. A spread quoted while the price is jumping away from
+// its recent average is usually gone by the time orders land.
+type PriceFeeds struct {
+	emaPeriod       int
+	averagePeriod   int
+	maxDeviationPct float64
+
+	mu    sync.Mutex
+	feeds map[string]*priceFeed // symbol → feed
+}
+
+type priceFeed struct {
+	bid sideFeed
+	ask sideFeed
+}
+
+// sideFeed is one side's history; window is a ring of the last prices
+type sideFeed struct {
+	ema     float64
+	window  []float64
+	next    int
+	sum     float64
+	samples int
+}
+
+// FeedStats is a market's rolling bid and ask statistics
+type FeedStats struct {
+	Samples    int     `json:"samples"`
+	BidEMA     float64 `json:"bid_ema"`
+	AskEMA     float64 `json:"ask_ema"`
+	BidAverage float64 `json:"bid_average"`
+	AskAverage float64 `json:"ask_average"`
+}
+
+// NewPriceFeeds creates feeds with the given EMA and moving-average
+// periods, in observations; a maxDeviationPct of 0 disables CheckStable
+func NewPriceFeeds(emaPeriod, averagePeriod int, maxDeviationPct float64) *PriceFeeds {
+	return &PriceFeeds{
+		emaPeriod:       max(emaPeriod, 1),
+		averagePeriod:   max(averagePeriod, 1),
+		maxDeviationPct: maxDeviationPct,
+		feeds:           make(map[string]*priceFeed),
+	}
+}
+
+// Observe records a market's best bid and ask. Unquoted sides (zero bids,
+// the no-ask sentinel) are skipped.
+func (p *PriceFeeds) Observe(symbol string, bestBid, bestAsk float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.observe(symbol, bestBid, bestAsk)
+}
+
+// Seed replays a market's candle closes as bid and ask observations, so
+// its EMA and average are warm from the first live quote instead of after
+// an EMA period of scans
+func (p *PriceFeeds) Seed(symbol string, history []types.Candle) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, candle := range history {
+		p.observe(symbol, candle.Close, candle.Close)
+	}
+}
+
+// CheckStable records the quotes and returns an error unless each quoted
+// side is within the deviation limit of its EMA before this observation.
+// Markets with fewer than an EMA period of history are not yet trusted.
+func (p *PriceFeeds) CheckStable(symbol string, bestBid, bestAsk float64) error {
+	if p == nil || p.maxDeviationPct <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	feed, ok := p.feeds[symbol]
+	reason := ""
+	switch {
+	case !ok || max(feed.bid.samples, feed.ask.samples) < p.emaPeriod:
+		samples := 0
+		if ok {
+			samples = max(feed.bid.samples, feed.ask.samples)
+		}
+		reason = fmt.Sprintf("price history warming up (%d/%d samples)", samples, p.emaPeriod)
+	default:
+		reason = p.compare("bid", feed.bid, bestBid)
+		if reason == "" {
+			reason = p.compare("ask", feed.ask, bestAsk)
+		}
+	}
+
+	p.observe(symbol, bestBid, bestAsk)
+	if reason != "" {
+		return fmt.Errorf("unstable price: %s", reason)
+	}
+	return nil
+}
+
+// Stats returns a market's current statistics
+func (p *PriceFeeds) Stats(symbol string) (FeedStats, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	feed, ok := p.feeds[symbol]
+	if !ok {
+		return FeedStats{}, false
+	}
+	return FeedStats{
+		Samples:    max(feed.bid.samples, feed.ask.samples),
+		BidEMA:     feed.bid.ema,
+		AskEMA:     feed.ask.ema,
+		BidAverage: feed.bid.average(),
+		AskAverage: feed.ask.average(),
+	}, true
+}
+
+// observe records quotes. Callers hold mu.
+func (p *PriceFeeds) observe(symbol string, bestBid, bestAsk float64) {
+	feed, ok := p.feeds[symbol]
+	if !ok {
+		feed = &priceFeed{}
+		p.feeds[symbol] = feed
+	}
+	if isQuoted(bestBid) {
+		feed.bid.add(bestBid, p.emaPeriod, p.averagePeriod)
+	}
+	if isQuoted(bestAsk) {
+		feed.ask.add(bestAsk, p.emaPeriod, p.averagePeriod)
+	}
+}
+
+// compare reports a quoted price too far from the side's EMA. Callers hold mu.
+func (p *PriceFeeds) compare(side string, feed sideFeed, price float64) string {
+	if !isQuoted(price) || feed.samples == 0 {
+		return ""
+	}
+	deviation := math.Abs(price-feed.ema) / feed.ema * 100
+	if deviation > p.maxDeviationPct {
+		return fmt.Sprintf("best %s %.8g is %.2f%% from its EMA %.8g", side, price, deviation, feed.ema)
+	}
+	return ""
+}
+
+func (s *sideFeed) add(price float64, emaPeriod, averagePeriod int) {
+	if s.samples == 0 {
+		s.ema = price
+	} else {
+		alpha := 2 / float64(emaPeriod+1)
+		s.ema += alpha * (price - s.ema)
+	}
+	s.samples++
+
+	if len(s.window) < averagePeriod {
+		s.window = append(s.window, price)
+		s.sum += price
+		return
+	}
+	s.sum += price - s.window[s.next]
+	s.window[s.next] = price
+	s.next = (s.next + 1) % averagePeriod
+}
+
+func (s sideFeed) average() float64 {
+	if len(s.window) == 0 {
+		return 0
+	}
+	return s.sum / float64(len(s.window))
+}
@@ -0,0 +1,30 @@
+package market
+
+import (
+	"testing"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestSeededPriceFeedsAreWarmFromFirstQuote(t *testing.T) {
+	history := []types.Candle{}
+	for i := 0; i < 20; i++ {
+		history = append(history, types.Candle{Close: 100})
+	}
+
+	if err := NewPriceFeeds(10, 20, 2).CheckStable("XYZINR", 100, 101); err == nil {
+		t.Fatal("unseeded feeds trusted their first quote")
+	}
+
+	feeds := NewPriceFeeds(10, 20, 2)
+	feeds.Seed("XYZINR", history)
+	if err := feeds.CheckStable("XYZINR", 100.5, 101); err != nil {
+		t.Errorf("first quote near the candle EMA: %v", err)
+	}
+
+	feeds = NewPriceFeeds(10, 20, 2)
+	feeds.Seed("XYZINR", history)
+	if err := feeds.CheckStable("XYZINR", 110, 111); err == nil {
+		t.Error("first quote 10% from the candle EMA was accepted")
+	}
+}
@@ -1,83 +1,187 @@
 package market
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/b-thark/cdcx-api/pkg/types"
 )
 
+// DefaultBookConcurrency is how many order books GetOrderBooks fetches at
+// once unless SetConcurrency says otherwise
+const DefaultBookConcurrency = 8
+
 type Fetcher struct {
-	baseURL string
-	client  *http.Client
+	baseURL     string
+	client      *http.Client
+	stream      *Stream
+	concurrency int // Books GetOrderBooks fetches at once
 }
 
 func NewFetcher() *Fetcher {
 	return &Fetcher{
-		baseURL: "https://api.coindcx.com",
-		client:  &http.Client{Timeout: 30 * time.Second},
+		baseURL:     "https://api.coindcx.com",
+		client:      &http.Client{Timeout: 30 * time.Second},
+		stream:      defaultStream,
+		concurrency: DefaultBookConcurrency,
 	}
 }
 
+// SetTransport sends the fetcher's requests through transport, e.g. the
+// process's shared exchange call budget
+func (f *Fetcher) SetTransport(transport http.RoundTripper) {
+	f.client.Transport = transport
+}
+
+// SetConcurrency bounds how many books GetOrderBooks fetches at once; below
+// 1 means one at a time
+func (f *Fetcher) SetConcurrency(concurrency int) {
+	f.concurrency = max(concurrency, 1)
+}
+
+// Subscribe streams the pairs' order books over the exchange websocket.
+// GetOrderBook then serves them from memory while they are fresh, and the
+// channel announces each change so callers can react without polling.
+func (f *Fetcher) Subscribe(pairs []string) (<-chan BookUpdate, func()) {
+	return f.stream.Subscribe(pairs)
+}
+
 func (f *Fetcher) GetMarketDetails() ([]types.MarketDetail, error) {
-	url := f.baseURL + "/exchange/v1/markets_details"
+	return f.GetMarketDetailsContext(context.Background())
+}
 
-	resp, err := f.client.Get(url)
+// GetMarketDetailsContext is GetMarketDetails, cancelled with ctx
+func (f *Fetcher) GetMarketDetailsContext(ctx context.Context) ([]types.MarketDetail, error) {
+	body, err := f.get(ctx, f.baseURL+"/exchange/v1/markets_details")
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d", resp.StatusCode)
+	var markets []types.MarketDetail
+	if err := json.Unmarshal(body, &markets); err != nil {
+		return nil, fmt.Errorf("parse error: %v", err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	RegisterPrecisions(markets)
+	return markets, nil
+}
+
+// GetOrderBook returns the pair's streamed book when subscribed and fresh,
+// otherwise fetches it from the REST endpoint
+func (f *Fetcher) GetOrderBook(pair string) (*OrderBook, error) {
+	return f.GetOrderBookContext(context.Background(), pair)
+}
+
+// GetOrderBookContext is GetOrderBook, cancelled with ctx
+func (f *Fetcher) GetOrderBookContext(ctx context.Context, pair string) (*OrderBook, error) {
+	if book, ok := f.stream.Book(pair); ok {
+		return book, nil
+	}
+
+	body, err := f.get(ctx, fmt.Sprintf("https://public.coindcx.com/market_data/orderbook?pair=%s", pair))
 	if err != nil {
-		return nil, fmt.Errorf("read error: %v", err)
+		return nil, err
 	}
 
-	var markets []types.MarketDetail
-	if err := json.Unmarshal(body, &markets); err != nil {
+	var orderBook OrderBook
+	if err := json.Unmarshal(body, &orderBook); err != nil {
 		return nil, fmt.Errorf("parse error: %v", err)
 	}
 
-	return markets, nil
+	return &orderBook, nil
 }
 
-func (f *Fetcher) GetOrderBook(pair string) (map[string]interface{}, error) {
-	url := fmt.Sprintf("https://public.coindcx.com/market_data/orderbook?pair=%s", pair)
+// BookResult is one pair's order book, or why it couldn't be fetched
+type BookResult struct {
+	Book *OrderBook
+	Err  error
+}
+
+// GetOrderBooks fetches the pairs' books with up to the fetcher's
+// concurrency in flight, streamed ones from memory. Every request still
+// waits for the exchange call budget all clients share, so the pool only
+// overlaps round trips; it never sends faster than the budget allows.
+func (f *Fetcher) GetOrderBooks(ctx context.Context, pairs []string) map[string]BookResult {
+	results := make(map[string]BookResult, len(pairs))
+	seen := make(map[string]bool, len(pairs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(f.concurrency, 1))
+	for _, pair := range pairs {
+		if seen[pair] {
+			continue
+		}
+		seen[pair] = true
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			book, err := f.GetOrderBookContext(ctx, pair)
+			mu.Lock()
+			results[pair] = BookResult{Book: book, Err: err}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
 
-	resp, err := f.client.Get(url)
+// GetTicker fetches last prices, top of book and 24h statistics for every market
+func (f *Fetcher) GetTicker() (types.Tickers, error) {
+	return f.GetTickerContext(context.Background())
+}
+
+// GetTickerContext is GetTicker, cancelled with ctx
+func (f *Fetcher) GetTickerContext(ctx context.Context) (types.Tickers, error) {
+	body, err := f.get(ctx, f.baseURL+"/exchange/ticker")
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d", resp.StatusCode)
+	tickers, err := types.ParseTickers(body)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %v", err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return tickers, nil
+}
+
+// GetCandles fetches up to limit (max 1000) of the most recent candles for a
+// pair (e.g. "B-BTC_USDT") at the given interval (1m, 5m, 1h, 1d, ...)
+func (f *Fetcher) GetCandles(pair, interval string, limit int) ([]types.Candle, error) {
+	return f.GetCandlesContext(context.Background(), pair, interval, limit)
+}
+
+// GetCandlesContext is GetCandles, cancelled with ctx
+func (f *Fetcher) GetCandlesContext(ctx context.Context, pair, interval string, limit int) ([]types.Candle, error) {
+	body, err := f.get(ctx, fmt.Sprintf("https://public.coindcx.com/market_data/candles?pair=%s&interval=%s&limit=%d", pair, interval, limit))
 	if err != nil {
-		return nil, fmt.Errorf("read error: %v", err)
+		return nil, err
 	}
 
-	var orderBook map[string]interface{}
-	if err := json.Unmarshal(body, &orderBook); err != nil {
+	var candles []types.Candle
+	if err := json.Unmarshal(body, &candles); err != nil {
 		return nil, fmt.Errorf("parse error: %v", err)
 	}
 
-	return orderBook, nil
+	return candles, nil
 }
 
-func (f *Fetcher) GetTicker() ([]map[string]interface{}, error) {
-	url := f.baseURL + "/exchange/ticker"
+// get reads the body of a successful GET, giving up when ctx is done
+func (f *Fetcher) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
 
-	resp, err := f.client.Get(url)
+	resp, err := f.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %v", err)
 	}
@@ -91,11 +195,5 @@ func (f *Fetcher) GetTicker() ([]map[string]interface{}, error) {
 	if err != nil {
 		return nil, fmt.Errorf("read error: %v", err)
 	}
-
-	var tickers []map[string]interface{}
-	if err := json.Unmarshal(body, &tickers); err != nil {
-		return nil, fmt.Errorf("parse error: %v", err)
-	}
-
-	return tickers, nil
+	return body, nil
 }
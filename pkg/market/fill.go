@@ -0,0 +1,119 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// FillState is what a fill model sees when a sell leg is being considered:
+// the sell market's book right now and the order that would go into it
+type FillState struct {
+	Market   string
+	Quantity float64
+	Bids     []types.OrderLevel // Best first, as in an OrderBook
+	Asks     []types.OrderLevel // Sellers competing for the same bids
+	Timeout  time.Duration      // How long the leg may work before it is given up
+}
+
+// Features are the book measurements fill models are built on
+type Features struct {
+	Coverage       float64 `json:"coverage"`        // Top ImbalanceLevels bid volume over the quantity
+	SpreadPct      float64 `json:"spread_pct"`      // Best ask over best bid
+	Imbalance      float64 `json:"imbalance"`       // Bids against asks near the top, -1 to +1
+	TimeoutSeconds float64 `json:"timeout_seconds"` // The leg's timeout
+}
+
+// Features measures the state; an empty side measures as no coverage or spread
+func (s FillState) Features() Features {
+	features := Features{
+		Imbalance:      MeasureImbalance(s.Bids, s.Asks).Signal(),
+		TimeoutSeconds: s.Timeout.Seconds(),
+	}
+	if s.Quantity > 0 {
+		features.Coverage = topVolume(s.Bids) / s.Quantity
+	}
+	if len(s.Bids) > 0 && len(s.Asks) > 0 && s.Bids[0].Price > 0 {
+		features.SpreadPct = (s.Asks[0].Price - s.Bids[0].Price) / s.Bids[0].Price * 100
+	}
+	return features
+}
+
+// FillModel estimates the probability, between 0 and 1, that a sell leg
+// fills completely within its timeout
+type FillModel interface {
+	FillProbability(state FillState) float64
+}
+
+// FillModelFunc adapts a function to a FillModel
+type FillModelFunc func(state FillState) float64
+
+// FillProbability calls f
+func (f FillModelFunc) FillProbability(state FillState) float64 {
+	return f(state)
+}
+
+// fillReferenceTimeout is the timeout at which DepthFillModel counts resting
+// bids once; longer timeouts give the book time to refill
+const fillReferenceTimeout = 30 * time.Second
+
+// DepthFillModel is the default heuristic: the more times the top bids cover
+// the quantity, the likelier the sell fills, and a long timeout lets bids
+// taken by others be replaced. One coverage within the reference timeout is
+// 63%, two 86%, three 95%.
+type DepthFillModel struct{}
+
+// FillProbability is 1 - e^(-coverage · (1 + timeout/30s) / 2)
+func (DepthFillModel) FillProbability(state FillState) float64 {
+	features := state.Features()
+	exposure := features.Coverage * (1 + state.Timeout.Seconds()/fillReferenceTimeout.Seconds()) / 2
+	return 1 - math.Exp(-exposure)
+}
+
+// LogisticFillModel is a trained model: a logistic regression over the
+// state's Features, e.g. fitted offline on past fills from the execution log
+type LogisticFillModel struct {
+	Intercept    float64            `json:"intercept"`
+	Coefficients map[string]float64 `json:"coefficients"` // Features json name → weight
+}
+
+// FillProbability is the logistic of the weighted features. Coverage enters
+// as its natural log, since fills depend on it multiplicatively.
+func (m *LogisticFillModel) FillProbability(state FillState) float64 {
+	features := state.Features()
+	values := map[string]float64{
+		"coverage":        math.Log(math.Max(features.Coverage, 1e-9)),
+		"spread_pct":      features.SpreadPct,
+		"imbalance":       features.Imbalance,
+		"timeout_seconds": features.TimeoutSeconds,
+	}
+	z := m.Intercept
+	for name, weight := range m.Coefficients {
+		z += weight * values[name]
+	}
+	return 1 / (1 + math.Exp(-z))
+}
+
+// LoadLogisticFillModel reads a trained model, rejecting features it can't measure
+func LoadLogisticFillModel(path string) (*LogisticFillModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fill model %s: %v", path, err)
+	}
+	var model LogisticFillModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("error parsing fill model %s: %v", path, err)
+	}
+	for name := range model.Coefficients {
+		switch name {
+		case "coverage", "spread_pct", "imbalance", "timeout_seconds":
+		default:
+			return nil, fmt.Errorf("fill model %s: unknown feature %q", path, name)
+		}
+	}
+	return &model, nil
+}
@@ -0,0 +1,77 @@
+package market
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// precision is a market's decimal places for prices and quantities
+type precision struct {
+	price    int // BaseCurrencyPrecision
+	quantity int // TargetCurrencyPrecision
+}
+
+var (
+	precisionMu sync.RWMutex
+	precisions  = make(map[string]precision)
+)
+
+// RegisterPrecisions records the price and quantity precision of each market
+// for the Format helpers. Fetcher.GetMarketDetails calls it automatically.
+func RegisterPrecisions(markets []types.MarketDetail) {
+	precisionMu.Lock()
+	defer precisionMu.Unlock()
+
+	for _, m := range markets {
+		precisions[m.Symbol] = precision{price: m.BaseCurrencyPrecision, quantity: m.TargetCurrencyPrecision}
+	}
+}
+
+// FormatPrice renders a price in the market's quote currency with the
+// market's precision
+func FormatPrice(symbol string, price float64) string {
+	precisionMu.RLock()
+	p, ok := precisions[symbol]
+	precisionMu.RUnlock()
+
+	if !ok {
+		return FormatDecimal(price, -1)
+	}
+	return FormatDecimal(price, p.price)
+}
+
+// FormatQuantity renders a quantity of the market's traded coin with the
+// market's precision
+func FormatQuantity(symbol string, quantity float64) string {
+	precisionMu.RLock()
+	p, ok := precisions[symbol]
+	precisionMu.RUnlock()
+
+	if !ok {
+		return FormatDecimal(quantity, -1)
+	}
+	return FormatDecimal(quantity, p.quantity)
+}
+
+// RoundQuantity rounds a quantity down to the market's precision, so an
+// order never asks for more than was sized; unknown markets are left as is
+func RoundQuantity(symbol string, quantity float64) float64 {
+	precisionMu.RLock()
+	p, ok := precisions[symbol]
+	precisionMu.RUnlock()
+
+	if !ok {
+		return quantity
+	}
+	return utils.RoundToPrecision(quantity, p.quantity, utils.RoundDown)
+}
+
+// FormatDecimal renders value with the given decimal places; a negative
+// precision (unknown market) uses the fewest digits that represent the value
+// exactly, so small-price tokens are never rounded to zero
+func FormatDecimal(value float64, decimals int) string {
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
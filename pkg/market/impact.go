@@ -0,0 +1,84 @@
+package market
+
+import (
+	"math"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Impact is what walking one side of a book with an order would cost
+type Impact struct {
+	Quantity       float64 // Requested
+	Filled         float64 // What the levels could take; below Quantity when the book is too thin
+	BestPrice      float64
+	EffectivePrice float64 // Volume-weighted average over the levels consumed
+	ImpactPct      float64 // Distance of EffectivePrice from BestPrice, always >= 0
+}
+
+// PerUnit is the price given up per unit against trading everything at the best price
+func (i Impact) PerUnit() float64 {
+	return math.Abs(i.EffectivePrice - i.BestPrice)
+}
+
+// EstimateImpact walks levels (best first, as in an OrderBook) with an
+// order of quantity. An empty book or a non-positive quantity estimates no
+// impact and nothing filled.
+func EstimateImpact(levels []types.OrderLevel, quantity float64) Impact {
+	impact := Impact{Quantity: quantity}
+	if len(levels) == 0 || quantity <= 0 {
+		return impact
+	}
+	impact.BestPrice = levels[0].Price
+
+	cost := 0.0
+	for _, level := range levels {
+		take := math.Min(level.Volume, quantity-impact.Filled)
+		impact.Filled += take
+		cost += take * level.Price
+		if impact.Filled >= quantity {
+			break
+		}
+	}
+
+	if impact.Filled > 0 {
+		impact.EffectivePrice = cost / impact.Filled
+	}
+	if impact.BestPrice > 0 {
+		impact.ImpactPct = impact.PerUnit() / impact.BestPrice * 100
+	}
+	return impact
+}
+
+// ImbalanceLevels is how many levels from the top of each book the
+// imbalance between them counts
+const ImbalanceLevels = 5
+
+// Imbalance compares the bid volume an arbitrage sells into with the ask
+// volume it buys from, near the top of the two books
+type Imbalance struct {
+	BidVolume float64 // Top ImbalanceLevels bids of the sell market
+	AskVolume float64 // Top ImbalanceLevels asks of the buy market
+}
+
+// MeasureImbalance sums the top ImbalanceLevels of bids and asks (best first)
+func MeasureImbalance(bids, asks []types.OrderLevel) Imbalance {
+	return Imbalance{BidVolume: topVolume(bids), AskVolume: topVolume(asks)}
+}
+
+// Signal is (bids - asks) / (bids + asks): +1 when only bids are resting,
+// -1 when only asks are, 0 for two empty books
+func (i Imbalance) Signal() float64 {
+	total := i.BidVolume + i.AskVolume
+	if total <= 0 {
+		return 0
+	}
+	return (i.BidVolume - i.AskVolume) / total
+}
+
+func topVolume(levels []types.OrderLevel) float64 {
+	volume := 0.0
+	for _, level := range levels[:min(len(levels), ImbalanceLevels)] {
+		volume += level.Volume
+	}
+	return volume
+}
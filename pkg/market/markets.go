@@ -0,0 +1,63 @@
+package market
+
+import (
+	"strings"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Markets resolves any of CoinDCX's names for a market (symbol, coindcx_name,
+// pair) or its canonical symbol to the market's details
+type Markets struct {
+	details  []types.MarketDetail
+	byName   map[string]int
+	bySymbol map[types.Symbol]int
+}
+
+// NewMarkets indexes a market list, e.g. from Fetcher.GetMarketDetails
+func NewMarkets(details []types.MarketDetail) *Markets {
+	m := &Markets{
+		details:  details,
+		byName:   make(map[string]int, 3*len(details)),
+		bySymbol: make(map[types.Symbol]int, len(details)),
+	}
+	for i, detail := range details {
+		for _, name := range []string{detail.Symbol, detail.CoinDCXName, detail.Pair} {
+			if name != "" {
+				m.byName[strings.ToUpper(name)] = i
+			}
+		}
+		m.bySymbol[detail.Canonical()] = i
+	}
+	return m
+}
+
+// Resolve looks a market up by any of its names
+func (m *Markets) Resolve(name string) (types.MarketDetail, bool) {
+	if i, ok := m.byName[strings.ToUpper(name)]; ok {
+		return m.details[i], true
+	}
+	if symbol, err := types.ParseSymbol(name); err == nil {
+		return m.Lookup(symbol)
+	}
+	return types.MarketDetail{}, false
+}
+
+// Lookup finds the market for a canonical symbol
+func (m *Markets) Lookup(symbol types.Symbol) (types.MarketDetail, bool) {
+	i, ok := m.bySymbol[symbol]
+	if !ok {
+		return types.MarketDetail{}, false
+	}
+	return m.details[i], true
+}
+
+// Find is Lookup for a coin and the currency it is priced in
+func (m *Markets) Find(base, quote string) (types.MarketDetail, bool) {
+	return m.Lookup(types.NewSymbol(base, quote))
+}
+
+// All returns the indexed markets
+func (m *Markets) All() []types.MarketDetail {
+	return m.details
+}
@@ -0,0 +1,88 @@
+package market
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// TradeSimulation is the expected outcome of a market order placed now:
+// where it would fill on the live book and what fees and taxes leave
+type TradeSimulation struct {
+	Market      string    `json:"market"`
+	Side        string    `json:"side"`
+	Quote       string    `json:"quote"`
+	Quantity    float64   `json:"quantity"` // Requested, in the coin
+	Filled      float64   `json:"filled"`   // Below Quantity when the book is too thin
+	BestPrice   float64   `json:"best_price"`
+	FillPrice   float64   `json:"fill_price"` // Volume-weighted over the levels consumed
+	ImpactPct   float64   `json:"impact_pct"`
+	FeeRate     float64   `json:"fee_rate"`
+	Cost        TradeCost `json:"cost"`
+	NetINR      float64   `json:"net_inr"` // Cost.Net in INR; 0 when no rate is known
+	SimulatedAt time.Time `json:"simulated_at"`
+}
+
+// Simulator prices hypothetical market orders against live books, for
+// what-if questions from other tools; it never places an order
+type Simulator struct {
+	books   BookFetcher
+	markets *Markets
+	config  *types.Config
+	toINR   func(currency string) (float64, error)
+}
+
+// NewSimulator prices orders on markets with books' order books, the
+// config's fee and tax rates, and toINR for the INR value of the result
+func NewSimulator(books BookFetcher, markets *Markets, config *types.Config, toINR func(currency string) (float64, error)) *Simulator {
+	return &Simulator{books: books, markets: markets, config: config, toINR: toINR}
+}
+
+// Simulate walks the book of market (any of its names) with a side order
+// of quantity coins
+func (s *Simulator) Simulate(market, side string, quantity float64) (TradeSimulation, error) {
+	if side != "buy" && side != "sell" {
+		return TradeSimulation{}, fmt.Errorf("side must be buy or sell, not %q", side)
+	}
+	if err := types.RequirePositive("quantity", quantity); err != nil {
+		return TradeSimulation{}, err
+	}
+	detail, ok := s.markets.Resolve(market)
+	if !ok {
+		return TradeSimulation{}, fmt.Errorf("unknown market %s", market)
+	}
+
+	orderBook, err := s.books.GetOrderBook(detail.Pair)
+	if err != nil {
+		return TradeSimulation{}, fmt.Errorf("%s order book: %v", detail.Symbol, err)
+	}
+	bookSide := "asks"
+	if side == "sell" {
+		bookSide = "bids"
+	}
+	levels := orderBook.Levels(bookSide)
+	if len(levels) == 0 {
+		return TradeSimulation{}, fmt.Errorf("%s has no %s", detail.Symbol, bookSide)
+	}
+
+	impact := EstimateImpact(levels, quantity)
+	sim := TradeSimulation{
+		Market:      detail.Symbol,
+		Side:        side,
+		Quote:       detail.Canonical().Quote,
+		Quantity:    quantity,
+		Filled:      impact.Filled,
+		BestPrice:   impact.BestPrice,
+		FillPrice:   impact.EffectivePrice,
+		ImpactPct:   impact.ImpactPct,
+		FeeRate:     s.config.FeeRateFor(detail.Symbol),
+		SimulatedAt: time.Now(),
+	}
+	sim.Cost = CostTrade(side, impact.Filled*impact.EffectivePrice, sim.FeeRate, s.config.GSTRate, s.config.TDSRate)
+
+	if rate, err := s.toINR(sim.Quote); err == nil {
+		sim.NetINR = sim.Cost.Net * rate
+	}
+	return sim, nil
+}
@@ -0,0 +1,76 @@
+package market
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Snapshot is a recording of order books and INR rates at one moment, so
+// detection can be re-run against identical prices
+type Snapshot struct {
+	CapturedAt time.Time             `json:"captured_at"`
+	Books      map[string]*OrderBook `json:"books"` // pair → order book
+	Rates      map[string]float64    `json:"rates"` // currency → INR
+	Run        *types.RunInfo        `json:"run,omitempty"`
+}
+
+// BookFetcher supplies order books, e.g. a Fetcher
+type BookFetcher interface {
+	GetOrderBook(pair string) (*OrderBook, error)
+}
+
+// CaptureSnapshot records the pairs' order books and each given currency's
+// INR rate. Books that fail to load are logged and left out.
+func CaptureSnapshot(books BookFetcher, pairs []string, toINR func(currency string) (float64, error), currencies []string) *Snapshot {
+	snapshot := &Snapshot{
+		CapturedAt: time.Now(),
+		Books:      make(map[string]*OrderBook, len(pairs)),
+		Rates:      map[string]float64{"INR": 1},
+	}
+	for _, pair := range pairs {
+		book, err := books.GetOrderBook(pair)
+		if err != nil {
+			logger.Warn("⚠️ Book not captured", "pair", pair, "error", err)
+			continue
+		}
+		snapshot.Books[pair] = book
+	}
+	for _, currency := range currencies {
+		if _, ok := snapshot.Rates[currency]; ok {
+			continue
+		}
+		rate, err := toINR(currency)
+		if err != nil {
+			logger.Warn("⚠️ INR rate not captured", "currency", currency, "error", err)
+			continue
+		}
+		snapshot.Rates[currency] = rate
+	}
+	return snapshot
+}
+
+// GetOrderBook returns the recorded book for a pair
+func (s *Snapshot) GetOrderBook(pair string) (*OrderBook, error) {
+	book, ok := s.Books[pair]
+	if !ok {
+		return nil, fmt.Errorf("%s not in snapshot", pair)
+	}
+	return book, nil
+}
+
+// Save writes the snapshot to path
+func (s *Snapshot) Save(path string) error {
+	return utils.SaveJSON(s, path)
+}
+
+// LoadSnapshot reads a snapshot written by Save
+func LoadSnapshot(path string) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := utils.LoadJSON(path, &snapshot); err != nil {
+		return nil, fmt.Errorf("error loading snapshot %s: %v", path, err)
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,332 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/gorilla/websocket"
+)
+
+var logger = logging.For("market")
+
+// StreamURL is CoinDCX's Socket.IO endpoint, spoken over a raw websocket
+// (Engine.IO v4)
+const StreamURL = "wss://stream.coindcx.com/socket.io/?EIO=4&transport=websocket"
+
+// StreamBookMaxAge is how long a streamed book is trusted without an update;
+// older books fall back to the REST endpoint
+const StreamBookMaxAge = 5 * time.Second
+
+// streamDepth is the number of levels per side requested for each book
+const streamDepth = 20
+
+// BookUpdate announces that a streamed order book changed; read the book
+// itself with GetOrderBook
+type BookUpdate struct {
+	Pair string    `json:"pair"`
+	Time time.Time `json:"time"`
+}
+
+// Stream maintains live order books from CoinDCX's websocket feed. It
+// connects on the first subscription and reconnects with backoff; books go
+// stale rather than wrong while it is disconnected.
+type Stream struct {
+	url         string
+	joined      map[string]bool // Pairs any subscriber wants
+	books       map[string]*streamBook
+	subscribers map[int]*subscriber
+	nextID      int
+	conn        *websocket.Conn
+	started     bool
+	mu          sync.Mutex
+	writeMu     sync.Mutex
+}
+
+type streamBook struct {
+	bids    map[string]interface{}
+	asks    map[string]interface{}
+	updated time.Time
+}
+
+type subscriber struct {
+	pairs   map[string]bool
+	updates chan BookUpdate
+}
+
+// NewStream creates a stream for the given endpoint; nothing connects until
+// the first Subscribe
+func NewStream(url string) *Stream {
+	return &Stream{
+		url:         url,
+		joined:      make(map[string]bool),
+		books:       make(map[string]*streamBook),
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+// defaultStream is shared by every Fetcher so the engine, detectors and
+// watchers in one process use a single connection
+var defaultStream = NewStream(StreamURL)
+
+// Subscribe streams the pairs' order books (e.g. "B-RENDER_INR") and returns
+// a channel announcing each change, plus a function that ends the
+// subscription and closes the channel. Updates are dropped rather than
+// queued when the reader falls behind; the book always holds the latest.
+func (s *Stream) Subscribe(pairs []string) (<-chan BookUpdate, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &subscriber{pairs: make(map[string]bool), updates: make(chan BookUpdate, 64)}
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = sub
+
+	for _, pair := range pairs {
+		sub.pairs[pair] = true
+		if !s.joined[pair] {
+			s.joined[pair] = true
+			if s.conn != nil {
+				go s.join(pair)
+			}
+		}
+	}
+
+	if !s.started {
+		s.started = true
+		go s.run()
+	}
+
+	var once sync.Once
+	return sub.updates, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers, id)
+			close(sub.updates)
+		})
+	}
+}
+
+// Book parses the pair's streamed order book; ok is false unless it was
+// updated within StreamBookMaxAge and parses
+func (s *Stream) Book(pair string) (*OrderBook, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	book, ok := s.books[pair]
+	if !ok || time.Since(book.updated) > StreamBookMaxAge {
+		return nil, false
+	}
+	parsed, err := NewOrderBook(map[string]interface{}{"bids": book.bids, "asks": book.asks})
+	if err != nil {
+		return nil, false
+	}
+	return parsed, true
+}
+
+// run keeps a connection open for the life of the process
+func (s *Stream) run() {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := s.session()
+		logger.Warn("⚠️ Order book stream disconnected", "error", err)
+
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+// session connects, joins every wanted pair and applies updates until the
+// connection fails
+func (s *Stream) session() error {
+	conn, _, err := websocket.DefaultDialer.Dial(s.url, nil)
+	if err != nil {
+		return fmt.Errorf("connect: %v", err)
+	}
+	defer conn.Close()
+
+	// Engine.IO open packet: "0{...}" with the server's heartbeat timing
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		return fmt.Errorf("handshake: %v", err)
+	}
+	if len(message) == 0 || message[0] != '0' {
+		return fmt.Errorf("handshake: unexpected %q", message)
+	}
+	var open struct {
+		PingInterval int `json:"pingInterval"`
+		PingTimeout  int `json:"pingTimeout"`
+	}
+	if err := json.Unmarshal(message[1:], &open); err != nil {
+		return fmt.Errorf("handshake: %v", err)
+	}
+	heartbeat := time.Duration(open.PingInterval+open.PingTimeout) * time.Millisecond
+	if heartbeat <= 0 {
+		heartbeat = time.Minute
+	}
+
+	// Socket.IO connect to the default namespace
+	if err := s.write(conn, "40"); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	pairs := make([]string, 0, len(s.joined))
+	for pair := range s.joined {
+		pairs = append(pairs, pair)
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.conn = nil
+		s.mu.Unlock()
+	}()
+
+	for _, pair := range pairs {
+		s.join(pair)
+	}
+	logger.Info("📡 Order book stream connected", "pairs", len(pairs))
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(heartbeat))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		packet := string(message)
+		switch {
+		case packet == "2":
+			// Server ping
+			if err := s.write(conn, "3"); err != nil {
+				return err
+			}
+		case packet == "41":
+			return fmt.Errorf("server closed the session")
+		case strings.HasPrefix(packet, "44"):
+			return fmt.Errorf("connect refused: %s", packet[2:])
+		case strings.HasPrefix(packet, "42"):
+			s.handleEvent(message[2:])
+		}
+	}
+}
+
+// join asks the server for a pair's order book channel
+func (s *Stream) join(pair string) {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return // Joined on the next connect
+	}
+
+	payload, _ := json.Marshal([]interface{}{"join", map[string]string{
+		"channelName": fmt.Sprintf("%s@orderbook@%d", pair, streamDepth),
+	}})
+	if err := s.write(conn, "42"+string(payload)); err != nil {
+		logger.Warn("⚠️ Could not join order book stream", "pair", pair, "error", err)
+	}
+}
+
+func (s *Stream) write(conn *websocket.Conn, packet string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, []byte(packet))
+}
+
+// depthMessage is a book event body; CoinDCX wraps it as a JSON string in "data"
+type depthMessage struct {
+	Pair string                 `json:"s"`
+	Bids map[string]interface{} `json:"bids"`
+	Asks map[string]interface{} `json:"asks"`
+}
+
+// handleEvent applies a Socket.IO event: ["depth-snapshot", {...}] replaces
+// a book, ["depth-update", {...}] changes levels (zero volume removes one)
+func (s *Stream) handleEvent(raw []byte) {
+	var event []json.RawMessage
+	if err := json.Unmarshal(raw, &event); err != nil || len(event) < 2 {
+		return
+	}
+	var name string
+	if err := json.Unmarshal(event[0], &name); err != nil {
+		return
+	}
+	if name != "depth-snapshot" && name != "depth-update" {
+		return
+	}
+
+	depth, err := decodeDepth(event[1])
+	if err != nil {
+		logger.Warn("⚠️ Bad stream message", "event", name, "error", err)
+		return
+	}
+	if depth.Pair == "" {
+		return
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.joined[depth.Pair] {
+		return
+	}
+	book, ok := s.books[depth.Pair]
+	if !ok || name == "depth-snapshot" {
+		book = &streamBook{bids: map[string]interface{}{}, asks: map[string]interface{}{}}
+		s.books[depth.Pair] = book
+	}
+	applyLevels(book.bids, depth.Bids)
+	applyLevels(book.asks, depth.Asks)
+	book.updated = now
+
+	update := BookUpdate{Pair: depth.Pair, Time: now}
+	for _, sub := range s.subscribers {
+		if !sub.pairs[depth.Pair] {
+			continue
+		}
+		select {
+		case sub.updates <- update:
+		default:
+		}
+	}
+}
+
+func decodeDepth(raw json.RawMessage) (depthMessage, error) {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	body := raw
+	if err := json.Unmarshal(raw, &envelope); err == nil && len(envelope.Data) > 0 {
+		body = envelope.Data
+		var text string
+		if json.Unmarshal(body, &text) == nil {
+			body = []byte(text)
+		}
+	}
+
+	var depth depthMessage
+	err := json.Unmarshal(body, &depth)
+	return depth, err
+}
+
+func applyLevels(book, changes map[string]interface{}) {
+	for price, volume := range changes {
+		if quantity, err := utils.ParseQuantity(volume); err == nil && quantity == 0 {
+			delete(book, price)
+			continue
+		}
+		book[price] = volume
+	}
+}
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/pkg/events"
+	"github.com/b-thark/cdcx-api/pkg/exchange"
+)
+
+var logger = logging.For("metrics")
+
+// Fill latency buckets in seconds, from an immediate market fill to a
+// limit order resting until the order timeout
+var fillLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// placedTTL is how long an order placement is remembered waiting for its fill
+const placedTTL = 10 * time.Minute
+
+// Collector turns engine events and exchange responses into Prometheus
+// metrics for a scrape endpoint
+type Collector struct {
+	registry Registry
+
+	opportunities *Counter
+	dropped       *Counter
+	attempted     *Counter
+	succeeded     *Counter
+	profit        *Gauge
+	fillLatency   *Histogram
+	recoveries    *Counter
+	unrecovered   *Counter
+	riskTrips     *Counter
+	apiRequests   *Counter
+	apiErrors     *Counter
+
+	mu     sync.Mutex
+	placed map[string]time.Time // Order ID → when it was placed, until it fills
+}
+
+// New creates a collector with every metric at zero
+func New() *Collector {
+	c := &Collector{placed: make(map[string]time.Time)}
+	r := &c.registry
+	c.opportunities = r.NewCounter("cdcx_opportunities_detected_total", "Viable opportunities detected", "currency")
+	c.dropped = r.NewCounter("cdcx_opportunities_dropped_total", "Detected opportunities dropped before execution", "reason")
+	c.attempted = r.NewCounter("cdcx_executions_attempted_total", "Opportunity executions attempted")
+	c.succeeded = r.NewCounter("cdcx_executions_succeeded_total", "Opportunity executions that completed")
+	c.profit = r.NewGauge("cdcx_realized_profit_inr", "Realized profit in INR across executions since start")
+	c.fillLatency = r.NewHistogram("cdcx_order_fill_latency_seconds", "Time from placing an order to its fill", fillLatencyBuckets, "side")
+	c.recoveries = r.NewCounter("cdcx_recoveries_total", "Failed arbitrages whose inventory was sent to recovery")
+	c.unrecovered = r.NewCounter("cdcx_recovery_failures_total", "Recoveries that left inventory unsold")
+	c.riskTrips = r.NewCounter("cdcx_risk_trips_total", "Trades blocked by a guard", "rule")
+	c.apiRequests = r.NewCounter("cdcx_api_requests_total", "Exchange API responses", "endpoint", "code")
+	c.apiErrors = r.NewCounter("cdcx_api_errors_total", "Exchange API requests that failed or answered 4xx/5xx", "endpoint")
+	return c
+}
+
+// Handle is the bus subscriber: engine.Events().Subscribe(collector.Handle)
+func (c *Collector) Handle(event events.Event) {
+	switch e := event.(type) {
+	case events.OpportunityDetected:
+		c.opportunities.Inc(e.Currency)
+	case events.OpportunityDropped:
+		c.dropped.Inc(e.Reason)
+	case events.OrderPlaced:
+		c.mu.Lock()
+		for id, at := range c.placed {
+			if e.Time.Sub(at) > placedTTL {
+				delete(c.placed, id)
+			}
+		}
+		c.placed[e.OrderID] = e.Time
+		c.mu.Unlock()
+	case events.OrderFilled:
+		c.mu.Lock()
+		placedAt, ok := c.placed[e.OrderID]
+		delete(c.placed, e.OrderID)
+		c.mu.Unlock()
+		if ok {
+			c.fillLatency.Observe(e.Time.Sub(placedAt).Seconds(), e.Side)
+		}
+	case events.ExecutionCompleted:
+		c.attempted.Inc()
+		if e.Success {
+			c.succeeded.Inc()
+			c.profit.Add(e.Profit)
+		}
+	case events.RecoveryTriggered:
+		c.recoveries.Inc()
+	case events.RecoveryFailed:
+		c.unrecovered.Inc()
+	case events.RiskTripped:
+		c.riskTrips.Inc(e.Rule)
+	}
+}
+
+// Transport wraps next so every exchange response is counted by endpoint
+// path and status code; transport failures count as code "error". Other
+// hosts (webhooks, bots with tokens in their paths) pass through uncounted.
+func (c *Collector) Transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if !exchange.IsExchangeHost(req.URL.Hostname()) {
+			return resp, err
+		}
+		endpoint := req.URL.Path
+		switch {
+		case err != nil:
+			c.apiRequests.Inc(endpoint, "error")
+			c.apiErrors.Inc(endpoint)
+		default:
+			c.apiRequests.Inc(endpoint, strconv.Itoa(resp.StatusCode))
+			if resp.StatusCode >= 400 {
+				c.apiErrors.Inc(endpoint)
+			}
+		}
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	c.registry.Write(w)
+}
+
+// Serve listens on addr and serves /metrics in the background. Bind to
+// localhost unless the port is otherwise protected: it is unauthenticated.
+func (c *Collector) Serve(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c)
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			logger.Warn("⚠️ Metrics endpoint stopped", "error", err)
+		}
+	}()
+	return nil
+}
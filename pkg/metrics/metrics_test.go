@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/events"
+)
+
+// statusTransport answers every request with a fixed status
+type statusTransport int
+
+func (s statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: int(s), Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header), Request: req}, nil
+}
+
+func scrape(t *testing.T, c *Collector) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	c.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	return recorder.Body.String()
+}
+
+func TestCollectorExposition(t *testing.T) {
+	c := New()
+	placed := events.NewOrderPlaced("42", "BTCUSDT", "buy", 0.1)
+	filled := events.NewOrderFilled("42", "BTCUSDT", "buy", 0.1, 60000, 0.0001)
+	filled.Time = placed.Time.Add(300 * time.Millisecond)
+
+	for _, event := range []events.Event{
+		events.NewOpportunityDetected("BTC", "BTCUSDT", "BTCINR", 2.5),
+		placed,
+		filled,
+		events.ExecutionCompleted{Currency: "BTC", Success: true, Profit: 12.5},
+		events.ExecutionCompleted{Currency: "ETH", Error: "sell failed"},
+		events.NewRiskTripped("sell_depth", "ETH", "sell bids too thin"),
+	} {
+		c.Handle(event)
+	}
+
+	transport := c.Transport(statusTransport(http.StatusTooManyRequests))
+	for _, url := range []string{"https://api.coindcx.com/exchange/ticker", "https://hooks.example.com/secret"} {
+		req, _ := http.NewRequest("GET", url, nil)
+		transport.RoundTrip(req)
+	}
+
+	body := scrape(t, c)
+	for _, want := range []string{
+		`cdcx_opportunities_detected_total{currency="BTC"} 1`,
+		"cdcx_executions_attempted_total 2",
+		"cdcx_executions_succeeded_total 1",
+		"cdcx_realized_profit_inr 12.5",
+		`cdcx_order_fill_latency_seconds_bucket{side="buy",le="0.25"} 0`,
+		`cdcx_order_fill_latency_seconds_bucket{side="buy",le="0.5"} 1`,
+		`cdcx_order_fill_latency_seconds_count{side="buy"} 1`,
+		`cdcx_risk_trips_total{rule="sell_depth"} 1`,
+		`cdcx_api_requests_total{endpoint="/exchange/ticker",code="429"} 1`,
+		`cdcx_api_errors_total{endpoint="/exchange/ticker"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape missing %q", want)
+		}
+	}
+	if strings.Contains(body, "secret") {
+		t.Error("non-exchange request was counted")
+	}
+}
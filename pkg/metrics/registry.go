@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metric is anything that can write itself in the Prometheus text format
+type metric interface {
+	write(w io.Writer)
+}
+
+// Registry holds metrics in the order they were registered
+type Registry struct {
+	metrics []metric
+}
+
+// Write renders every metric in the Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) {
+	for _, m := range r.metrics {
+		m.write(w)
+	}
+}
+
+// series is one labelled value of a metric family; label values are joined
+// with a separator that can't appear in them
+type series map[string]float64
+
+const labelSeparator = "\xff"
+
+func seriesKey(values []string) string {
+	return strings.Join(values, labelSeparator)
+}
+
+// labelPairs renders {name="value",...} for a series key
+func labelPairs(names []string, key string, extra ...string) string {
+	pairs := []string{}
+	if len(names) > 0 {
+		for i, value := range strings.Split(key, labelSeparator) {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", names[i], strconv.Quote(value)))
+		}
+	}
+	pairs = append(pairs, extra...)
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Counter only goes up, optionally split by labels
+type Counter struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values series
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, values: series{}}
+	r.metrics = append(r.metrics, c)
+	return c
+}
+
+// Add increases the series for labelValues by delta; negative deltas are ignored
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[seriesKey(labelValues)] += delta
+}
+
+// Inc adds one
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if len(c.labels) == 0 && len(c.values) == 0 {
+		fmt.Fprintf(w, "%s 0\n", c.name)
+	}
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelPairs(c.labels, key), formatValue(c.values[key]))
+	}
+}
+
+// Gauge goes up and down
+type Gauge struct {
+	name, help string
+
+	mu    sync.Mutex
+	value float64
+}
+
+// NewGauge registers an unlabelled gauge
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{name: name, help: help}
+	r.metrics = append(r.metrics, g)
+	return g
+}
+
+// Add moves the gauge by delta
+func (g *Gauge) Add(delta float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value += delta
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", g.name, g.help, g.name, g.name, formatValue(g.value))
+}
+
+// Histogram counts observations into cumulative upper-bound buckets,
+// optionally split by labels
+type Histogram struct {
+	name, help string
+	labels     []string
+	buckets    []float64 // Upper bounds, ascending; +Inf is implied
+
+	mu     sync.Mutex
+	counts map[string][]uint64 // Per bucket, not cumulative
+	sums   series
+	totals map[string]uint64
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		name: name, help: help, labels: labels, buckets: buckets,
+		counts: map[string][]uint64{}, sums: series{}, totals: map[string]uint64{},
+	}
+	r.metrics = append(r.metrics, h)
+	return h
+}
+
+// Observe records one value for labelValues
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := seriesKey(labelValues)
+	if h.counts[key] == nil {
+		h.counts[key] = make([]uint64, len(h.buckets))
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[key][i]++
+			break
+		}
+	}
+	h.sums[key] += value
+	h.totals[key]++
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.totals) {
+		cumulative := uint64(0)
+		for i, bound := range h.buckets {
+			cumulative += h.counts[key][i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelPairs(h.labels, key, fmt.Sprintf("le=%q", formatValue(bound))), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelPairs(h.labels, key, `le="+Inf"`), h.totals[key])
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labelPairs(h.labels, key), formatValue(h.sums[key]))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labelPairs(h.labels, key), h.totals[key])
+	}
+}
@@ -0,0 +1,230 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/events"
+)
+
+// Webhook posts every notification as JSON to a URL
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a webhook backend posting to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+func (w *Webhook) Send(severity Severity, event events.Event, text string) error {
+	// A NaN margin would fail the whole payload
+	body, _ := utils.SanitizeFloats(map[string]interface{}{
+		"kind":     event.Kind(),
+		"severity": severity.String(),
+		"text":     text,
+		"time":     event.At(),
+		"event":    event,
+	})
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode error: %v", err)
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook error: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Telegram sends notifications to a chat through a bot
+type Telegram struct {
+	token  string
+	chatID string
+	client *http.Client
+}
+
+// NewTelegram creates a Telegram backend for the bot token and chat
+func NewTelegram(token, chatID string) *Telegram {
+	return &Telegram{token: token, chatID: chatID, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (t *Telegram) Name() string {
+	return "telegram"
+}
+
+func (t *Telegram) Send(severity Severity, event events.Event, text string) error {
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.token)
+	if severity >= SeverityWarning {
+		text = fmt.Sprintf("[%s] %s", strings.ToUpper(severity.String()), text)
+	}
+
+	resp, err := t.client.PostForm(endpoint, url.Values{"chat_id": {t.chatID}, "text": {text}})
+	if err != nil {
+		// The request URL carries the bot token; keep it out of logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram error: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Slack posts notifications to a channel through an incoming webhook
+type Slack struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlack creates a Slack backend for an incoming webhook URL
+func NewSlack(webhookURL string) *Slack {
+	return &Slack{webhookURL: webhookURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+func (s *Slack) Send(severity Severity, event events.Event, text string) error {
+	if severity >= SeverityWarning {
+		text = fmt.Sprintf("*[%s]* %s", strings.ToUpper(severity.String()), text)
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("encode error: %v", err)
+	}
+
+	resp, err := s.client.Post(s.webhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		// The webhook URL is the credential; keep it out of logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack error: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FromEnv builds a notifier from NOTIFY_* variables; nil when no backend is
+// configured. Each backend takes _LEVEL (minimum severity), _EVENTS (kinds
+// always sent, comma-separated), _PER_MINUTE (rate limit) and _MIN_MARGIN
+// (smallest detected opportunity margin in percent worth sending).
+func FromEnv() (*Notifier, error) {
+	notifier := NewNotifier()
+
+	if webhookURL := os.Getenv("NOTIFY_WEBHOOK_URL"); webhookURL != "" {
+		filter, perMinute, err := routeFromEnv("NOTIFY_WEBHOOK", SeverityInfo, 30)
+		if err != nil {
+			return nil, err
+		}
+		notifier.Add(NewWebhook(webhookURL), filter, perMinute)
+	}
+
+	token, chatID := os.Getenv("NOTIFY_TELEGRAM_TOKEN"), os.Getenv("NOTIFY_TELEGRAM_CHAT_ID")
+	if token != "" && chatID != "" {
+		filter, perMinute, err := routeFromEnv("NOTIFY_TELEGRAM", SeverityWarning, 10)
+		if err != nil {
+			return nil, err
+		}
+		notifier.Add(NewTelegram(token, chatID), filter, perMinute)
+	}
+
+	if slackURL := os.Getenv("NOTIFY_SLACK_WEBHOOK_URL"); slackURL != "" {
+		filter, perMinute, err := routeFromEnv("NOTIFY_SLACK", SeverityWarning, 20)
+		if err != nil {
+			return nil, err
+		}
+		notifier.Add(NewSlack(slackURL), filter, perMinute)
+	}
+
+	if len(notifier.routes) == 0 {
+		return nil, nil
+	}
+	return notifier, nil
+}
+
+var knownKinds = map[string]bool{
+	events.KindOpportunityDetected: true,
+	events.KindOpportunityDropped:  true,
+	events.KindOrderPlaced:         true,
+	events.KindOrderFilled:         true,
+	events.KindExecutionCompleted:  true,
+	events.KindRecoveryTriggered:   true,
+	events.KindRecoveryFailed:      true,
+	events.KindRiskTripped:         true,
+	events.KindBalanceMismatch:     true,
+	events.KindLowBalance:          true,
+}
+
+func routeFromEnv(prefix string, defaultSeverity Severity, defaultPerMinute int) (Filter, int, error) {
+	filter := Filter{MinSeverity: defaultSeverity}
+	perMinute := defaultPerMinute
+
+	if level := os.Getenv(prefix + "_LEVEL"); level != "" {
+		severity, err := ParseSeverity(level)
+		if err != nil {
+			return Filter{}, 0, fmt.Errorf("invalid %s_LEVEL: %v", prefix, err)
+		}
+		filter.MinSeverity = severity
+	}
+
+	for _, kind := range strings.Split(os.Getenv(prefix+"_EVENTS"), ",") {
+		if kind = strings.TrimSpace(kind); kind == "" {
+			continue
+		}
+		if !knownKinds[kind] {
+			return Filter{}, 0, fmt.Errorf("invalid %s_EVENTS: unknown event kind %s", prefix, kind)
+		}
+		filter.Kinds = append(filter.Kinds, kind)
+	}
+
+	if limit := os.Getenv(prefix + "_PER_MINUTE"); limit != "" {
+		val, err := strconv.Atoi(limit)
+		if err != nil || val < 0 {
+			return Filter{}, 0, fmt.Errorf("invalid %s_PER_MINUTE %q", prefix, limit)
+		}
+		perMinute = val
+	}
+
+	if margin := os.Getenv(prefix + "_MIN_MARGIN"); margin != "" {
+		val, err := strconv.ParseFloat(margin, 64)
+		if err != nil {
+			return Filter{}, 0, fmt.Errorf("invalid %s_MIN_MARGIN %q", prefix, margin)
+		}
+		filter.MinMarginPct = val
+	}
+
+	return filter, perMinute, nil
+}
@@ -0,0 +1,231 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/pkg/events"
+)
+
+var logger = logging.For("notify")
+
+// Severity orders how urgent an event is
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityOff // As a route minimum: only the route's listed kinds are sent
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+	SeverityOff:     "off",
+}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// ParseSeverity parses info, warning, error or off
+func ParseSeverity(s string) (Severity, error) {
+	for severity, name := range severityNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return severity, nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q (want info, warning, error or off)", s)
+}
+
+// SeverityOf classifies a bus event: failed executions and recoveries are
+// errors, guards, recoveries, unexplained balance changes, low funding and
+// losing executions are warnings, everything else is informational
+func SeverityOf(event events.Event) Severity {
+	switch e := event.(type) {
+	case events.ExecutionCompleted:
+		if !e.Success {
+			return SeverityError
+		}
+		if e.Profit < 0 {
+			return SeverityWarning
+		}
+	case events.RecoveryFailed:
+		return SeverityError
+	case events.RecoveryTriggered, events.RiskTripped, events.BalanceMismatch, events.LowBalance:
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+// Backend delivers rendered notifications to one destination
+type Backend interface {
+	Name() string
+	Send(severity Severity, event events.Event, text string) error
+}
+
+// Filter selects the events a backend receives: any event at or above
+// MinSeverity, plus every event whose kind is listed. Detected opportunities
+// below MinMarginPct are never sent.
+type Filter struct {
+	MinSeverity  Severity
+	Kinds        []string
+	MinMarginPct float64
+}
+
+// Match reports whether the filter lets the event through
+func (f Filter) Match(event events.Event) bool {
+	if opp, ok := event.(events.OpportunityDetected); ok && opp.MarginPct < f.MinMarginPct {
+		return false
+	}
+	for _, kind := range f.Kinds {
+		if kind == event.Kind() {
+			return true
+		}
+	}
+	return SeverityOf(event) >= f.MinSeverity && f.MinSeverity != SeverityOff
+}
+
+// route is one backend with its filter and rate limit
+type route struct {
+	backend    Backend
+	filter     Filter
+	perMinute  int
+	sent       []time.Time // Send times within the last minute
+	suppressed int         // Dropped since the last delivered notification
+	mu         sync.Mutex
+}
+
+// allow applies the per-minute limit, returning how many notifications were
+// dropped since the last one allowed
+func (r *route) allow(now time.Time) (bool, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.perMinute <= 0 {
+		return true, 0
+	}
+
+	recent := r.sent[:0]
+	for _, at := range r.sent {
+		if now.Sub(at) < time.Minute {
+			recent = append(recent, at)
+		}
+	}
+	r.sent = recent
+
+	if len(r.sent) >= r.perMinute {
+		r.suppressed++
+		return false, 0
+	}
+
+	r.sent = append(r.sent, now)
+	suppressed := r.suppressed
+	r.suppressed = 0
+	return true, suppressed
+}
+
+// Notifier routes bus events to notification backends. Delivery happens in
+// the background so a slow backend never delays an execution.
+type Notifier struct {
+	routes  []*route
+	pending sync.WaitGroup
+}
+
+// NewNotifier creates a notifier with no backends
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+// Add routes events matching filter to backend, at most perMinute per
+// minute (0 is unlimited). Excess notifications are dropped and counted
+// in the next one delivered.
+func (n *Notifier) Add(backend Backend, filter Filter, perMinute int) {
+	n.routes = append(n.routes, &route{backend: backend, filter: filter, perMinute: perMinute})
+}
+
+// Backends returns the configured backends' names
+func (n *Notifier) Backends() []string {
+	names := make([]string, 0, len(n.routes))
+	for _, r := range n.routes {
+		names = append(names, r.backend.Name())
+	}
+	return names
+}
+
+// Handle is the bus subscriber: engine.Events().Subscribe(notifier.Handle)
+func (n *Notifier) Handle(event events.Event) {
+	severity := SeverityOf(event)
+	now := time.Now()
+
+	for _, r := range n.routes {
+		if !r.filter.Match(event) {
+			continue
+		}
+		ok, suppressed := r.allow(now)
+		if !ok {
+			continue
+		}
+
+		text := Message(event)
+		if suppressed > 0 {
+			text += fmt.Sprintf(" (%d notification(s) suppressed by rate limit)", suppressed)
+		}
+
+		n.pending.Add(1)
+		go func(backend Backend) {
+			defer n.pending.Done()
+			if err := backend.Send(severity, event, text); err != nil {
+				logger.Warn("⚠️ Notification failed", "backend", backend.Name(), "error", err)
+			}
+		}(r.backend)
+	}
+}
+
+// Flush waits for notifications still being delivered
+func (n *Notifier) Flush() {
+	n.pending.Wait()
+}
+
+// Message renders an event as a one-line notification
+func Message(event events.Event) string {
+	switch e := event.(type) {
+	case events.OpportunityDetected:
+		return fmt.Sprintf("🎯 %s: %s → %s at %.2f%% net margin", e.Currency, e.BuyMarket, e.SellMarket, e.MarginPct)
+	case events.OpportunityDropped:
+		return fmt.Sprintf("🗑️ %s: %s → %s at %.2f%% dropped before execution (%s)", e.Currency, e.BuyMarket, e.SellMarket, e.MarginPct, e.Reason)
+	case events.OrderPlaced:
+		return fmt.Sprintf("📤 %s %.6f on %s (order %s)", e.Side, e.Quantity, e.Market, e.OrderID)
+	case events.OrderFilled:
+		return fmt.Sprintf("✅ %s filled: %.6f on %s at %.8f", e.Side, e.Quantity, e.Market, e.AvgPrice)
+	case events.ExecutionCompleted:
+		if e.Success && e.Profit < 0 {
+			return fmt.Sprintf("📉 %s executed at a loss: ₹%.2f (%.2f%%)", e.Currency, -e.Profit, e.MarginPct)
+		}
+		if e.Success {
+			return fmt.Sprintf("💰 %s executed: ₹%.2f profit (%.2f%%)", e.Currency, e.Profit, e.MarginPct)
+		}
+		return fmt.Sprintf("❌ %s execution failed: %s", e.Currency, e.Error)
+	case events.RecoveryTriggered:
+		return fmt.Sprintf("⚠️ Recovering %.6f %s: %s", e.Volume, e.Currency, e.Reason)
+	case events.RecoveryFailed:
+		if e.ManualRequired {
+			return fmt.Sprintf("🚨 Could not recover %.6f %s, manual action required: %s", e.Volume, e.Currency, e.Reason)
+		}
+		return fmt.Sprintf("🚨 Could not recover %.6f %s, still held: %s", e.Volume, e.Currency, e.Reason)
+	case events.RiskTripped:
+		return fmt.Sprintf("🛑 %s blocked %s: %s", e.Rule, e.Subject, e.Detail)
+	case events.BalanceMismatch:
+		return fmt.Sprintf("🚨 %s balance changed outside the bot by %+.8f (expected %.8f, found %.8f); reservations and exposure may be off",
+			e.Currency, e.Unexplained, e.Expected, e.Actual)
+	case events.LowBalance:
+		return fmt.Sprintf("🪫 %s balance low: %.6f spendable (%.2f USDT), %.2f USDT needed to keep trading",
+			e.Currency, e.Available, e.AvailableUSDT, e.RequiredUSDT)
+	}
+	return event.Kind()
+}
@@ -1,34 +1,115 @@
 package opportunity
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"net/http"
 	"sort"
-	"strconv"
 	"time"
 
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/internal/version"
 	"github.com/b-thark/cdcx-api/pkg/exchange"
 	"github.com/b-thark/cdcx-api/pkg/market"
 	"github.com/b-thark/cdcx-api/pkg/types"
-	"github.com/b-thark/cdcx-api/pkg/utils"
 )
 
+// logger is the detectors' progress; --quiet leaves only their results
+var logger = logging.For("detector")
+
 type Detector struct {
 	fetcher     *market.Fetcher
+	books       market.BookFetcher // The fetcher, or a recorded snapshot
+	replay      bool               // Books come from a snapshot: no live ticker or price history
+	capturedAt  time.Time          // The snapshot's capture time, which replayed opportunities carry
 	rateManager *exchange.RateManager
+	anomalies   *market.AnomalyFilter
+	feeds       *market.PriceFeeds
 	config      *types.Config
+	shortfall   *Shortfall // Near misses of the last FindOpportunities
 }
 
 func NewDetector(config *types.Config) *Detector {
+	fetcher := market.NewFetcher()
+	fetcher.SetConcurrency(config.BookFetchConcurrency)
 	return &Detector{
-		fetcher:     market.NewFetcher(),
+		fetcher:     fetcher,
+		books:       fetcher,
 		rateManager: exchange.NewRateManager(config),
+		anomalies:   market.NewAnomalyFilter(config.MaxQuoteDeviationPct),
+		feeds:       market.NewPriceFeeds(config.PriceEMAPeriod, config.PriceAveragePeriod, config.MaxEMADeviationPct),
 		config:      config,
 	}
 }
 
+// NewSnapshotDetector creates a detector that prices opportunities from a
+// recorded snapshot's books and INR rates instead of the live exchange
+func NewSnapshotDetector(config *types.Config, snapshot *market.Snapshot) *Detector {
+	d := NewDetector(config)
+	d.books = snapshot
+	d.replay = true
+	d.capturedAt = snapshot.CapturedAt
+	d.rateManager.Pin(snapshot.Rates)
+	d.feeds = nil // One frozen observation can't establish a price trend
+	return d
+}
+
+// SetTransport sends the detector's requests through transport
+func (d *Detector) SetTransport(transport http.RoundTripper) {
+	d.fetcher.SetTransport(transport)
+	d.rateManager.SetTransport(transport)
+}
+
+// now is when opportunities are found: the snapshot's time when replaying,
+// so a replay produces identical results
+func (d *Detector) now() time.Time {
+	if d.replay {
+		return d.capturedAt
+	}
+	return time.Now()
+}
+
+// refreshTicker updates the last prices order books are sanity-checked
+// against; without them only the previous snapshot is compared
+func (d *Detector) refreshTicker() {
+	if d.replay {
+		return
+	}
+	tickers, err := d.fetcher.GetTicker()
+	if err != nil {
+		logger.Warn("⚠️ Ticker unavailable for quote sanity checks", "error", err)
+		return
+	}
+	d.anomalies.UpdateTicker(tickers)
+}
+
+// Seed primes a market's quote sanity checks with recorded candles
+func (d *Detector) Seed(symbol string, history []types.Candle) {
+	d.anomalies.Seed(symbol, history)
+	if d.feeds != nil {
+		d.feeds.Seed(symbol, history)
+	}
+}
+
+// Quarantined returns the markets excluded for suspicious quotes and why
+func (d *Detector) Quarantined() map[string]string {
+	return d.anomalies.Quarantined()
+}
+
 func (d *Detector) FindOpportunities(pairs map[string]types.ArbitragePairs) ([]types.ArbitrageOpportunity, error) {
-	log.Println("🔍 Analyzing arbitrage opportunities...")
+	logger.Info("🔍 Analyzing arbitrage opportunities...")
+	d.refreshTicker()
+	d.shortfall = NewShortfall(d.config)
+
+	// Every book up front, several at a time, instead of one pair after another
+	scanned := []types.PairInfo{}
+	for _, pairGroup := range pairs {
+		if len(pairGroup.Pairs) >= 2 {
+			scanned = append(scanned, pairGroup.Pairs...)
+		}
+	}
+	books := d.fetchBooks(scanned)
 
 	opportunities := []types.ArbitrageOpportunity{}
 	totalCurrencies := 0
@@ -40,11 +121,11 @@ func (d *Detector) FindOpportunities(pairs map[string]types.ArbitragePairs) ([]t
 			continue
 		}
 
-		log.Printf("📊 Analyzing %s (%d pairs)...", currency, len(pairGroup.Pairs))
+		logger.Info("📊 Analyzing", "currency", currency, "pairs", len(pairGroup.Pairs))
 
-		currencyOpps, err := d.analyzeCurrency(currency, pairGroup.Pairs)
+		currencyOpps, err := d.analyzeCurrency(currency, pairGroup.Pairs, books)
 		if err != nil {
-			log.Printf("❌ %s: %v", currency, err)
+			logger.Info("❌ Not analyzed", "currency", currency, "reason", err)
 			continue
 		}
 
@@ -66,20 +147,43 @@ func (d *Detector) FindOpportunities(pairs map[string]types.ArbitragePairs) ([]t
 	// Save rate cache
 	d.rateManager.SaveCache()
 
-	log.Printf("✅ Analysis complete: %d total currencies, %d with viable opportunities",
-		totalCurrencies, checkedCurrencies)
+	logger.Info("✅ Analysis complete", "currencies", totalCurrencies, "viable_currencies", checkedCurrencies)
+	for symbol, reason := range d.Quarantined() {
+		logger.Warn("🚧 Quarantined", "market", symbol, "reason", reason)
+	}
 
 	return opportunities, nil
 }
 
-func (d *Detector) analyzeCurrency(currency string, pairs []types.PairInfo) ([]types.ArbitrageOpportunity, error) {
+// fetchBooks loads the pairs' order books, from the exchange concurrently or
+// from the replayed snapshot
+func (d *Detector) fetchBooks(pairs []types.PairInfo) map[string]market.BookResult {
+	names := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		names = append(names, pair.Pair)
+	}
+	if !d.replay {
+		return d.fetcher.GetOrderBooks(context.Background(), names)
+	}
+
+	books := make(map[string]market.BookResult, len(names))
+	for _, name := range names {
+		book, err := d.books.GetOrderBook(name)
+		books[name] = market.BookResult{Book: book, Err: err}
+	}
+	return books
+}
+
+// analyzeCurrency compares the currency's pairs priced from books, as
+// fetched by fetchBooks
+func (d *Detector) analyzeCurrency(currency string, pairs []types.PairInfo, books map[string]market.BookResult) ([]types.ArbitrageOpportunity, error) {
 	// Get current prices for all pairs
 	pairPrices := make(map[string]PriceInfo)
 
 	for _, pair := range pairs {
-		priceInfo, err := d.getPriceInfo(pair)
+		priceInfo, err := d.getPriceInfo(pair, books[pair.Pair])
 		if err != nil {
-			log.Printf("   ⚠️ %s: %v", pair.Symbol, err)
+			logger.Warn("⚠️ Not priced", "market", pair.Symbol, "error", err)
 			continue
 		}
 
@@ -88,8 +192,8 @@ func (d *Detector) analyzeCurrency(currency string, pairs []types.PairInfo) ([]t
 		askLiquidityINR := priceInfo.AskVolume * priceInfo.BestAskINR
 
 		if bidLiquidityINR < d.config.MinLiquidity || askLiquidityINR < d.config.MinLiquidity {
-			log.Printf("   📉 %s: Low liquidity (₹%.2f bid, ₹%.2f ask)",
-				pair.Symbol, bidLiquidityINR, askLiquidityINR)
+			logger.Info("📉 Low liquidity", "market", pair.Symbol, "bid_inr", bidLiquidityINR, "ask_inr", askLiquidityINR)
+			d.shortfall.ObserveLiquidity(pair.Symbol, min(bidLiquidityINR, askLiquidityINR))
 			continue
 		}
 
@@ -110,14 +214,18 @@ func (d *Detector) analyzeCurrency(currency string, pairs []types.PairInfo) ([]t
 				continue
 			}
 
-			opp := d.calculateArbitrage(currency, buyPrice, sellPrice)
+			opp, err := d.calculateArbitrage(currency, buyPrice, sellPrice)
+			if err != nil {
+				logger.Warn("⚠️ Not compared", "buy_market", buySymbol, "sell_market", sellSymbol, "error", err)
+				continue
+			}
 			if opp.NetMarginPct >= d.config.MinNetMargin {
 				opp.Viable = true
-				log.Printf("   🎯 VIABLE: %s → %s (%.2f%% net margin)",
-					buySymbol, sellSymbol, opp.NetMarginPct)
+				logger.Info("🎯 VIABLE", "buy_market", buySymbol, "sell_market", sellSymbol, "net_margin_pct", opp.NetMarginPct)
 			} else {
-				log.Printf("   ❌ %s → %s: %.2f%% margin (below %.1f%% threshold)",
-					buySymbol, sellSymbol, opp.NetMarginPct, d.config.MinNetMargin)
+				logger.Info("❌ Below threshold", "buy_market", buySymbol, "sell_market", sellSymbol,
+					"net_margin_pct", opp.NetMarginPct, "min_net_margin", d.config.MinNetMargin)
+				d.shortfall.ObserveOpportunity(opp)
 			}
 
 			opportunities = append(opportunities, opp)
@@ -138,71 +246,69 @@ type PriceInfo struct {
 	HasLiquidity bool
 }
 
-func (d *Detector) getPriceInfo(pair types.PairInfo) (PriceInfo, error) {
-	orderBook, err := d.fetcher.GetOrderBook(pair.Pair)
+func (d *Detector) getPriceInfo(pair types.PairInfo, fetched market.BookResult) (PriceInfo, error) {
+	orderBook, err := fetched.Book, fetched.Err
 	if err != nil {
 		return PriceInfo{}, err
 	}
+	if orderBook == nil {
+		return PriceInfo{}, fmt.Errorf("%s book not fetched", pair.Pair)
+	}
 
 	priceInfo := PriceInfo{Pair: pair}
 
-	// Parse bids (buy orders)
-	if bids, ok := orderBook["bids"].(map[string]interface{}); ok {
-		for priceStr, volumeInterface := range bids {
-			price, _ := strconv.ParseFloat(priceStr, 64)
-			var volume float64
-			switch v := volumeInterface.(type) {
-			case string:
-				volume, _ = strconv.ParseFloat(v, 64)
-			case float64:
-				volume = v
-			}
-
-			if price > priceInfo.BestBid {
-				priceInfo.BestBid = price
-				priceInfo.BidVolume = volume
-			}
-		}
+	// Bids (buy orders)
+	if bestBid, ok := orderBook.BestBid(); ok {
+		priceInfo.BestBid, priceInfo.BidVolume = bestBid.Price, bestBid.Volume
 	}
 
-	// Parse asks (sell orders)
+	// Asks (sell orders)
 	priceInfo.BestAsk = 999999999.0
-	if asks, ok := orderBook["asks"].(map[string]interface{}); ok {
-		for priceStr, volumeInterface := range asks {
-			price, _ := strconv.ParseFloat(priceStr, 64)
-			var volume float64
-			switch v := volumeInterface.(type) {
-			case string:
-				volume, _ = strconv.ParseFloat(v, 64)
-			case float64:
-				volume = v
-			}
+	if bestAsk, ok := orderBook.BestAsk(); ok {
+		priceInfo.BestAsk, priceInfo.AskVolume = bestAsk.Price, bestAsk.Volume
+	}
 
-			if price < priceInfo.BestAsk {
-				priceInfo.BestAsk = price
-				priceInfo.AskVolume = volume
-			}
-		}
+	// A bogus quote would look like a huge opportunity; skip the book instead
+	if err := d.anomalies.Check(pair.Symbol, priceInfo.BestBid, priceInfo.BestAsk); err != nil {
+		return PriceInfo{}, err
+	}
+
+	// A spread between prices that are still moving is likely gone before orders land
+	if err := d.feeds.CheckStable(pair.Symbol, priceInfo.BestBid, priceInfo.BestAsk); err != nil {
+		return PriceInfo{}, err
 	}
 
 	// Convert to INR
 	if priceInfo.BestBid > 0 {
-		priceInfo.BestBidINR, _ = d.rateManager.ConvertToINR(priceInfo.BestBid, pair.BaseCurrency)
+		if priceInfo.BestBidINR, err = d.rateManager.ConvertToINR(priceInfo.BestBid, pair.BaseCurrency); err != nil {
+			return PriceInfo{}, fmt.Errorf("INR conversion: %v", err)
+		}
 	}
 	if priceInfo.BestAsk < 999999999.0 {
-		priceInfo.BestAskINR, _ = d.rateManager.ConvertToINR(priceInfo.BestAsk, pair.BaseCurrency)
+		if priceInfo.BestAskINR, err = d.rateManager.ConvertToINR(priceInfo.BestAsk, pair.BaseCurrency); err != nil {
+			return PriceInfo{}, fmt.Errorf("INR conversion: %v", err)
+		}
 	}
 
 	return priceInfo, nil
 }
 
-func (d *Detector) calculateArbitrage(currency string, buyPrice, sellPrice PriceInfo) types.ArbitrageOpportunity {
+func (d *Detector) calculateArbitrage(currency string, buyPrice, sellPrice PriceInfo) (types.ArbitrageOpportunity, error) {
+	// Both sides must be quoted; an empty book side would divide by zero below
+	if err := types.RequirePositive(buyPrice.Pair.Symbol+" ask INR", buyPrice.BestAskINR); err != nil {
+		return types.ArbitrageOpportunity{}, err
+	}
+	if err := types.RequirePositive(sellPrice.Pair.Symbol+" bid INR", sellPrice.BestBidINR); err != nil {
+		return types.ArbitrageOpportunity{}, err
+	}
+
 	// Calculate margins in INR terms
 	grossMargin := sellPrice.BestBidINR - buyPrice.BestAskINR
 	grossMarginPct := (grossMargin / buyPrice.BestAskINR) * 100
 
-	// Estimate fees
-	estimatedFees := (buyPrice.BestAskINR + sellPrice.BestBidINR) * d.config.FeeRate
+	// Estimate fees, honouring per-market overrides
+	estimatedFees := buyPrice.BestAskINR*d.config.FeeRateFor(buyPrice.Pair.Symbol) +
+		sellPrice.BestBidINR*d.config.FeeRateFor(sellPrice.Pair.Symbol)
 
 	// Calculate net margins
 	netMargin := grossMargin - estimatedFees
@@ -236,11 +342,35 @@ func (d *Detector) calculateArbitrage(currency string, buyPrice, sellPrice Price
 		NetMargin:      netMargin,
 		NetMarginPct:   netMarginPct,
 		Viable:         false, // Set by caller
-		Timestamp:      time.Now(),
+		Timestamp:      d.now(),
+	}, nil
+}
+
+// Shortfall is how close the last FindOpportunities came to finding
+// something viable; without one (results loaded from a file) it is measured
+// from opportunities' margins alone
+func (d *Detector) Shortfall(opportunities []types.ArbitrageOpportunity) *Shortfall {
+	if d.shortfall != nil {
+		return d.shortfall
+	}
+	shortfall := NewShortfall(d.config)
+	for _, opp := range opportunities {
+		shortfall.ObserveOpportunity(opp)
+	}
+	return shortfall
+}
+
+func printSuggestions(shortfall *Shortfall) {
+	for _, suggestion := range shortfall.Suggestions() {
+		fmt.Printf("💡 %s\n", suggestion)
 	}
 }
 
 func (d *Detector) SaveOpportunities(opportunities []types.ArbitrageOpportunity, filename string) error {
+	run := version.Current()
+	for i := range opportunities {
+		opportunities[i].Run = run
+	}
 	return utils.SaveJSON(opportunities, filename)
 }
 
@@ -267,6 +397,7 @@ func (d *Detector) DisplayResults(opportunities []types.ArbitrageOpportunity) {
 
 	if len(viableOpps) == 0 {
 		fmt.Printf("\n❌ No viable arbitrage opportunities found with %.1f%%+ net margin\n", d.config.MinNetMargin)
+		printSuggestions(d.Shortfall(opportunities))
 		return
 	}
 
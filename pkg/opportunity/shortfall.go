@@ -0,0 +1,65 @@
+package opportunity
+
+import (
+	"fmt"
+
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// Shortfall tracks how close a scan's rejected candidates came to the
+// thresholds, so a scan that finds nothing viable can say what would change that
+type Shortfall struct {
+	minNetMargin float64
+	minLiquidity float64
+
+	bestMargin    *types.ArbitrageOpportunity // Highest net margin below MinNetMargin
+	liquidityPair string                      // Deepest pair dropped for low liquidity
+	liquidityINR  float64                     // What its thinner side carried
+}
+
+// NewShortfall measures candidates against config's thresholds
+func NewShortfall(config *types.Config) *Shortfall {
+	return &Shortfall{minNetMargin: config.MinNetMargin, minLiquidity: config.MinLiquidity}
+}
+
+// ObserveOpportunity records an opportunity; viable ones are ignored
+func (s *Shortfall) ObserveOpportunity(opp types.ArbitrageOpportunity) {
+	if opp.Viable {
+		return
+	}
+	if s.bestMargin == nil || opp.NetMarginPct > s.bestMargin.NetMarginPct {
+		s.bestMargin = &opp
+	}
+}
+
+// ObserveLiquidity records a pair dropped because its best bid or ask
+// carried less than MinLiquidity; liquidityINR is the thinner of the two
+func (s *Shortfall) ObserveLiquidity(symbol string, liquidityINR float64) {
+	if liquidityINR > s.liquidityINR {
+		s.liquidityPair, s.liquidityINR = symbol, liquidityINR
+	}
+}
+
+// Suggestions explains the gap between the best candidates and the
+// thresholds, with the setting that would have admitted each
+func (s *Shortfall) Suggestions() []string {
+	suggestions := []string{}
+	if opp := s.bestMargin; opp != nil {
+		route := fmt.Sprintf("%s %s → %s", opp.TargetCurrency, opp.BuyMarket.Symbol, opp.SellMarket.Symbol)
+		if opp.NetMarginPct > 0 {
+			suggestions = append(suggestions, fmt.Sprintf("best margin was %.2f%% vs %.1f%% threshold (%s): MIN_NET_MARGIN=%.2f would admit it",
+				opp.NetMarginPct, s.minNetMargin, route, opp.NetMarginPct))
+		} else {
+			suggestions = append(suggestions, fmt.Sprintf("best margin was %.2f%% after fees (%s): no route is profitable, so lowering MIN_NET_MARGIN won't help",
+				opp.NetMarginPct, route))
+		}
+	}
+	if s.liquidityPair != "" {
+		suggestions = append(suggestions, fmt.Sprintf("deepest thin pair was %s with ₹%.0f vs ₹%.0f minimum liquidity: MIN_LIQUIDITY=%.0f would admit it",
+			s.liquidityPair, s.liquidityINR, s.minLiquidity, s.liquidityINR))
+	}
+	if len(suggestions) == 0 {
+		suggestions = append(suggestions, "no pair could be priced: check the pairs file and exchange connectivity")
+	}
+	return suggestions
+}
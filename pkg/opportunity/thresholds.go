@@ -0,0 +1,77 @@
+package opportunity
+
+import (
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// ThresholdRun is what the detector finds at one MinNetMargin/MinLiquidity setting
+type ThresholdRun struct {
+	MinNetMargin  float64 `json:"min_net_margin"`
+	MinLiquidity  float64 `json:"min_liquidity"`
+	Opportunities int     `json:"opportunities"` // Viable ones
+	Currencies    int     `json:"currencies"`
+	BestMarginPct float64 `json:"best_margin_pct"`
+	AvgMarginPct  float64 `json:"avg_margin_pct"`
+	EstProfitINR  float64 `json:"est_profit_inr"` // Net margin on what both best levels carry, summed
+}
+
+// CompareThresholds runs the detector against the same snapshot once per
+// combination of minimum net margin and minimum liquidity, so the settings
+// can be chosen from identical prices without trading
+func CompareThresholds(config *types.Config, snapshot *market.Snapshot, pairs map[string]types.ArbitragePairs, margins, liquidities []float64) []ThresholdRun {
+	runs := make([]ThresholdRun, 0, len(margins)*len(liquidities))
+	for _, margin := range margins {
+		for _, liquidity := range liquidities {
+			runConfig := *config
+			runConfig.MinNetMargin, runConfig.MinLiquidity = margin, liquidity
+
+			// Errors are per currency and already skipped; none is returned
+			opportunities, _ := NewSnapshotDetector(&runConfig, snapshot).FindOpportunities(pairs)
+			runs = append(runs, summarizeRun(margin, liquidity, opportunities, snapshot))
+		}
+	}
+	return runs
+}
+
+func summarizeRun(margin, liquidity float64, opportunities []types.ArbitrageOpportunity, snapshot *market.Snapshot) ThresholdRun {
+	run := ThresholdRun{MinNetMargin: margin, MinLiquidity: liquidity}
+	currencies := make(map[string]bool)
+	totalMargin := 0.0
+
+	for _, opp := range opportunities {
+		if !opp.Viable {
+			continue
+		}
+		run.Opportunities++
+		currencies[opp.TargetCurrency] = true
+		totalMargin += opp.NetMarginPct
+		run.BestMarginPct = max(run.BestMarginPct, opp.NetMarginPct)
+		run.EstProfitINR += opp.NetMargin * topQuantity(snapshot, opp.BuyMarket.Pair, opp.SellMarket.Pair)
+	}
+
+	run.Currencies = len(currencies)
+	if run.Opportunities > 0 {
+		run.AvgMarginPct = totalMargin / float64(run.Opportunities)
+	}
+	return run
+}
+
+// topQuantity is what the buy market's best ask and the sell market's best bid can both take
+func topQuantity(snapshot *market.Snapshot, buyPair, sellPair string) float64 {
+	buyBook, err := snapshot.GetOrderBook(buyPair)
+	if err != nil {
+		return 0
+	}
+	sellBook, err := snapshot.GetOrderBook(sellPair)
+	if err != nil {
+		return 0
+	}
+
+	ask, okAsk := buyBook.BestAsk()
+	bid, okBid := sellBook.BestBid()
+	if !okAsk || !okBid {
+		return 0
+	}
+	return min(ask.Volume, bid.Volume)
+}
@@ -0,0 +1,272 @@
+package opportunity
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/internal/version"
+	"github.com/b-thark/cdcx-api/pkg/exchange"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// TriangularDetector finds three-leg cycles within CoinDCX, e.g.
+// USDT→BTC→ETH→USDT, whose best prices return more than they started with
+// after every leg's fee
+type TriangularDetector struct {
+	fetcher     *market.Fetcher
+	rateManager *exchange.RateManager
+	anomalies   *market.AnomalyFilter
+	config      *types.Config
+}
+
+func NewTriangularDetector(config *types.Config) *TriangularDetector {
+	return &TriangularDetector{
+		fetcher:     market.NewFetcher(),
+		rateManager: exchange.NewRateManager(config),
+		anomalies:   market.NewAnomalyFilter(config.MaxQuoteDeviationPct),
+		config:      config,
+	}
+}
+
+// SetTransport sends the detector's requests through transport
+func (d *TriangularDetector) SetTransport(transport http.RoundTripper) {
+	d.fetcher.SetTransport(transport)
+	d.rateManager.SetTransport(transport)
+}
+
+// Cycles lists every three-leg route from start back to start over the
+// active markets, without prices. Each market is an edge both ways: its
+// quote buys the coin, the coin sells for the quote.
+func Cycles(markets []types.MarketDetail, start string) [][]types.TriangularLeg {
+	edges := make(map[string][]types.TriangularLeg)
+	for _, detail := range markets {
+		if detail.Status != "active" {
+			continue
+		}
+		symbol := detail.Canonical()
+		edges[symbol.Quote] = append(edges[symbol.Quote], types.TriangularLeg{
+			Symbol: detail.Symbol, Pair: detail.Pair, Side: "buy", From: symbol.Quote, To: symbol.Base,
+		})
+		edges[symbol.Base] = append(edges[symbol.Base], types.TriangularLeg{
+			Symbol: detail.Symbol, Pair: detail.Pair, Side: "sell", From: symbol.Base, To: symbol.Quote,
+		})
+	}
+
+	cycles := [][]types.TriangularLeg{}
+	for _, first := range edges[start] {
+		for _, second := range edges[first.To] {
+			if second.To == start || second.Symbol == first.Symbol {
+				continue
+			}
+			for _, third := range edges[second.To] {
+				if third.To == start {
+					cycles = append(cycles, []types.TriangularLeg{first, second, third})
+				}
+			}
+		}
+	}
+	return cycles
+}
+
+// FindOpportunities prices every cycle through the start currencies (e.g.
+// USDT, INR) against live order books
+func (d *TriangularDetector) FindOpportunities(starts []string) ([]types.TriangularOpportunity, error) {
+	logger.Info("🔺 Analyzing triangular opportunities...")
+
+	markets, err := d.fetcher.GetMarketDetails()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch markets: %v", err)
+	}
+	if tickers, err := d.fetcher.GetTicker(); err == nil {
+		d.anomalies.UpdateTicker(tickers)
+	} else {
+		logger.Warn("⚠️ Ticker unavailable for quote sanity checks", "error", err)
+	}
+
+	// Many cycles share a market; fetch each book once per pass
+	books := make(map[string]bookTop)
+	top := func(leg types.TriangularLeg) (bookTop, error) {
+		if book, ok := books[leg.Pair]; ok {
+			return book, book.err
+		}
+		book := d.bookTop(leg)
+		books[leg.Pair] = book
+		return book, book.err
+	}
+
+	opportunities := []types.TriangularOpportunity{}
+	for _, start := range starts {
+		cycles := Cycles(markets, start)
+		logger.Info("📊 Cycles", "start", start, "cycles", len(cycles))
+
+		for _, legs := range cycles {
+			priced := true
+			for i := range legs {
+				book, err := top(legs[i])
+				if err != nil {
+					priced = false
+					break
+				}
+				legs[i].FeeRate = d.config.FeeRateFor(legs[i].Symbol)
+				if legs[i].Side == "buy" {
+					legs[i].Price, legs[i].Volume = book.ask.Price, book.ask.Volume
+				} else {
+					legs[i].Price, legs[i].Volume = book.bid.Price, book.bid.Volume
+				}
+				if legs[i].Price <= 0 {
+					priced = false
+					break
+				}
+			}
+			if !priced {
+				continue
+			}
+
+			opportunities = append(opportunities, d.evaluate(start, legs))
+		}
+	}
+
+	d.rateManager.SaveCache()
+
+	viable := 0
+	for _, opp := range opportunities {
+		if opp.Viable {
+			viable++
+		}
+	}
+	logger.Info("✅ Triangular analysis complete", "priced", len(opportunities), "viable", viable)
+	for symbol, reason := range d.anomalies.Quarantined() {
+		logger.Warn("🚧 Quarantined", "market", symbol, "reason", reason)
+	}
+
+	return opportunities, nil
+}
+
+// bookTop is a market's best bid and ask, or why they are unusable
+type bookTop struct {
+	bid types.OrderLevel
+	ask types.OrderLevel
+	err error
+}
+
+func (d *TriangularDetector) bookTop(leg types.TriangularLeg) bookTop {
+	orderBook, err := d.fetcher.GetOrderBook(leg.Pair)
+	if err != nil {
+		return bookTop{err: err}
+	}
+
+	var book bookTop
+	book.bid, _ = orderBook.BestBid()
+	book.ask, _ = orderBook.BestAsk()
+
+	// A bogus quote would look like a huge opportunity; skip the book instead
+	if err := d.anomalies.Check(leg.Symbol, book.bid.Price, book.ask.Price); err != nil {
+		return bookTop{err: err}
+	}
+	return book
+}
+
+// evaluate computes a priced cycle's return and how much of the start
+// currency the best levels of all three books can carry through
+func (d *TriangularDetector) evaluate(start string, legs []types.TriangularLeg) types.TriangularOpportunity {
+	gross, net := 1.0, 1.0
+	maxStart := math.Inf(1)
+	for _, leg := range legs {
+		// What this leg's best level takes, in its From currency, expressed in start units
+		capacity := leg.Volume
+		if leg.Side == "buy" {
+			capacity = leg.Volume * leg.Price
+		}
+		maxStart = min(maxStart, capacity/net)
+
+		feeless := leg
+		feeless.FeeRate = 0
+		gross *= feeless.Rate()
+		net *= leg.Rate()
+	}
+
+	opp := types.TriangularOpportunity{
+		Start:          start,
+		Legs:           legs,
+		GrossReturnPct: (gross - 1) * 100,
+		NetReturnPct:   (net - 1) * 100,
+		MaxStart:       maxStart,
+		Timestamp:      time.Now(),
+	}
+
+	if maxINR, err := d.rateManager.ReportINR(maxStart, start); err == nil {
+		opp.MaxStartINR = maxINR
+		opp.NetProfitINR = maxINR * (net - 1)
+	} else {
+		logger.Warn("⚠️ INR conversion", "route", opp.Route(), "error", err)
+	}
+
+	opp.Viable = opp.NetReturnPct >= d.config.MinNetMargin && opp.MaxStartINR >= d.config.MinLiquidity
+	return opp
+}
+
+func (d *TriangularDetector) SaveOpportunities(opportunities []types.TriangularOpportunity, filename string) error {
+	run := version.Current()
+	for i := range opportunities {
+		opportunities[i].Run = run
+	}
+	return utils.SaveJSON(opportunities, filename)
+}
+
+func (d *TriangularDetector) LoadOpportunities(filename string) ([]types.TriangularOpportunity, error) {
+	var opportunities []types.TriangularOpportunity
+	err := utils.LoadJSON(filename, &opportunities)
+	return opportunities, err
+}
+
+func (d *TriangularDetector) DisplayResults(opportunities []types.TriangularOpportunity) {
+	fmt.Printf("\n🔺 TRIANGULAR OPPORTUNITY ANALYSIS RESULTS\n")
+	fmt.Printf("=========================================\n")
+
+	sorted := append([]types.TriangularOpportunity(nil), opportunities...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].NetReturnPct > sorted[j].NetReturnPct
+	})
+
+	viable := 0
+	for _, opp := range sorted {
+		if opp.Viable {
+			viable++
+		}
+	}
+	fmt.Printf("💰 Priced cycles: %d\n", len(sorted))
+	fmt.Printf("✅ Viable cycles: %d\n", viable)
+
+	if viable == 0 {
+		fmt.Printf("\n❌ No cycle returns %.1f%%+ net with ₹%.0f+ of liquidity\n", d.config.MinNetMargin, d.config.MinLiquidity)
+		if len(sorted) > 0 {
+			best := sorted[0]
+			fmt.Printf("📊 Best cycle: %s at %.3f%% net (%.3f%% gross)\n", best.Route(), best.NetReturnPct, best.GrossReturnPct)
+		}
+		return
+	}
+
+	oppNum := 1
+	for _, opp := range sorted {
+		if !opp.Viable {
+			continue
+		}
+		fmt.Printf("\n   %d. %s\n", oppNum, opp.Route())
+		for _, leg := range opp.Legs {
+			emoji := "🟢 BUY "
+			if leg.Side == "sell" {
+				emoji = "🔴 SELL"
+			}
+			fmt.Printf("      %s %s at %s (%s available)\n", emoji, leg.Symbol,
+				market.FormatPrice(leg.Symbol, leg.Price), market.FormatQuantity(leg.Symbol, leg.Volume))
+		}
+		fmt.Printf("      💵 Gross Return: %.3f%%\n", opp.GrossReturnPct)
+		fmt.Printf("      💰 Net Return: %.3f%% (₹%.2f on ₹%.2f)\n", opp.NetReturnPct, opp.NetProfitINR, opp.MaxStartINR)
+		oppNum++
+	}
+}
@@ -2,14 +2,17 @@ package pairs
 
 import (
 	"fmt"
-	"log"
+	"net/http"
 	"time"
 
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
 	"github.com/b-thark/cdcx-api/pkg/market"
 	"github.com/b-thark/cdcx-api/pkg/types"
-	"github.com/b-thark/cdcx-api/pkg/utils"
 )
 
+var logger = logging.For("pairs")
+
 type Analyzer struct {
 	fetcher *market.Fetcher
 	config  *types.Config
@@ -22,15 +25,20 @@ func NewAnalyzer(config *types.Config) *Analyzer {
 	}
 }
 
+// SetTransport sends the analyzer's requests through transport
+func (a *Analyzer) SetTransport(transport http.RoundTripper) {
+	a.fetcher.SetTransport(transport)
+}
+
 func (a *Analyzer) ExtractArbitragePairs() (map[string]types.ArbitragePairs, error) {
-	log.Println("🔍 Fetching market details...")
+	logger.Info("🔍 Fetching market details...")
 
 	markets, err := a.fetcher.GetMarketDetails()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch markets: %v", err)
 	}
 
-	log.Printf("✅ Found %d total markets", len(markets))
+	logger.Info("✅ Found markets", "markets", len(markets))
 
 	// Group pairs by target currency
 	allPairs := make(map[string][]types.PairInfo)
@@ -80,7 +88,7 @@ func (a *Analyzer) ExtractArbitragePairs() (map[string]types.ArbitragePairs, err
 		}
 	}
 
-	log.Printf("🎯 Found %d currencies with arbitrage potential", len(arbitragePairs))
+	logger.Info("🎯 Found currencies with arbitrage potential", "currencies", len(arbitragePairs))
 	return arbitragePairs, nil
 }
 
@@ -120,8 +128,8 @@ func (a *Analyzer) DisplaySummary(pairs map[string]types.ArbitragePairs) {
 		fmt.Printf("\n💰 %s (%d pairs):\n", currency, len(data.Pairs))
 
 		for _, pair := range data.Pairs {
-			fmt.Printf("   📊 %s (%s) - Min: %.8f, Notional: %.8f\n",
-				pair.Symbol, pair.BaseCurrency, pair.MinQuantity, pair.MinNotional)
+			fmt.Printf("   📊 %s (%s) - Min: %s, Notional: %s\n", pair.Symbol, pair.BaseCurrency,
+				market.FormatQuantity(pair.Symbol, pair.MinQuantity), market.FormatPrice(pair.Symbol, pair.MinNotional))
 			baseCurrencyCount[pair.BaseCurrency]++
 			totalPairs++
 		}
@@ -0,0 +1,134 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/market"
+)
+
+var logger = logging.For("recorder")
+
+// Recorder captures timestamped order book snapshots of a set of pairs to a
+// gzip-compressed JSON-lines file, one market.Snapshot per line. Each
+// capture is appended as its own gzip member, so a crash loses at most the
+// frame being written and a recording can be resumed by appending.
+type Recorder struct {
+	path       string
+	books      market.BookFetcher
+	pairs      []string
+	toINR      func(currency string) (float64, error)
+	currencies []string // Whose INR rates each frame records
+	mu         sync.Mutex
+}
+
+// NewRecorder records pairs' books from books, with the INR rates of
+// currencies, to path
+func NewRecorder(path string, books market.BookFetcher, pairs []string, toINR func(currency string) (float64, error), currencies []string) *Recorder {
+	return &Recorder{path: path, books: books, pairs: pairs, toINR: toINR, currencies: currencies}
+}
+
+// Capture records one frame and returns it
+func (r *Recorder) Capture() (*market.Snapshot, error) {
+	frame := market.CaptureSnapshot(r.books, r.pairs, r.toINR, r.currencies)
+	return frame, r.Append(frame)
+}
+
+// Append writes a frame to the recording
+func (r *Recorder) Append(frame *market.Snapshot) error {
+	sanitized, _ := utils.SanitizeFloats(frame)
+	line, err := json.Marshal(sanitized)
+	if err != nil {
+		return fmt.Errorf("error encoding frame: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %v", r.path, err)
+	}
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	if _, err := writer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing %s: %v", r.path, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %v", r.path, err)
+	}
+	return nil
+}
+
+// Recording is a loaded recording's frames, oldest first
+type Recording struct {
+	Frames []*market.Snapshot
+}
+
+// Load reads a recording. A frame cut short by a crash ends it; malformed
+// lines are skipped.
+func Load(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", path, err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	defer reader.Close()
+
+	recording := &Recording{}
+	lines := bufio.NewReader(reader)
+	for line := 1; ; line++ {
+		raw, err := lines.ReadBytes('\n')
+		if len(raw) > 0 && err == nil {
+			var frame market.Snapshot
+			if err := json.Unmarshal(raw, &frame); err != nil {
+				logger.Warn("⚠️ Frame skipped", "path", path, "frame", line, "error", err)
+			} else {
+				recording.Frames = append(recording.Frames, &frame)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Warn("⚠️ Recording ends early", "path", path, "frame", line, "error", err)
+			break
+		}
+	}
+
+	sort.SliceStable(recording.Frames, func(i, j int) bool {
+		return recording.Frames[i].CapturedAt.Before(recording.Frames[j].CapturedAt)
+	})
+	if len(recording.Frames) == 0 {
+		return nil, fmt.Errorf("%s holds no frames", path)
+	}
+	return recording, nil
+}
+
+// At returns the latest frame captured at or before t, the first frame when
+// t precedes the recording: the market as a strategy deciding at t saw it
+func (r *Recording) At(t time.Time) *market.Snapshot {
+	i := sort.Search(len(r.Frames), func(i int) bool { return r.Frames[i].CapturedAt.After(t) })
+	return r.Frames[max(i-1, 0)]
+}
+
+// Span returns the first and last capture times
+func (r *Recording) Span() (time.Time, time.Time) {
+	return r.Frames[0].CapturedAt, r.Frames[len(r.Frames)-1].CapturedAt
+}
@@ -0,0 +1,340 @@
+package shadow
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b-thark/cdcx-api/internal/logging"
+	"github.com/b-thark/cdcx-api/internal/utils"
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+var logger = logging.For("shadow")
+
+// Decision is a trade a shadow strategy would have made: a chain of
+// conversions from Start back to Start, and how it turned out when re-priced
+// on the books a horizon later
+type Decision struct {
+	Strategy          string                `json:"strategy"`
+	Route             string                `json:"route"`
+	DecidedAt         time.Time             `json:"decided_at"`
+	Start             string                `json:"start"`
+	StartAmount       float64               `json:"start_amount"`
+	StartINR          float64               `json:"start_inr"` // INR per unit of Start when decided
+	Legs              []types.TriangularLeg `json:"legs"`      // Conversions in order, priced when decided
+	ExpectedReturnPct float64               `json:"expected_return_pct"`
+	ExpectedProfitINR float64               `json:"expected_profit_inr"`
+
+	EvaluatedAt       time.Time `json:"evaluated_at"`
+	RealizedReturnPct float64   `json:"realized_return_pct"`
+	RealizedProfitINR float64   `json:"realized_profit_inr"`
+	FillRatio         float64   `json:"fill_ratio"` // Smallest share of a leg the later books could fill
+	Error             string    `json:"error,omitempty"`
+}
+
+// Strategy decides trades that are logged and evaluated but never executed
+type Strategy interface {
+	Name() string
+	Decide() ([]Decision, error)
+}
+
+// builtin are the strategies SHADOW_STRATEGIES can name
+var builtin = map[string]func(config *types.Config) Strategy{
+	"triangular": NewTriangular,
+}
+
+// Names lists the built-in strategies
+func Names() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a built-in strategy by name
+func New(name string, config *types.Config) (Strategy, error) {
+	create, ok := builtin[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown shadow strategy %q (known: %s)", name, strings.Join(Names(), ", "))
+	}
+	return create(config), nil
+}
+
+// Runner asks its strategies for decisions on a schedule and, once the
+// horizon has passed, re-prices each on the books of the time and appends
+// it to a JSON-lines log. Nothing it does places an order.
+type Runner struct {
+	strategies []Strategy
+	books      market.BookFetcher
+	horizon    time.Duration
+	path       string
+	mu         sync.Mutex
+	pending    []Decision
+	summaries  map[string]*Summary
+}
+
+// NewRunner evaluates decisions on books a horizon after they are made,
+// logging them to path
+func NewRunner(books market.BookFetcher, horizon time.Duration, path string) *Runner {
+	return &Runner{books: books, horizon: horizon, path: path, summaries: make(map[string]*Summary)}
+}
+
+// Register adds a strategy, built-in or not
+func (r *Runner) Register(strategy Strategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strategies = append(r.strategies, strategy)
+}
+
+// Decide collects every strategy's decisions for evaluation
+func (r *Runner) Decide() {
+	r.mu.Lock()
+	strategies := r.strategies
+	r.mu.Unlock()
+
+	for _, strategy := range strategies {
+		decisions, err := strategy.Decide()
+		if err != nil {
+			logger.Warn("👻 Strategy failed", "strategy", strategy.Name(), "error", err)
+			continue
+		}
+		for _, decision := range decisions {
+			decision.Strategy = strategy.Name()
+			if decision.DecidedAt.IsZero() {
+				decision.DecidedAt = time.Now()
+			}
+			logger.Info("👻 Would trade, not executed", "strategy", decision.Strategy, "route", decision.Route,
+				"amount", decision.StartAmount, "currency", decision.Start, "expected_return_pct", decision.ExpectedReturnPct,
+				"expected_profit_inr", decision.ExpectedProfitINR)
+
+			r.mu.Lock()
+			r.pending = append(r.pending, decision)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// Evaluate re-prices and logs the decisions whose horizon has passed
+func (r *Runner) Evaluate(now time.Time) {
+	r.mu.Lock()
+	due, waiting := []Decision{}, []Decision{}
+	for _, decision := range r.pending {
+		if now.Sub(decision.DecidedAt) >= r.horizon {
+			due = append(due, decision)
+		} else {
+			waiting = append(waiting, decision)
+		}
+	}
+	r.pending = waiting
+	r.mu.Unlock()
+
+	for _, decision := range due {
+		Evaluate(&decision, r.books)
+		decision.EvaluatedAt = now
+		if err := r.append(decision); err != nil {
+			logger.Warn("⚠️ Decision not recorded", "error", err)
+		}
+
+		r.mu.Lock()
+		summary, ok := r.summaries[decision.Strategy]
+		if !ok {
+			summary = &Summary{Strategy: decision.Strategy}
+			r.summaries[decision.Strategy] = summary
+		}
+		summary.add(decision)
+		r.mu.Unlock()
+	}
+}
+
+// Run decides every interval and evaluates as horizons pass, until ctx is
+// done; decisions still inside their horizon then are dropped
+func (r *Runner) Run(ctx context.Context, interval time.Duration) {
+	decide := time.NewTicker(interval)
+	defer decide.Stop()
+	evaluate := time.NewTicker(max(r.horizon/2, time.Second))
+	defer evaluate.Stop()
+
+	r.Decide()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-decide.C:
+			r.Decide()
+		case now := <-evaluate.C:
+			r.Evaluate(now)
+		}
+	}
+}
+
+// Summaries returns what has been evaluated so far, per strategy
+func (r *Runner) Summaries() []Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	summaries := make([]Summary, 0, len(r.summaries))
+	for _, summary := range r.summaries {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Strategy < summaries[j].Strategy })
+	return summaries
+}
+
+func (r *Runner) append(decision Decision) error {
+	sanitized, _ := utils.SanitizeFloats(decision)
+	line, err := json.Marshal(sanitized)
+	if err != nil {
+		return fmt.Errorf("error encoding shadow decision: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %v", r.path, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing %s: %v", r.path, err)
+	}
+	return nil
+}
+
+// Evaluate re-prices a decision on the current books: each leg converts
+// what the previous one produced, walking the book for its size instead of
+// trading at the price quoted when it was decided
+func Evaluate(decision *Decision, books market.BookFetcher) {
+	amount := decision.StartAmount
+	decision.FillRatio = 1
+	if amount <= 0 {
+		decision.Error = "nothing to trade"
+		return
+	}
+
+	for i, leg := range decision.Legs {
+		book, err := books.GetOrderBook(leg.Pair)
+		if err != nil {
+			decision.Error = fmt.Sprintf("leg %d %s: %v", i+1, leg.Symbol, err)
+			return
+		}
+		side := "bids"
+		if leg.Side == "buy" {
+			side = "asks"
+		}
+		levels := book.Levels(side)
+
+		quantity := leg.Quantity(amount)
+		impact := market.EstimateImpact(levels, quantity)
+		if impact.Filled <= 0 {
+			decision.Error = fmt.Sprintf("leg %d %s: no %s", i+1, leg.Symbol, side)
+			decision.FillRatio = 0
+			return
+		}
+		decision.FillRatio = min(decision.FillRatio, impact.Filled/quantity)
+
+		// The whole amount at the price walking the book gives; FillRatio
+		// records how much of it the book could actually take
+		repriced := leg
+		repriced.Price = impact.EffectivePrice
+		amount *= repriced.Rate()
+	}
+
+	decision.RealizedReturnPct = (amount/decision.StartAmount - 1) * 100
+	decision.RealizedProfitINR = (amount - decision.StartAmount) * decision.StartINR
+}
+
+// Summary is how a strategy's evaluated decisions turned out
+type Summary struct {
+	Strategy          string  `json:"strategy"`
+	Decisions         int     `json:"decisions"`
+	Failed            int     `json:"failed"` // Couldn't be re-priced: a book was missing or empty
+	Wins              int     `json:"wins"`
+	ExpectedProfitINR float64 `json:"expected_profit_inr"`
+	RealizedProfitINR float64 `json:"realized_profit_inr"`
+	expectedPct       float64
+	realizedPct       float64
+}
+
+func (s *Summary) add(decision Decision) {
+	s.Decisions++
+	if decision.Error != "" {
+		s.Failed++
+		return
+	}
+	if decision.RealizedProfitINR > 0 {
+		s.Wins++
+	}
+	s.ExpectedProfitINR += decision.ExpectedProfitINR
+	s.RealizedProfitINR += decision.RealizedProfitINR
+	s.expectedPct += decision.ExpectedReturnPct
+	s.realizedPct += decision.RealizedReturnPct
+}
+
+// AvgExpectedPct is the mean return decided on, over re-priced decisions
+func (s Summary) AvgExpectedPct() float64 {
+	if priced := s.Decisions - s.Failed; priced > 0 {
+		return s.expectedPct / float64(priced)
+	}
+	return 0
+}
+
+// AvgRealizedPct is the mean return the later books gave
+func (s Summary) AvgRealizedPct() float64 {
+	if priced := s.Decisions - s.Failed; priced > 0 {
+		return s.realizedPct / float64(priced)
+	}
+	return 0
+}
+
+// Summarize groups evaluated decisions per strategy
+func Summarize(decisions []Decision) []Summary {
+	byStrategy := make(map[string]*Summary)
+	for _, decision := range decisions {
+		summary, ok := byStrategy[decision.Strategy]
+		if !ok {
+			summary = &Summary{Strategy: decision.Strategy}
+			byStrategy[decision.Strategy] = summary
+		}
+		summary.add(decision)
+	}
+	summaries := make([]Summary, 0, len(byStrategy))
+	for _, summary := range byStrategy {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Strategy < summaries[j].Strategy })
+	return summaries
+}
+
+// LoadDecisions reads a shadow log; malformed lines are skipped
+func LoadDecisions(path string) ([]Decision, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", path, err)
+	}
+	defer file.Close()
+
+	decisions := []Decision{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var decision Decision
+		if err := json.Unmarshal(scanner.Bytes(), &decision); err != nil {
+			logger.Warn("⚠️ Line skipped", "path", path, "line", line, "error", err)
+			continue
+		}
+		decisions = append(decisions, decision)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return decisions, nil
+}
@@ -0,0 +1,46 @@
+package shadow
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/b-thark/cdcx-api/pkg/market"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+func TestEvaluateRepricesEachLegOnLaterBooks(t *testing.T) {
+	// USDT → BTC → INR → USDT, decided at 1% but the BTC bids have since fallen 2%
+	decision := Decision{
+		Strategy:    "test",
+		StartAmount: 100,
+		StartINR:    90,
+		Legs: []types.TriangularLeg{
+			{Symbol: "BTCUSDT", Pair: "B-BTC_USDT", Side: "buy", Price: 100},
+			{Symbol: "BTCINR", Pair: "I-BTC_INR", Side: "sell", Price: 9090},
+			{Symbol: "USDTINR", Pair: "I-USDT_INR", Side: "buy", Price: 90},
+		},
+		ExpectedReturnPct: 1,
+	}
+	books := &market.Snapshot{CapturedAt: time.Now(), Books: map[string]*market.OrderBook{
+		"B-BTC_USDT": {Asks: []types.OrderLevel{{Price: 100, Volume: 10}}},
+		"I-BTC_INR":  {Bids: []types.OrderLevel{{Price: 8908.2, Volume: 10}}},
+		"I-USDT_INR": {Asks: []types.OrderLevel{{Price: 90, Volume: 1000}}},
+	}}
+
+	Evaluate(&decision, books)
+	if decision.Error != "" {
+		t.Fatal(decision.Error)
+	}
+	if math.Abs(decision.RealizedReturnPct-(-1.02)) > 1e-9 || math.Abs(decision.RealizedProfitINR-(-91.8)) > 1e-9 {
+		t.Errorf("realized %.4f%% (₹%.2f), want -1.02%% (₹-91.80)", decision.RealizedReturnPct, decision.RealizedProfitINR)
+	}
+	if decision.FillRatio != 1 {
+		t.Errorf("fill ratio %.2f, want 1", decision.FillRatio)
+	}
+
+	summaries := Summarize([]Decision{decision, {Strategy: "test", Error: "leg 1: no asks"}})
+	if len(summaries) != 1 || summaries[0].Decisions != 2 || summaries[0].Failed != 1 || summaries[0].Wins != 0 {
+		t.Errorf("summary %+v, want 2 decisions, 1 failed, no wins", summaries)
+	}
+}
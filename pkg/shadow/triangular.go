@@ -0,0 +1,58 @@
+package shadow
+
+import (
+	"net/http"
+
+	"github.com/b-thark/cdcx-api/pkg/opportunity"
+	"github.com/b-thark/cdcx-api/pkg/types"
+)
+
+// triangularStarts are the currencies shadow triangular cycles start from
+var triangularStarts = []string{"USDT", "INR"}
+
+// Triangular decides every viable three-leg cycle through USDT and INR, at
+// the largest size its best levels carry through
+type Triangular struct {
+	detector *opportunity.TriangularDetector
+}
+
+// NewTriangular creates the triangular shadow strategy
+func NewTriangular(config *types.Config) Strategy {
+	return &Triangular{detector: opportunity.NewTriangularDetector(config)}
+}
+
+// SetTransport sends the strategy's requests through transport
+func (t *Triangular) SetTransport(transport http.RoundTripper) {
+	t.detector.SetTransport(transport)
+}
+
+// Name is the strategy's name in SHADOW_STRATEGIES and the log
+func (t *Triangular) Name() string {
+	return "triangular"
+}
+
+// Decide prices every cycle on the live books
+func (t *Triangular) Decide() ([]Decision, error) {
+	opportunities, err := t.detector.FindOpportunities(triangularStarts)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := []Decision{}
+	for _, opp := range opportunities {
+		if !opp.Viable || opp.MaxStart <= 0 {
+			continue
+		}
+		decisions = append(decisions, Decision{
+			Route:             opp.Route(),
+			DecidedAt:         opp.Timestamp,
+			Start:             opp.Start,
+			StartAmount:       opp.MaxStart,
+			StartINR:          opp.MaxStartINR / opp.MaxStart,
+			Legs:              opp.Legs,
+			ExpectedReturnPct: opp.NetReturnPct,
+			ExpectedProfitINR: opp.NetProfitINR,
+		})
+	}
+	return decisions, nil
+}
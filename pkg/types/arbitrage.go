@@ -42,15 +42,35 @@ type ArbitragePairs struct {
 	LastUpdated    time.Time  `json:"last_updated"`
 }
 
+// Candle is one OHLCV bar; Time is the bar's open in Unix milliseconds
+type Candle struct {
+	Time   int64   `json:"time"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume float64 `json:"volume"`
+}
+
 // Exchange Rate Types
 type ExchangeRate struct {
 	FromCurrency string    `json:"from_currency"`
 	ToCurrency   string    `json:"to_currency"`
 	Rate         float64   `json:"rate"`
 	Timestamp    time.Time `json:"timestamp"`
-	Source       string    `json:"source"`
+	Source       string    `json:"source"`               // ticker, book_mid, cross_usdt, fx or pinned
+	Confidence   string    `json:"confidence,omitempty"` // RateConfidenceHigh, Medium or Low
 }
 
+// How far a rate's source can be trusted: a direct market's last trade,
+// then derived ones (book mid, a cross through USDT), then prices from
+// outside the exchange
+const (
+	RateConfidenceHigh   = "high"
+	RateConfidenceMedium = "medium"
+	RateConfidenceLow    = "low"
+)
+
 type ExchangeRateCache struct {
 	Rates       map[string]ExchangeRate `json:"rates"`
 	LastUpdated time.Time               `json:"last_updated"`
@@ -104,6 +124,7 @@ type ArbitrageOpportunity struct {
 	NetMarginPct   float64   `json:"net_margin_pct"`
 	Viable         bool      `json:"viable"`
 	Timestamp      time.Time `json:"timestamp"`
+	Run            *RunInfo  `json:"run,omitempty"`
 }
 
 // Quick Depth Analysis Types (for real-time processing)
@@ -132,6 +153,9 @@ type OrderSimulation struct {
 	NetMargin      float64 `json:"net_margin"`
 	NetMarginPct   float64 `json:"net_margin_pct"`
 	Profitable     bool    `json:"profitable"`
+	// Native prices of the two levels, what an order on each market is placed at
+	BuyLevelPrice  float64 `json:"buy_level_price,omitempty"`
+	SellLevelPrice float64 `json:"sell_level_price,omitempty"`
 	Cumulative     struct {
 		Volume    float64 `json:"volume"`
 		VolumeINR float64 `json:"volume_inr"`
@@ -150,18 +174,150 @@ type ArbitrageDepthAnalysis struct {
 	BottleneckSide        string            `json:"bottleneck_side"`
 	OpportunityRating     string            `json:"opportunity_rating"`
 	Timestamp             time.Time         `json:"timestamp"`
+	BooksAt               time.Time         `json:"books_at"` // When the older of the two order books was read
+	Run                   *RunInfo          `json:"run,omitempty"`
+}
+
+// BooksTime is when the analysis's order books were read; files saved
+// before analyses were stamped fall back to the analysis time
+func (a ArbitrageDepthAnalysis) BooksTime() time.Time {
+	if !a.BooksAt.IsZero() {
+		return a.BooksAt
+	}
+	return a.Timestamp
+}
+
+// Opportunity is the analysis's market pair as a top-of-book opportunity,
+// for executing it without its simulated levels
+func (a ArbitrageDepthAnalysis) Opportunity() ArbitrageOpportunity {
+	opp := ArbitrageOpportunity{
+		TargetCurrency: a.Currency,
+		BuyPriceINR:    a.BuyMarket.BestAskINR,
+		SellPriceINR:   a.SellMarket.BestBidINR,
+		Viable:         true,
+		Timestamp:      a.BooksTime(),
+		Run:            a.Run,
+	}
+	opp.BuyMarket.Symbol, opp.BuyMarket.Pair, opp.BuyMarket.BaseCurrency = a.BuyMarket.Symbol, a.BuyMarket.Pair, a.BuyMarket.BaseCurrency
+	opp.SellMarket.Symbol, opp.SellMarket.Pair, opp.SellMarket.BaseCurrency = a.SellMarket.Symbol, a.SellMarket.Pair, a.SellMarket.BaseCurrency
+	return opp
 }
 
 // Configuration
 type Config struct {
-	MinNetMargin    float64       `json:"min_net_margin"`
-	MinLiquidity    float64       `json:"min_liquidity"`
-	FeeRate         float64       `json:"fee_rate"`
-	MaxOrderLevels  int           `json:"max_order_levels"`
-	CacheDuration   time.Duration `json:"cache_duration"`
-	RateCacheFile   string        `json:"rate_cache_file"`
-	ValidCurrencies []string      `json:"valid_currencies"`
-	EnableAllPairs  bool          `json:"enable_all_pairs"`
+	MinNetMargin    float64       `json:"min_net_margin" env:"MIN_NET_MARGIN" desc:"Minimum net margin percentage after fees for an opportunity to be viable"`
+	MinLiquidity    float64       `json:"min_liquidity" env:"MIN_LIQUIDITY" desc:"Minimum order book liquidity in INR on each leg"`
+	FeeRate         float64       `json:"fee_rate" desc:"Fee buffer per trade as a fraction (0.02 = 2%)"`
+	MaxOrderLevels  int           `json:"max_order_levels" desc:"Order book levels walked during depth analysis"`
+	CacheDuration   time.Duration `json:"cache_duration" desc:"How long fetched exchange rates are reused"`
+	RateCacheFile   string        `json:"rate_cache_file" desc:"File the exchange rate cache is persisted to"`
+	ValidCurrencies []string      `json:"valid_currencies" desc:"Quote currencies considered when detecting pairs"`
+	EnableAllPairs  bool          `json:"enable_all_pairs" env:"ENABLE_ALL_PAIRS" desc:"Include all currency pairs, not just major ones"`
+
+	// When a rate can't be refreshed, reporting conversions (inventory marks,
+	// P&L attribution, analysis output) may fall back to the cached rate up
+	// to this age; detection and execution conversions never do
+	MaxStaleRateMinutes int `json:"max_stale_rate_minutes" env:"MAX_STALE_RATE_MINUTES" desc:"Oldest cached exchange rate reports may use while the exchange can't refresh it; execution always needs a fresh rate (0 disables the fallback)"`
+
+	// Rates come from the direct market's ticker, else its top-of-book mid,
+	// else a cross through USDT, else (for fiat) an external FX API
+	MaxTickerAgeMinutes int    `json:"max_ticker_age_minutes" env:"MAX_TICKER_AGE_MINUTES" desc:"Ignore a market's ticker for rates once it hasn't updated for this long and derive the rate another way (0 disables)"`
+	FXRateURL           string `json:"fx_rate_url,omitempty" env:"FX_RATE_URL" desc:"FX API for fiat rates the exchange doesn't list: {from} is replaced by the currency and the JSON response must hold rates.INR"`
+
+	// MaxQuoteDeviationPct quarantines books whose best prices move further
+	// than this from the ticker last price or the previous snapshot
+	MaxQuoteDeviationPct float64 `json:"max_quote_deviation_pct" env:"MAX_QUOTE_DEVIATION_PCT" desc:"Quarantine order books whose best price deviates more than this percentage from the ticker or previous snapshot (0 disables)"`
+
+	// Price stability: each scan's best prices are compared with their EMA
+	// over recent scans before a spread between them is trusted
+	MaxEMADeviationPct float64 `json:"max_ema_deviation_pct" env:"MAX_EMA_DEVIATION_PCT" desc:"Skip markets whose best price is more than this percentage from its short EMA over recent scans (0 disables)"`
+	PriceEMAPeriod     int     `json:"price_ema_period" desc:"Scans the short price EMA spans; a market needs this much history before it is trusted"`
+	PriceAveragePeriod int     `json:"price_average_period" desc:"Scans in the rolling average of each market's best bid and ask"`
+
+	// Indian taxes on crypto trades, applied by trade simulations on top of the fee
+	GSTRate float64 `json:"gst_rate" desc:"GST charged on exchange fees as a fraction (0.18 = 18%)"`
+	TDSRate float64 `json:"tds_rate" desc:"TDS withheld from sell proceeds as a fraction (0.01 = 1%)"`
+
+	// FeeOverrides replaces FeeRate for markets with their own fee schedule
+	// (promotional zero-fee markets, for example), keyed by market symbol
+	FeeOverrides map[string]float64 `json:"fee_overrides,omitempty" env:"FEE_OVERRIDES" desc:"Per-market fee rates replacing fee_rate, as SYMBOL=rate pairs (BTCUSDT=0,ETHINR=0.001)"`
+
+	// FeeSchedule holds the account's maker and taker rates per market type
+	// (INR-quoted or C2C), configured or detected from its recent trades
+	FeeSchedule map[string]float64 `json:"fee_schedule,omitempty" env:"FEE_SCHEDULE" desc:"Account fee rates by market type replacing fee_rate, as INR_TAKER, INR_MAKER, C2C_TAKER and C2C_MAKER pairs (INR_TAKER=0.005,C2C_TAKER=0.001)"`
+	DetectFees  bool               `json:"detect_fees,omitempty" env:"DETECT_FEES" desc:"Fill in fee_schedule taker rates from the fees the account paid over its last 30 days of trades"`
+
+	// Scheduler guardrails: with a very low MinNetMargin everything looks
+	// viable, and these keep the scan loop from hammering the exchange anyway
+	MinScanIntervalSeconds int `json:"min_scan_interval_seconds" env:"MIN_SCAN_INTERVAL_SECONDS" desc:"Minimum seconds between the starts of two full market scans (never below 5)"`
+	MaxAPICallsPerMinute   int `json:"max_api_calls_per_minute" env:"MAX_API_CALLS_PER_MINUTE" desc:"Exchange request tokens refilled per minute across all components; requests without tokens queue"`
+	APICallBurst           int `json:"api_call_burst" env:"API_CALL_BURST" desc:"Request tokens that may be spent back to back before the per-minute refill paces them (at least 1)"`
+	BookFetchConcurrency   int `json:"book_fetch_concurrency" env:"BOOK_FETCH_CONCURRENCY" desc:"Order books a scan fetches at once; each request still waits for the call budget (at least 1)"`
+
+	// Transient exchange failures (timeouts, 5xx) are retried for every
+	// client before the caller sees them; order creation never is
+	HTTPRetryAttempts     int   `json:"http_retry_attempts" env:"HTTP_RETRY_ATTEMPTS" desc:"Tries per exchange request before a transient failure is returned (1 disables retrying)"`
+	HTTPRetryBackoffMs    int   `json:"http_retry_backoff_ms" env:"HTTP_RETRY_BACKOFF_MS" desc:"Wait before the first retry in milliseconds, doubling per retry with jitter"`
+	HTTPRetryMaxBackoffMs int   `json:"http_retry_max_backoff_ms" env:"HTTP_RETRY_MAX_BACKOFF_MS" desc:"Longest wait between retries in milliseconds"`
+	HTTPRetryStatuses     []int `json:"http_retry_statuses" env:"HTTP_RETRY_STATUSES" desc:"Response statuses retried as transient, comma separated (429 is handled by the call budget)"`
+
+	// QuoteScanIntervals scans the pairs quoted in some currencies on their
+	// own cadence, since INR and USDT books move differently
+	QuoteScanIntervals map[string]int `json:"quote_scan_intervals,omitempty" env:"QUOTE_SCAN_INTERVALS" desc:"Seconds between scans of the pairs quoted in a currency, as QUOTE=seconds pairs (INR=30,USDT=10); other quotes follow min_scan_interval_seconds and none goes below 5"`
+
+	// APICallWeights sets how many tokens requests to an endpoint path (and
+	// the paths under it) cost, on top of the built-in weights
+	APICallWeights map[string]int `json:"api_call_weights,omitempty" desc:"Token cost per exchange endpoint path prefix, e.g. {\"/exchange/ticker\": 5}; unlisted paths cost 1"`
+
+	// StreamOrderBooks keeps books live over the exchange websocket instead
+	// of polling the REST endpoint for each one
+	StreamOrderBooks bool `json:"stream_order_books" env:"STREAM_ORDER_BOOKS" desc:"Stream order books over the exchange websocket and rescan a currency as soon as its books change"`
+
+	// Shadow strategies decide alongside the live 2-leg strategy; their
+	// decisions are logged and scored against later books, never executed
+	ShadowStrategies      []string `json:"shadow_strategies,omitempty" env:"SHADOW_STRATEGIES" desc:"Strategies cdcx live runs in shadow mode, comma separated (triangular)"`
+	ShadowIntervalSeconds int      `json:"shadow_interval_seconds" env:"SHADOW_INTERVAL_SECONDS" desc:"Seconds between shadow strategy decisions"`
+	ShadowHorizonSeconds  int      `json:"shadow_horizon_seconds" env:"SHADOW_HORIZON_SECONDS" desc:"Seconds after a shadow decision its trades are re-priced on the books of the time"`
+}
+
+// Floors applied whatever the configuration says
+const (
+	MinScanIntervalFloor = 5 * time.Second
+	MaxAPICallsCeiling   = 1200
+)
+
+// ScanInterval is the configured minimum time between scans, never below MinScanIntervalFloor
+func (c *Config) ScanInterval() time.Duration {
+	interval := time.Duration(c.MinScanIntervalSeconds) * time.Second
+	if interval < MinScanIntervalFloor {
+		return MinScanIntervalFloor
+	}
+	return interval
+}
+
+// QuoteScanInterval is how often pairs quoted in quote are scanned: its
+// QuoteScanIntervals entry when set, otherwise ScanInterval, never below
+// MinScanIntervalFloor
+func (c *Config) QuoteScanInterval(quote string) time.Duration {
+	seconds, ok := c.QuoteScanIntervals[quote]
+	if !ok {
+		return c.ScanInterval()
+	}
+	return max(time.Duration(seconds)*time.Second, MinScanIntervalFloor)
+}
+
+// HTTPRetryBackoff is the configured first and longest retry waits
+func (c *Config) HTTPRetryBackoff() (time.Duration, time.Duration) {
+	return time.Duration(c.HTTPRetryBackoffMs) * time.Millisecond, time.Duration(c.HTTPRetryMaxBackoffMs) * time.Millisecond
+}
+
+// APICallBudget is the configured per-minute call budget, capped at
+// MaxAPICallsCeiling; zero or negative means the ceiling
+func (c *Config) APICallBudget() int {
+	if c.MaxAPICallsPerMinute <= 0 || c.MaxAPICallsPerMinute > MaxAPICallsCeiling {
+		return MaxAPICallsCeiling
+	}
+	return c.MaxAPICallsPerMinute
 }
 
 // Default configuration
@@ -175,69 +331,314 @@ func DefaultConfig() *Config {
 		RateCacheFile:   "exchange_rates.json",
 		ValidCurrencies: []string{"INR", "USDT", "BTC", "ETH", "BNB", "BUSD", "USDC"},
 		EnableAllPairs:  false,
+
+		MaxStaleRateMinutes: 60,
+		MaxTickerAgeMinutes: 10,
+
+		MaxQuoteDeviationPct: 30.0,
+
+		GSTRate: 0.18,
+		TDSRate: 0.01,
+
+		PriceEMAPeriod:     5,
+		PriceAveragePeriod: 20,
+
+		MinScanIntervalSeconds: 15,
+		MaxAPICallsPerMinute:   600,
+		APICallBurst:           20,
+		BookFetchConcurrency:   8,
+
+		HTTPRetryAttempts:     3,
+		HTTPRetryBackoffMs:    250,
+		HTTPRetryMaxBackoffMs: 2000,
+		HTTPRetryStatuses:     []int{500, 502, 503, 504},
+
+		ShadowIntervalSeconds: 60,
+		ShadowHorizonSeconds:  10,
 	}
 }
 
 // Execution Configuration
 type ExecutionConfig struct {
-	MaxPositionUSDT     float64 `json:"max_position_usdt"`     // Maximum position size in USDT
-	MinRequiredUSDT     float64 `json:"min_required_usdt"`     // Minimum USDT balance required
-	StopLossPct         float64 `json:"stop_loss_pct"`         // Stop loss threshold percentage
-	OrderTimeoutSeconds int     `json:"order_timeout_seconds"` // Order fill timeout
-	DelayBetweenOrders  int     `json:"delay_between_orders"`  // Delay between orders in milliseconds
-	UseMarketOrders     bool    `json:"use_market_orders"`     // Use market orders vs limit orders
-	MaxOrdersPerRun     int     `json:"max_orders_per_run"`    // Maximum orders to execute per run
-	RiskToleranceLevel  string  `json:"risk_tolerance_level"`  // conservative, moderate, aggressive
+	MaxPositionUSDT         float64 `json:"max_position_usdt" env:"MAX_POSITION_USDT" desc:"Maximum position size in USDT"`
+	MinRequiredUSDT         float64 `json:"min_required_usdt" desc:"Minimum USDT balance required"`
+	StopLossPct             float64 `json:"stop_loss_pct" env:"STOP_LOSS_PCT" desc:"Stop loss threshold percentage"`
+	OrderTimeoutSeconds     int     `json:"order_timeout_seconds" desc:"Order fill timeout in seconds"`
+	DelayBetweenOrders      int     `json:"delay_between_orders" desc:"Delay between orders in milliseconds"`
+	UseMarketOrders         bool    `json:"use_market_orders" env:"USE_MARKET_ORDERS" desc:"Market orders, or (false) limit orders at the validated level price that are repriced or cancelled after a timeout"`
+	MaxOrdersPerRun         int     `json:"max_orders_per_run" desc:"Maximum orders to execute per run"`
+	RiskToleranceLevel      string  `json:"risk_tolerance_level" desc:"Risk profile: conservative, moderate or aggressive"`
+	ExecutionPolicy         string  `json:"execution_policy" env:"EXECUTION_POLICY" desc:"sequential (buy then sell) or atomic (both legs at once, cancel on partial)"`
+	AtomicTimeInForce       string  `json:"atomic_time_in_force" desc:"immediate_or_cancel or fill_or_kill for atomic legs"`
+	ProtectiveStopPct       float64 `json:"protective_stop_pct" env:"PROTECTIVE_STOP_PCT" desc:"Stop-limit below each buy fill until the sell leg takes the coins (0 disables)"`
+	MaxSlices               int     `json:"max_slices" desc:"Max timed slices when size exceeds top of book (1 disables)"`
+	SliceIntervalMs         int     `json:"slice_interval_ms" desc:"Delay between slices in milliseconds"`
+	PrevalidationIntervalMs int     `json:"prevalidation_interval_ms" desc:"Background re-validation cadence while waiting for the execution lock"`
+	MaxValidationAgeMs      int     `json:"max_validation_age_ms" desc:"Re-validate before executing if the last check is older than this"`
+	MaxAnalysisAgeSeconds   int     `json:"max_analysis_age_seconds" env:"MAX_ANALYSIS_AGE_SECONDS" desc:"cdcx execute refuses depth analyses whose order books were read longer ago than this (0 disables)"`
+	QueueTTLSeconds         int     `json:"queue_ttl_seconds" env:"QUEUE_TTL_SECONDS" desc:"How long a queued opportunity waits, across restarts too, without being detected again before it is dropped as stale"`
+	ExecutionWorkers        int     `json:"execution_workers" env:"EXECUTION_WORKERS" desc:"Workers taking queued opportunities; one holds the execution lock while the others re-validate theirs (at least 1)"`
+	MaxQueuedOpportunities  int     `json:"max_queued_opportunities" env:"MAX_QUEUED_OPPORTUNITIES" desc:"Opportunities that may wait for the execution lock; when full the lowest-margin one is dropped (0 disables the bound)"`
+	FundingCurrency         string  `json:"funding_currency" env:"FUNDING_CURRENCY" desc:"Currency the account trades from: USDT or INR"`
+	MaxImpactMarginShare    float64 `json:"max_impact_margin_share" env:"MAX_IMPACT_MARGIN_SHARE" desc:"Reject sizes whose estimated price impact on both legs would eat more than this fraction of the expected margin (0 disables)"`
+	MinSellDepthRatio       float64 `json:"min_sell_depth_ratio" env:"MIN_SELL_DEPTH_RATIO" desc:"Require the sell market's top five bid levels to hold this multiple of the volume bought (0 disables)"`
+	DryRun                  bool    `json:"dry_run" env:"DRY_RUN" desc:"Simulate fills against live order books (slippage and fees included) instead of placing orders"`
+	ConfirmTrades           string  `json:"confirm_trades" env:"CONFIRM_TRADES" desc:"Preview both legs before placing and ask at a terminal: off, trade (every trade) or session (once, then unattended)"`
+
+	// Limit-order legs (UseMarketOrders=false): each leg works at the validated
+	// price, then its unfilled remainder is re-placed at the current best level
+	LimitTimeInForce         string  `json:"limit_time_in_force" env:"LIMIT_TIME_IN_FORCE" desc:"immediate_or_cancel (take what the level holds now) or good_till_cancel (rest until the timeout) for limit legs"`
+	LimitOrderTimeoutSeconds int     `json:"limit_order_timeout_seconds" env:"LIMIT_ORDER_TIMEOUT_SECONDS" desc:"Seconds a limit leg works before its remainder is cancelled and repriced"`
+	MaxReprices              int     `json:"max_reprices" env:"MAX_REPRICES" desc:"Times a limit leg's unfilled remainder is re-placed at the current best level (0 disables)"`
+	MaxRepricePct            float64 `json:"max_reprice_pct" env:"MAX_REPRICE_PCT" desc:"Price protection: a limit leg is never repriced further than this percent past its validated price"`
+
+	// Lock-in exit (market orders): after the buy fills, the sell rests as a
+	// limit at the price that still nets LockInMarginPct before going to market
+	LockInSeconds   int     `json:"lock_in_seconds" env:"LOCK_IN_SECONDS" desc:"Sell the bought volume at a limit locking lock_in_margin_pct for this many seconds, then sell what is left at market (0 disables)"`
+	LockInMarginPct float64 `json:"lock_in_margin_pct" env:"LOCK_IN_MARGIN_PCT" desc:"Net margin over the buy fill and both legs' fees that the lock-in limit sell is priced to keep"`
+
+	// Depth execution: trade each profitable level the depth analysis simulated
+	// with its own orders instead of only the best level
+	DepthExecution bool `json:"depth_execution" env:"DEPTH_EXECUTION" desc:"cdcx execute places sized limit orders per simulated depth level, stopping once a level's realized net margin drops below min_net_margin"`
+
+	// Hard cap on what a single trade can lose, whatever the stop-loss settings
+	MaxTradeLossINR float64 `json:"max_trade_loss_inr,omitempty" env:"MAX_TRADE_LOSS_INR" desc:"Refuse trades whose worst case (buy slipped, sell failed, recovered at current bids, fees) would lose more than this many INR (0 disables)"`
+	WorstCaseLevels int     `json:"worst_case_levels" env:"WORST_CASE_LEVELS" desc:"Ask levels the worst-case buy is assumed to slip through"`
+
+	// Sell legs that are unlikely to fill strand inventory: size them down or skip them
+	MinFillProbability float64 `json:"min_fill_probability" env:"MIN_FILL_PROBABILITY" desc:"Halve the volume (up to four times) until the sell leg's estimated fill probability reaches this, else refuse the trade (0 disables)"`
+	FillModelFile      string  `json:"fill_model_file,omitempty" env:"FILL_MODEL_FILE" desc:"Logistic fill model (intercept and coefficients over coverage, spread_pct, imbalance, timeout_seconds) to use instead of the depth heuristic"`
+
+	// Failed or badly deviating executions save the books, balances and order statuses right after
+	FailureSnapshots     bool    `json:"failure_snapshots" env:"FAILURE_SNAPSHOTS" desc:"Save fresh books, balances and order statuses when an execution that placed orders fails"`
+	SnapshotShortfallPct float64 `json:"snapshot_shortfall_pct" env:"SNAPSHOT_SHORTFALL_PCT" desc:"Also snapshot successful executions whose profit falls this many percent short of expected (0 disables)"`
+
+	// Session mode: keep scanning until a limit is hit, then flatten and stop
+	SessionMinutes         int     `json:"session_minutes,omitempty" env:"SESSION_MINUTES" desc:"Trade in repeated passes for this many minutes, then flatten inventory and stop (0 with no targets runs a single pass)"`
+	SessionProfitTargetINR float64 `json:"session_profit_target_inr,omitempty" env:"SESSION_PROFIT_TARGET_INR" desc:"End the session once realized P&L reaches this many INR (0 disables)"`
+	SessionLossLimitINR    float64 `json:"session_loss_limit_inr,omitempty" env:"SESSION_LOSS_LIMIT_INR" desc:"End the session once realized losses reach this many INR (0 disables)"`
+
+	// Daemon mode: a session without limits that keeps scanning while trades
+	// execute and only ends when the process is stopped
+	RunForever          bool `json:"run_forever,omitempty" env:"RUN_FOREVER" desc:"Keep scanning and trading until stopped; inventory is kept for the next start instead of flattened"`
+	PairsRefreshMinutes int  `json:"pairs_refresh_minutes" env:"PAIRS_REFRESH_MINUTES" desc:"In session mode, re-extract arbitrage pairs from the exchange's markets this often (0 disables)"`
+
+	// Between session passes, stranded inventory and dust go back through the recovery planner
+	RecoverySweepMinutes int     `json:"recovery_sweep_minutes" env:"RECOVERY_SWEEP_MINUTES" desc:"In session mode, sweep held inventory and dust through the recovery planner this often (0 disables)"`
+	RecoverySweepMinINR  float64 `json:"recovery_sweep_min_inr" env:"RECOVERY_SWEEP_MIN_INR" desc:"Leave positions and dust worth less than this many INR for a later sweep (0 sweeps everything sellable)"`
+}
+
+// RecoverySweepInterval is how often session mode sweeps stranded assets; zero disables
+func (e *ExecutionConfig) RecoverySweepInterval() time.Duration {
+	return time.Duration(max(e.RecoverySweepMinutes, 0)) * time.Minute
 }
 
 // Default execution configuration
 func DefaultExecutionConfig() *ExecutionConfig {
 	return &ExecutionConfig{
-		MaxPositionUSDT:     100.0, // Start with $100 max position
-		MinRequiredUSDT:     10.0,  // Require at least $10 USDT
-		StopLossPct:         3.0,   // 3% stop loss as requested
-		OrderTimeoutSeconds: 30,    // 30 second timeout per order
-		DelayBetweenOrders:  2000,  // 2 second delay between orders
-		UseMarketOrders:     true,  // Use market orders for immediate execution
-		MaxOrdersPerRun:     5,     // Limit to 5 orders per run initially
-		RiskToleranceLevel:  "conservative",
+		MaxPositionUSDT:         100.0, // Start with $100 max position
+		MinRequiredUSDT:         10.0,  // Require at least $10 USDT
+		StopLossPct:             3.0,   // 3% stop loss as requested
+		OrderTimeoutSeconds:     30,    // 30 second timeout per order
+		DelayBetweenOrders:      2000,  // 2 second delay between orders
+		UseMarketOrders:         true,  // Use market orders for immediate execution
+		MaxOrdersPerRun:         5,     // Limit to 5 orders per run initially
+		RiskToleranceLevel:      "conservative",
+		ExecutionPolicy:         "sequential",
+		AtomicTimeInForce:       "immediate_or_cancel",
+		MaxSlices:               5,    // Up to 5 slices for oversized trades
+		SliceIntervalMs:         1500, // 1.5 seconds between slices
+		PrevalidationIntervalMs: 1000,
+		MaxValidationAgeMs:      1500,
+		MaxAnalysisAgeSeconds:   300, // Long enough to review the depth results before executing
+		QueueTTLSeconds:         60,
+		ExecutionWorkers:        2,
+		MaxQueuedOpportunities:  8,
+		FundingCurrency:         "USDT",
+		MaxImpactMarginShare:    0.5, // Walking the books may cost at most half the margin
+		MinSellDepthRatio:       2,   // Room for the bids to thin out between the buy and the sell
+		ConfirmTrades:           "off",
+		RecoverySweepMinutes:    15,
+		RecoverySweepMinINR:     100, // Smaller leftovers aren't worth the fees and API calls
+
+		LimitTimeInForce:         "immediate_or_cancel",
+		LimitOrderTimeoutSeconds: 5,
+		MaxReprices:              2,
+		MaxRepricePct:            0.3, // Beyond that the margin is usually gone
+
+		LockInMarginPct: 0.5,
+
+		WorstCaseLevels: 5,
+
+		MinFillProbability: 0.5,
+
+		FailureSnapshots:     true,
+		SnapshotShortfallPct: 50,
+
+		PairsRefreshMinutes: 60,
 	}
 }
 
 // Executed Order Result
 type ExecutedOrder struct {
-	OrderNumber     int       `json:"order_number"`
-	Currency        string    `json:"currency"`
-	BuyMarket       string    `json:"buy_market"`
-	SellMarket      string    `json:"sell_market"`
-	BuyOrderID      string    `json:"buy_order_id"`
-	SellOrderID     string    `json:"sell_order_id"`
-	PlannedVolume   float64   `json:"planned_volume"`
-	VolumeExecuted  float64   `json:"volume_executed"`
-	BuyPrice        float64   `json:"buy_price"`
-	SellPrice       float64   `json:"sell_price"`
-	ExpectedProfit  float64   `json:"expected_profit"`
-	ActualProfit    float64   `json:"actual_profit"`
-	ActualMarginPct float64   `json:"actual_margin_pct"`
-	Success         bool      `json:"success"`
-	ErrorMessage    string    `json:"error_message,omitempty"`
-	StartTime       time.Time `json:"start_time"`
-	EndTime         time.Time `json:"end_time"`
-	ExecutionTimeMs int64     `json:"execution_time_ms"`
+	ExecutionID     string             `json:"execution_id,omitempty"` // Ties the attempt's log lines, events, API calls and records together
+	OrderNumber     int                `json:"order_number"`
+	Currency        string             `json:"currency"`
+	BuyMarket       string             `json:"buy_market"`
+	SellMarket      string             `json:"sell_market"`
+	BuyOrderID      string             `json:"buy_order_id"`
+	SellOrderID     string             `json:"sell_order_id"`
+	StopOrderID     string             `json:"stop_order_id,omitempty"`
+	LimitOrderIDs   []string           `json:"limit_order_ids,omitempty"` // Every order the limit legs placed, reprices included
+	PlannedVolume   float64            `json:"planned_volume"`
+	VolumeExecuted  float64            `json:"volume_executed"`
+	BuyPrice        float64            `json:"buy_price"`
+	SellPrice       float64            `json:"sell_price"`
+	ExpectedProfit  float64            `json:"expected_profit"`
+	ActualProfit    float64            `json:"actual_profit"`
+	ActualMarginPct float64            `json:"actual_margin_pct"`
+	Success         bool               `json:"success"`
+	ErrorMessage    string             `json:"error_message,omitempty"`
+	StartTime       time.Time          `json:"start_time"`
+	EndTime         time.Time          `json:"end_time"`
+	ExecutionTimeMs int64              `json:"execution_time_ms"`
+	CapitalUSDT     float64            `json:"capital_usdt,omitempty"` // What the buy leg tied up, valued in USDT
+	Slices          []SliceFill        `json:"slices,omitempty"`
+	DecisionBooks   *BookSnapshot      `json:"decision_books,omitempty"`
+	Attribution     *ProfitAttribution `json:"attribution,omitempty"`
+	Latency         PhaseLatency       `json:"latency"`
+	Annotation      *TradeAnnotation   `json:"annotation,omitempty"`
+}
+
+// TradeNote is a free-form operator note on an execution
+type TradeNote struct {
+	Text      string    `json:"text"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TradeAnnotation holds operator notes and manual-intervention marks for an
+// execution, keyed by its buy order ID
+type TradeAnnotation struct {
+	OrderID      string      `json:"order_id"`
+	Notes        []TradeNote `json:"notes,omitempty"`
+	Manual       bool        `json:"manual"`                   // Resolved by hand outside the system
+	ManualPnLINR float64     `json:"manual_pnl_inr,omitempty"` // Operator-reported P&L replacing the automated figure
+	ManualAt     time.Time   `json:"manual_at,omitempty"`
+}
+
+// PhaseLatency records milliseconds spent in each execution phase
+type PhaseLatency struct {
+	ValidationMs int64 `json:"validation_ms"`
+	BuyPlaceMs   int64 `json:"buy_place_ms"`
+	BuyFillMs    int64 `json:"buy_fill_ms"`
+	SellPlaceMs  int64 `json:"sell_place_ms"`
+	SellFillMs   int64 `json:"sell_fill_ms"`
+}
+
+// LatencyPercentiles summarizes one execution phase across many trades
+type LatencyPercentiles struct {
+	Count int   `json:"count"`
+	P50   int64 `json:"p50_ms"`
+	P90   int64 `json:"p90_ms"`
+	P99   int64 `json:"p99_ms"`
+	Max   int64 `json:"max_ms"`
+}
+
+// ProfitAttribution splits realized P&L (INR) into the spread captured at
+// decision time and price drift while the orders were executing
+type ProfitAttribution struct {
+	SpreadCaptured float64 `json:"spread_captured"` // (decision sell - decision buy) × volume
+	BuyDrift       float64 `json:"buy_drift"`       // decision buy - fill buy; negative means we paid more
+	SellDrift      float64 `json:"sell_drift"`      // fill sell - decision sell; negative means we received less
+	Fees           float64 `json:"fees"`
+	Realized       float64 `json:"realized"`
+}
+
+// SliceFill records one timed slice of an execution split across book refreshes
+type SliceFill struct {
+	Slice          int       `json:"slice"`
+	PlannedVolume  float64   `json:"planned_volume"`
+	VolumeExecuted float64   `json:"volume_executed"`
+	BuyPrice       float64   `json:"buy_price"`
+	SellPrice      float64   `json:"sell_price"`
+	MarginPct      float64   `json:"margin_pct"`
+	ActualProfit   float64   `json:"actual_profit"`
+	Success        bool      `json:"success"`
+	ErrorMessage   string    `json:"error_message,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// BookSnapshot is the top of both order books as validation saw them when an
+// execution was decided, kept with the record so fills can be compared with
+// the depth that was actually available
+type BookSnapshot struct {
+	BuyMarket  string       `json:"buy_market"`
+	SellMarket string       `json:"sell_market"`
+	BuyAsks    []OrderLevel `json:"buy_asks"`  // Best first
+	SellBids   []OrderLevel `json:"sell_bids"` // Best first
+	CapturedAt time.Time    `json:"captured_at"`
+}
+
+// BookSnapshotDepth is how many levels of each book an execution record keeps
+const BookSnapshotDepth = 5
+
+// NewBookSnapshot keeps the best depth levels of each side, copied so later
+// book updates don't alter the record
+func NewBookSnapshot(buyMarket, sellMarket string, asks, bids []OrderLevel, depth int) *BookSnapshot {
+	top := func(levels []OrderLevel) []OrderLevel {
+		if len(levels) > depth {
+			levels = levels[:depth]
+		}
+		return append([]OrderLevel{}, levels...)
+	}
+	return &BookSnapshot{
+		BuyMarket:  buyMarket,
+		SellMarket: sellMarket,
+		BuyAsks:    top(asks),
+		SellBids:   top(bids),
+		CapturedAt: time.Now(),
+	}
+}
+
+// Position is inventory held outside an arbitrage cycle, e.g. after a failed sell leg
+type Position struct {
+	Currency   string    `json:"currency"`
+	Quantity   float64   `json:"quantity"`
+	CostINR    float64   `json:"cost_inr"` // Total acquisition cost including fees
+	Source     string    `json:"source"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// PositionValuation marks a position to live prices
+type PositionValuation struct {
+	Position
+	MarkPriceINR  float64 `json:"mark_price_inr"`
+	ValueINR      float64 `json:"value_inr"`
+	UnrealizedINR float64 `json:"unrealized_inr"`
+	UnrealizedPct float64 `json:"unrealized_pct"`
 }
 
 // Complete Execution Result
 type ExecutionResult struct {
-	Currency        string          `json:"currency"`
-	BuyMarket       string          `json:"buy_market"`
-	SellMarket      string          `json:"sell_market"`
-	StartTime       time.Time       `json:"start_time"`
-	EndTime         time.Time       `json:"end_time"`
-	TotalProfit     float64         `json:"total_profit"`
-	TotalVolume     float64         `json:"total_volume"`
-	TotalInvestment float64         `json:"total_investment"`
-	Orders          []ExecutedOrder `json:"orders"`
-	Successful      bool            `json:"successful"`
-	Timestamp       time.Time       `json:"timestamp"`
-	Config          ExecutionConfig `json:"config"`
+	Currency        string                        `json:"currency"`
+	BuyMarket       string                        `json:"buy_market"`
+	SellMarket      string                        `json:"sell_market"`
+	StartTime       time.Time                     `json:"start_time"`
+	EndTime         time.Time                     `json:"end_time"`
+	TotalProfit     float64                       `json:"total_profit"`
+	TotalVolume     float64                       `json:"total_volume"`
+	TotalInvestment float64                       `json:"total_investment"`
+	Orders          []ExecutedOrder               `json:"orders"`
+	Successful      bool                          `json:"successful"`
+	Timestamp       time.Time                     `json:"timestamp"`
+	Config          ExecutionConfig               `json:"config"`
+	LatencySummary  map[string]LatencyPercentiles `json:"latency_summary,omitempty"`
+	Inventory       []PositionValuation           `json:"inventory,omitempty"`
+	UnrealizedPnL   float64                       `json:"unrealized_pnl"`
+	Run             *RunInfo                      `json:"run,omitempty"` // Build and config that produced the log
+
+	// Spendable funding in USDT at the last balance check before the
+	// execution, what its orders' capital is measured against
+	AvailableCapitalUSDT float64 `json:"available_capital_usdt,omitempty"`
 }
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ParamDoc documents one configuration parameter
+type ParamDoc struct {
+	Section     string `json:"section"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Env         string `json:"env,omitempty"`
+	Description string `json:"description"`
+}
+
+// ConfigReference documents every trading and execution parameter from the
+// struct tags on Config and ExecutionConfig, with their built-in defaults
+func ConfigReference() []ParamDoc {
+	docs := Explain("trading", DefaultConfig())
+	return append(docs, Explain("execution", DefaultExecutionConfig())...)
+}
+
+// Explain documents the fields of a config struct (or pointer to one) using
+// its json, env and desc tags and the values in v as defaults
+func Explain(section string, v interface{}) []ParamDoc {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	structType := value.Type()
+
+	docs := []ParamDoc{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+
+		docs = append(docs, ParamDoc{
+			Section:     section,
+			Name:        name,
+			Type:        field.Type.String(),
+			Default:     fmt.Sprintf("%v", value.Field(i).Interface()),
+			Env:         field.Tag.Get("env"),
+			Description: field.Tag.Get("desc"),
+		})
+	}
+	return docs
+}
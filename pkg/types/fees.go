@@ -0,0 +1,116 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Market types fee schedules distinguish
+const (
+	MarketTypeINR = "INR" // Quoted in INR
+	MarketTypeC2C = "C2C" // Crypto to crypto: quoted in USDT, BTC and the like
+)
+
+// FeeMarketType is the fee schedule market type of a CoinDCX symbol
+// ("BTCINR", "ETHUSDT"). No coin ticker ends in INR, so the suffix is enough
+// to tell them apart without the market list.
+func FeeMarketType(symbol string) string {
+	if strings.HasSuffix(strings.ToUpper(symbol), "INR") {
+		return MarketTypeINR
+	}
+	return MarketTypeC2C
+}
+
+// FeeRateFor returns the fee rate charged on the given market: its override,
+// else its market type's taker rate, else FeeRate. Margins are computed at
+// taker rates because every leg takes liquidity.
+func (c *Config) FeeRateFor(symbol string) float64 {
+	if rate, ok := c.KnownFeeRate(symbol); ok {
+		return rate
+	}
+	return c.FeeRate
+}
+
+// KnownFeeRate is FeeRateFor without the flat FeeRate buffer: false when
+// neither an override nor the fee schedule covers symbol
+func (c *Config) KnownFeeRate(symbol string) (float64, bool) {
+	if rate, ok := c.FeeOverrides[symbol]; ok {
+		return rate, true
+	}
+	rate, ok := c.FeeSchedule[FeeMarketType(symbol)+"_TAKER"]
+	return rate, ok
+}
+
+// MakerFeeRateFor returns the rate for an order resting on symbol's book:
+// its market type's maker rate, else the FeeRateFor rate
+func (c *Config) MakerFeeRateFor(symbol string) float64 {
+	if _, ok := c.FeeOverrides[symbol]; !ok {
+		if rate, ok := c.FeeSchedule[FeeMarketType(symbol)+"_MAKER"]; ok {
+			return rate
+		}
+	}
+	return c.FeeRateFor(symbol)
+}
+
+// ParseFeeOverrides parses a comma-separated SYMBOL=rate list
+func ParseFeeOverrides(s string) (map[string]float64, error) {
+	overrides := map[string]float64{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		symbol, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("fee override %q is not SYMBOL=rate", entry)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate < 0 || rate >= 1 {
+			return nil, fmt.Errorf("fee override %q needs a rate between 0 and 1", entry)
+		}
+		overrides[strings.ToUpper(strings.TrimSpace(symbol))] = rate
+	}
+	return overrides, nil
+}
+
+// ParseStatusCodes parses "502,503" into HTTP status codes
+func ParseStatusCodes(s string) ([]int, error) {
+	codes := []int{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		code, err := strconv.Atoi(entry)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("status %q is not an HTTP status code", entry)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// ParseQuoteScanIntervals parses "INR=30,USDT=10" into per-quote scan
+// intervals in seconds
+func ParseQuoteScanIntervals(s string) (map[string]int, error) {
+	intervals := map[string]int{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		quote, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("scan interval %q is not QUOTE=seconds", entry)
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("scan interval %q needs a positive number of seconds", entry)
+		}
+		intervals[strings.ToUpper(strings.TrimSpace(quote))] = seconds
+	}
+	return intervals, nil
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"fmt"
+	"math"
+)
+
+// InvalidValueError is a price, volume or rate a calculation refused to use:
+// zero, negative, NaN or infinite. Dividing by one would put NaN or Inf into
+// margins and saved logs.
+type InvalidValueError struct {
+	Field string
+	Value float64
+}
+
+func (e *InvalidValueError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Field, e.Value)
+}
+
+// RequirePositive returns an *InvalidValueError unless value is positive and finite
+func RequirePositive(field string, value float64) error {
+	if value > 0 && !math.IsInf(value, 1) {
+		return nil
+	}
+	return &InvalidValueError{Field: field, Value: value}
+}
+
+// PercentOf returns part as a percentage of whole. A whole that isn't
+// positive and finite (the field named) is an *InvalidValueError.
+func PercentOf(part, whole float64, field string) (float64, error) {
+	if err := RequirePositive(field, whole); err != nil {
+		return 0, err
+	}
+	if math.IsNaN(part) || math.IsInf(part, 0) {
+		return 0, &InvalidValueError{Field: field + " share", Value: part}
+	}
+	return part / whole * 100, nil
+}
@@ -0,0 +1,23 @@
+package types
+
+// RunInfo identifies the code and parameters that produced an artifact
+type RunInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	ConfigHash string `json:"config_hash,omitempty"` // Short hash of the trading and execution config in effect
+}
+
+// Label is a compact "version@commit/config" form for reports and logs
+func (r *RunInfo) Label() string {
+	if r == nil {
+		return "unknown"
+	}
+	label := r.Version
+	if r.Commit != "" {
+		label += "@" + r.Commit
+	}
+	if r.ConfigHash != "" {
+		label += "/" + r.ConfigHash
+	}
+	return label
+}
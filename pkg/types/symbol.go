@@ -0,0 +1,72 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Symbol is the canonical, exchange-agnostic identifier of a market: the coin
+// traded (Base) and the currency it is priced in (Quote), e.g. RENDER/INR.
+//
+// CoinDCX names the same market three ways (coindcx_name and symbol
+// "RENDERINR", pair "B-RENDER_INR") and calls the pricing currency the
+// "base" currency and the coin the "target". Code should compare markets by
+// Symbol and resolve CoinDCX names through market.Markets rather than
+// concatenating or substring-matching them.
+type Symbol struct {
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+}
+
+// NewSymbol creates a symbol, normalizing case
+func NewSymbol(base, quote string) Symbol {
+	return Symbol{Base: strings.ToUpper(base), Quote: strings.ToUpper(quote)}
+}
+
+// String is the canonical "BASE/QUOTE" form
+func (s Symbol) String() string {
+	return s.Base + "/" + s.Quote
+}
+
+// Code is CoinDCX's usual symbol for the market (BASEQUOTE). Only use it
+// where no market list is available; market.Markets knows the real names.
+func (s Symbol) Code() string {
+	return s.Base + s.Quote
+}
+
+// ParseSymbol reads the canonical "BASE/QUOTE" form or a CoinDCX pair
+// ("B-RENDER_INR"). CoinDCX symbols like "RENDERINR" can't be split without
+// the market list; resolve those with market.Markets.
+func ParseSymbol(name string) (Symbol, error) {
+	if base, quote, ok := strings.Cut(name, "/"); ok && base != "" && quote != "" {
+		return NewSymbol(base, quote), nil
+	}
+
+	// Pair: <exchange code>-<BASE>_<QUOTE>
+	if _, rest, ok := strings.Cut(name, "-"); ok {
+		if base, quote, ok := strings.Cut(rest, "_"); ok && base != "" && quote != "" {
+			return NewSymbol(base, quote), nil
+		}
+	}
+	return Symbol{}, fmt.Errorf("cannot parse market name %q", name)
+}
+
+// Canonical is the market's exchange-agnostic symbol
+func (m MarketDetail) Canonical() Symbol {
+	return NewSymbol(m.TargetCurrencyShortName, m.BaseCurrencyShortName)
+}
+
+// Canonical is the pair's exchange-agnostic symbol
+func (p PairInfo) Canonical() Symbol {
+	return NewSymbol(p.TargetCurrency, p.BaseCurrency)
+}
+
+// BuySymbol is the canonical symbol of the market the coin is bought on
+func (o ArbitrageOpportunity) BuySymbol() Symbol {
+	return NewSymbol(o.TargetCurrency, o.BuyMarket.BaseCurrency)
+}
+
+// SellSymbol is the canonical symbol of the market the coin is sold on
+func (o ArbitrageOpportunity) SellSymbol() Symbol {
+	return NewSymbol(o.TargetCurrency, o.SellMarket.BaseCurrency)
+}
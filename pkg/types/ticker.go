@@ -0,0 +1,110 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// TickerStats is one market's entry from /exchange/ticker: last trade, top
+// of book and rolling 24h statistics. Prices are in the market's quote
+// currency; zero means the exchange didn't report the value.
+type TickerStats struct {
+	Market    string  `json:"market"` // Exchange symbol, e.g. RENDERINR
+	LastPrice float64 `json:"last_price"`
+	Bid       float64 `json:"bid"`
+	Ask       float64 `json:"ask"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Volume    float64 `json:"volume"`         // 24h volume
+	Change24h float64 `json:"change_24_hour"` // 24h price change, percent
+	Timestamp int64   `json:"timestamp"`      // Unix seconds
+}
+
+// UnmarshalJSON accepts the exchange's mix of quoted and bare numbers
+func (t *TickerStats) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Market    string      `json:"market"`
+		LastPrice tickerValue `json:"last_price"`
+		Bid       tickerValue `json:"bid"`
+		Ask       tickerValue `json:"ask"`
+		High      tickerValue `json:"high"`
+		Low       tickerValue `json:"low"`
+		Volume    tickerValue `json:"volume"`
+		Change24h tickerValue `json:"change_24_hour"`
+		Timestamp tickerValue `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*t = TickerStats{
+		Market:    raw.Market,
+		LastPrice: float64(raw.LastPrice),
+		Bid:       float64(raw.Bid),
+		Ask:       float64(raw.Ask),
+		High:      float64(raw.High),
+		Low:       float64(raw.Low),
+		Volume:    float64(raw.Volume),
+		Change24h: float64(raw.Change24h),
+		Timestamp: int64(raw.Timestamp),
+	}
+	return nil
+}
+
+// tickerValue decodes a number, a numeric string, or an empty/null value as zero
+type tickerValue float64
+
+func (v *tickerValue) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" || text == `""` {
+		*v = 0
+		return nil
+	}
+	if len(text) >= 2 && text[0] == '"' {
+		text = text[1 : len(text)-1]
+	}
+	val, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fmt.Errorf("bad ticker value %s", data)
+	}
+	*v = tickerValue(val)
+	return nil
+}
+
+// Tickers indexes ticker entries by exchange market symbol
+type Tickers map[string]TickerStats
+
+// ParseTickers decodes an /exchange/ticker response
+func ParseTickers(body []byte) (Tickers, error) {
+	var entries []TickerStats
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, err
+	}
+
+	tickers := make(Tickers, len(entries))
+	for _, entry := range entries {
+		if entry.Market != "" {
+			tickers[entry.Market] = entry
+		}
+	}
+	return tickers, nil
+}
+
+// Get returns a market's ticker entry
+func (t Tickers) Get(market string) (TickerStats, bool) {
+	stats, ok := t[market]
+	return stats, ok
+}
+
+// Last returns a market's last traded price; ok is false when it has none
+func (t Tickers) Last(market string) (float64, bool) {
+	stats, ok := t[market]
+	return stats.LastPrice, ok && stats.LastPrice > 0
+}
+
+// Bid returns a market's best bid; ok is false when it has none
+func (t Tickers) Bid(market string) (float64, bool) {
+	stats, ok := t[market]
+	return stats.Bid, ok && stats.Bid > 0
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// TriangularLeg is one conversion in a cycle: trading From for To on a
+// single market, buying its coin at the ask or selling it at the bid
+type TriangularLeg struct {
+	Symbol  string  `json:"symbol"` // Market to place the order on, e.g. ETHBTC
+	Pair    string  `json:"pair"`   // Order book pair, e.g. B-ETH_BTC
+	Side    string  `json:"side"`   // buy: From is the quote; sell: From is the coin
+	From    string  `json:"from"`
+	To      string  `json:"to"`
+	Price   float64 `json:"price"`  // Best ask for a buy, best bid for a sell, in the quote
+	Volume  float64 `json:"volume"` // Coin available at Price
+	FeeRate float64 `json:"fee_rate"`
+}
+
+// Rate is how much To one unit of From converts to on this leg, after fees
+func (l TriangularLeg) Rate() float64 {
+	if l.Price <= 0 {
+		return 0
+	}
+	rate := l.Price
+	if l.Side == "buy" {
+		rate = 1 / l.Price
+	}
+	return rate * (1 - l.FeeRate)
+}
+
+// Quantity is the coin order size for converting amount of From
+func (l TriangularLeg) Quantity(amount float64) float64 {
+	if l.Side == "buy" {
+		if l.Price <= 0 {
+			return 0
+		}
+		return amount / l.Price
+	}
+	return amount
+}
+
+// TriangularOpportunity is a cycle of three trades on one exchange that
+// starts and ends in the same currency, e.g. USDT→BTC→ETH→USDT
+type TriangularOpportunity struct {
+	Start          string          `json:"start"`
+	Legs           []TriangularLeg `json:"legs"`
+	GrossReturnPct float64         `json:"gross_return_pct"` // Before fees
+	NetReturnPct   float64         `json:"net_return_pct"`   // After every leg's fee
+	MaxStart       float64         `json:"max_start"`        // Largest Start amount the best levels carry through
+	MaxStartINR    float64         `json:"max_start_inr"`
+	NetProfitINR   float64         `json:"net_profit_inr"` // At MaxStart
+	Viable         bool            `json:"viable"`
+	Timestamp      time.Time       `json:"timestamp"`
+	Run            *RunInfo        `json:"run,omitempty"`
+}
+
+// Route names the currencies in order, e.g. USDT → BTC → ETH → USDT
+func (t TriangularOpportunity) Route() string {
+	route := []string{t.Start}
+	for _, leg := range t.Legs {
+		route = append(route, leg.To)
+	}
+	return strings.Join(route, " → ")
+}
+
+// LegQuantities returns each leg's coin order size when the cycle starts
+// with amount of Start, assuming every leg fills at its quoted price
+func (t TriangularOpportunity) LegQuantities(amount float64) []float64 {
+	quantities := make([]float64, 0, len(t.Legs))
+	for _, leg := range t.Legs {
+		quantities = append(quantities, leg.Quantity(amount))
+		amount *= leg.Rate()
+	}
+	return quantities
+}